@@ -30,6 +30,7 @@ import (
 	"github.com/baidu/ote-stack/pkg/clustershim"
 	"github.com/baidu/ote-stack/pkg/clustershim/handler"
 	"github.com/baidu/ote-stack/pkg/k8sclient"
+	"github.com/baidu/ote-stack/pkg/tunnel"
 )
 
 var (
@@ -64,6 +65,11 @@ func NewK3sClusterShimCommand() *cobra.Command {
 	cmd.PersistentFlags().StringVarP(&shimSock, "listen", "l",
 		":8262", "Websocket address of ClusterShim")
 	cmd.PersistentFlags().StringVarP(&kubeConfig, "kube-config", "k", "/root/.kube/config", "KubeConfig file path")
+	cmd.PersistentFlags().StringVar(&tunnel.TransportName, "tunnel-transport", tunnel.TransportName, "transport of tunnel to cluster controller, websocket or grpc, must be the same as cluster controller")
+	cmd.PersistentFlags().IntVar(&tunnel.MaxMessageSize, "max-message-size", tunnel.MaxMessageSize, "max size in bytes of a message to or from cluster controller")
+	cmd.PersistentFlags().IntVar(&tunnel.MessageChunkSize, "message-chunk-size", tunnel.MessageChunkSize, "messages larger than it are sent in chunks of it to cluster controller, 0 to disable")
+	cmd.PersistentFlags().DurationVar(&tunnel.HeartbeatInterval, "heartbeat-interval", tunnel.HeartbeatInterval, "interval to ping cluster controller, heartbeat is disabled if it is 0")
+	cmd.PersistentFlags().IntVar(&tunnel.HeartbeatMissThreshold, "heartbeat-miss-threshold", tunnel.HeartbeatMissThreshold, "number of heartbeat intervals without response after which cluster controller is considered dead")
 	fs := cmd.Flags()
 	fs.AddGoFlagSet(flag.CommandLine)
 
@@ -34,6 +34,7 @@ import (
 	"github.com/baidu/ote-stack/pkg/clustershim/handler"
 	"github.com/baidu/ote-stack/pkg/k8sclient"
 	"github.com/baidu/ote-stack/pkg/reporter"
+	"github.com/baidu/ote-stack/pkg/tunnel"
 )
 
 var (
@@ -74,6 +75,11 @@ func NewK8sClusterShimCommand() *cobra.Command {
 	cmd.PersistentFlags().StringVarP(&shimSock, "listen", "l",
 		":8262", "Websocket address of ClusterShim")
 	cmd.PersistentFlags().StringVarP(&kubeConfig, "kube-config", "k", "/root/.kube/config", "KubeConfig file path")
+	cmd.PersistentFlags().StringVar(&tunnel.TransportName, "tunnel-transport", tunnel.TransportName, "transport of tunnel to cluster controller, websocket or grpc, must be the same as cluster controller")
+	cmd.PersistentFlags().IntVar(&tunnel.MaxMessageSize, "max-message-size", tunnel.MaxMessageSize, "max size in bytes of a message to or from cluster controller")
+	cmd.PersistentFlags().IntVar(&tunnel.MessageChunkSize, "message-chunk-size", tunnel.MessageChunkSize, "messages larger than it are sent in chunks of it to cluster controller, 0 to disable")
+	cmd.PersistentFlags().DurationVar(&tunnel.HeartbeatInterval, "heartbeat-interval", tunnel.HeartbeatInterval, "interval to ping cluster controller, heartbeat is disabled if it is 0")
+	cmd.PersistentFlags().IntVar(&tunnel.HeartbeatMissThreshold, "heartbeat-miss-threshold", tunnel.HeartbeatMissThreshold, "number of heartbeat intervals without response after which cluster controller is considered dead")
 	cmd.PersistentFlags().StringVarP(&helmConfig, "helm-addr", "", "", "Helm proxy address")
 	cmd.PersistentFlags().BoolVarP(&lightweightReport, "lightweight-report", "r", false, "Lightweight reporting resources")
 	fs := cmd.Flags()
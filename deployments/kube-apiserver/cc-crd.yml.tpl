@@ -36,6 +36,10 @@ spec:
           properties:
             name:
               type: string
+            labels:
+              type: object
+              additionalProperties:
+                type: string
   version: v1
 ---
 apiVersion: apiextensions.k8s.io/v1beta1
@@ -53,6 +57,16 @@ spec:
     - ccs
     singular: clustercontroller
   scope: Namespaced
+  additionalPrinterColumns:
+    - name: Selector
+      type: string
+      JSONPath: .spec.clusterSelector
+    - name: Phase
+      type: string
+      JSONPath: .phase
+    - name: Age
+      type: date
+      JSONPath: .metadata.creationTimestamp
   validation:
     openAPIV3Schema:
       properties:
@@ -66,6 +80,21 @@ spec:
               type: string
             body:
               type: string
+            timeoutSeconds:
+              type: integer
+              minimum: 0
+            ttlSecondsAfterFinished:
+              type: integer
+              minimum: 0
+            retry:
+              type: object
+              properties:
+                limit:
+                  type: integer
+                  minimum: 0
+                intervalSeconds:
+                  type: integer
+                  minimum: 0
   version: v1
 ---
 apiVersion: rbac.authorization.k8s.io/v1beta1
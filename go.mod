@@ -9,6 +9,7 @@ require (
 	github.com/golang/protobuf v1.3.2
 	github.com/gorilla/mux v1.7.2
 	github.com/gorilla/websocket v1.4.0
+	github.com/klauspost/compress v1.11.13
 	github.com/pborman/uuid v1.2.0 // indirect
 	github.com/pkg/errors v0.9.1
 	github.com/rancher/dynamiclistener v0.2.0
@@ -0,0 +1,352 @@
+//go:build ignore
+// +build ignore
+
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+gen-zstd-dict trains the builtin zstd dictionary of tunnel messages.
+
+It makes EdgeReport messages of pods, nodes, deployments and services like reporters do,
+trains a dictionary on them by zstd cli, and writes it to pkg/tunnel/zz_generated.zstd_dict.go.
+
+	go run hack/gen-zstd-dict.go
+*/
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/golang/protobuf/proto"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/baidu/ote-stack/pkg/clustermessage"
+	"github.com/baidu/ote-stack/pkg/reporter"
+)
+
+const (
+	samples    = 2000
+	dictSize   = 8192
+	outputFile = "pkg/tunnel/zz_generated.zstd_dict.go"
+)
+
+var (
+	namespaces = []string{"default", "kube-system", "edge-apps", "monitoring"}
+	apps       = []string{"nginx", "redis", "mqtt-broker", "video-analyzer", "node-exporter", "coredns", "kube-proxy"}
+	images     = []string{"nginx:1.17", "redis:5.0", "eclipse-mosquitto:1.6", "registry.local/video-analyzer:v2.3.1",
+		"prom/node-exporter:v0.18.1", "k8s.gcr.io/coredns:1.6.2", "k8s.gcr.io/kube-proxy:v1.16.3"}
+)
+
+func name(app string) string {
+	return fmt.Sprintf("%s-%x-%s", app, rand.Int63()%0xffffffff, randString(5))
+}
+
+func randString(n int) string {
+	const letters = "bcdfghjklmnpqrstvwxz2456789"
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = letters[rand.Intn(len(letters))]
+	}
+	return string(b)
+}
+
+func ip() string {
+	return fmt.Sprintf("10.%d.%d.%d", rand.Intn(256), rand.Intn(256), rand.Intn(256))
+}
+
+func objectMeta(app, ns string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      name(app),
+		Namespace: ns,
+		Labels: map[string]string{
+			"app":                       app,
+			reporter.ClusterLabel:       fmt.Sprintf("edge-%d", rand.Intn(100)),
+			reporter.EdgeVersionLabel:   fmt.Sprintf("%d", rand.Intn(100000)),
+			"pod-template-hash":         randString(10),
+			"app.kubernetes.io/part-of": "ote",
+		},
+		ResourceVersion: fmt.Sprintf("%d", rand.Intn(10000000)),
+	}
+}
+
+func pod() *corev1.Pod {
+	i := rand.Intn(len(apps))
+	now := metav1.Now()
+	return &corev1.Pod{
+		TypeMeta:   metav1.TypeMeta{Kind: "Pod", APIVersion: "v1"},
+		ObjectMeta: objectMeta(apps[i], namespaces[rand.Intn(len(namespaces))]),
+		Spec: corev1.PodSpec{
+			NodeName: fmt.Sprintf("edge-node-%d", rand.Intn(50)),
+			Containers: []corev1.Container{{
+				Name:            apps[i],
+				Image:           images[i],
+				ImagePullPolicy: corev1.PullIfNotPresent,
+				Ports:           []corev1.ContainerPort{{ContainerPort: int32(8000 + rand.Intn(1000)), Protocol: corev1.ProtocolTCP}},
+				Resources: corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("500m"),
+						corev1.ResourceMemory: resource.MustParse("512Mi"),
+					},
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("100m"),
+						corev1.ResourceMemory: resource.MustParse("128Mi"),
+					},
+				},
+				VolumeMounts: []corev1.VolumeMount{{
+					Name:      "default-token-" + randString(5),
+					ReadOnly:  true,
+					MountPath: "/var/run/secrets/kubernetes.io/serviceaccount",
+				}},
+				TerminationMessagePath:   corev1.TerminationMessagePathDefault,
+				TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+			}},
+			Volumes: []corev1.Volume{{
+				Name: "default-token-" + randString(5),
+				VolumeSource: corev1.VolumeSource{
+					Secret: &corev1.SecretVolumeSource{SecretName: "default-token-" + randString(5)},
+				},
+			}},
+			Tolerations: []corev1.Toleration{
+				{Key: "node.kubernetes.io/not-ready", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute},
+				{Key: "node.kubernetes.io/unreachable", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute},
+			},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:         apps[i],
+				Ready:        true,
+				RestartCount: int32(rand.Intn(5)),
+				Image:        images[i],
+				ImageID:      "docker-pullable://" + images[i] + "@sha256:" + randString(64),
+				ContainerID:  "docker://" + randString(64),
+				State: corev1.ContainerState{
+					Running: &corev1.ContainerStateRunning{StartedAt: now},
+				},
+			}},
+		},
+	}
+}
+
+func node() *corev1.Node {
+	return &corev1.Node{
+		TypeMeta:   metav1.TypeMeta{Kind: "Node", APIVersion: "v1"},
+		ObjectMeta: objectMeta("edge-node", ""),
+		Status: corev1.NodeStatus{
+			Capacity: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(fmt.Sprintf("%d", 2+rand.Intn(6))),
+				corev1.ResourceMemory: resource.MustParse(fmt.Sprintf("%dKi", 2000000+rand.Intn(8000000))),
+				corev1.ResourcePods:   resource.MustParse("110"),
+			},
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(fmt.Sprintf("%d", 2+rand.Intn(6))),
+				corev1.ResourceMemory: resource.MustParse(fmt.Sprintf("%dKi", 2000000+rand.Intn(8000000))),
+				corev1.ResourcePods:   resource.MustParse("110"),
+			},
+			Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionFalse, Reason: "KubeletHasSufficientMemory",
+					Message: "kubelet has sufficient memory available", LastHeartbeatTime: metav1.Now()},
+				{Type: corev1.NodeDiskPressure, Status: corev1.ConditionFalse, Reason: "KubeletHasNoDiskPressure",
+					Message: "kubelet has no disk pressure", LastHeartbeatTime: metav1.Now()},
+				{Type: corev1.NodeReady, Status: corev1.ConditionTrue, Reason: "KubeletReady",
+					Message: "kubelet is posting ready status", LastHeartbeatTime: metav1.Now()},
+			},
+			Addresses: []corev1.NodeAddress{
+				{Type: corev1.NodeInternalIP, Address: ip()},
+				{Type: corev1.NodeHostName, Address: fmt.Sprintf("edge-node-%d", rand.Intn(50))},
+			},
+			NodeInfo: corev1.NodeSystemInfo{
+				MachineID:               randString(32),
+				SystemUUID:              randString(32),
+				KernelVersion:           "4.15.0-72-generic",
+				OSImage:                 "Ubuntu 18.04.3 LTS",
+				ContainerRuntimeVersion: "docker://19.3.5",
+				KubeletVersion:          "v1.16.3",
+				KubeProxyVersion:        "v1.16.3",
+				OperatingSystem:         "linux",
+				Architecture:            []string{"amd64", "arm64", "arm"}[rand.Intn(3)],
+			},
+		},
+	}
+}
+
+func deployment() *appsv1.Deployment {
+	i := rand.Intn(len(apps))
+	replicas := int32(1 + rand.Intn(3))
+	return &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+		ObjectMeta: objectMeta(apps[i], namespaces[rand.Intn(len(namespaces))]),
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": apps[i]}},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": apps[i]}},
+				Spec:       pod().Spec,
+			},
+			Strategy: appsv1.DeploymentStrategy{Type: appsv1.RollingUpdateDeploymentStrategyType},
+		},
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration: int64(rand.Intn(10)),
+			Replicas:           replicas,
+			UpdatedReplicas:    replicas,
+			ReadyReplicas:      replicas,
+			AvailableReplicas:  replicas,
+			Conditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue, Reason: "MinimumReplicasAvailable",
+					Message: "Deployment has minimum availability."},
+				{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionTrue, Reason: "NewReplicaSetAvailable",
+					Message: fmt.Sprintf("ReplicaSet \"%s\" has successfully progressed.", name(apps[i]))},
+			},
+		},
+	}
+}
+
+func service() *corev1.Service {
+	i := rand.Intn(len(apps))
+	port := int32(80 + rand.Intn(9000))
+	return &corev1.Service{
+		TypeMeta:   metav1.TypeMeta{Kind: "Service", APIVersion: "v1"},
+		ObjectMeta: objectMeta(apps[i], namespaces[rand.Intn(len(namespaces))]),
+		Spec: corev1.ServiceSpec{
+			Type:      corev1.ServiceTypeClusterIP,
+			ClusterIP: ip(),
+			Selector:  map[string]string{"app": apps[i]},
+			Ports: []corev1.ServicePort{{
+				Name:       "http",
+				Protocol:   corev1.ProtocolTCP,
+				Port:       port,
+				TargetPort: intstr.FromInt(int(port)),
+			}},
+			SessionAffinity: corev1.ServiceAffinityNone,
+		},
+	}
+}
+
+// report makes an EdgeReport body of a random resource type.
+func report() []byte {
+	var typ int
+	var status interface{}
+	n := 1 + rand.Intn(5)
+	switch rand.Intn(4) {
+	case 0:
+		s := reporter.PodResourceStatus{UpdateMap: map[string]*corev1.Pod{}}
+		for j := 0; j < n; j++ {
+			p := pod()
+			s.UpdateMap[p.Namespace+"/"+p.Name] = p
+		}
+		typ, status = reporter.ResourceTypePod, s
+	case 1:
+		s := reporter.NodeResourceStatus{UpdateMap: map[string]*corev1.Node{}}
+		for j := 0; j < n; j++ {
+			o := node()
+			s.UpdateMap[o.Name] = o
+		}
+		typ, status = reporter.ResourceTypeNode, s
+	case 2:
+		s := reporter.DeploymentResourceStatus{UpdateMap: map[string]*appsv1.Deployment{}}
+		for j := 0; j < n; j++ {
+			d := deployment()
+			s.UpdateMap[d.Namespace+"/"+d.Name] = d
+		}
+		typ, status = reporter.ResourceTypeDeployment, s
+	default:
+		s := reporter.ServiceResourceStatus{UpdateMap: map[string]*corev1.Service{}}
+		for j := 0; j < n; j++ {
+			o := service()
+			s.UpdateMap[o.Namespace+"/"+o.Name] = o
+		}
+		typ, status = reporter.ResourceTypeService, s
+	}
+	body, err := json.Marshal(status)
+	if err != nil {
+		panic(err)
+	}
+	data, err := json.Marshal(reporter.Reports{{ResourceType: typ, Body: body}})
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+func main() {
+	dir, err := ioutil.TempDir("", "zstd-dict")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	rand.Seed(2019)
+	for i := 0; i < samples; i++ {
+		msg := &clustermessage.ClusterMessage{
+			Head: &clustermessage.MessageHead{
+				Command:     clustermessage.CommandType_EdgeReport,
+				ClusterName: fmt.Sprintf("edge-%d", rand.Intn(100)),
+			},
+			Body: report(),
+		}
+		data, err := proto.Marshal(msg)
+		if err != nil {
+			panic(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, fmt.Sprintf("%d", i)), data, 0644); err != nil {
+			panic(err)
+		}
+	}
+
+	dictFile := filepath.Join(dir, "dict")
+	cmd := exec.Command("zstd", "--train", "-q", "-r", dir, "--maxdict="+fmt.Sprintf("%d", dictSize), "-o", dictFile)
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		panic(err)
+	}
+	dict, err := ioutil.ReadFile(dictFile)
+	if err != nil {
+		panic(err)
+	}
+
+	var out bytes.Buffer
+	boilerplate, err := ioutil.ReadFile("hack/boilerplate.go.txt")
+	if err != nil {
+		panic(err)
+	}
+	out.Write(bytes.TrimSpace(boilerplate))
+	out.WriteString("\n\n// Code generated by hack/gen-zstd-dict.go. DO NOT EDIT.\n\npackage tunnel\n\n")
+	out.WriteString("// builtinZstdDict is the zstd dictionary trained on EdgeReport messages of k8s objects.\n")
+	out.WriteString("var builtinZstdDict = []byte{")
+	for i, b := range dict {
+		if i%16 == 0 {
+			out.WriteString("\n\t")
+		} else {
+			out.WriteString(" ")
+		}
+		fmt.Fprintf(&out, "0x%02x,", b)
+	}
+	out.WriteString("\n}\n")
+	if err := ioutil.WriteFile(outputFile, out.Bytes(), 0644); err != nil {
+		panic(err)
+	}
+}
@@ -45,6 +45,34 @@ const (
 	ClusterStatusOffline = "offline"
 )
 
+// ClusterControllerStatus* describe the state of a ClusterController in a cluster
+// before it is processed there, should be set to ClusterControllerStatus.Body.
+const (
+	ClusterControllerStatusQueued  = "queued"  // queued since the cluster is offline
+	ClusterControllerStatusExpired = "expired" // expired before the cluster is online
+)
+
+// ClusterControllerPhase* describe the phase of a ClusterController over all selected clusters,
+// should be set to ClusterController.Phase.
+const (
+	ClusterControllerPhasePending            = "Pending"            // no selected cluster is dispatched yet
+	ClusterControllerPhaseDispatched         = "Dispatched"         // waiting for responses of selected clusters
+	ClusterControllerPhasePartiallySucceeded = "PartiallySucceeded" // some selected clusters succeeded
+	ClusterControllerPhaseSucceeded          = "Succeeded"          // all selected clusters succeeded
+	ClusterControllerPhaseFailed             = "Failed"             // no selected cluster succeeded
+	ClusterControllerPhaseTimedOut           = "TimedOut"           // timeout before all selected clusters responded
+)
+
+// ClusterControllerState* describe the state of a ClusterController in a cluster,
+// should be set to ClusterControllerStatus.State.
+const (
+	ClusterControllerStateDispatched = "Dispatched" // dispatched, no response yet
+	ClusterControllerStateQueued     = "Queued"     // queued since the cluster is offline
+	ClusterControllerStateSucceeded  = "Succeeded"  // responded with 2xx
+	ClusterControllerStateFailed     = "Failed"     // responded with other code
+	ClusterControllerStateNotReached = "NotReached" // no route to the cluster, or expired before reaching it
+)
+
 // ClusterNamespace defines the namespace of k8s crd must be in.
 // CRD out of the namespace won't be watched.
 const (
@@ -61,6 +89,11 @@ type ClusterController struct {
 
 	Spec   ClusterControllerSpec              `json:"spec"`
 	Status map[string]ClusterControllerStatus `json:"status"`
+	// Phase is the phase over all selected clusters, it is beside Status
+	// since Status is keyed by cluster names.
+	Phase string `json:"phase,omitempty"`
+	// FinishTimestamp is when Phase becomes Succeeded, PartiallySucceeded, Failed or TimedOut.
+	FinishTimestamp int64 `json:"finishTimestamp,omitempty"`
 }
 
 // ClusterControllerSpec is specification of a ClusterController.
@@ -73,6 +106,24 @@ type ClusterControllerSpec struct {
 	URL             string `json:"url"`
 
 	Body string `json:"body"`
+
+	// TimeoutSeconds is how long to wait for responses of selected clusters after creation,
+	// a default one is used if it is 0.
+	TimeoutSeconds int64 `json:"timeoutSeconds,omitempty"`
+	// Retry is how selected clusters not responded are dispatched again, they are not if it is nil.
+	Retry *ClusterControllerRetry `json:"retry,omitempty"`
+	// TTLSecondsAfterFinished is how long to keep the ClusterController after it finishes,
+	// it is deleted by garbage collector then, a default one is used if it is nil.
+	TTLSecondsAfterFinished *int64 `json:"ttlSecondsAfterFinished,omitempty"`
+}
+
+// ClusterControllerRetry is retry policy of a ClusterController.
+type ClusterControllerRetry struct {
+	// Limit is the max times a cluster is dispatched again.
+	Limit int `json:"limit"`
+	// IntervalSeconds is how long to wait for a response before dispatching again,
+	// a default one is used if it is 0.
+	IntervalSeconds int64 `json:"intervalSeconds,omitempty"`
 }
 
 // ClusterControllerStatus is status of a ClusterController.
@@ -80,6 +131,24 @@ type ClusterControllerStatus struct {
 	Timestamp  int64  `json:"timestamp"`
 	StatusCode int    `json:"code"`
 	Body       string `json:"body"`
+	// Path is the clusters the response is transmitted by, from the cluster to root.
+	Path []string `json:"path,omitempty"`
+
+	// State is the state of the ClusterController in the cluster, one of ClusterControllerState*.
+	State string `json:"state,omitempty"`
+	// Attempts is the times the cluster is dispatched.
+	Attempts int `json:"attempts,omitempty"`
+	// DispatchTimestamp is when the cluster is dispatched lastly.
+	DispatchTimestamp int64 `json:"dispatchTimestamp,omitempty"`
+
+	// Size is the size in bytes of the whole response body.
+	Size int `json:"size,omitempty"`
+	// Hash is the hash of the whole response body, e.g., sha256:<hex>.
+	Hash string `json:"hash,omitempty"`
+	// BodyRef refers to the whole response body in the response store, set if Body is truncated.
+	BodyRef string `json:"bodyRef,omitempty"`
+	// Truncated is true if Body is only the leading part of the response body.
+	Truncated bool `json:"truncated,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -106,6 +175,9 @@ type Cluster struct {
 // ClusterSpec is specification of a Cluster.
 type ClusterSpec struct {
 	Name string `json:"name"`
+	// Labels are set by the cluster when it registers, and can be edited here,
+	// ClusterSelector of a ClusterController selects clusters by them.
+	Labels map[string]string `json:"labels,omitempty"`
 }
 
 // ClusterStatus is status of a Cluster.
@@ -0,0 +1,241 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package clusterauth authenticates child clusters connecting to a cloud tunnel.
+/*
+A child presents a bootstrap token when it connects to its parent.
+The token looks like "<token-id>.<token-secret>", and is validated locally
+by the parent with tokens from two sources:
+
+1. secrets of type bootstrap.ote.baidu.com/token in kube-system,
+if the cluster can access a k8s apiserver.
+
+2. a token file, one token per line, if the cluster has no k8s apiserver.
+
+So each cluster in the tree validates tokens of its own subtree
+without a round-trip to root.
+
+A token with usage controller-manager, only from secrets since root always has a k8s apiserver,
+authenticates ote controller managers connecting to root instead of clusters.
+*/
+package clusterauth
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog"
+
+	"github.com/baidu/ote-stack/pkg/clusterselector"
+	"github.com/baidu/ote-stack/pkg/config"
+)
+
+const (
+	// TokenSecretType is the type of secret which stores a bootstrap token.
+	TokenSecretType = "bootstrap.ote.baidu.com/token"
+	// TokenSecretPrefix is the name prefix of secret which stores a bootstrap token.
+	TokenSecretPrefix = "ote-token-"
+
+	// keys in data of a token secret.
+	TokenIDKey              = "token-id"
+	TokenSecretKey          = "token-secret"
+	TokenExpirationKey      = "expiration"
+	TokenRevokedKey         = "revoked"
+	TokenClusterSelectorKey = "cluster-selector"
+	TokenUsageKey           = "usage"
+
+	// TokenUsageControllerManager is the usage of a token authenticating controller managers.
+	TokenUsageControllerManager = "controller-manager"
+)
+
+var (
+	tokenPattern = regexp.MustCompile(`^([a-z0-9]{6})\.([a-z0-9]{16})$`)
+
+	// ErrInvalidToken is returned if a token is malformed or unknown.
+	ErrInvalidToken = fmt.Errorf("invalid bootstrap token")
+	// ErrExpiredToken is returned if a token is expired.
+	ErrExpiredToken = fmt.Errorf("bootstrap token expired")
+	// ErrRevokedToken is returned if a token is revoked.
+	ErrRevokedToken = fmt.Errorf("bootstrap token revoked")
+)
+
+// Authenticator authenticates a cluster with the token it presents.
+type Authenticator interface {
+	// Authenticate returns nil if the cluster is allowed to connect with token.
+	Authenticate(token string, cr *config.ClusterRegistry) error
+}
+
+// Token is a bootstrap token.
+type Token struct {
+	ID     string
+	Secret string
+	// Expiration is the time after which the token is invalid, zero means never.
+	Expiration time.Time
+	Revoked    bool
+	// ClusterSelector restricts clusters which can use the token by name, empty means any.
+	// It must not be a label selector, since labels are reported by the cluster itself.
+	ClusterSelector string
+	// Usage is TokenUsageControllerManager for a token of controller managers, empty for clusters.
+	Usage string
+}
+
+// ParseToken splits a token string to id and secret.
+func ParseToken(s string) (string, string, error) {
+	parts := tokenPattern.FindStringSubmatch(s)
+	if parts == nil {
+		return "", "", ErrInvalidToken
+	}
+	return parts[1], parts[2], nil
+}
+
+// String returns the token string presented by a cluster.
+func (t *Token) String() string {
+	return t.ID + "." + t.Secret
+}
+
+// usable checks if the token is of usage and not revoked or expired at now.
+func (t *Token) usable(usage string, now time.Time) error {
+	if t.Usage != usage {
+		return ErrInvalidToken
+	}
+	if t.Revoked {
+		return ErrRevokedToken
+	}
+	if !t.Expiration.IsZero() && now.After(t.Expiration) {
+		return ErrExpiredToken
+	}
+	return nil
+}
+
+// checkClusterSelector checks if s is usable as cluster selector of a token.
+func checkClusterSelector(s string) error {
+	if clusterselector.IsLabelSelector(s) {
+		return fmt.Errorf("cluster selector %q of token must select names, not labels reported by clusters", s)
+	}
+	return nil
+}
+
+// valid checks if the token is usable now by cluster named clusterName.
+func (t *Token) valid(clusterName string, now time.Time) error {
+	if err := t.usable("", now); err != nil {
+		return err
+	}
+	if err := checkClusterSelector(t.ClusterSelector); err != nil {
+		return err
+	}
+	if t.ClusterSelector != "" && !clusterselector.NewSelector(t.ClusterSelector).Has(clusterName) {
+		return fmt.Errorf("bootstrap token %s is not allowed for cluster %s", t.ID, clusterName)
+	}
+	return nil
+}
+
+// TokenAuthenticator is an Authenticator which validates bootstrap tokens.
+// Tokens are added from several sources, each source owns its tokens.
+type TokenAuthenticator struct {
+	// source name -> token id -> token
+	tokens map[string]map[string]*Token
+	mutex  *sync.RWMutex
+}
+
+// NewTokenAuthenticator returns a TokenAuthenticator without tokens.
+func NewTokenAuthenticator() *TokenAuthenticator {
+	return &TokenAuthenticator{
+		tokens: make(map[string]map[string]*Token),
+		mutex:  &sync.RWMutex{},
+	}
+}
+
+// SetToken adds or updates a token of source.
+func (a *TokenAuthenticator) SetToken(source string, token *Token) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if _, ok := a.tokens[source]; !ok {
+		a.tokens[source] = make(map[string]*Token)
+	}
+	a.tokens[source][token.ID] = token
+	klog.V(3).Infof("bootstrap token %s from %s updated", token.ID, source)
+}
+
+// DeleteToken deletes a token of source.
+func (a *TokenAuthenticator) DeleteToken(source, id string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if tokens, ok := a.tokens[source]; ok {
+		delete(tokens, id)
+		klog.V(3).Infof("bootstrap token %s from %s deleted", id, source)
+	}
+}
+
+// ReplaceTokens replaces all tokens of source.
+func (a *TokenAuthenticator) ReplaceTokens(source string, tokens []*Token) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	m := make(map[string]*Token)
+	for _, t := range tokens {
+		m[t.ID] = t
+	}
+	a.tokens[source] = m
+	klog.V(3).Infof("%d bootstrap tokens from %s loaded", len(m), source)
+}
+
+// Authenticate validates token presented by cluster cr.
+func (a *TokenAuthenticator) Authenticate(token string, cr *config.ClusterRegistry) error {
+	if cr == nil {
+		return fmt.Errorf("cluster registry is nil")
+	}
+	now := time.Now()
+	return a.authenticate(token, func(t *Token) error {
+		return t.valid(cr.Name, now)
+	})
+}
+
+// AuthenticateControllerManager validates token presented by a controller manager.
+func (a *TokenAuthenticator) AuthenticateControllerManager(token string) error {
+	now := time.Now()
+	return a.authenticate(token, func(t *Token) error {
+		return t.usable(TokenUsageControllerManager, now)
+	})
+}
+
+// authenticate returns nil if any token of the id and secret of token passes valid.
+func (a *TokenAuthenticator) authenticate(token string, valid func(*Token) error) error {
+	id, secret, err := ParseToken(strings.TrimSpace(token))
+	if err != nil {
+		return err
+	}
+
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	ret := ErrInvalidToken
+	for _, tokens := range a.tokens {
+		t, ok := tokens[id]
+		if !ok || subtle.ConstantTimeCompare([]byte(t.Secret), []byte(secret)) != 1 {
+			continue
+		}
+		if ret = valid(t); ret == nil {
+			return nil
+		}
+	}
+	return ret
+}
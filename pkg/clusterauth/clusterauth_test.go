@@ -0,0 +1,275 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterauth
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	otev1 "github.com/baidu/ote-stack/pkg/apis/ote/v1"
+	"github.com/baidu/ote-stack/pkg/config"
+)
+
+func TestParseToken(t *testing.T) {
+	id, secret, err := ParseToken("abcdef.0123456789abcdef")
+	assert.Nil(t, err)
+	assert.Equal(t, "abcdef", id)
+	assert.Equal(t, "0123456789abcdef", secret)
+
+	_, _, err = ParseToken("")
+	assert.Equal(t, ErrInvalidToken, err)
+	_, _, err = ParseToken("ABCDEF.0123456789abcdef")
+	assert.Equal(t, ErrInvalidToken, err)
+	_, _, err = ParseToken("abcdef.0123")
+	assert.Equal(t, ErrInvalidToken, err)
+}
+
+func TestAuthenticate(t *testing.T) {
+	a := NewTokenAuthenticator()
+	cr := &config.ClusterRegistry{Name: "c1"}
+
+	assert.NotNil(t, a.Authenticate("abcdef.0123456789abcdef", nil))
+	assert.Equal(t, ErrInvalidToken, a.Authenticate("abcdef.0123456789abcdef", cr))
+
+	a.SetToken(secretSource, &Token{ID: "abcdef", Secret: "0123456789abcdef"})
+	assert.Nil(t, a.Authenticate("abcdef.0123456789abcdef", cr))
+	assert.Equal(t, ErrInvalidToken, a.Authenticate("abcdef.aaaaaaaaaaaaaaaa", cr))
+	assert.Equal(t, ErrInvalidToken, a.Authenticate("", cr))
+
+	// expired
+	a.SetToken(secretSource, &Token{ID: "abcdef", Secret: "0123456789abcdef",
+		Expiration: time.Now().Add(-time.Minute)})
+	assert.Equal(t, ErrExpiredToken, a.Authenticate("abcdef.0123456789abcdef", cr))
+
+	// revoked
+	a.SetToken(secretSource, &Token{ID: "abcdef", Secret: "0123456789abcdef", Revoked: true})
+	assert.Equal(t, ErrRevokedToken, a.Authenticate("abcdef.0123456789abcdef", cr))
+
+	// restricted by cluster selector
+	a.SetToken(secretSource, &Token{ID: "abcdef", Secret: "0123456789abcdef",
+		ClusterSelector: "^bj-"})
+	assert.NotNil(t, a.Authenticate("abcdef.0123456789abcdef", cr))
+	assert.Nil(t, a.Authenticate("abcdef.0123456789abcdef", &config.ClusterRegistry{Name: "bj-1"}))
+
+	// labels reported by the cluster are never matched, so spoofed ones are rejected
+	assert.NotNil(t, a.Authenticate("abcdef.0123456789abcdef",
+		&config.ClusterRegistry{Name: "c2", Labels: map[string]string{"region": "north"}}))
+	for _, selector := range []string{"region=north", "!gpu"} {
+		a.SetToken(secretSource, &Token{ID: "abcdef", Secret: "0123456789abcdef",
+			ClusterSelector: selector})
+		assert.NotNil(t, a.Authenticate("abcdef.0123456789abcdef", cr))
+		assert.NotNil(t, a.Authenticate("abcdef.0123456789abcdef",
+			&config.ClusterRegistry{Name: "c2", Labels: map[string]string{"region": "north"}}))
+	}
+
+	// token of another source is still valid
+	a.SetToken(fileSource, &Token{ID: "abcdef", Secret: "0123456789abcdef"})
+	assert.Nil(t, a.Authenticate("abcdef.0123456789abcdef", cr))
+
+	a.DeleteToken(fileSource, "abcdef")
+	a.DeleteToken(secretSource, "abcdef")
+	assert.Equal(t, ErrInvalidToken, a.Authenticate("abcdef.0123456789abcdef", cr))
+}
+
+func TestAuthenticateControllerManager(t *testing.T) {
+	a := NewTokenAuthenticator()
+	cr := &config.ClusterRegistry{Name: "c1"}
+
+	// a token of clusters does not authenticate controller managers
+	a.SetToken(secretSource, &Token{ID: "abcdef", Secret: "0123456789abcdef"})
+	assert.Equal(t, ErrInvalidToken, a.AuthenticateControllerManager("abcdef.0123456789abcdef"))
+	assert.Equal(t, ErrInvalidToken, a.AuthenticateControllerManager(""))
+
+	// and vice versa
+	a.SetToken(secretSource, &Token{ID: "ghijkl", Secret: "0123456789abcdef", Usage: TokenUsageControllerManager})
+	assert.Nil(t, a.AuthenticateControllerManager("ghijkl.0123456789abcdef"))
+	assert.Equal(t, ErrInvalidToken, a.Authenticate("ghijkl.0123456789abcdef", cr))
+	assert.Equal(t, ErrInvalidToken, a.AuthenticateControllerManager("ghijkl.aaaaaaaaaaaaaaaa"))
+
+	a.SetToken(secretSource, &Token{ID: "ghijkl", Secret: "0123456789abcdef", Usage: TokenUsageControllerManager,
+		Revoked: true})
+	assert.Equal(t, ErrRevokedToken, a.AuthenticateControllerManager("ghijkl.0123456789abcdef"))
+}
+
+func newTokenSecret(id, secret string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      TokenSecretPrefix + id,
+			Namespace: otev1.ClusterNamespace,
+		},
+		Type: TokenSecretType,
+		Data: map[string][]byte{
+			TokenIDKey:     []byte(id),
+			TokenSecretKey: []byte(secret),
+		},
+	}
+}
+
+func TestTokenFromSecret(t *testing.T) {
+	secret := newTokenSecret("abcdef", "0123456789abcdef")
+	token, err := TokenFromSecret(secret)
+	assert.Nil(t, err)
+	assert.Equal(t, "abcdef.0123456789abcdef", token.String())
+	assert.True(t, token.Expiration.IsZero())
+
+	secret.Data[TokenExpirationKey] = []byte("2019-01-01T00:00:00Z")
+	secret.Data[TokenRevokedKey] = []byte("true")
+	token, err = TokenFromSecret(secret)
+	assert.Nil(t, err)
+	assert.True(t, token.Revoked)
+	assert.Equal(t, 2019, token.Expiration.Year())
+
+	secret.Data[TokenExpirationKey] = []byte("tomorrow")
+	_, err = TokenFromSecret(secret)
+	assert.NotNil(t, err)
+
+	secret = newTokenSecret("abcdef", "0123456789abcdef")
+	secret.Data[TokenUsageKey] = []byte(TokenUsageControllerManager)
+	token, err = TokenFromSecret(secret)
+	assert.Nil(t, err)
+	assert.Equal(t, TokenUsageControllerManager, token.Usage)
+	secret.Data[TokenUsageKey] = []byte("admin")
+	_, err = TokenFromSecret(secret)
+	assert.NotNil(t, err)
+
+	secret = newTokenSecret("abcdef", "0123456789abcdef")
+	secret.Data[TokenClusterSelectorKey] = []byte("region=north")
+	_, err = TokenFromSecret(secret)
+	assert.NotNil(t, err)
+
+	secret = newTokenSecret("abcdef", "short")
+	_, err = TokenFromSecret(secret)
+	assert.NotNil(t, err)
+
+	secret = newTokenSecret("abcdef", "0123456789abcdef")
+	secret.Name = "other"
+	_, err = TokenFromSecret(secret)
+	assert.NotNil(t, err)
+
+	secret.Type = corev1.SecretTypeOpaque
+	_, err = TokenFromSecret(secret)
+	assert.NotNil(t, err)
+}
+
+func TestWatchSecrets(t *testing.T) {
+	secret := newTokenSecret("abcdef", "0123456789abcdef")
+	client := fake.NewSimpleClientset(secret)
+	a := NewTokenAuthenticator()
+	stop := make(chan struct{})
+	defer close(stop)
+	a.WatchSecrets(client, stop)
+
+	cr := &config.ClusterRegistry{Name: "c1"}
+	eventually(t, func() bool {
+		return a.Authenticate("abcdef.0123456789abcdef", cr) == nil
+	}, 5*time.Second, 100*time.Millisecond)
+
+	// a malformed secret drops the token.
+	bad := secret.DeepCopy()
+	bad.Data[TokenExpirationKey] = []byte("never")
+	_, err := client.CoreV1().Secrets(otev1.ClusterNamespace).Update(bad)
+	assert.Nil(t, err)
+	eventually(t, func() bool {
+		return a.Authenticate("abcdef.0123456789abcdef", cr) == ErrInvalidToken
+	}, 5*time.Second, 100*time.Millisecond)
+	_, err = client.CoreV1().Secrets(otev1.ClusterNamespace).Update(secret)
+	assert.Nil(t, err)
+	eventually(t, func() bool {
+		return a.Authenticate("abcdef.0123456789abcdef", cr) == nil
+	}, 5*time.Second, 100*time.Millisecond)
+
+	// revoke by delete the secret
+	err = client.CoreV1().Secrets(otev1.ClusterNamespace).Delete(secret.Name, &metav1.DeleteOptions{})
+	assert.Nil(t, err)
+	eventually(t, func() bool {
+		return a.Authenticate("abcdef.0123456789abcdef", cr) == ErrInvalidToken
+	}, 5*time.Second, 100*time.Millisecond)
+}
+
+func TestParseTokenFile(t *testing.T) {
+	data := []byte(`
+# comment
+abcdef.0123456789abcdef
+bcdefg.0123456789abcdef,2019-01-01T00:00:00Z
+cdefgh.0123456789abcdef,,^bj-,^sh-
+`)
+	tokens, err := ParseTokenFile(data)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(tokens))
+	assert.Equal(t, "abcdef", tokens[0].ID)
+	assert.Equal(t, 2019, tokens[1].Expiration.Year())
+	assert.True(t, tokens[2].Expiration.IsZero())
+	assert.Equal(t, "^bj-,^sh-", tokens[2].ClusterSelector)
+
+	_, err = ParseTokenFile([]byte("invalid"))
+	assert.NotNil(t, err)
+	_, err = ParseTokenFile([]byte("abcdef.0123456789abcdef,tomorrow"))
+	assert.NotNil(t, err)
+	_, err = ParseTokenFile([]byte("abcdef.0123456789abcdef,,region in (north)"))
+	assert.NotNil(t, err)
+}
+
+func TestLoadTokenFile(t *testing.T) {
+	f, err := ioutil.TempFile("", "tokens")
+	assert.Nil(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString("abcdef.0123456789abcdef\n")
+	assert.Nil(t, err)
+	f.Close()
+
+	oldDuration := tokenFileSyncDuration
+	tokenFileSyncDuration = 100 * time.Millisecond
+	defer func() { tokenFileSyncDuration = oldDuration }()
+
+	a := NewTokenAuthenticator()
+	stop := make(chan struct{})
+	defer close(stop)
+	assert.NotNil(t, a.LoadTokenFile("notexist", stop))
+	assert.Nil(t, a.LoadTokenFile(f.Name(), stop))
+
+	cr := &config.ClusterRegistry{Name: "c1"}
+	assert.Nil(t, a.Authenticate("abcdef.0123456789abcdef", cr))
+
+	// revoke by remove the line
+	assert.Nil(t, ioutil.WriteFile(f.Name(), []byte("bcdefg.0123456789abcdef\n"), 0600))
+	future := time.Now().Add(time.Minute)
+	assert.Nil(t, os.Chtimes(f.Name(), future, future))
+	eventually(t, func() bool {
+		return a.Authenticate("abcdef.0123456789abcdef", cr) == ErrInvalidToken &&
+			a.Authenticate("bcdefg.0123456789abcdef", cr) == nil
+	}, 5*time.Second, 100*time.Millisecond)
+}
+
+// eventually is assert.Eventually checking condition in the calling goroutine,
+// testify v1.4 may panic once a check started by a late tick ends after it returns.
+func eventually(t *testing.T, condition func() bool, waitFor, tick time.Duration, msgAndArgs ...interface{}) bool {
+	deadline := time.Now().Add(waitFor)
+	for !condition() {
+		if time.Now().After(deadline) {
+			return assert.Fail(t, "Condition never satisfied", msgAndArgs...)
+		}
+		time.Sleep(tick)
+	}
+	return true
+}
@@ -0,0 +1,213 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterauth
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	otev1 "github.com/baidu/ote-stack/pkg/apis/ote/v1"
+	"github.com/baidu/ote-stack/pkg/config"
+)
+
+const (
+	secretSource = "secret"
+	fileSource   = "file"
+)
+
+var (
+	tokenFileSyncDuration = 10 * time.Second
+)
+
+// TokenFromSecret gets a bootstrap token from a secret.
+func TokenFromSecret(secret *corev1.Secret) (*Token, error) {
+	if secret.Type != TokenSecretType {
+		return nil, fmt.Errorf("secret %s is not a bootstrap token", secret.Name)
+	}
+
+	id := string(secret.Data[TokenIDKey])
+	if secret.Name != TokenSecretPrefix+id {
+		return nil, fmt.Errorf("secret name %s does not match token id %s", secret.Name, id)
+	}
+	token := &Token{
+		ID:              id,
+		Secret:          string(secret.Data[TokenSecretKey]),
+		Revoked:         string(secret.Data[TokenRevokedKey]) == "true",
+		ClusterSelector: string(secret.Data[TokenClusterSelectorKey]),
+		Usage:           string(secret.Data[TokenUsageKey]),
+	}
+	if token.Usage != "" && token.Usage != TokenUsageControllerManager {
+		return nil, fmt.Errorf("secret %s has unknown usage %q", secret.Name, token.Usage)
+	}
+	if err := checkClusterSelector(token.ClusterSelector); err != nil {
+		return nil, fmt.Errorf("secret %s has invalid cluster selector: %v", secret.Name, err)
+	}
+	if _, _, err := ParseToken(token.String()); err != nil {
+		return nil, fmt.Errorf("secret %s has invalid token: %v", secret.Name, err)
+	}
+	if expiration := string(secret.Data[TokenExpirationKey]); expiration != "" {
+		t, err := time.Parse(time.RFC3339, expiration)
+		if err != nil {
+			return nil, fmt.Errorf("secret %s has invalid expiration: %v", secret.Name, err)
+		}
+		token.Expiration = t
+	}
+	return token, nil
+}
+
+// WatchSecrets keeps tokens in secrets of kube-system up to date until stop is closed.
+func (a *TokenAuthenticator) WatchSecrets(client kubernetes.Interface, stop <-chan struct{}) {
+	factory := informers.NewSharedInformerFactoryWithOptions(client,
+		config.K8sInformerSyncDuration*time.Second,
+		informers.WithNamespace(otev1.ClusterNamespace))
+	informer := factory.Core().V1().Secrets().Informer()
+
+	update := func(obj interface{}) {
+		secret, ok := obj.(*corev1.Secret)
+		if !ok || secret.Type != TokenSecretType {
+			return
+		}
+		token, err := TokenFromSecret(secret)
+		if err != nil {
+			// the token is dropped, so a token cannot be kept valid by a malformed secret.
+			klog.Errorf("get bootstrap token failed, token of secret %s is dropped: %v", secret.Name, err)
+			a.DeleteToken(secretSource, strings.TrimPrefix(secret.Name, TokenSecretPrefix))
+			return
+		}
+		a.SetToken(secretSource, token)
+	}
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: update,
+		UpdateFunc: func(old, new interface{}) {
+			update(new)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			secret, ok := obj.(*corev1.Secret)
+			if !ok || secret.Type != TokenSecretType {
+				return
+			}
+			a.DeleteToken(secretSource, strings.TrimPrefix(secret.Name, TokenSecretPrefix))
+		},
+	})
+	go informer.Run(stop)
+}
+
+/*
+ParseTokenFile parses content of a token file.
+Each line is "<token>[,<expiration in RFC3339>[,<cluster selector>]]",
+empty lines and lines start with # are ignored.
+Cluster selector must be the last column because it may contain comma.
+*/
+func ParseTokenFile(data []byte) ([]*Token, error) {
+	ret := make([]*Token, 0)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		columns := strings.SplitN(text, ",", 3)
+		id, secret, err := ParseToken(strings.TrimSpace(columns[0]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		token := &Token{ID: id, Secret: secret}
+		if len(columns) > 1 && strings.TrimSpace(columns[1]) != "" {
+			t, err := time.Parse(time.RFC3339, strings.TrimSpace(columns[1]))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid expiration: %v", line, err)
+			}
+			token.Expiration = t
+		}
+		if len(columns) > 2 {
+			token.ClusterSelector = strings.TrimSpace(columns[2])
+			if err := checkClusterSelector(token.ClusterSelector); err != nil {
+				return nil, fmt.Errorf("line %d: %v", line, err)
+			}
+		}
+		ret = append(ret, token)
+	}
+	return ret, scanner.Err()
+}
+
+// LoadTokenFile loads tokens from file, and reloads them once the file changed until stop is closed.
+// Revoke a token in the file by removing its line.
+func (a *TokenAuthenticator) LoadTokenFile(file string, stop <-chan struct{}) error {
+	modTime, err := a.loadTokenFile(file)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		ticker := time.NewTicker(tokenFileSyncDuration)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				info, err := os.Stat(file)
+				if err != nil {
+					klog.Errorf("stat token file %s failed: %v", file, err)
+					continue
+				}
+				if info.ModTime().Equal(modTime) {
+					continue
+				}
+				if t, err := a.loadTokenFile(file); err != nil {
+					klog.Errorf("reload token file failed: %v", err)
+				} else {
+					modTime = t
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+func (a *TokenAuthenticator) loadTokenFile(file string) (time.Time, error) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("stat token file %s failed: %v", file, err)
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("read token file %s failed: %v", file, err)
+	}
+	tokens, err := ParseTokenFile(data)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse token file %s failed: %v", file, err)
+	}
+	a.ReplaceTokens(fileSource, tokens)
+	return info.ModTime(), nil
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterhandler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/baidu/ote-stack/pkg/priorityqueue"
+)
+
+var (
+	// childQueueSize is the max number of messages waiting to be sent to a child.
+	childQueueSize = 10000
+	// childQueueFlushPeriod is the interval to check if queues are flushed.
+	childQueueFlushPeriod = 20 * time.Millisecond
+)
+
+// childQueue sends messages to a connected child by priority.
+type childQueue struct {
+	queue *priorityqueue.Queue
+	stop  chan struct{}
+}
+
+// childQueues keeps queues of connected childs.
+type childQueues struct {
+	mutex  sync.Mutex
+	childs map[string]*childQueue
+}
+
+// open starts the queue of child, messages are sent by send.
+func (q *childQueues) open(name string, send func([]byte) error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.childs == nil {
+		q.childs = make(map[string]*childQueue)
+	}
+	if _, ok := q.childs[name]; ok {
+		return
+	}
+	child := &childQueue{
+		queue: priorityqueue.NewQueue(name, childQueueSize, send),
+		stop:  make(chan struct{}),
+	}
+	q.childs[name] = child
+	go child.queue.Run(child.stop)
+}
+
+// get returns the queue of child, nil if it is not open.
+func (q *childQueues) get(name string) *priorityqueue.Queue {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if child, ok := q.childs[name]; ok {
+		return child.queue
+	}
+	return nil
+}
+
+// close stops the queue of child, messages not sent are finished with priorityqueue.ErrQueueClosed.
+func (q *childQueues) close(name string) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if child, ok := q.childs[name]; ok {
+		close(child.stop)
+		delete(q.childs, name)
+	}
+}
+
+// flush waits until messages in queues of all childs are sent or ctx is done.
+func (q *childQueues) flush(ctx context.Context) error {
+	return wait.PollImmediateUntil(childQueueFlushPeriod, func() (bool, error) {
+		return q.len() == 0, nil
+	}, ctx.Done())
+}
+
+// len returns number of messages in queues of all childs.
+func (q *childQueues) len() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	n := 0
+	for _, child := range q.childs {
+		n += child.queue.Len()
+	}
+	return n
+}
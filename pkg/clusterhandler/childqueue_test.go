@@ -0,0 +1,105 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterhandler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/baidu/ote-stack/pkg/clustermessage"
+	"github.com/baidu/ote-stack/pkg/config"
+	"github.com/baidu/ote-stack/pkg/priorityqueue"
+)
+
+func TestChildQueues(t *testing.T) {
+	q := &childQueues{}
+	assert.Nil(t, q.get("c1"))
+
+	sent := make(chan string, 10)
+	block := make(chan struct{})
+	q.open("c1", func(data []byte) error {
+		<-block
+		sent <- string(data)
+		return nil
+	})
+	queue := q.get("c1")
+	assert.NotNil(t, queue)
+
+	// the first message is being sent, routes go out before bulk messages queued meanwhile.
+	assert.Nil(t, queue.Push(clustermessage.Priority_Control, []byte("control"), nil))
+	time.Sleep(10 * time.Millisecond)
+	assert.Nil(t, queue.Push(clustermessage.Priority_Bulk, []byte("bulk"), nil))
+	assert.Nil(t, queue.Push(clustermessage.Priority_Route, []byte("route"), nil))
+	close(block)
+	for _, expect := range []string{"control", "route", "bulk"} {
+		assert.Equal(t, expect, <-sent)
+	}
+
+	// queue is stopped after close.
+	q.close("c1")
+	assert.Nil(t, q.get("c1"))
+	eventually(t, func() bool {
+		return queue.Push(clustermessage.Priority_Route, []byte("route"), nil) == priorityqueue.ErrQueueClosed
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestStopFlushChildQueues(t *testing.T) {
+	c := &clusterHandler{
+		conf: &config.ClusterControllerConfig{ClusterName: "c"},
+		tunn: newFakeCloudTunnel(),
+	}
+	sent := make(chan string, 10)
+	block := make(chan struct{})
+	c.queues.open("c1", func(data []byte) error {
+		<-block
+		sent <- string(data)
+		return nil
+	})
+	defer c.queues.close("c1")
+	assert.Nil(t, c.queues.get("c1").Push(clustermessage.Priority_Control, []byte("m1"), nil))
+	assert.Nil(t, c.queues.get("c1").Push(clustermessage.Priority_Control, []byte("m2"), nil))
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(block)
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.Nil(t, c.Stop(ctx))
+	assert.Equal(t, 0, c.queues.len())
+	assert.Equal(t, "m1", <-sent)
+	assert.Equal(t, "m2", <-sent)
+
+	// new childs are rejected once it is stopping.
+	assert.False(t, c.checkClusterName(&config.ClusterRegistry{Name: "c2"}))
+}
+
+// eventually is assert.Eventually checking condition in the calling goroutine,
+// testify v1.4 may panic once a check started by a late tick ends after it returns.
+func eventually(t *testing.T, condition func() bool, waitFor, tick time.Duration, msgAndArgs ...interface{}) bool {
+	deadline := time.Now().Add(waitFor)
+	for !condition() {
+		if time.Now().After(deadline) {
+			return assert.Fail(t, "Condition never satisfied", msgAndArgs...)
+		}
+		time.Sleep(tick)
+	}
+	return true
+}
@@ -20,22 +20,27 @@ limitations under the License.
 package clusterhandler
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
 
 	"github.com/golang/protobuf/proto"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog"
 
 	otev1 "github.com/baidu/ote-stack/pkg/apis/ote/v1"
+	"github.com/baidu/ote-stack/pkg/clusterauth"
 	"github.com/baidu/ote-stack/pkg/clustermessage"
 	"github.com/baidu/ote-stack/pkg/clusterrouter"
 	"github.com/baidu/ote-stack/pkg/clusterselector"
 	"github.com/baidu/ote-stack/pkg/config"
 	oteinformer "github.com/baidu/ote-stack/pkg/generated/informers/externalversions"
+	otelister "github.com/baidu/ote-stack/pkg/generated/listers/ote/v1"
 	"github.com/baidu/ote-stack/pkg/k8sclient"
+	"github.com/baidu/ote-stack/pkg/responsestore"
 	"github.com/baidu/ote-stack/pkg/tunnel"
 )
 
@@ -56,6 +61,10 @@ var (
 // Get one by NewClusterHandler and Start it.
 type ClusterHandler interface {
 	Start() error // nonblock
+	// Stop rejects new childs, flushes messages to connected childs,
+	// tells childs and controller managers it is going away and closes connections to them.
+	// ctx limits how long to flush and wait.
+	Stop(ctx context.Context) error
 }
 
 type clusterHandler struct {
@@ -65,10 +74,29 @@ type clusterHandler struct {
 	clusterControllerCRD *k8sclient.ClusterControllerCRD
 	k8sEnable            bool
 	rootClusterEnable    bool
+	authenticator        *clusterauth.TokenAuthenticator
+	events               *k8sclient.ClusterEvents
+	// listers of crds watched, set if k8s is enable
+	clusterLister           otelister.ClusterLister
+	clusterControllerLister otelister.ClusterControllerLister
+	// store of response bodies truncated in status, set if it is root and enabled
+	responseStore responsestore.Store
+	// childs connected only to be told of name conflicts
+	rejected rejectedChilds
+	// messages to offline childs
+	pending pendingQueue
+	// messages to connected childs
+	queues childQueues
+	// versions of subtree routes applied for each child
+	subtreeVersions subtreeVersions
 	// msg from clusters back to controller manager
 	backToControllerManagerChan chan clustermessage.ClusterMessage
 	// msg from controller manager to publish to clusters
 	controllerManagerPublishChan chan clustermessage.ClusterMessage
+	// stopper is closed once it is stopping.
+	stopper     chan struct{}
+	stopperInit sync.Once
+	stopOnce    sync.Once
 }
 
 // NewClusterHandler news a ClusterHandler by ClusterControllerConfig.
@@ -85,18 +113,31 @@ func NewClusterHandler(c *config.ClusterControllerConfig) (ClusterHandler, error
 	if err := ch.valid(); err != nil {
 		return nil, err
 	}
-	tunn := tunnel.NewCloudTunnel(c.TunnelListenAddr)
+	// name of a cluster with parent is set once connected to parent.
+	clusterrouter.Router().SetName(c.ClusterName)
+	tlsConfig, err := tunnel.NewServerTLSConfig(c.TunnelCertFile, c.TunnelKeyFile, c.TunnelCAFile)
+	if err != nil {
+		return nil, err
+	}
+	tunn := tunnel.NewCloudTunnel(c.TunnelListenAddr, tlsConfig)
 	if tunn == nil {
 		return nil, fmt.Errorf("tunnel is nil with no error, listen addr is " + c.TunnelListenAddr)
 	}
 	tunn.RegistRedirectFunc(func() string {
 		return c.LeaderListenAddr
 	})
+	if c.TokenAuthEnable {
+		ch.authenticator = clusterauth.NewTokenAuthenticator()
+		tunn.RegistAuthenticateFunc(ch.authenticator.Authenticate)
+		tunn.RegistControllerManagerAuthenticateFunc(ch.authenticator.AuthenticateControllerManager)
+	}
 	tunn.RegistCheckNameValidFunc(ch.checkClusterName)
 	tunn.RegistReturnMessageFunc(ch.handleMessageFromCloudTunnel)
 	tunn.RegistClientCloseHandler(ch.closeChild)
 	tunn.RegistAfterConnectHook(ch.afterClusterConnect)
 	tunn.RegistControllerManagerMsgHandler(ch.controllerMsgHandler)
+	tunn.RegistHTTPHandler(topologyURI, ch.topologyHandler)
+	tunn.RegistHTTPHandler(topologyTreeURI, ch.topologyTreeHandler)
 	ch.tunn = tunn
 	return ch, nil
 }
@@ -144,6 +185,12 @@ func (c *clusterHandler) valid() error {
 	if c.conf.TunnelListenAddr == "" {
 		return fmt.Errorf("listen tunn is empty, listen addr is " + c.conf.TunnelListenAddr)
 	}
+	if c.conf.TokenAuthEnable && c.conf.BootstrapTokenFile == "" && c.conf.KubeClient == nil {
+		return fmt.Errorf("token auth is enabled but no token source, set token file or k8s client")
+	}
+	if err := validDuplicateNamePolicy(DuplicateNamePolicy); err != nil {
+		return err
+	}
 
 	// if it is root, and root cc connects to shim, it can be a single root cluster.
 	if c.isRoot() && c.conf.RemoteShimAddr != "" {
@@ -172,6 +219,14 @@ func (c *clusterHandler) valid() error {
 		if c.clusterControllerCRD == nil {
 			return fmt.Errorf("cluster controller crd not init in root, please check kubeconfig")
 		}
+		if c.conf.KubeClient != nil {
+			c.events = k8sclient.NewClusterEvents(c.conf.KubeClient, "clustercontroller-"+c.conf.ClusterName)
+		}
+		store, err := c.newResponseStore()
+		if err != nil {
+			return err
+		}
+		c.responseStore = store
 	}
 	return nil
 }
@@ -181,6 +236,11 @@ func (c *clusterHandler) valid() error {
 // 2. handle message from parent,
 // 3. if k8s is configed, watch clustercontroller crd.
 func (c *clusterHandler) Start() error {
+	// load bootstrap tokens before accept any child
+	if err := c.startTokenSources(); err != nil {
+		return err
+	}
+
 	// start listen tunnel
 	if err := c.tunn.Start(); err != nil {
 		return err
@@ -192,6 +252,9 @@ func (c *clusterHandler) Start() error {
 	// handle message from parent
 	go c.handleMessageFromParent()
 
+	// drop expired messages of offline childs
+	go wait.Until(c.checkPendingMessages, pendingCheckPeriod, c.stopChan())
+
 	// watch k8s apiserver for clustercontroller crd if k8s is enable
 	if c.k8sEnable {
 		if c.rootClusterEnable {
@@ -205,8 +268,6 @@ func (c *clusterHandler) Start() error {
 			config.K8sInformerSyncDuration*time.Second,
 			oteinformer.WithNamespace(otev1.ClusterNamespace))
 		informer := factory.Ote().V1().ClusterControllers().Informer()
-		// actually, graceful stop is not supported
-		stopper := make(chan struct{})
 		// add handler
 		informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
 			AddFunc: func(obj interface{}) {
@@ -214,8 +275,31 @@ func (c *clusterHandler) Start() error {
 				klog.V(3).Infof("clustercontroller add %v", ca)
 				c.addClusterController(ca)
 			},
+			DeleteFunc: c.deleteResponses,
+		})
+		c.clusterControllerLister = factory.Ote().V1().ClusterControllers().Lister()
+		go informer.Run(c.stopChan())
+
+		// labels edited in cluster crd take precedence over the reported ones
+		clusterInformer := factory.Ote().V1().Clusters().Informer()
+		clusterInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				c.overrideClusterLabels(obj, false)
+			},
+			UpdateFunc: func(old, new interface{}) {
+				c.overrideClusterLabels(new, false)
+			},
+			DeleteFunc: func(obj interface{}) {
+				c.overrideClusterLabels(obj, true)
+			},
 		})
-		go informer.Run(stopper)
+		c.clusterLister = factory.Ote().V1().Clusters().Lister()
+		go clusterInformer.Run(c.stopChan())
+
+		// dispatch crds not done again, or time them out
+		if c.isRoot() {
+			go wait.Until(c.checkClusterControllers, lifecycleCheckPeriod, c.stopChan())
+		}
 	}
 
 	// if root cc connects to shim, it should handle message from shim.
@@ -233,24 +317,86 @@ func (c *clusterHandler) Start() error {
 	return nil
 }
 
+func (c *clusterHandler) Stop(ctx context.Context) error {
+	c.stopOnce.Do(func() {
+		close(c.stopChan())
+	})
+	klog.Infof("stop cluster handler")
+
+	if err := c.queues.flush(ctx); err != nil {
+		klog.Errorf("flush messages to childs failed: %v, %d are dropped", err, c.queues.len())
+	}
+	return c.tunn.Shutdown(ctx)
+}
+
+func (c *clusterHandler) stopChan() chan struct{} {
+	c.stopperInit.Do(func() {
+		c.stopper = make(chan struct{})
+	})
+	return c.stopper
+}
+
+// stopping checks if Stop is called.
+func (c *clusterHandler) stopping() bool {
+	select {
+	case <-c.stopChan():
+		return true
+	default:
+		return false
+	}
+}
+
+// startTokenSources loads bootstrap tokens of subtree if token auth is enabled.
+func (c *clusterHandler) startTokenSources() error {
+	if c.authenticator == nil {
+		return nil
+	}
+	stopper := c.stopChan()
+	if c.conf.BootstrapTokenFile != "" {
+		if err := c.authenticator.LoadTokenFile(c.conf.BootstrapTokenFile, stopper); err != nil {
+			return err
+		}
+	}
+	if c.conf.KubeClient != nil {
+		c.authenticator.WatchSecrets(c.conf.KubeClient, stopper)
+	}
+	return nil
+}
+
 /*
 addClusterController is k8s cluster controller crd watch AddFunc.
-1. tag parent name as self cluster name,
-2. send to child.
+root dispatches the crd by its lifecycle, other clusters send it to the selected clusters.
 */
 func (c *clusterHandler) addClusterController(cc *otev1.ClusterController) {
 	// check if crd is valid to process, drop it if invalid
 	if !hasToProcessClusterController(cc) {
 		return
 	}
+	if c.isRoot() && c.clusterControllerCRD != nil {
+		c.dispatchClusterController(cc.ObjectMeta.Namespace, cc.ObjectMeta.Name, time.Now())
+		return
+	}
+	c.sendClusterController(cc, cc.Spec.ClusterSelector)
+}
+
+/*
+sendClusterController sends crd to clusters selected by selector.
+1. tag parent name as self cluster name,
+2. send to child.
+*/
+func (c *clusterHandler) sendClusterController(cc *otev1.ClusterController, selector string) {
+	cc = cc.DeepCopy()
 	// add parentClusterName
 	cc.Spec.ParentClusterName = c.conf.ClusterName
+	cc.Spec.ClusterSelector = selector
 	// transfer crd to cluster message
 	msg := clusterControllerCRDToClusterMessage(cc, clustermessage.CommandType_ControlReq)
 	if msg == nil {
 		klog.Errorf("cluster msg is nil when add a crd %v", cc)
 		return
 	}
+	// crd is not processed after timeout, so is the message
+	msg.Head.ExpireTime = requestDeadline(cc).Unix()
 
 	// if root cc connects to shim, send to root edgehandler.
 	if c.rootClusterEnable {
@@ -266,6 +412,7 @@ func (c *clusterHandler) addClusterController(cc *otev1.ClusterController) {
 		klog.V(3).Infof("send %v to %s with selector %s", portMsg, port, portMsg.Head.ClusterSelector)
 		c.sendToChild(portMsg, port)
 	}
+	c.holdForOfflineChilds(msg)
 
 	// broadcast to all childs if do not use selector
 	// c.sendToChild(msg)
@@ -277,7 +424,7 @@ func selectChild(msg *clustermessage.ClusterMessage) map[string]*clustermessage.
 	var selectedSubTreeClusters []string
 	ret := make(map[string]*clustermessage.ClusterMessage)
 	for _, subtreeCluster := range subtreeClusters {
-		if selector.Has(subtreeCluster) {
+		if selector.Match(subtreeCluster, clusterrouter.Router().Labels(subtreeCluster)) {
 			selectedSubTreeClusters = append(selectedSubTreeClusters, subtreeCluster)
 		}
 	}
@@ -293,12 +440,16 @@ func selectChild(msg *clustermessage.ClusterMessage) map[string]*clustermessage.
 
 /*
 hasToProcessClusterController check if cluster controller crd is valid to process.
-process clustercontroller crd added in 1 hour and has no response.
+process clustercontroller crd not timed out and has no response,
+one already dispatched is dispatched again by its lifecycle in root.
 */
 func hasToProcessClusterController(ca *otev1.ClusterController) bool {
-	createTime := ca.ObjectMeta.CreationTimestamp
-	if !createTime.Add(1 * time.Hour).After(time.Now()) {
-		klog.V(1).Infof("clustercontroller %s created 1 hour ago", ca.ObjectMeta.Name)
+	if !requestDeadline(ca).After(time.Now()) {
+		klog.V(1).Infof("clustercontroller %s created %v ago", ca.ObjectMeta.Name, requestTimeout(ca))
+		return false
+	}
+	if ca.Phase != "" {
+		klog.V(1).Infof("clustercontroller %s is %s, do not do it", ca.ObjectMeta.Name, ca.Phase)
 		return false
 	}
 	if len(ca.Status) == 0 {
@@ -311,8 +462,9 @@ func hasToProcessClusterController(ca *otev1.ClusterController) bool {
 
 /*
 sendToChild send ClusterController to child.
-if tos is not empty, send cc to them,
+if tos is not empty, send cc to them by priority of the message,
 otherwise, broadcast message to all child.
+a control message failed to send is queued until the child connects again.
 */
 func (c *clusterHandler) sendToChild(msg *clustermessage.ClusterMessage, tos ...string) {
 	if msg == nil {
@@ -326,13 +478,37 @@ func (c *clusterHandler) sendToChild(msg *clustermessage.ClusterMessage, tos ...
 	}
 	if len(tos) == 0 {
 		go c.tunn.Broadcast(data)
-	} else {
-		for _, to := range tos {
-			go c.tunn.Send(to, data)
+		return
+	}
+
+	priority := clustermessage.PriorityOf(msg)
+	for _, to := range tos {
+		to := to
+		sent := func(err error) {
+			if err != nil {
+				go c.sendToChildFailed(msg, to, err)
+			}
+		}
+		queue := c.queues.get(to)
+		if queue == nil {
+			go func() {
+				sent(c.tunn.Send(to, data))
+			}()
+			continue
+		}
+		if err := queue.Push(priority, data, sent); err != nil {
+			sent(err)
 		}
 	}
 }
 
+func (c *clusterHandler) sendToChildFailed(msg *clustermessage.ClusterMessage, to string, err error) {
+	klog.Errorf("send message(%s) to child %s failed: %v", msg.Head.MessageID, to, err)
+	if isPendable(msg) {
+		c.holdForChild(msg, to, selectorClusters(msg.Head.ClusterSelector))
+	}
+}
+
 /*
 handleMessageFromParent handler message from parent(edge handler of this process).
 */
@@ -343,13 +519,17 @@ func (c *clusterHandler) handleMessageFromParent() {
 		// otherwise, send to child
 		if msg.Head.Command == clustermessage.CommandType_NeighborRoute {
 			clusterrouter.UpdateRouter(&msg, c.sendToChild)
-		} else {
+		} else if msg.Head.Command == clustermessage.CommandType_SubTreeRoute {
+			// ack of subtree routes is handled by edge handler.
+			continue
+		} else if c.hop(&msg) {
 			// directed broadcast by cluster selector
 			selectedChild := selectChild(&msg)
 			for port, portMsg := range selectedChild {
 				klog.V(3).Infof("send %v to %s with selector %s", portMsg, port, portMsg.Head.ClusterSelector)
 				c.sendToChild(portMsg, port)
 			}
+			c.holdForOfflineChilds(&msg)
 
 			// broadcast to all childs if do not use selector
 			// c.sendToChild(msg)
@@ -394,10 +574,20 @@ func (c *clusterHandler) checkClusterName(cr *config.ClusterRegistry) bool {
 		return false
 	}
 
+	// new childs go to other clusters while stopping.
+	if c.stopping() {
+		return false
+	}
+
 	if isInParentPool(cr.Name) {
 		return false
 	}
 
+	// a child with a conflicting name connects only to be told of the conflict.
+	if !c.resolveNameConflict(cr.Name, cr.Name) {
+		return c.rejected.get(cr.Name) != nil
+	}
+
 	cr.ParentName = c.conf.ClusterName
 	cc, err := cr.WrapperToClusterMessage(clustermessage.CommandType_ClusterRegist)
 	if err != nil {
@@ -421,8 +611,17 @@ func (c *clusterHandler) checkClusterName(cr *config.ClusterRegistry) bool {
 afterClusterConnect runs after connect to a child.
 */
 func (c *clusterHandler) afterClusterConnect(cr *config.ClusterRegistry) {
+	if c.sendToRejectedChild(cr.Name) {
+		return
+	}
+	// start the queue to send messages to child by priority
+	c.queues.open(cr.Name, func(data []byte) error {
+		return c.tunn.Send(cr.Name, data)
+	})
 	// add cluster to route
 	clusterrouter.Router().AddChild(cr.Name, cr.Listen, c.sendToChild)
+	// send messages queued when the child is offline
+	c.flushPendingMessages(cr.Name)
 }
 
 /*
@@ -446,6 +645,10 @@ func (c *clusterHandler) handleMessageFromChild(client string, data []byte) (ret
 		klog.Error(ret)
 		return
 	}
+	if c.rejected.get(client) != nil {
+		klog.V(3).Infof("drop message from rejected child %s", client)
+		return
+	}
 	// if the msg has no parentClusterName, set it to self
 	if msg.Head.ParentClusterName == "" {
 		msg.Head.ParentClusterName = c.conf.ClusterName
@@ -466,8 +669,17 @@ func (c *clusterHandler) handleMessageFromChild(client string, data []byte) (ret
 		ret = c.handleUnregistClusterMessage(client, msg)
 	case clustermessage.CommandType_SubTreeRoute:
 		c.updateRouteToSubtree(msg)
+	case clustermessage.CommandType_ClusterNameConflict:
+		if !c.isRoot() {
+			c.transmitToParent(msg)
+		} else if c.hop(msg) {
+			ret = c.handleNameConflictFromChild(msg)
+		}
 	default:
 		if c.isRoot() {
+			if !c.hop(msg) {
+				return
+			}
 			// send to controller manager
 			ret = c.sendToControllerManager(msg)
 			// TODO return error if failed
@@ -509,7 +721,7 @@ func (c *clusterHandler) isRoot() bool {
 /*
 handleRegistClusterMessage handle a cluster-regist message.
 once get a regist message, a cluster should do things below:
-1. TODO check if the cluster name is valid,
+1. resolve name conflict by DuplicateNamePolicy,
 2. write cluster info to k8s apiserver if self is root, else transmit to parent,
 3. record cluster router.
 */
@@ -524,16 +736,22 @@ func (c *clusterHandler) handleRegistClusterMessage(
 		return
 	}
 
+	if !c.resolveNameConflict(cr.Name, client) {
+		ret = fmt.Errorf("cluster %s from %s is not accepted: %v", cr.Name, client, config.ErrDuplicatedName)
+		klog.Error(ret)
+		return
+	}
+
 	// add the cluster to router
 	// and if failed to add, do not transmit to parent or save to k8s
 	err := clusterrouter.Router().AddRoute(cr.Name, client)
 	if err != nil {
-		// handle rename situation
-		// TODO make the new one reconnect
 		ret = fmt.Errorf("add route failed: %v", err)
 		klog.Error(ret)
 		return
 	}
+	clusterrouter.Router().SetLabels(cr.Name, cr.Labels)
+	clusterrouter.Router().SetParent(cr.Name, cr.ParentName)
 
 	if c.isRoot() {
 		if ret = c.createOrUpdateCluster(cluster); ret != nil {
@@ -557,8 +775,17 @@ func (c *clusterHandler) closeChild(cr *config.ClusterRegistry) {
 	if cr == nil {
 		return
 	}
+	if c.rejected.remove(cr.Name) {
+		klog.Infof("rejected child %s is disconnected", cr.Name)
+		return
+	}
 
 	cr.ParentName = c.conf.ClusterName
+	// remember subtree of child to queue messages to it
+	c.pending.offline(cr.Name, append(clusterrouter.Router().SubTreeOfPort(cr.Name), cr.Name))
+	// messages not sent to child are queued as pending
+	c.queues.close(cr.Name)
+	c.subtreeVersions.forget(cr.Name)
 	// delete child from route
 	clusterrouter.Router().DelChild(cr.Name, c.sendToChild)
 
@@ -596,6 +823,12 @@ func (c *clusterHandler) handleUnregistClusterMessage(
 		return
 	}
 
+	// the cluster is reached from another child, the one unregisting is a duplicate rejected or replaced.
+	if port, ok := clusterrouter.Router().Port(cluster.ObjectMeta.Name); ok && port != client {
+		klog.Infof("ignore unregist of cluster %s from %s, it is reached from %s",
+			cluster.ObjectMeta.Name, client, port)
+		return
+	}
 	clusterrouter.Router().DelRoute(cluster.ObjectMeta.Name, client)
 
 	if c.isRoot() {
@@ -623,13 +856,20 @@ mergeToApiserver merge response to etcd with mutex lock.
 cc is part of response to a cluster controller crd reqeust.
 */
 func (c *clusterHandler) mergeToApiserver(msg *clustermessage.ClusterMessage) error {
-	mergeToApiserverMutex.Lock()
-	defer mergeToApiserverMutex.Unlock()
 	// transfer cluster message to crd
 	cc := clusterMessageToClusterControllerCRD(msg)
 	if cc == nil {
 		return fmt.Errorf("transfer cluster message to crd failed")
 	}
+	// bodies are stored before locking, those not referred by status after merging are deleted after unlocking.
+	c.offloadResponses(cc)
+	var obsolete []string
+	defer func() {
+		c.deleteResponseBodies(obsolete)
+	}()
+
+	mergeToApiserverMutex.Lock()
+	defer mergeToApiserverMutex.Unlock()
 	// get clustercontroller crd by name
 	if origin := c.clusterControllerCRD.Get(cc.ObjectMeta.Namespace, cc.ObjectMeta.Name); origin != nil {
 		// merge status and update timestamp
@@ -637,19 +877,44 @@ func (c *clusterHandler) mergeToApiserver(msg *clustermessage.ClusterMessage) er
 		if new.Status == nil {
 			new.Status = make(map[string]otev1.ClusterControllerStatus)
 		}
+		var merged, replaced []string
 		for cn, s := range cc.Status {
+			s.State = responseState(s)
 			if originStatus, ok := origin.Status[cn]; !ok {
 				new.Status[cn] = s
+				merged = append(merged, s.BodyRef)
 			} else {
-				// update cluster status if timestamp is new
-				if originStatus.Timestamp < s.Timestamp {
+				// update cluster status if timestamp is new,
+				// or it is a response of the cluster to replace queued state
+				if originStatus.Timestamp < s.Timestamp ||
+					(originStatus.Timestamp == s.Timestamp && isPendingStatus(originStatus) && !isPendingStatus(s)) {
+					s.Attempts = originStatus.Attempts
+					s.DispatchTimestamp = originStatus.DispatchTimestamp
 					new.Status[cn] = s
+					merged = append(merged, s.BodyRef)
+					if originStatus.BodyRef != s.BodyRef {
+						replaced = append(replaced, originStatus.BodyRef)
+					}
+				} else {
+					obsolete = append(obsolete, s.BodyRef)
 				}
 			}
 		}
+		// phase of a crd dispatched by its lifecycle follows states of clusters
+		if new.Phase != "" {
+			setPhase(new, clusterControllerPhase(new), time.Now())
+		}
 		// update new to apiserver
 		klog.Infof("crd response update %s-%s", new.ObjectMeta.Namespace, new.ObjectMeta.Name)
-		c.clusterControllerCRD.Update(new)
+		if err := c.clusterControllerCRD.Update(new); err != nil {
+			obsolete = append(obsolete, merged...)
+			return err
+		}
+		obsolete = append(obsolete, replaced...)
+		return nil
+	}
+	for _, s := range cc.Status {
+		obsolete = append(obsolete, s.BodyRef)
 	}
 	return nil
 }
@@ -658,11 +923,24 @@ func (c *clusterHandler) mergeToApiserver(msg *clustermessage.ClusterMessage) er
 transmitToParent transmit message to parent asynchronously.
 */
 func (c *clusterHandler) transmitToParent(msg *clustermessage.ClusterMessage) {
+	if !c.hop(msg) {
+		return
+	}
 	go func() {
 		c.conf.ClusterToEdgeChan <- *msg
 	}()
 }
 
+// hop records msg is transmitted by this cluster, it returns false if msg is dropped for ttl or loop.
+func (c *clusterHandler) hop(msg *clustermessage.ClusterMessage) bool {
+	if err := clustermessage.Hop(msg, c.conf.ClusterName); err != nil {
+		klog.Warningf("drop message(%s) %s through %v: %v",
+			msg.Head.MessageID, msg.Head.Command.String(), msg.Head.Path, err)
+		return false
+	}
+	return true
+}
+
 func getClusterFromClusterController(cc *otev1.ClusterController) *otev1.Cluster {
 	// deserialize cluster
 	cluster, err := otev1.ClusterDeserialize([]byte(cc.Spec.Body))
@@ -693,7 +971,8 @@ func getClusterFromClusterRegistry(cr *config.ClusterRegistry) *otev1.Cluster {
 			Namespace: otev1.ClusterNamespace,
 		},
 		Spec: otev1.ClusterSpec{
-			Name: cr.UserDefineName,
+			Name:   cr.UserDefineName,
+			Labels: cr.Labels,
 		},
 		Status: otev1.ClusterStatus{
 			Listen:     cr.Listen,
@@ -703,24 +982,62 @@ func getClusterFromClusterRegistry(cr *config.ClusterRegistry) *otev1.Cluster {
 	}
 }
 
-// updateRouteToSubtree updates router to subtree of child.
+// overrideClusterLabels sets labels of a cluster crd to router, or removes them if the crd is deleted.
+func (c *clusterHandler) overrideClusterLabels(obj interface{}, deleted bool) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	cluster, ok := obj.(*otev1.Cluster)
+	if !ok {
+		return
+	}
+	if deleted {
+		clusterrouter.Router().OverrideLabels(cluster.Name, nil)
+		return
+	}
+	labels := cluster.Spec.Labels
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	clusterrouter.Router().OverrideLabels(cluster.Name, labels)
+}
+
+// updateRouteToSubtree updates router to subtree of child by a full sync or a delta.
 func (c *clusterHandler) updateRouteToSubtree(msg *clustermessage.ClusterMessage) error {
-	subtrees := clusterrouter.SubtreeFromClusterController(msg)
-	if subtrees == nil {
+	info := clusterrouter.SubtreeInfoFromClusterController(msg)
+	if info == nil {
 		return fmt.Errorf("subtree route is empty")
 	}
-	var err error
-	for to := range subtrees {
-		err = clusterrouter.Router().AddRoute(to, msg.Head.ClusterName)
-		if err != nil {
-			klog.Errorf("add subtree router %s-%s failed: %v", to, msg.Head.ClusterName, err)
+	child := msg.Head.ClusterName
+	switch {
+	case info.Version == 0:
+		// bare full sync of a child not knowing versions or not told yet,
+		// it is told once, so a child of old versions logs the unknown ack only once.
+		c.replaceRouteToSubtree(child, info)
+		if c.subtreeVersions.offer(child) {
+			c.sendSubtreeAck(child, &clusterrouter.SubtreeAck{Versioned: true})
 		}
-	}
-	// delete route from child but not in subtrees
-	childsOfChild := clusterrouter.Router().SubTreeOfPort(msg.Head.ClusterName)
-	for _, c := range childsOfChild {
-		if _, ok := subtrees[c]; !ok {
-			clusterrouter.Router().DelRoute(c, msg.Head.ClusterName)
+	case info.Base == 0:
+		c.replaceRouteToSubtree(child, info)
+		c.subtreeVersions.set(child, info.Version)
+		c.sendSubtreeAck(child, &clusterrouter.SubtreeAck{Version: info.Version})
+	default:
+		if version := c.subtreeVersions.get(child); version != info.Base {
+			klog.Warningf("subtree of %s is at version %d, delta is based on %d, ask for full sync",
+				child, version, info.Base)
+			c.resyncSubtree(child)
+			return nil
+		}
+		c.applySubtreeDelta(child, info)
+		c.subtreeVersions.set(child, info.Version)
+		if info.Checksum == "" {
+			return nil
+		}
+		checksum := clusterrouter.SubtreeChecksum(clusterrouter.Router().SubTreeOfPort(child))
+		if checksum != info.Checksum {
+			klog.Warningf("checksum of subtree of %s is %s, expect %s, ask for full sync",
+				child, checksum, info.Checksum)
+			c.resyncSubtree(child)
 		}
 	}
 
@@ -768,7 +1085,8 @@ func (c *clusterHandler) newRootCluster() *otev1.Cluster {
 			Namespace: otev1.ClusterNamespace,
 		},
 		Spec: otev1.ClusterSpec{
-			Name: c.conf.ClusterName,
+			Name:   c.conf.ClusterName,
+			Labels: c.conf.ClusterLabels,
 		},
 		Status: otev1.ClusterStatus{
 			Listen:     c.conf.TunnelListenAddr,
@@ -785,6 +1103,7 @@ func (c *clusterHandler) createOrUpdateCluster(cluster *otev1.Cluster) error {
 	if old == nil {
 		cluster.Status.Status = otev1.ClusterStatusOnline
 		cluster.Status.Timestamp = time.Now().Unix()
+		k8sclient.AnnotateReportedLabels(cluster)
 		c.clusterCRD.Create(cluster)
 	} else {
 		// update cluster status to online
@@ -793,6 +1112,10 @@ func (c *clusterHandler) createOrUpdateCluster(cluster *otev1.Cluster) error {
 		old.Status.Listen = cluster.Status.Listen
 		old.Status.ParentName = cluster.Status.ParentName
 
+		// labels set by the cluster replace those it set before, others edited in crd are kept
+		if err := c.clusterCRD.ReplaceReportedLabels(cluster); err != nil {
+			return fmt.Errorf("update cluster labels failed: %v", err)
+		}
 		err := c.clusterCRD.UpdateStatus(old)
 		if err != nil {
 			return fmt.Errorf("update cluster status failed: %v", err)
@@ -883,5 +1206,6 @@ func clusterMessageToClusterControllerStatusCRD(
 		Timestamp:  controllerTaskResp.Timestamp,
 		StatusCode: int(controllerTaskResp.StatusCode),
 		Body:       string(controllerTaskResp.Body),
+		Path:       msg.Head.Path,
 	}
 }
@@ -17,7 +17,9 @@ limitations under the License.
 package clusterhandler
 
 import (
+	"context"
 	"encoding/json"
+	"net/http"
 	"testing"
 	"time"
 
@@ -28,6 +30,7 @@ import (
 	otev1 "github.com/baidu/ote-stack/pkg/apis/ote/v1"
 	"github.com/baidu/ote-stack/pkg/clustermessage"
 	"github.com/baidu/ote-stack/pkg/clusterrouter"
+	"github.com/baidu/ote-stack/pkg/clusterselector"
 	"github.com/baidu/ote-stack/pkg/config"
 	oteclient "github.com/baidu/ote-stack/pkg/generated/clientset/versioned/fake"
 	"github.com/baidu/ote-stack/pkg/k8sclient"
@@ -68,6 +71,13 @@ func TestInit(t *testing.T) {
 	assert.Error(t, h.valid())
 	h.conf.ParentCluster = "parent"
 	assert.NoError(t, h.valid())
+	// token auth without token source
+	h.conf.TokenAuthEnable = true
+	assert.Error(t, h.valid())
+	h.conf.BootstrapTokenFile = "tokens"
+	assert.NoError(t, h.valid())
+	h.conf.TokenAuthEnable = false
+	h.conf.BootstrapTokenFile = ""
 
 	h2, err := NewClusterHandler(h.conf)
 	assert.Nil(t, err)
@@ -105,8 +115,24 @@ func TestSelectChild(t *testing.T) {
 	}
 	selected := selectChild(msg)
 	assert.Equal(t, 2, len(selected))
-	assert.Equal(t, "c3", selected["c1"].Head.ClusterSelector)
-	assert.Equal(t, "c5", selected["c4"].Head.ClusterSelector)
+	assert.Equal(t, "^c3$", selected["c1"].Head.ClusterSelector)
+	assert.Equal(t, "^c5$", selected["c4"].Head.ClusterSelector)
+
+	// exclude items
+	msg.Head.ClusterSelector = "^c,-=c1,-c[45]"
+	selected = selectChild(msg)
+	assert.Equal(t, 1, len(selected))
+	assert.ElementsMatch(t, []string{"c2", "c3"}, clusterselector.Clusters(selected["c1"].Head.ClusterSelector))
+
+	// label selector is resolved to names of selected clusters
+	clusterrouter.Router().SetLabels("c2", map[string]string{"region": "north", "tier": "edge"})
+	clusterrouter.Router().SetLabels("c3", map[string]string{"region": "north", "gpu": "true"})
+	clusterrouter.Router().SetLabels("c5", map[string]string{"region": "north", "tier": "mec"})
+	msg.Head.ClusterSelector = "region=north,tier in (edge,mec),!gpu"
+	selected = selectChild(msg)
+	assert.Equal(t, 2, len(selected))
+	assert.Equal(t, "^c2$", selected["c1"].Head.ClusterSelector)
+	assert.Equal(t, "^c5$", selected["c4"].Head.ClusterSelector)
 }
 
 func TestHasToProcessClusterController(t *testing.T) {
@@ -363,6 +389,50 @@ func TestHandleRegistClusterMessage(t *testing.T) {
 	assert.Nil(err)
 }
 
+func TestRegistClusterLabels(t *testing.T) {
+	assert := assert.New(t)
+	c := newFakeRootClusterHandler(t)
+	cr := &config.ClusterRegistry{
+		Name:   "l1",
+		Time:   time.Now().Unix(),
+		Labels: map[string]string{"region": "north"},
+	}
+	msg, err := cr.WrapperToClusterMessage(clustermessage.CommandType_ClusterRegist)
+	assert.Nil(err)
+	assert.Nil(c.handleRegistClusterMessage("l1", msg))
+	assert.Equal(map[string]string{"region": "north"}, clusterrouter.Router().Labels("l1"))
+	cluster := c.clusterCRD.Get(otev1.ClusterNamespace, "l1")
+	assert.NotNil(cluster)
+	assert.Equal(map[string]string{"region": "north"}, cluster.Spec.Labels)
+
+	// labels edited in crd are kept, labels set by cluster are overwritten
+	cluster.Spec.Labels["zone"] = "a"
+	c.clusterCRD.Update(cluster)
+	cr.Time++
+	cr.Labels = map[string]string{"region": "south"}
+	msg, err = cr.WrapperToClusterMessage(clustermessage.CommandType_ClusterRegist)
+	assert.Nil(err)
+	assert.Nil(c.handleRegistClusterMessage("l1", msg))
+	cluster = c.clusterCRD.Get(otev1.ClusterNamespace, "l1")
+	assert.Equal(map[string]string{"region": "south", "zone": "a"}, cluster.Spec.Labels)
+
+	// labels no longer set by cluster are removed, labels edited in crd are kept
+	cr.Time++
+	cr.Labels = map[string]string{"tier": "edge"}
+	msg, err = cr.WrapperToClusterMessage(clustermessage.CommandType_ClusterRegist)
+	assert.Nil(err)
+	assert.Nil(c.handleRegistClusterMessage("l1", msg))
+	assert.Equal(map[string]string{"tier": "edge"}, clusterrouter.Router().Labels("l1"))
+	cluster = c.clusterCRD.Get(otev1.ClusterNamespace, "l1")
+	assert.Equal(map[string]string{"tier": "edge", "zone": "a"}, cluster.Spec.Labels)
+
+	// labels of crd take precedence
+	c.overrideClusterLabels(cluster, false)
+	assert.Equal(map[string]string{"tier": "edge", "zone": "a"}, clusterrouter.Router().Labels("l1"))
+	c.overrideClusterLabels(cluster, true)
+	assert.Equal(map[string]string{"tier": "edge"}, clusterrouter.Router().Labels("l1"))
+}
+
 func TestHandleUnregistClusterMessage(t *testing.T) {
 	assert := assert.New(t)
 	c := newFakeRootClusterHandler(t)
@@ -416,6 +486,17 @@ func TestUpdateRouteToSubtree(t *testing.T) {
 	msg.Body = ccbytes
 	err = c.updateRouteToSubtree(msg)
 	assert.Nil(err)
+	// subtree route with labels
+	ccbytes, err = json.Marshal(map[string]interface{}{
+		"routes": clusterrouter.SubTreeRouter{"c2": "c1", "c3": "c1"},
+		"labels": map[string]map[string]string{"c3": {"region": "north"}},
+	})
+	assert.Nil(err)
+	msg.Body = ccbytes
+	err = c.updateRouteToSubtree(msg)
+	assert.Nil(err)
+	assert.True(clusterrouter.Router().HasRoute("c3", "c1"))
+	assert.Equal(map[string]string{"region": "north"}, clusterrouter.Router().Labels("c3"))
 }
 
 type fakeCloudTunnel struct {
@@ -443,6 +524,10 @@ func (f *fakeCloudTunnel) Stop() error {
 	return nil
 }
 
+func (f *fakeCloudTunnel) Shutdown(ctx context.Context) error {
+	return nil
+}
+
 func (f *fakeCloudTunnel) Send(clusterName string, msg []byte) error {
 	f.sendCalled = true
 	return nil
@@ -463,6 +548,10 @@ func (f *fakeCloudTunnel) SendToControllerManager(msg []byte) error {
 
 func (f *fakeCloudTunnel) RegistRedirectFunc(fn tunnel.RedirectFunc) {}
 
+func (f *fakeCloudTunnel) RegistAuthenticateFunc(fn tunnel.ClusterAuthenticator) {}
+
+func (f *fakeCloudTunnel) RegistControllerManagerAuthenticateFunc(fn tunnel.ControllerManagerAuthenticator) {}
+
 func (f *fakeCloudTunnel) RegistCheckNameValidFunc(fn tunnel.ClusterNameChecker) {}
 
 func (f *fakeCloudTunnel) RegistAfterConnectHook(fn tunnel.AfterConnectHook) {}
@@ -475,6 +564,14 @@ func (f *fakeCloudTunnel) RegistControllerManagerMsgHandler(
 	fn tunnel.ControllerManagerMsgHandleFunc) {
 }
 
+func (f *fakeCloudTunnel) RegistHTTPHandler(path string, fn http.HandlerFunc) {}
+
+func (f *fakeCloudTunnel) ChildLinks() map[string]tunnel.LinkInfo {
+	return map[string]tunnel.LinkInfo{
+		"c1": {ConnectedAt: time.Unix(100, 0)},
+	}
+}
+
 func newFakeRootClusterHandler(t *testing.T) *clusterHandler {
 	ret := &clusterHandler{
 		conf: &config.ClusterControllerConfig{
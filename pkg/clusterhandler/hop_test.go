@@ -0,0 +1,70 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterhandler
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/baidu/ote-stack/pkg/clustermessage"
+	"github.com/baidu/ote-stack/pkg/config"
+)
+
+func TestTransmitToParentHop(t *testing.T) {
+	c := &clusterHandler{
+		conf: &config.ClusterControllerConfig{
+			ClusterName:       "c1",
+			ClusterToEdgeChan: make(chan clustermessage.ClusterMessage, 10),
+		},
+	}
+
+	// message already transmitted by this cluster is dropped.
+	c.transmitToParent(&clustermessage.ClusterMessage{
+		Head: &clustermessage.MessageHead{MessageID: "loop", Path: []string{"c2", "c1"}},
+	})
+	// message whose ttl is exhausted is dropped.
+	c.transmitToParent(&clustermessage.ClusterMessage{
+		Head: &clustermessage.MessageHead{MessageID: "ttl", TTL: 1, Path: []string{"c2"}},
+	})
+	c.transmitToParent(&clustermessage.ClusterMessage{
+		Head: &clustermessage.MessageHead{MessageID: "ok", Path: []string{"c2"}},
+	})
+	msg := <-c.conf.ClusterToEdgeChan
+	assert.Equal(t, "ok", msg.Head.MessageID)
+	assert.Equal(t, []string{"c2", "c1"}, msg.Head.Path)
+	assert.Equal(t, clustermessage.DefaultMessageTTL-1, msg.Head.TTL)
+	assert.Equal(t, 0, len(c.conf.ClusterToEdgeChan))
+}
+
+func TestStatusPath(t *testing.T) {
+	body, err := proto.Marshal(&clustermessage.ControllerTaskResponse{StatusCode: 200})
+	assert.Nil(t, err)
+	msg := &clustermessage.ClusterMessage{
+		Head: &clustermessage.MessageHead{
+			ClusterName: "c2",
+			Command:     clustermessage.CommandType_ControlResp,
+			Path:        []string{"c2", "c1", "root"},
+		},
+		Body: body,
+	}
+	name, status := clusterMessageToClusterControllerStatusCRD(msg)
+	assert.Equal(t, "c2", name)
+	assert.Equal(t, 200, status.StatusCode)
+	assert.Equal(t, []string{"c2", "c1", "root"}, status.Path)
+}
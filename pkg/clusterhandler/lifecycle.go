@@ -0,0 +1,342 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterhandler
+
+import (
+	"net/http"
+	"reflect"
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog"
+
+	otev1 "github.com/baidu/ote-stack/pkg/apis/ote/v1"
+	"github.com/baidu/ote-stack/pkg/clusterrouter"
+	"github.com/baidu/ote-stack/pkg/clusterselector"
+)
+
+/*
+Root drives a ClusterController crd through phases, and keeps the state of every selected cluster in its status.
+
+A new crd is dispatched to the selected clusters reachable from root, including those queued for offline childs,
+selected clusters known by Cluster crds but unreachable are NotReached.
+Phase is Dispatched once any cluster is dispatched, and Pending before that.
+Responses update states of clusters, Phase becomes Succeeded, PartiallySucceeded or Failed
+once no response is waited for.
+
+Crds in Pending or Dispatched phase are checked every lifecycleCheckPeriod:
+* selected clusters never dispatched are dispatched once they are reachable,
+* clusters dispatched without response, or not reached, are dispatched again
+  every Spec.Retry.IntervalSeconds up to Spec.Retry.Limit times,
+  and are NotReached if no response is got in the interval after the last time,
+* Phase becomes TimedOut after Spec.TimeoutSeconds, clusters still waited for are NotReached.
+A cluster responded is never dispatched again.
+*/
+
+var (
+	// DefaultRequestTimeout is how long to wait for responses of a crd without timeoutSeconds.
+	DefaultRequestTimeout = 1 * time.Hour
+	// DefaultRetryInterval is the retry interval of a crd without intervalSeconds.
+	DefaultRetryInterval = 1 * time.Minute
+
+	lifecycleCheckPeriod = 10 * time.Second
+)
+
+func requestTimeout(cc *otev1.ClusterController) time.Duration {
+	if cc.Spec.TimeoutSeconds > 0 {
+		return time.Duration(cc.Spec.TimeoutSeconds) * time.Second
+	}
+	return DefaultRequestTimeout
+}
+
+// requestDeadline returns when cc times out.
+func requestDeadline(cc *otev1.ClusterController) time.Time {
+	return cc.ObjectMeta.CreationTimestamp.Add(requestTimeout(cc))
+}
+
+func retryInterval(cc *otev1.ClusterController) time.Duration {
+	if cc.Spec.Retry != nil && cc.Spec.Retry.IntervalSeconds > 0 {
+		return time.Duration(cc.Spec.Retry.IntervalSeconds) * time.Second
+	}
+	return DefaultRetryInterval
+}
+
+// isPhaseDone returns if phase is final, a crd in it is not dispatched any more.
+func isPhaseDone(phase string) bool {
+	switch phase {
+	case otev1.ClusterControllerPhaseSucceeded, otev1.ClusterControllerPhasePartiallySucceeded,
+		otev1.ClusterControllerPhaseFailed, otev1.ClusterControllerPhaseTimedOut:
+		return true
+	}
+	return false
+}
+
+// responseState returns state of a cluster by its response.
+func responseState(s otev1.ClusterControllerStatus) string {
+	switch {
+	case isPendingStatus(s):
+		return otev1.ClusterControllerStateQueued
+	case s.StatusCode == http.StatusGatewayTimeout && s.Body == otev1.ClusterControllerStatusExpired:
+		return otev1.ClusterControllerStateNotReached
+	case s.StatusCode >= 200 && s.StatusCode < 300:
+		return otev1.ClusterControllerStateSucceeded
+	default:
+		return otev1.ClusterControllerStateFailed
+	}
+}
+
+// isAnswered returns if the cluster responded by itself.
+func isAnswered(state string) bool {
+	return state == otev1.ClusterControllerStateSucceeded || state == otev1.ClusterControllerStateFailed
+}
+
+// isWaiting returns if a response of the cluster is still waited for.
+func isWaiting(s otev1.ClusterControllerStatus, retry *otev1.ClusterControllerRetry) bool {
+	switch s.State {
+	case otev1.ClusterControllerStateDispatched, otev1.ClusterControllerStateQueued:
+		return true
+	case otev1.ClusterControllerStateNotReached:
+		return s.Attempts == 0 || (retry != nil && s.Attempts <= retry.Limit)
+	}
+	return false
+}
+
+// isDue returns if the cluster should be dispatched at now.
+func isDue(cc *otev1.ClusterController, s otev1.ClusterControllerStatus, now time.Time) bool {
+	// a queued message is sent once the child connects.
+	if isAnswered(s.State) || s.State == otev1.ClusterControllerStateQueued {
+		return false
+	}
+	if s.Attempts == 0 {
+		return true
+	}
+	retry := cc.Spec.Retry
+	if retry == nil || s.Attempts > retry.Limit {
+		return false
+	}
+	return isRetryIntervalPassed(cc, s, now)
+}
+
+// isGivenUp returns if the cluster dispatched is not waited for at now since no retry is left.
+func isGivenUp(cc *otev1.ClusterController, s otev1.ClusterControllerStatus, now time.Time) bool {
+	retry := cc.Spec.Retry
+	return s.State == otev1.ClusterControllerStateDispatched &&
+		retry != nil && s.Attempts > retry.Limit && isRetryIntervalPassed(cc, s, now)
+}
+
+func isRetryIntervalPassed(cc *otev1.ClusterController, s otev1.ClusterControllerStatus, now time.Time) bool {
+	return !time.Unix(s.DispatchTimestamp, 0).Add(retryInterval(cc)).After(now)
+}
+
+// clusterControllerPhase returns phase of cc by states of selected clusters.
+func clusterControllerPhase(cc *otev1.ClusterController) string {
+	if cc.Phase == otev1.ClusterControllerPhaseTimedOut {
+		return cc.Phase
+	}
+	succeeded := 0
+	dispatched, waiting := false, false
+	for _, s := range cc.Status {
+		if s.State == otev1.ClusterControllerStateSucceeded {
+			succeeded++
+		}
+		if s.Attempts > 0 || isAnswered(s.State) {
+			dispatched = true
+		}
+		if isWaiting(s, cc.Spec.Retry) {
+			waiting = true
+		}
+	}
+	var phase string
+	switch {
+	case !dispatched:
+		phase = otev1.ClusterControllerPhasePending
+	case waiting:
+		phase = otev1.ClusterControllerPhaseDispatched
+	case succeeded == len(cc.Status):
+		phase = otev1.ClusterControllerPhaseSucceeded
+	case succeeded > 0:
+		phase = otev1.ClusterControllerPhasePartiallySucceeded
+	default:
+		phase = otev1.ClusterControllerPhaseFailed
+	}
+	// a late response does not bring a crd done back.
+	if isPhaseDone(cc.Phase) && !isPhaseDone(phase) {
+		return cc.Phase
+	}
+	return phase
+}
+
+// setPhase sets phase of cc, and the time it finishes at now if phase is done.
+func setPhase(cc *otev1.ClusterController, phase string, now time.Time) {
+	if isPhaseDone(phase) && !isPhaseDone(cc.Phase) {
+		cc.FinishTimestamp = now.Unix()
+	}
+	cc.Phase = phase
+}
+
+// timeoutClusterController marks clusters still waited for as NotReached, and cc as TimedOut.
+func timeoutClusterController(cc *otev1.ClusterController, now time.Time) {
+	for name, s := range cc.Status {
+		if s.State == otev1.ClusterControllerStateDispatched || s.State == otev1.ClusterControllerStateQueued {
+			s.State = otev1.ClusterControllerStateNotReached
+			cc.Status[name] = s
+		}
+	}
+	setPhase(cc, otev1.ClusterControllerPhaseTimedOut, now)
+}
+
+/*
+advanceClusterController moves cc forward at now, clusters due are marked in status of cc and returned,
+they should be dispatched once cc is updated.
+*/
+func (c *clusterHandler) advanceClusterController(cc *otev1.ClusterController, now time.Time) []string {
+	if cc.Status == nil {
+		cc.Status = make(map[string]otev1.ClusterControllerStatus)
+	}
+	if !requestDeadline(cc).After(now) {
+		timeoutClusterController(cc, now)
+		return nil
+	}
+
+	reachable, known := c.selectedClusters(cc)
+	var dispatched []string
+	for _, cluster := range known {
+		s := cc.Status[cluster]
+		if !isDue(cc, s, now) {
+			continue
+		}
+		if reachable[cluster] {
+			s.State = otev1.ClusterControllerStateDispatched
+			s.Attempts++
+			s.DispatchTimestamp = now.Unix()
+			dispatched = append(dispatched, cluster)
+		} else {
+			s.State = otev1.ClusterControllerStateNotReached
+		}
+		cc.Status[cluster] = s
+	}
+	for cluster, s := range cc.Status {
+		if isGivenUp(cc, s, now) {
+			s.State = otev1.ClusterControllerStateNotReached
+			cc.Status[cluster] = s
+		}
+	}
+	setPhase(cc, clusterControllerPhase(cc), now)
+	return dispatched
+}
+
+/*
+selectedClusters returns clusters selected by cc, reachable ones are in subtree routes
+or in subtree of offline childs, others are known by Cluster crds.
+a root connected to shim handles cc by itself.
+*/
+func (c *clusterHandler) selectedClusters(cc *otev1.ClusterController) (map[string]bool, []string) {
+	reachable := make(map[string]bool)
+	if c.rootClusterEnable {
+		reachable[c.conf.ClusterName] = true
+		return reachable, []string{c.conf.ClusterName}
+	}
+
+	selector := clusterselector.NewSelector(cc.Spec.ClusterSelector)
+	routed := make(map[string]bool)
+	for _, cluster := range clusterrouter.Router().SubTreeClusters() {
+		routed[cluster] = true
+		if selector.Match(cluster, clusterrouter.Router().Labels(cluster)) {
+			reachable[cluster] = true
+		}
+	}
+	for _, clusters := range c.pending.selectOffline(selector, routed) {
+		for _, cluster := range clusters {
+			reachable[cluster] = true
+		}
+	}
+
+	known := make([]string, 0, len(reachable))
+	for cluster := range reachable {
+		known = append(known, cluster)
+	}
+	if c.clusterLister == nil {
+		return reachable, known
+	}
+	clusters, err := c.clusterLister.Clusters(otev1.ClusterNamespace).List(labels.Everything())
+	if err != nil {
+		klog.Errorf("list cluster crd failed: %v", err)
+		return reachable, known
+	}
+	for _, cluster := range clusters {
+		name := cluster.ObjectMeta.Name
+		if reachable[name] || name == c.conf.ClusterName {
+			continue
+		}
+		if selector.Match(name, cluster.Spec.Labels) {
+			known = append(known, name)
+		}
+	}
+	return reachable, known
+}
+
+/*
+dispatchClusterController advances the crd at now and dispatches it to clusters due.
+the crd is got again to advance, so states merged from responses are not lost.
+*/
+func (c *clusterHandler) dispatchClusterController(namespace, name string, now time.Time) {
+	mergeToApiserverMutex.Lock()
+	origin := c.clusterControllerCRD.Get(namespace, name)
+	if origin == nil || isPhaseDone(origin.Phase) {
+		mergeToApiserverMutex.Unlock()
+		return
+	}
+	new := origin.DeepCopy()
+	clusters := c.advanceClusterController(new, now)
+	if new.Phase != origin.Phase || !reflect.DeepEqual(new.Status, origin.Status) {
+		klog.Infof("clustercontroller %s-%s is %s, dispatch to %v", namespace, name, new.Phase, clusters)
+		if err := c.clusterControllerCRD.Update(new); err != nil {
+			// dispatch next time, so attempts are recorded.
+			clusters = nil
+		}
+	}
+	mergeToApiserverMutex.Unlock()
+
+	if len(clusters) > 0 {
+		c.sendClusterController(new, clusterselector.ClustersToSelector(&clusters))
+	}
+}
+
+// checkClusterControllers dispatches crds not done again, or times them out.
+func (c *clusterHandler) checkClusterControllers() {
+	if c.clusterControllerLister == nil {
+		return
+	}
+	ccs, err := c.clusterControllerLister.ClusterControllers(otev1.ClusterNamespace).List(labels.Everything())
+	if err != nil {
+		klog.Errorf("list clustercontroller crd failed: %v", err)
+		return
+	}
+	now := time.Now()
+	for _, cc := range ccs {
+		if cc.Phase != otev1.ClusterControllerPhasePending && cc.Phase != otev1.ClusterControllerPhaseDispatched {
+			continue
+		}
+		// get and update the crd only if it changes.
+		new := cc.DeepCopy()
+		clusters := c.advanceClusterController(new, now)
+		if len(clusters) == 0 && new.Phase == cc.Phase && reflect.DeepEqual(new.Status, cc.Status) {
+			continue
+		}
+		c.dispatchClusterController(cc.ObjectMeta.Namespace, cc.ObjectMeta.Name, now)
+	}
+}
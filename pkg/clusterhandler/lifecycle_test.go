@@ -0,0 +1,217 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterhandler
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	otev1 "github.com/baidu/ote-stack/pkg/apis/ote/v1"
+	"github.com/baidu/ote-stack/pkg/clustermessage"
+	"github.com/baidu/ote-stack/pkg/clusterrouter"
+	"github.com/baidu/ote-stack/pkg/clusterselector"
+	"github.com/baidu/ote-stack/pkg/config"
+	otelister "github.com/baidu/ote-stack/pkg/generated/listers/ote/v1"
+)
+
+func TestClusterControllerPhase(t *testing.T) {
+	succeeded := otev1.ClusterControllerStatus{State: otev1.ClusterControllerStateSucceeded, Attempts: 1}
+	failed := otev1.ClusterControllerStatus{State: otev1.ClusterControllerStateFailed, Attempts: 1}
+	dispatched := otev1.ClusterControllerStatus{State: otev1.ClusterControllerStateDispatched, Attempts: 1}
+	unknown := otev1.ClusterControllerStatus{State: otev1.ClusterControllerStateNotReached}
+	givenUp := otev1.ClusterControllerStatus{State: otev1.ClusterControllerStateNotReached, Attempts: 1}
+
+	cases := []struct {
+		status map[string]otev1.ClusterControllerStatus
+		phase  string
+		expect string
+	}{
+		{nil, "", otev1.ClusterControllerPhasePending},
+		{map[string]otev1.ClusterControllerStatus{"c1": unknown}, "", otev1.ClusterControllerPhasePending},
+		{map[string]otev1.ClusterControllerStatus{"c1": succeeded, "c2": dispatched}, "", otev1.ClusterControllerPhaseDispatched},
+		{map[string]otev1.ClusterControllerStatus{"c1": succeeded, "c2": unknown}, "", otev1.ClusterControllerPhaseDispatched},
+		{map[string]otev1.ClusterControllerStatus{"c1": succeeded}, "", otev1.ClusterControllerPhaseSucceeded},
+		{map[string]otev1.ClusterControllerStatus{"c1": succeeded, "c2": failed}, "", otev1.ClusterControllerPhasePartiallySucceeded},
+		{map[string]otev1.ClusterControllerStatus{"c1": succeeded, "c2": givenUp}, "", otev1.ClusterControllerPhasePartiallySucceeded},
+		{map[string]otev1.ClusterControllerStatus{"c1": failed, "c2": givenUp}, "", otev1.ClusterControllerPhaseFailed},
+		// done phases are not brought back.
+		{map[string]otev1.ClusterControllerStatus{"c1": dispatched}, otev1.ClusterControllerPhaseFailed, otev1.ClusterControllerPhaseFailed},
+		{map[string]otev1.ClusterControllerStatus{"c1": succeeded}, otev1.ClusterControllerPhaseTimedOut, otev1.ClusterControllerPhaseTimedOut},
+	}
+	for i, c := range cases {
+		cc := &otev1.ClusterController{Status: c.status, Phase: c.phase}
+		assert.Equal(t, c.expect, clusterControllerPhase(cc), "case %d", i)
+	}
+
+	// not reached cluster is waited for while it has retry left.
+	cc := &otev1.ClusterController{
+		Spec:   otev1.ClusterControllerSpec{Retry: &otev1.ClusterControllerRetry{Limit: 1}},
+		Status: map[string]otev1.ClusterControllerStatus{"c1": givenUp},
+	}
+	assert.Equal(t, otev1.ClusterControllerPhaseDispatched, clusterControllerPhase(cc))
+}
+
+func TestResponseState(t *testing.T) {
+	assert.Equal(t, otev1.ClusterControllerStateQueued, responseState(otev1.ClusterControllerStatus{
+		StatusCode: http.StatusAccepted, Body: otev1.ClusterControllerStatusQueued}))
+	assert.Equal(t, otev1.ClusterControllerStateNotReached, responseState(otev1.ClusterControllerStatus{
+		StatusCode: http.StatusGatewayTimeout, Body: otev1.ClusterControllerStatusExpired}))
+	assert.Equal(t, otev1.ClusterControllerStateSucceeded, responseState(otev1.ClusterControllerStatus{
+		StatusCode: http.StatusCreated}))
+	assert.Equal(t, otev1.ClusterControllerStateFailed, responseState(otev1.ClusterControllerStatus{
+		StatusCode: http.StatusNotFound}))
+}
+
+func newLifecycleClusterHandler(t *testing.T, clusters ...*otev1.Cluster) *clusterHandler {
+	c := newFakeRootClusterHandler(t)
+	c.conf.ClusterName = config.RootClusterName
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc,
+		cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, cluster := range clusters {
+		indexer.Add(cluster)
+	}
+	c.clusterLister = otelister.NewClusterLister(indexer)
+	return c
+}
+
+func getClusterController(t *testing.T, c *clusterHandler, name string) *otev1.ClusterController {
+	cc := c.clusterControllerCRD.Get(otev1.ClusterNamespace, name)
+	assert.NotNil(t, cc)
+	return cc
+}
+
+func TestDispatchClusterController(t *testing.T) {
+	c := newLifecycleClusterHandler(t, &otev1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "lc2", Namespace: otev1.ClusterNamespace},
+	})
+	clusterrouter.Router().AddRoute("lc1", "lc1")
+	defer clusterrouter.Router().DelChild("lc1", c.sendToChild)
+
+	now := time.Now()
+	cc := &otev1.ClusterController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "cc1",
+			Namespace:         otev1.ClusterNamespace,
+			CreationTimestamp: metav1.NewTime(now),
+		},
+		Spec: otev1.ClusterControllerSpec{
+			ClusterSelector: clusterselector.ClustersToSelector(&[]string{"lc1", "lc2"}),
+			Retry:           &otev1.ClusterControllerRetry{Limit: 1, IntervalSeconds: 60},
+		},
+	}
+	_, err := c.conf.K8sClient.OteV1().ClusterControllers(otev1.ClusterNamespace).Create(cc)
+	assert.Nil(t, err)
+
+	// c1 is dispatched, c2 is known but not reachable.
+	c.addClusterController(cc)
+	eventually(t, func() bool { return fakeTunn.sendCalled }, time.Second, 10*time.Millisecond)
+	cc = getClusterController(t, c, "cc1")
+	assert.Equal(t, otev1.ClusterControllerPhaseDispatched, cc.Phase)
+	assert.Equal(t, otev1.ClusterControllerStateDispatched, cc.Status["lc1"].State)
+	assert.Equal(t, 1, cc.Status["lc1"].Attempts)
+	assert.Equal(t, otev1.ClusterControllerStateNotReached, cc.Status["lc2"].State)
+	assert.Equal(t, 0, cc.Status["lc2"].Attempts)
+	// crd dispatched is not processed again when it is added.
+	assert.False(t, hasToProcessClusterController(cc))
+
+	// c1 responds.
+	body, err := proto.Marshal(&clustermessage.ControllerTaskResponse{
+		Timestamp:  now.Unix(),
+		StatusCode: http.StatusOK,
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, c.mergeToApiserver(&clustermessage.ClusterMessage{
+		Head: &clustermessage.MessageHead{
+			MessageID:   "cc1",
+			Command:     clustermessage.CommandType_ControlResp,
+			ClusterName: "lc1",
+		},
+		Body: body,
+	}))
+	cc = getClusterController(t, c, "cc1")
+	assert.Equal(t, otev1.ClusterControllerPhaseDispatched, cc.Phase)
+	assert.Equal(t, otev1.ClusterControllerStateSucceeded, cc.Status["lc1"].State)
+	assert.Equal(t, 1, cc.Status["lc1"].Attempts)
+
+	// c2 is dispatched once reachable, c1 responded is not.
+	clusterrouter.Router().AddRoute("lc2", "lc1")
+	now = now.Add(time.Second)
+	c.dispatchClusterController(otev1.ClusterNamespace, "cc1", now)
+	cc = getClusterController(t, c, "cc1")
+	assert.Equal(t, 1, cc.Status["lc1"].Attempts)
+	assert.Equal(t, otev1.ClusterControllerStateDispatched, cc.Status["lc2"].State)
+	assert.Equal(t, 1, cc.Status["lc2"].Attempts)
+
+	// c2 is dispatched again after retry interval.
+	c.dispatchClusterController(otev1.ClusterNamespace, "cc1", now.Add(30*time.Second))
+	assert.Equal(t, 1, getClusterController(t, c, "cc1").Status["lc2"].Attempts)
+	now = now.Add(60 * time.Second)
+	c.dispatchClusterController(otev1.ClusterNamespace, "cc1", now)
+	cc = getClusterController(t, c, "cc1")
+	assert.Equal(t, 1, cc.Status["lc1"].Attempts)
+	assert.Equal(t, 2, cc.Status["lc2"].Attempts)
+	assert.Equal(t, otev1.ClusterControllerPhaseDispatched, cc.Phase)
+
+	// c2 is given up after its last retry.
+	c.dispatchClusterController(otev1.ClusterNamespace, "cc1", now.Add(60*time.Second))
+	cc = getClusterController(t, c, "cc1")
+	assert.Equal(t, 2, cc.Status["lc2"].Attempts)
+	assert.Equal(t, otev1.ClusterControllerStateNotReached, cc.Status["lc2"].State)
+	assert.Equal(t, otev1.ClusterControllerPhasePartiallySucceeded, cc.Phase)
+	assert.Equal(t, now.Add(60*time.Second).Unix(), cc.FinishTimestamp)
+	clusterrouter.Router().DelRoute("lc2", "lc1")
+}
+
+func TestClusterControllerTimeout(t *testing.T) {
+	c := newLifecycleClusterHandler(t)
+	created := time.Now().Add(-time.Hour)
+	cc := &otev1.ClusterController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "cc2",
+			Namespace:         otev1.ClusterNamespace,
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Spec: otev1.ClusterControllerSpec{
+			ClusterSelector: "c3",
+			TimeoutSeconds:  60,
+		},
+		Status: map[string]otev1.ClusterControllerStatus{
+			"c3": {State: otev1.ClusterControllerStateDispatched, Attempts: 1},
+		},
+		Phase: otev1.ClusterControllerPhaseDispatched,
+	}
+	assert.False(t, hasToProcessClusterController(cc))
+	_, err := c.conf.K8sClient.OteV1().ClusterControllers(otev1.ClusterNamespace).Create(cc)
+	assert.Nil(t, err)
+
+	c.dispatchClusterController(otev1.ClusterNamespace, "cc2", created.Add(30*time.Second))
+	cc = getClusterController(t, c, "cc2")
+	assert.Equal(t, otev1.ClusterControllerPhaseDispatched, cc.Phase)
+	assert.Equal(t, int64(0), cc.FinishTimestamp)
+
+	c.dispatchClusterController(otev1.ClusterNamespace, "cc2", created.Add(60*time.Second))
+	cc = getClusterController(t, c, "cc2")
+	assert.Equal(t, otev1.ClusterControllerPhaseTimedOut, cc.Phase)
+	assert.Equal(t, created.Add(60*time.Second).Unix(), cc.FinishTimestamp)
+	assert.Equal(t, otev1.ClusterControllerStateNotReached, cc.Status["c3"].State)
+}
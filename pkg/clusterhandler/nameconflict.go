@@ -0,0 +1,228 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterhandler
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog"
+
+	"github.com/baidu/ote-stack/pkg/clustermessage"
+	"github.com/baidu/ote-stack/pkg/clusterrouter"
+	"github.com/baidu/ote-stack/pkg/clusterselector"
+	"github.com/baidu/ote-stack/pkg/config"
+)
+
+/*
+A cluster regists with a name already routed to another child is a name conflict,
+it is resolved by DuplicateNamePolicy of the cluster controller finding it, which is root mostly:
+
+1. reject-new, the new cluster is told of the conflict and exits,
+2. replace-old, the route to the old cluster is evicted, the old one is told of the conflict and exits,
+3. auto-suffix, the new cluster is told to reconnect with its name suffixed by a number, e.g., "c1-1".
+
+The notice is routed to the cluster by its name like other messages.
+A conflict found below root is forwarded to root as well, where it is recorded as an event of the Cluster crd.
+*/
+
+var (
+	// DuplicateNamePolicy is the policy on a cluster regists with a name already routed.
+	DuplicateNamePolicy = config.DuplicateNameRejectNew
+)
+
+const (
+	eventReasonNameRejected = "NameConflictRejected"
+	eventReasonNameReplaced = "NameConflictReplaced"
+	eventReasonNameRenamed  = "NameConflictRenamed"
+)
+
+func validDuplicateNamePolicy(policy string) error {
+	switch policy {
+	case config.DuplicateNameRejectNew, config.DuplicateNameReplaceOld, config.DuplicateNameAutoSuffix:
+		return nil
+	default:
+		return fmt.Errorf("unknown duplicate name policy %q, use %s, %s or %s", policy,
+			config.DuplicateNameRejectNew, config.DuplicateNameReplaceOld, config.DuplicateNameAutoSuffix)
+	}
+}
+
+// rejectedChilds are childs which connect only to be told of name conflicts.
+type rejectedChilds struct {
+	mutex   sync.Mutex
+	notices map[string]*clustermessage.ClusterMessage
+}
+
+func (r *rejectedChilds) put(name string, notice *clustermessage.ClusterMessage) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.notices == nil {
+		r.notices = make(map[string]*clustermessage.ClusterMessage)
+	}
+	r.notices[name] = notice
+}
+
+// get returns the notice to child, nil if the child is not rejected.
+func (r *rejectedChilds) get(name string) *clustermessage.ClusterMessage {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.notices[name]
+}
+
+// remove forgets child, it returns false if the child is not rejected.
+func (r *rejectedChilds) remove(name string) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	_, ok := r.notices[name]
+	delete(r.notices, name)
+	return ok
+}
+
+/*
+resolveNameConflict applies DuplicateNamePolicy if cluster name reached from port
+is already routed to another child, it returns if the new cluster is accepted.
+the notice to a rejected child is sent once it connects.
+*/
+func (c *clusterHandler) resolveNameConflict(name, port string) bool {
+	oldPort, ok := clusterrouter.Router().Port(name)
+	if !ok || oldPort == port {
+		return true
+	}
+
+	conflict := &config.NameConflict{
+		Name:   name,
+		Policy: DuplicateNamePolicy,
+	}
+	switch DuplicateNamePolicy {
+	case config.DuplicateNameReplaceOld:
+		conflict.Reason = fmt.Sprintf("replaced by a new cluster from %s on %s", port, c.conf.ClusterName)
+		klog.Warningf("cluster %s from %s replaces the one from %s", name, port, oldPort)
+		c.notifyNameConflict(conflict, oldPort)
+		clusterrouter.Router().DelRoute(name, oldPort)
+		c.recordNameConflict(conflict)
+		return true
+	case config.DuplicateNameAutoSuffix:
+		conflict.NewName = c.suffixedName(name)
+		conflict.Reason = fmt.Sprintf("used by the cluster from %s on %s, renamed to %s",
+			oldPort, c.conf.ClusterName, conflict.NewName)
+		klog.Warningf("cluster %s from %s is renamed to %s", name, port, conflict.NewName)
+		c.recordNameConflict(conflict)
+	default:
+		conflict.Reason = fmt.Sprintf("used by the cluster from %s on %s", oldPort, c.conf.ClusterName)
+		klog.Warningf("cluster %s from %s is rejected, it is reached from %s", name, port, oldPort)
+		c.recordNameConflict(conflict)
+	}
+
+	if port != name {
+		c.notifyNameConflict(conflict, port)
+		return false
+	}
+	notice, err := c.nameConflictMessage(conflict)
+	if err != nil {
+		klog.Error(err)
+		return false
+	}
+	c.rejected.put(name, notice)
+	return false
+}
+
+func (c *clusterHandler) nameConflictMessage(conflict *config.NameConflict) (*clustermessage.ClusterMessage, error) {
+	msg, err := conflict.WrapperToClusterMessage(c.conf.ClusterName)
+	if err != nil {
+		return nil, err
+	}
+	msg.Head.ClusterSelector = clusterselector.QuoteName(conflict.Name)
+	return msg, nil
+}
+
+// notifyNameConflict sends the notice of conflict through port.
+func (c *clusterHandler) notifyNameConflict(conflict *config.NameConflict, port string) {
+	msg, err := c.nameConflictMessage(conflict)
+	if err != nil {
+		klog.Error(err)
+		return
+	}
+	c.sendToChild(msg, port)
+}
+
+// sendToRejectedChild sends the notice to child if it is rejected, and returns if it is.
+func (c *clusterHandler) sendToRejectedChild(name string) bool {
+	notice := c.rejected.get(name)
+	if notice == nil {
+		return false
+	}
+	data, err := proto.Marshal(notice)
+	if err != nil {
+		klog.Errorf("serialize name conflict to %s failed: %v", name, err)
+		return true
+	}
+	if err := c.tunn.Send(name, data); err != nil {
+		klog.Errorf("send name conflict to %s failed: %v", name, err)
+	}
+	return true
+}
+
+// suffixedName returns name suffixed by the least number not routed.
+func (c *clusterHandler) suffixedName(name string) string {
+	for i := 1; ; i++ {
+		newName := fmt.Sprintf("%s-%d", name, i)
+		if _, ok := clusterrouter.Router().Port(newName); !ok && newName != c.conf.ClusterName {
+			return newName
+		}
+	}
+}
+
+// recordNameConflict records conflict as an event at root, or forwards it to parent.
+func (c *clusterHandler) recordNameConflict(conflict *config.NameConflict) {
+	if !c.isRoot() {
+		msg, err := conflict.WrapperToClusterMessage(c.conf.ClusterName)
+		if err != nil {
+			klog.Error(err)
+			return
+		}
+		c.transmitToParent(msg)
+		return
+	}
+	if c.events == nil {
+		return
+	}
+	reason := eventReasonNameRejected
+	switch conflict.Policy {
+	case config.DuplicateNameReplaceOld:
+		reason = eventReasonNameReplaced
+	case config.DuplicateNameAutoSuffix:
+		reason = eventReasonNameRenamed
+	}
+	if err := c.events.Record(conflict.Name, corev1.EventTypeWarning, reason, conflict.Reason); err != nil {
+		klog.Errorf("record event of cluster %s failed: %v", conflict.Name, err)
+	}
+}
+
+// handleNameConflictFromChild records a conflict found below root and forwarded by child.
+func (c *clusterHandler) handleNameConflictFromChild(msg *clustermessage.ClusterMessage) error {
+	conflict, err := config.NameConflictDeserialize(msg.Body)
+	if err != nil {
+		return fmt.Errorf("deserialize name conflict failed: %v", err)
+	}
+	c.recordNameConflict(conflict)
+	return nil
+}
@@ -0,0 +1,241 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterhandler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	otev1 "github.com/baidu/ote-stack/pkg/apis/ote/v1"
+	"github.com/baidu/ote-stack/pkg/clustermessage"
+	"github.com/baidu/ote-stack/pkg/clusterrouter"
+	"github.com/baidu/ote-stack/pkg/clusterselector"
+	"github.com/baidu/ote-stack/pkg/config"
+	oteclient "github.com/baidu/ote-stack/pkg/generated/clientset/versioned/fake"
+)
+
+// openChildQueue returns a channel receiving name conflicts sent to child.
+func openChildQueue(c *clusterHandler, child string) chan *config.NameConflict {
+	ret := make(chan *config.NameConflict, 10)
+	c.queues.open(child, func(data []byte) error {
+		msg := &clustermessage.ClusterMessage{}
+		if err := proto.Unmarshal(data, msg); err != nil {
+			return err
+		}
+		if msg.Head.Command != clustermessage.CommandType_ClusterNameConflict ||
+			msg.Head.ClusterSelector != clusterselector.QuoteName(msg.Head.ClusterName) {
+			return nil
+		}
+		conflict, err := config.NameConflictDeserialize(msg.Body)
+		if err != nil {
+			return err
+		}
+		ret <- conflict
+		return nil
+	})
+	return ret
+}
+
+func receiveNameConflict(t *testing.T, ch chan *config.NameConflict) *config.NameConflict {
+	select {
+	case conflict := <-ch:
+		return conflict
+	case <-time.After(time.Second):
+		t.Fatal("no name conflict is sent")
+	}
+	return nil
+}
+
+func registMessage(t *testing.T, name string, ts int64) *clustermessage.ClusterMessage {
+	cr := &config.ClusterRegistry{
+		Name: name,
+		Time: ts,
+	}
+	msg, err := cr.WrapperToClusterMessage(clustermessage.CommandType_ClusterRegist)
+	assert.Nil(t, err)
+	return msg
+}
+
+func TestDuplicateNamePolicy(t *testing.T) {
+	policy := DuplicateNamePolicy
+	defer func() {
+		DuplicateNamePolicy = policy
+	}()
+
+	now := time.Now().Unix()
+	kubeClient := kubefake.NewSimpleClientset()
+	c := &clusterHandler{
+		conf: &config.ClusterControllerConfig{
+			ClusterName:           config.RootClusterName,
+			ClusterUserDefineName: config.RootClusterName,
+			TunnelListenAddr:      "8272",
+			K8sClient:             oteclient.NewSimpleClientset(),
+			KubeClient:            kubeClient,
+		},
+		tunn: fakeTunn,
+	}
+	assert.Nil(t, c.valid())
+	assert.NotNil(t, c.events)
+	fromP1 := openChildQueue(c, "dp1")
+	fromP2 := openChildQueue(c, "dp2")
+	defer c.queues.close("dp1")
+	defer c.queues.close("dp2")
+	clusterrouter.Router().AddRoute("dp1", "dp1")
+	clusterrouter.Router().AddRoute("dp2", "dp2")
+	defer clusterrouter.Router().DelRoute("dp1", "dp1")
+	defer clusterrouter.Router().DelRoute("dp2", "dp2")
+	assert.Nil(t, c.handleRegistClusterMessage("dp1", registMessage(t, "d1", now)))
+
+	// reject the new one
+	DuplicateNamePolicy = config.DuplicateNameRejectNew
+	assert.NotNil(t, c.handleRegistClusterMessage("dp2", registMessage(t, "d1", now)))
+	conflict := receiveNameConflict(t, fromP2)
+	assert.Equal(t, "d1", conflict.Name)
+	assert.Equal(t, config.DuplicateNameRejectNew, conflict.Policy)
+	assert.Equal(t, "", conflict.NewName)
+	assert.True(t, clusterrouter.Router().HasRoute("d1", "dp1"))
+	// unregist of the rejected one does not affect the old one
+	cr := &config.ClusterRegistry{Name: "d1", Time: now}
+	unregist, err := cr.WrapperToClusterMessage(clustermessage.CommandType_ClusterUnregist)
+	assert.Nil(t, err)
+	assert.Nil(t, c.handleUnregistClusterMessage("dp2", unregist))
+	assert.True(t, clusterrouter.Router().HasRoute("d1", "dp1"))
+	assert.Equal(t, otev1.ClusterStatusOnline,
+		c.clusterCRD.Get(otev1.ClusterNamespace, "d1").Status.Status)
+
+	// rename the new one
+	DuplicateNamePolicy = config.DuplicateNameAutoSuffix
+	assert.Nil(t, c.handleRegistClusterMessage("dp1", registMessage(t, "d1-1", now)))
+	assert.NotNil(t, c.handleRegistClusterMessage("dp2", registMessage(t, "d1", now)))
+	conflict = receiveNameConflict(t, fromP2)
+	assert.Equal(t, "d1-2", conflict.NewName)
+	assert.True(t, clusterrouter.Router().HasRoute("d1", "dp1"))
+
+	// replace the old one
+	DuplicateNamePolicy = config.DuplicateNameReplaceOld
+	assert.Nil(t, c.handleRegistClusterMessage("dp2", registMessage(t, "d1", now+1)))
+	conflict = receiveNameConflict(t, fromP1)
+	assert.Equal(t, "d1", conflict.Name)
+	assert.Equal(t, config.DuplicateNameReplaceOld, conflict.Policy)
+	assert.True(t, clusterrouter.Router().HasRoute("d1", "dp2"))
+	assert.Nil(t, c.handleUnregistClusterMessage("dp1", unregist))
+	assert.True(t, clusterrouter.Router().HasRoute("d1", "dp2"))
+
+	// conflicts are recorded as events
+	events, err := kubeClient.CoreV1().Events(otev1.ClusterNamespace).List(metav1.ListOptions{})
+	assert.Nil(t, err)
+	reasons := make([]string, 0)
+	for _, e := range events.Items {
+		assert.Equal(t, "d1", e.InvolvedObject.Name)
+		assert.Equal(t, "Cluster", e.InvolvedObject.Kind)
+		reasons = append(reasons, e.Reason)
+	}
+	assert.ElementsMatch(t, []string{eventReasonNameRejected,
+		eventReasonNameRenamed, eventReasonNameReplaced}, reasons)
+
+	// unknown policy
+	DuplicateNamePolicy = "unknown"
+	assert.NotNil(t, c.valid())
+}
+
+func TestForwardNameConflict(t *testing.T) {
+	policy := DuplicateNamePolicy
+	defer func() {
+		DuplicateNamePolicy = policy
+	}()
+	DuplicateNamePolicy = config.DuplicateNameRejectNew
+
+	// a conflict found below root is forwarded to parent.
+	c := newFakeNoRootClusterHandler(t)
+	c.conf.ClusterName = "fc"
+	c.conf.ClusterToEdgeChan = make(chan clustermessage.ClusterMessage, 10)
+	clusterrouter.Router().AddRoute("fp1", "fp1")
+	clusterrouter.Router().AddRoute("f1", "fp1")
+	defer clusterrouter.Router().DelRoute("fp1", "fp1")
+	assert.False(t, c.resolveNameConflict("f1", "fp2"))
+	var msg clustermessage.ClusterMessage
+	select {
+	case msg = <-c.conf.ClusterToEdgeChan:
+	case <-time.After(time.Second):
+		t.Fatal("name conflict is not forwarded to parent")
+	}
+	assert.Equal(t, clustermessage.CommandType_ClusterNameConflict, msg.Head.Command)
+	assert.Equal(t, "f1", msg.Head.ClusterName)
+
+	// root records it as an event.
+	kubeClient := kubefake.NewSimpleClientset()
+	root := &clusterHandler{
+		conf: &config.ClusterControllerConfig{
+			ClusterName:           config.RootClusterName,
+			ClusterUserDefineName: config.RootClusterName,
+			TunnelListenAddr:      "8272",
+			K8sClient:             oteclient.NewSimpleClientset(),
+			KubeClient:            kubeClient,
+		},
+		tunn: fakeTunn,
+	}
+	assert.Nil(t, root.valid())
+	data, err := proto.Marshal(&msg)
+	assert.Nil(t, err)
+	assert.Nil(t, root.handleMessageFromChild(c.conf.ClusterName, data))
+	events, err := kubeClient.CoreV1().Events(otev1.ClusterNamespace).List(metav1.ListOptions{})
+	assert.Nil(t, err)
+	if assert.Len(t, events.Items, 1) {
+		assert.Equal(t, "f1", events.Items[0].InvolvedObject.Name)
+		assert.Equal(t, eventReasonNameRejected, events.Items[0].Reason)
+	}
+}
+
+func TestRejectChild(t *testing.T) {
+	policy := DuplicateNamePolicy
+	defer func() {
+		DuplicateNamePolicy = policy
+	}()
+	DuplicateNamePolicy = config.DuplicateNameRejectNew
+
+	c := newFakeRootClusterHandler(t)
+	clusterrouter.Router().AddRoute("rp1", "rp1")
+	clusterrouter.Router().AddRoute("r1", "rp1")
+	defer clusterrouter.Router().DelRoute("rp1", "rp1")
+
+	// a child with conflicting name connects only to be told of the conflict
+	cr := &config.ClusterRegistry{Name: "r1", Listen: "r1:8287"}
+	assert.True(t, c.checkClusterName(cr))
+	assert.NotNil(t, c.rejected.get("r1"))
+	c.afterClusterConnect(cr)
+	assert.True(t, fakeTunn.sendCalled)
+	assert.False(t, clusterrouter.Router().HasChild("r1"))
+	assert.Nil(t, c.queues.get("r1"))
+
+	// messages from it are dropped
+	msg := registMessage(t, "r2", time.Now().Unix())
+	data, err := proto.Marshal(msg)
+	assert.Nil(t, err)
+	assert.Nil(t, c.handleMessageFromChild("r1", data))
+	_, ok := clusterrouter.Router().Port("r2")
+	assert.False(t, ok)
+
+	// route to the old one is kept after it disconnects
+	c.closeChild(cr)
+	assert.Nil(t, c.rejected.get("r1"))
+	assert.True(t, clusterrouter.Router().HasRoute("r1", "rp1"))
+}
@@ -0,0 +1,301 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterhandler
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"k8s.io/klog"
+
+	otev1 "github.com/baidu/ote-stack/pkg/apis/ote/v1"
+	"github.com/baidu/ote-stack/pkg/clustermessage"
+	"github.com/baidu/ote-stack/pkg/clusterrouter"
+	"github.com/baidu/ote-stack/pkg/clusterselector"
+)
+
+/*
+Store-and-forward keeps messages addressed to an offline child in a pending queue
+of that child, and sends them once the child connects again.
+
+When a child disconnects, clusters of its subtree are remembered,
+so messages selecting those clusters are still queued though there is no route to them.
+A queued message expires at ExpireTime of its head, or after PendingMessageTTL if it is not set.
+Queued and expired states are reported to root as responses of the selected clusters,
+so root can show them in status of the ClusterController crd.
+*/
+
+var (
+	// PendingQueueSize is the max number of messages queued for an offline child, 0 disables queueing.
+	PendingQueueSize = 100
+	// PendingMessageTTL is how long a message without expire time is queued.
+	PendingMessageTTL = 1 * time.Hour
+
+	pendingCheckPeriod = 10 * time.Second
+)
+
+type pendingMessage struct {
+	msg *clustermessage.ClusterMessage
+	// clusters are the selected clusters in subtree of the child.
+	clusters []string
+	expire   time.Time
+}
+
+type offlineChild struct {
+	// subtree is the clusters reachable from the child before it disconnected.
+	subtree  []string
+	since    time.Time
+	messages []*pendingMessage
+}
+
+// pendingQueue keeps pending messages of offline childs.
+type pendingQueue struct {
+	mutex  sync.Mutex
+	childs map[string]*offlineChild
+}
+
+func (q *pendingQueue) child(name string) *offlineChild {
+	if q.childs == nil {
+		q.childs = make(map[string]*offlineChild)
+	}
+	child, ok := q.childs[name]
+	if !ok {
+		child = &offlineChild{
+			since:    time.Now(),
+			messages: make([]*pendingMessage, 0),
+		}
+		q.childs[name] = child
+	}
+	return child
+}
+
+// offline marks child as offline with clusters of its subtree.
+func (q *pendingQueue) offline(name string, subtree []string) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.child(name).subtree = subtree
+}
+
+// online removes child from offline childs and returns its pending messages.
+func (q *pendingQueue) online(name string) []*pendingMessage {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	child, ok := q.childs[name]
+	if !ok {
+		return nil
+	}
+	delete(q.childs, name)
+	return child.messages
+}
+
+// push puts msg to the queue of child, and returns messages evicted since the queue is full.
+func (q *pendingQueue) push(name string, msg *pendingMessage) []*pendingMessage {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	child := q.child(name)
+	child.messages = append(child.messages, msg)
+	if len(child.messages) <= PendingQueueSize {
+		return nil
+	}
+	n := len(child.messages) - PendingQueueSize
+	evicted := child.messages[:n]
+	child.messages = child.messages[n:]
+	return evicted
+}
+
+/*
+selectOffline returns clusters selected by selector in subtree of offline childs,
+key is name of the child, value is the selected clusters.
+clusters in routed are skipped since they can be reached from another child.
+*/
+func (q *pendingQueue) selectOffline(
+	selector clusterselector.Selector, routed map[string]bool) map[string][]string {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	ret := make(map[string][]string)
+	for name, child := range q.childs {
+		for _, cluster := range child.subtree {
+			if routed[cluster] || !selector.Match(cluster, clusterrouter.Router().Labels(cluster)) {
+				continue
+			}
+			ret[name] = append(ret[name], cluster)
+		}
+	}
+	return ret
+}
+
+/*
+expired removes and returns messages expired before now.
+offline childs with no message are forgotten after PendingMessageTTL.
+*/
+func (q *pendingQueue) expired(now time.Time) []*pendingMessage {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	ret := make([]*pendingMessage, 0)
+	for name, child := range q.childs {
+		messages := make([]*pendingMessage, 0, len(child.messages))
+		for _, p := range child.messages {
+			if p.expire.After(now) {
+				messages = append(messages, p)
+			} else {
+				ret = append(ret, p)
+			}
+		}
+		child.messages = messages
+		if len(messages) == 0 && now.Sub(child.since) > PendingMessageTTL {
+			delete(q.childs, name)
+		}
+	}
+	return ret
+}
+
+// isPendable returns if msg should be queued when the child is offline.
+func isPendable(msg *clustermessage.ClusterMessage) bool {
+	if PendingQueueSize <= 0 || msg == nil || msg.Head == nil {
+		return false
+	}
+	return msg.Head.Command == clustermessage.CommandType_ControlReq ||
+		msg.Head.Command == clustermessage.CommandType_ControlMultiReq
+}
+
+func messageExpireTime(msg *clustermessage.ClusterMessage) time.Time {
+	if msg.Head.ExpireTime > 0 {
+		return time.Unix(msg.Head.ExpireTime, 0)
+	}
+	return time.Now().Add(PendingMessageTTL)
+}
+
+// holdForOfflineChilds queues msg for offline childs whose subtree is selected by msg.
+func (c *clusterHandler) holdForOfflineChilds(msg *clustermessage.ClusterMessage) {
+	if !isPendable(msg) {
+		return
+	}
+	routed := make(map[string]bool)
+	for _, cluster := range clusterrouter.Router().SubTreeClusters() {
+		routed[cluster] = true
+	}
+	selector := clusterselector.NewSelector(msg.Head.ClusterSelector)
+	for name, clusters := range c.pending.selectOffline(selector, routed) {
+		childMsg := proto.Clone(msg).(*clustermessage.ClusterMessage)
+		childMsg.Head.ClusterSelector = clusterselector.ClustersToSelector(&clusters)
+		c.holdForChild(childMsg, name, clusters)
+	}
+}
+
+// holdForChild queues msg for child, clusters are the ones selected by msg in subtree of child.
+func (c *clusterHandler) holdForChild(
+	msg *clustermessage.ClusterMessage, name string, clusters []string) {
+	p := &pendingMessage{
+		msg:      msg,
+		clusters: clusters,
+		expire:   messageExpireTime(msg),
+	}
+	if !p.expire.After(time.Now()) {
+		klog.Infof("message(%s) to offline child %s is expired", msg.Head.MessageID, name)
+		c.reportPendingState(p, http.StatusGatewayTimeout, otev1.ClusterControllerStatusExpired)
+		return
+	}
+
+	klog.Infof("child %s is offline, queue message(%s)", name, msg.Head.MessageID)
+	evicted := c.pending.push(name, p)
+	c.reportPendingState(p, http.StatusAccepted, otev1.ClusterControllerStatusQueued)
+	for _, e := range evicted {
+		klog.Warningf("pending queue of child %s is full, drop message(%s)", name, e.msg.Head.MessageID)
+		c.reportPendingState(e, http.StatusGatewayTimeout, otev1.ClusterControllerStatusExpired)
+	}
+}
+
+// flushPendingMessages sends messages queued for child when it connects.
+func (c *clusterHandler) flushPendingMessages(name string) {
+	messages := c.pending.online(name)
+	if len(messages) == 0 {
+		return
+	}
+	klog.Infof("child %s is online, send %d pending messages", name, len(messages))
+	now := time.Now()
+	for _, p := range messages {
+		if !p.expire.After(now) {
+			c.reportPendingState(p, http.StatusGatewayTimeout, otev1.ClusterControllerStatusExpired)
+			continue
+		}
+		c.sendToChild(p.msg, name)
+	}
+}
+
+// checkPendingMessages drops expired messages of offline childs.
+func (c *clusterHandler) checkPendingMessages() {
+	for _, p := range c.pending.expired(time.Now()) {
+		klog.Infof("pending message(%s) is expired", p.msg.Head.MessageID)
+		c.reportPendingState(p, http.StatusGatewayTimeout, otev1.ClusterControllerStatusExpired)
+	}
+}
+
+/*
+reportPendingState reports state of a pending message as responses of the selected clusters.
+only message from a ClusterController crd is reported, since only its status is shown.
+*/
+func (c *clusterHandler) reportPendingState(p *pendingMessage, code int, state string) {
+	head := p.msg.Head
+	if head.Command != clustermessage.CommandType_ControlReq || head.MessageID == "" {
+		return
+	}
+	resp := &clustermessage.ControllerTaskResponse{
+		Timestamp:  time.Now().Unix(),
+		StatusCode: int32(code),
+		Body:       []byte(state),
+	}
+	data, err := proto.Marshal(resp)
+	if err != nil {
+		klog.Errorf("marshal controller task resp failed: %v", err)
+		return
+	}
+	for _, cluster := range p.clusters {
+		msg := &clustermessage.ClusterMessage{
+			Head: &clustermessage.MessageHead{
+				MessageID:         head.MessageID,
+				Command:           clustermessage.CommandType_ControlResp,
+				ClusterName:       cluster,
+				ParentClusterName: head.ParentClusterName,
+			},
+			Body: data,
+		}
+		if c.isRoot() {
+			if err := c.mergeToApiserver(msg); err != nil {
+				klog.Errorf("merge %s state of %s to apiserver failed: %v", state, cluster, err)
+			}
+		} else {
+			c.transmitToParent(msg)
+		}
+	}
+}
+
+// selectorClusters returns clusters in a selector made by ClustersToSelector.
+func selectorClusters(selector string) []string {
+	return clusterselector.Clusters(selector)
+}
+
+// isPendingStatus returns if status is reported by a pending queue rather than the cluster.
+func isPendingStatus(s otev1.ClusterControllerStatus) bool {
+	return s.StatusCode == http.StatusAccepted && s.Body == otev1.ClusterControllerStatusQueued
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterhandler
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	otev1 "github.com/baidu/ote-stack/pkg/apis/ote/v1"
+	"github.com/baidu/ote-stack/pkg/clustermessage"
+	"github.com/baidu/ote-stack/pkg/clusterrouter"
+	"github.com/baidu/ote-stack/pkg/clusterselector"
+	"github.com/baidu/ote-stack/pkg/config"
+)
+
+func newPendingMessage(id string, expire time.Time) *pendingMessage {
+	return &pendingMessage{
+		msg: &clustermessage.ClusterMessage{
+			Head: &clustermessage.MessageHead{
+				MessageID: id,
+				Command:   clustermessage.CommandType_ControlReq,
+			},
+		},
+		expire: expire,
+	}
+}
+
+func TestPendingQueue(t *testing.T) {
+	size := PendingQueueSize
+	PendingQueueSize = 2
+	defer func() {
+		PendingQueueSize = size
+	}()
+
+	q := &pendingQueue{}
+	assert.Nil(t, q.online("p1"))
+	q.offline("p1", []string{"p1", "p11", "p12"})
+
+	// clusters with route are not selected.
+	selected := q.selectOffline(clusterselector.NewSelector("p11,p12"), map[string]bool{"p12": true})
+	assert.Equal(t, map[string][]string{"p1": {"p11"}}, selected)
+
+	// the oldest is evicted once full.
+	future := time.Now().Add(time.Hour)
+	assert.Nil(t, q.push("p1", newPendingMessage("m1", future)))
+	assert.Nil(t, q.push("p1", newPendingMessage("m2", time.Now())))
+	evicted := q.push("p1", newPendingMessage("m3", future))
+	assert.Equal(t, 1, len(evicted))
+	assert.Equal(t, "m1", evicted[0].msg.Head.MessageID)
+
+	// expired messages are removed.
+	expired := q.expired(time.Now().Add(time.Second))
+	assert.Equal(t, 1, len(expired))
+	assert.Equal(t, "m2", expired[0].msg.Head.MessageID)
+
+	messages := q.online("p1")
+	assert.Equal(t, 1, len(messages))
+	assert.Equal(t, "m3", messages[0].msg.Head.MessageID)
+	assert.Equal(t, 0, len(q.selectOffline(clusterselector.NewSelector("p11"), nil)))
+
+	// offline child without message is forgotten after ttl.
+	q.offline("p2", []string{"p2"})
+	q.expired(time.Now())
+	assert.Equal(t, 1, len(q.childs))
+	q.expired(time.Now().Add(PendingMessageTTL + time.Second))
+	assert.Equal(t, 0, len(q.childs))
+}
+
+func TestStoreAndForward(t *testing.T) {
+	c := &clusterHandler{
+		conf: &config.ClusterControllerConfig{
+			ClusterName:           "c1",
+			ClusterUserDefineName: "c1",
+			ClusterToEdgeChan:     make(chan clustermessage.ClusterMessage, 10),
+		},
+		tunn: fakeTunn,
+	}
+	clusterrouter.Router().AddRoute("p1", "p1")
+	clusterrouter.Router().AddRoute("p11", "p1")
+
+	c.closeChild(&config.ClusterRegistry{Name: "p1"})
+	// routes to subtree are deleted before unregist message is transmitted
+	<-c.conf.ClusterToEdgeChan
+
+	// message to subtree of offline child is queued, and queued state is reported.
+	msg := &clustermessage.ClusterMessage{
+		Head: &clustermessage.MessageHead{
+			MessageID:       "cc1",
+			Command:         clustermessage.CommandType_ControlReq,
+			ClusterSelector: "p11",
+		},
+	}
+	fakeTunn.reset()
+	c.holdForOfflineChilds(msg)
+	assert.Equal(t, 1, len(c.pending.childs["p1"].messages))
+	resp := <-c.conf.ClusterToEdgeChan
+	assert.Equal(t, clustermessage.CommandType_ControlResp, resp.Head.Command)
+	assert.Equal(t, "cc1", resp.Head.MessageID)
+	assert.Equal(t, "p11", resp.Head.ClusterName)
+	taskResp := &clustermessage.ControllerTaskResponse{}
+	assert.Nil(t, proto.Unmarshal(resp.Body, taskResp))
+	assert.Equal(t, int32(http.StatusAccepted), taskResp.StatusCode)
+	assert.Equal(t, otev1.ClusterControllerStatusQueued, string(taskResp.Body))
+
+	// expired message is reported.
+	expiredMsg := proto.Clone(msg).(*clustermessage.ClusterMessage)
+	expiredMsg.Head.MessageID = "cc2"
+	expiredMsg.Head.ExpireTime = time.Now().Unix() - 1
+	c.holdForOfflineChilds(expiredMsg)
+	resp = <-c.conf.ClusterToEdgeChan
+	assert.Equal(t, "cc2", resp.Head.MessageID)
+	assert.Nil(t, proto.Unmarshal(resp.Body, taskResp))
+	assert.Equal(t, int32(http.StatusGatewayTimeout), taskResp.StatusCode)
+	assert.Equal(t, otev1.ClusterControllerStatusExpired, string(taskResp.Body))
+
+	// queued message is sent after child connects.
+	c.afterClusterConnect(&config.ClusterRegistry{Name: "p1"})
+	time.Sleep(100 * time.Millisecond)
+	assert.True(t, fakeTunn.sendCalled)
+	assert.Equal(t, 0, len(c.pending.childs))
+	clusterrouter.Router().DelChild("p1", c.sendToChild)
+}
+
+func TestQueuedClusterControllerExpireTime(t *testing.T) {
+	c := &clusterHandler{
+		conf: &config.ClusterControllerConfig{
+			ClusterName:           "c1",
+			ClusterUserDefineName: "c1",
+			ClusterToEdgeChan:     make(chan clustermessage.ClusterMessage, 10),
+		},
+		tunn: fakeTunn,
+	}
+	clusterrouter.Router().AddRoute("q1", "q1")
+	clusterrouter.Router().AddRoute("q11", "q1")
+	c.closeChild(&config.ClusterRegistry{Name: "q1"})
+	<-c.conf.ClusterToEdgeChan
+	defer clusterrouter.Router().DelChild("q1", c.sendToChild)
+
+	// message queued expires when the crd times out.
+	created := time.Now().Truncate(time.Second)
+	c.sendClusterController(&otev1.ClusterController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "cc1",
+			Namespace:         otev1.ClusterNamespace,
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Spec: otev1.ClusterControllerSpec{TimeoutSeconds: 120},
+	}, "q11")
+	if assert.Equal(t, 1, len(c.pending.childs["q1"].messages)) {
+		assert.Equal(t, created.Add(120*time.Second), c.pending.childs["q1"].messages[0].expire)
+	}
+}
+
+func TestMergePendingStatus(t *testing.T) {
+	queued := otev1.ClusterControllerStatus{
+		StatusCode: http.StatusAccepted,
+		Body:       otev1.ClusterControllerStatusQueued,
+	}
+	assert.True(t, isPendingStatus(queued))
+	assert.False(t, isPendingStatus(otev1.ClusterControllerStatus{StatusCode: http.StatusOK}))
+	assert.Equal(t, []string{"c1", "c2"}, selectorClusters("c1,c2"))
+	assert.Nil(t, selectorClusters(""))
+}
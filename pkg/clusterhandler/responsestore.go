@@ -0,0 +1,143 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterhandler
+
+import (
+	"unicode/utf8"
+
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	otev1 "github.com/baidu/ote-stack/pkg/apis/ote/v1"
+	"github.com/baidu/ote-stack/pkg/responsestore"
+)
+
+/*
+Responses of clusters are merged into status of a ClusterController crd,
+bodies longer than ResponseInlineLimit are truncated in status to keep the crd small,
+and the whole bodies are put to the response store, referred by status.<cluster>.bodyRef.
+*/
+
+var (
+	// ResponseStore is the kind of response store, configmap or file, no body is stored if it is empty.
+	ResponseStore string
+	// ResponseStoreDir is the dir of file response store.
+	ResponseStoreDir string
+	// ResponseInlineLimit is the max bytes of a response body kept in status, 0 means no limit.
+	ResponseInlineLimit = 4096
+)
+
+// newResponseStore news the response store of root by ResponseStore, nil if it is not set.
+func (c *clusterHandler) newResponseStore() (responsestore.Store, error) {
+	if ResponseStore == "" || !c.isRoot() {
+		return nil, nil
+	}
+	return responsestore.New(ResponseStore, c.conf.KubeClient, ResponseStoreDir)
+}
+
+// truncateBody returns the leading part of body in limit bytes, not splitting a utf-8 character.
+func truncateBody(body string, limit int) string {
+	if len(body) <= limit {
+		return body
+	}
+	i := limit
+	for i > 0 && !utf8.RuneStart(body[i]) {
+		i--
+	}
+	return body[:i]
+}
+
+/*
+offloadResponses sets size and hash of responses in cc,
+and truncates bodies exceeding ResponseInlineLimit, after putting them to the response store.
+a body is truncated even if it fails to be stored, so that the crd is kept updatable.
+it is called before responses are merged, so a slow store does not block merging responses to other crds.
+*/
+func (c *clusterHandler) offloadResponses(cc *otev1.ClusterController) {
+	var key *responsestore.Key
+	for cn, s := range cc.Status {
+		if s.Body != "" {
+			s.Size = len(s.Body)
+			s.Hash = responsestore.Hash([]byte(s.Body))
+		}
+		if ResponseInlineLimit > 0 && len(s.Body) > ResponseInlineLimit {
+			if c.responseStore != nil && key == nil {
+				key = c.responseKey(cc)
+			}
+			if key != nil {
+				key.Cluster = cn
+				key.Timestamp = s.Timestamp
+				ref, err := c.responseStore.Put(*key, []byte(s.Body))
+				if err != nil {
+					klog.Errorf("store response of %s to %s failed, only the leading %d bytes are kept: %v",
+						cn, cc.ObjectMeta.Name, ResponseInlineLimit, err)
+				}
+				s.BodyRef = ref
+			}
+			s.Body = truncateBody(s.Body, ResponseInlineLimit)
+			s.Truncated = true
+		}
+		cc.Status[cn] = s
+	}
+}
+
+// responseKey returns key of responses to cc, with uid of the crd for owner of bodies, nil if the crd is not found.
+func (c *clusterHandler) responseKey(cc *otev1.ClusterController) *responsestore.Key {
+	origin := c.clusterControllerCRD.Get(cc.ObjectMeta.Namespace, cc.ObjectMeta.Name)
+	if origin == nil {
+		return nil
+	}
+	return &responsestore.Key{
+		Namespace: origin.ObjectMeta.Namespace,
+		Name:      origin.ObjectMeta.Name,
+		UID:       origin.ObjectMeta.UID,
+	}
+}
+
+// deleteResponseBodies deletes bodies referred by refs from the response store, empty refs are skipped.
+func (c *clusterHandler) deleteResponseBodies(refs []string) {
+	for _, ref := range refs {
+		if ref != "" {
+			c.deleteResponseBody(ref)
+		}
+	}
+}
+
+func (c *clusterHandler) deleteResponseBody(ref string) {
+	if c.responseStore == nil {
+		return
+	}
+	if err := c.responseStore.Delete(ref); err != nil {
+		klog.Errorf("delete response body %s failed: %v", ref, err)
+	}
+}
+
+// deleteResponses deletes bodies of responses to a crd deleted from the response store.
+func (c *clusterHandler) deleteResponses(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	cc, ok := obj.(*otev1.ClusterController)
+	if !ok {
+		return
+	}
+	for _, s := range cc.Status {
+		if s.BodyRef != "" {
+			c.deleteResponseBody(s.BodyRef)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterhandler
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	otev1 "github.com/baidu/ote-stack/pkg/apis/ote/v1"
+	"github.com/baidu/ote-stack/pkg/clustermessage"
+	"github.com/baidu/ote-stack/pkg/responsestore"
+)
+
+func TestTruncateBody(t *testing.T) {
+	assert.Equal(t, "abc", truncateBody("abc", 3))
+	assert.Equal(t, "ab", truncateBody("abc", 2))
+	// "中" is 3 bytes, it is not split.
+	assert.Equal(t, "a", truncateBody("a中", 2))
+	assert.Equal(t, "a中", truncateBody("a中b", 4))
+}
+
+func responseMessage(t *testing.T, name, cluster string, timestamp int64, body string) *clustermessage.ClusterMessage {
+	data, err := proto.Marshal(&clustermessage.ControllerTaskResponse{
+		Timestamp:  timestamp,
+		StatusCode: http.StatusOK,
+		Body:       []byte(body),
+	})
+	assert.Nil(t, err)
+	return &clustermessage.ClusterMessage{
+		Head: &clustermessage.MessageHead{
+			MessageID:   name,
+			Command:     clustermessage.CommandType_ControlResp,
+			ClusterName: cluster,
+		},
+		Body: data,
+	}
+}
+
+func TestOffloadResponse(t *testing.T) {
+	dir, err := ioutil.TempDir("", "responsestore")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	store, err := responsestore.New(responsestore.KindFile, nil, dir)
+	assert.Nil(t, err)
+
+	oldLimit := ResponseInlineLimit
+	defer func() { ResponseInlineLimit = oldLimit }()
+	ResponseInlineLimit = 8
+
+	c := newFakeRootClusterHandler(t)
+	c.responseStore = store
+	cc := &otev1.ClusterController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rs1",
+			Namespace: otev1.ClusterNamespace,
+			UID:       "uid1",
+		},
+	}
+	_, err = c.conf.K8sClient.OteV1().ClusterControllers(otev1.ClusterNamespace).Create(cc)
+	assert.Nil(t, err)
+	now := time.Now().Unix()
+
+	// a short body is kept in status.
+	assert.Nil(t, c.mergeToApiserver(responseMessage(t, "rs1", "c1", now, "short")))
+	s := getClusterController(t, c, "rs1").Status["c1"]
+	assert.Equal(t, "short", s.Body)
+	assert.Equal(t, 5, s.Size)
+	assert.Equal(t, responsestore.Hash([]byte("short")), s.Hash)
+	assert.False(t, s.Truncated)
+	assert.Empty(t, s.BodyRef)
+
+	// a long body is truncated, the whole body is in store.
+	long := strings.Repeat("0123456789", 10)
+	assert.Nil(t, c.mergeToApiserver(responseMessage(t, "rs1", "c2", now, long)))
+	s = getClusterController(t, c, "rs1").Status["c2"]
+	assert.Equal(t, long[:8], s.Body)
+	assert.Equal(t, len(long), s.Size)
+	assert.Equal(t, responsestore.Hash([]byte(long)), s.Hash)
+	assert.True(t, s.Truncated)
+	body, err := store.Get(s.BodyRef)
+	assert.Nil(t, err)
+	assert.Equal(t, long, string(body))
+
+	// a newer short response replaces it, the body stored is deleted.
+	assert.Nil(t, c.mergeToApiserver(responseMessage(t, "rs1", "c2", now+1, "done")))
+	s2 := getClusterController(t, c, "rs1").Status["c2"]
+	assert.Equal(t, "done", s2.Body)
+	assert.Empty(t, s2.BodyRef)
+	_, err = store.Get(s.BodyRef)
+	assert.NotNil(t, err)
+
+	// a stale response is not merged, and its body stored is deleted.
+	assert.Nil(t, c.mergeToApiserver(responseMessage(t, "rs1", "c2", now, long)))
+	assert.Equal(t, s2, getClusterController(t, c, "rs1").Status["c2"])
+	files, _ := ioutil.ReadDir(filepath.Join(dir, otev1.ClusterNamespace, "rs1"))
+	assert.Empty(t, files)
+
+	// bodies stored are deleted with the crd.
+	assert.Nil(t, c.mergeToApiserver(responseMessage(t, "rs1", "c3", now, long)))
+	cc = getClusterController(t, c, "rs1")
+	ref := cc.Status["c3"].BodyRef
+	_, err = store.Get(ref)
+	assert.Nil(t, err)
+	c.deleteResponses(cache.DeletedFinalStateUnknown{Obj: cc})
+	_, err = store.Get(ref)
+	assert.NotNil(t, err)
+
+	// the body is truncated even without store.
+	c.responseStore = nil
+	assert.Nil(t, c.mergeToApiserver(responseMessage(t, "rs1", "c4", now, long)))
+	s = getClusterController(t, c, "rs1").Status["c4"]
+	assert.Equal(t, long[:8], s.Body)
+	assert.True(t, s.Truncated)
+	assert.Empty(t, s.BodyRef)
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterhandler
+
+import (
+	"sync"
+
+	"k8s.io/klog"
+
+	"github.com/baidu/ote-stack/pkg/clusterrouter"
+	"github.com/baidu/ote-stack/pkg/clusterselector"
+)
+
+// subtreeVersions are versions of subtree routes applied for each child.
+type subtreeVersions struct {
+	mutex    sync.Mutex
+	versions map[string]uint64
+	// offered are childs told that versions are understood.
+	offered map[string]bool
+}
+
+// get returns version of subtree of child, 0 if it is unknown.
+func (s *subtreeVersions) get(child string) uint64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.versions[child]
+}
+
+func (s *subtreeVersions) set(child string, version uint64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.versions == nil {
+		s.versions = make(map[string]uint64)
+	}
+	s.versions[child] = version
+}
+
+// offer returns true if child is not told that versions are understood yet, and marks it told.
+func (s *subtreeVersions) offer(child string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.offered[child] {
+		return false
+	}
+	if s.offered == nil {
+		s.offered = make(map[string]bool)
+	}
+	s.offered[child] = true
+	return true
+}
+
+func (s *subtreeVersions) forget(child string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.versions, child)
+	delete(s.offered, child)
+}
+
+// replaceRouteToSubtree replaces routes through child by a full sync.
+func (c *clusterHandler) replaceRouteToSubtree(child string, info *clusterrouter.SubtreeInfo) {
+	for to := range info.Routes {
+		if err := clusterrouter.Router().AddRoute(to, child); err != nil {
+			klog.Errorf("add subtree router %s-%s failed: %v", to, child, err)
+			continue
+		}
+		clusterrouter.Router().SetLabels(to, info.Labels[to])
+		clusterrouter.Router().SetParent(to, info.Parents[to])
+	}
+	// delete route from child but not in subtrees
+	for _, to := range clusterrouter.Router().SubTreeOfPort(child) {
+		if _, ok := info.Routes[to]; !ok {
+			clusterrouter.Router().DelRoute(to, child)
+		}
+	}
+}
+
+// applySubtreeDelta applies added and deleted routes through child.
+func (c *clusterHandler) applySubtreeDelta(child string, delta *clusterrouter.SubtreeInfo) {
+	for to := range delta.Routes {
+		if err := clusterrouter.Router().AddRoute(to, child); err != nil {
+			klog.Errorf("add subtree router %s-%s failed: %v", to, child, err)
+			continue
+		}
+		clusterrouter.Router().SetLabels(to, delta.Labels[to])
+		clusterrouter.Router().SetParent(to, delta.Parents[to])
+	}
+	for _, to := range delta.Deleted {
+		if to != child {
+			clusterrouter.Router().DelRoute(to, child)
+		}
+	}
+}
+
+// resyncSubtree forgets version of subtree of child and asks it for a full sync.
+func (c *clusterHandler) resyncSubtree(child string) {
+	c.subtreeVersions.forget(child)
+	c.sendSubtreeAck(child, &clusterrouter.SubtreeAck{Resync: true})
+}
+
+func (c *clusterHandler) sendSubtreeAck(child string, ack *clusterrouter.SubtreeAck) {
+	msg := ack.Message()
+	if msg == nil {
+		return
+	}
+	msg.Head.ClusterSelector = clusterselector.QuoteName(child)
+	c.sendToChild(msg, child)
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterhandler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/baidu/ote-stack/pkg/clustermessage"
+	"github.com/baidu/ote-stack/pkg/clusterrouter"
+	"github.com/baidu/ote-stack/pkg/clusterselector"
+)
+
+// openSubtreeAckQueue returns a channel receiving subtree acks sent to child.
+func openSubtreeAckQueue(c *clusterHandler, child string) chan *clusterrouter.SubtreeAck {
+	ret := make(chan *clusterrouter.SubtreeAck, 10)
+	c.queues.open(child, func(data []byte) error {
+		msg := &clustermessage.ClusterMessage{}
+		if err := proto.Unmarshal(data, msg); err != nil {
+			return err
+		}
+		if msg.Head.Command != clustermessage.CommandType_SubTreeRoute ||
+			msg.Head.ClusterSelector != clusterselector.QuoteName(child) {
+			return nil
+		}
+		ret <- clusterrouter.SubtreeAckFromClusterMessage(msg)
+		return nil
+	})
+	return ret
+}
+
+func receiveSubtreeAck(t *testing.T, ch chan *clusterrouter.SubtreeAck) *clusterrouter.SubtreeAck {
+	select {
+	case ack := <-ch:
+		return ack
+	case <-time.After(time.Second):
+		t.Fatal("no subtree ack is sent")
+	}
+	return nil
+}
+
+func subtreeMessage(child string, info *clusterrouter.SubtreeInfo) *clustermessage.ClusterMessage {
+	msg := info.Message()
+	msg.Head.ClusterName = child
+	return msg
+}
+
+func TestVersionedSubtree(t *testing.T) {
+	c := newFakeRootClusterHandler(t)
+	acks := openSubtreeAckQueue(c, "s1")
+	defer c.queues.close("s1")
+	defer clusterrouter.Router().DelRoute("s1", "s1")
+
+	// full sync is acknowledged
+	assert.Nil(t, c.updateRouteToSubtree(subtreeMessage("s1", &clusterrouter.SubtreeInfo{
+		Routes:  clusterrouter.SubTreeRouter{"s2": "s2", "s3": "s2"},
+		Version: 1,
+	})))
+	assert.Equal(t, &clusterrouter.SubtreeAck{Version: 1}, receiveSubtreeAck(t, acks))
+	assert.True(t, clusterrouter.Router().HasRoute("s3", "s1"))
+
+	// delta is applied
+	assert.Nil(t, c.updateRouteToSubtree(subtreeMessage("s1", &clusterrouter.SubtreeInfo{
+		Routes:   clusterrouter.SubTreeRouter{"s4": "s4"},
+		Labels:   map[string]map[string]string{"s4": {"region": "north"}},
+		Deleted:  []string{"s3"},
+		Base:     1,
+		Version:  2,
+		Checksum: clusterrouter.SubtreeChecksum([]string{"s2", "s4"}),
+	})))
+	assert.True(t, clusterrouter.Router().HasRoute("s4", "s1"))
+	assert.False(t, clusterrouter.Router().HasRoute("s3", "s1"))
+	assert.Equal(t, map[string]string{"region": "north"}, clusterrouter.Router().Labels("s4"))
+	assert.Equal(t, uint64(2), c.subtreeVersions.get("s1"))
+
+	// a version gap asks for full sync
+	assert.Nil(t, c.updateRouteToSubtree(subtreeMessage("s1", &clusterrouter.SubtreeInfo{
+		Routes:  clusterrouter.SubTreeRouter{"s5": "s5"},
+		Base:    3,
+		Version: 4,
+	})))
+	assert.Equal(t, &clusterrouter.SubtreeAck{Resync: true}, receiveSubtreeAck(t, acks))
+	assert.False(t, clusterrouter.Router().HasRoute("s5", "s1"))
+	assert.Equal(t, uint64(0), c.subtreeVersions.get("s1"))
+
+	// so does a checksum mismatch
+	assert.Nil(t, c.updateRouteToSubtree(subtreeMessage("s1", &clusterrouter.SubtreeInfo{
+		Routes:  clusterrouter.SubTreeRouter{"s2": "s2"},
+		Version: 5,
+	})))
+	assert.Equal(t, &clusterrouter.SubtreeAck{Version: 5}, receiveSubtreeAck(t, acks))
+	assert.False(t, clusterrouter.Router().HasRoute("s4", "s1"))
+	assert.Nil(t, c.updateRouteToSubtree(subtreeMessage("s1", &clusterrouter.SubtreeInfo{
+		Routes:   clusterrouter.SubTreeRouter{},
+		Base:     5,
+		Version:  5,
+		Checksum: clusterrouter.SubtreeChecksum([]string{"s2", "s4"}),
+	})))
+	assert.Equal(t, &clusterrouter.SubtreeAck{Resync: true}, receiveSubtreeAck(t, acks))
+}
+
+func TestBareSubtree(t *testing.T) {
+	c := newFakeRootClusterHandler(t)
+	acks := openSubtreeAckQueue(c, "b1")
+	defer c.queues.close("b1")
+	defer clusterrouter.Router().DelRoute("b1", "b1")
+
+	bareMessage := func(routes clusterrouter.SubTreeRouter) *clustermessage.ClusterMessage {
+		msg := routes.Message()
+		msg.Head.ClusterName = "b1"
+		return msg
+	}
+
+	// bare routes are applied, and child is told once that versions are understood
+	assert.Nil(t, c.updateRouteToSubtree(bareMessage(clusterrouter.SubTreeRouter{"b2": "b2"})))
+	assert.Equal(t, &clusterrouter.SubtreeAck{Versioned: true}, receiveSubtreeAck(t, acks))
+	assert.True(t, clusterrouter.Router().HasRoute("b2", "b1"))
+	assert.Nil(t, c.updateRouteToSubtree(bareMessage(clusterrouter.SubTreeRouter{"b3": "b3"})))
+	assert.True(t, clusterrouter.Router().HasRoute("b3", "b1"))
+	assert.False(t, clusterrouter.Router().HasRoute("b2", "b1"))
+	select {
+	case ack := <-acks:
+		t.Errorf("unexpected subtree ack %v", ack)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// and told again after reconnecting
+	c.subtreeVersions.forget("b1")
+	assert.Nil(t, c.updateRouteToSubtree(bareMessage(clusterrouter.SubTreeRouter{"b3": "b3"})))
+	assert.Equal(t, &clusterrouter.SubtreeAck{Versioned: true}, receiveSubtreeAck(t, acks))
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterhandler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"k8s.io/klog"
+
+	"github.com/baidu/ote-stack/pkg/clusterrouter"
+	"github.com/baidu/ote-stack/pkg/tunnel"
+)
+
+/*
+Topology of a cluster is served as json on the tunnel listener for operators and dashboards.
+
+GET /topology returns route info of this cluster, which are childs, neighbor, parent neighbor,
+subtree routes, state of links to childs, and state of connections dialed, e.g., to parent.
+
+GET /topology/tree returns the subtree of this cluster assembled from subtree routes,
+at root it is the whole tree.
+*/
+
+const (
+	topologyURI     = "/topology"
+	topologyTreeURI = "/topology/tree"
+)
+
+// topology is the view of the tree from a cluster.
+type topology struct {
+	Name string `json:"name"`
+	*clusterrouter.Topology
+	Links map[string]tunnel.LinkInfo `json:"links"`
+	// Connections are connections dialed by this cluster, e.g., to parent.
+	Connections map[string]tunnel.ReconnectStatus `json:"connections"`
+}
+
+func (c *clusterHandler) topology() *topology {
+	return &topology{
+		Name:        c.conf.ClusterName,
+		Topology:    clusterrouter.Router().Topology(),
+		Links:       c.tunn.ChildLinks(),
+		Connections: tunnel.ReconnectStatuses(),
+	}
+}
+
+// topologyTree returns subtree of this cluster with state of links to childs.
+func (c *clusterHandler) topologyTree() *clusterrouter.TreeNode {
+	tree := clusterrouter.Router().Tree(c.conf.ClusterName)
+	tree.Labels = c.conf.ClusterLabels
+	links := c.tunn.ChildLinks()
+	for _, child := range tree.Childs {
+		if link, ok := links[child.Name]; ok {
+			child.Link = link
+		}
+	}
+	return tree
+}
+
+func (c *clusterHandler) topologyHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, c.topology())
+}
+
+func (c *clusterHandler) topologyTreeHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, c.topologyTree())
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		klog.Errorf("marshal %v failed: %v", v, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterhandler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/baidu/ote-stack/pkg/clusterrouter"
+	"github.com/baidu/ote-stack/pkg/tunnel"
+)
+
+func TestTopologyHandler(t *testing.T) {
+	c := newFakeRootClusterHandler(t)
+	c.conf.ClusterName = "root"
+	clusterrouter.Router().AddRoute("t1", "t1")
+	clusterrouter.Router().AddRoute("t2", "t1")
+	clusterrouter.Router().SetParent("t2", "t1")
+	defer clusterrouter.Router().DelRoute("t1", "t1")
+	reconnector := tunnel.NewReconnector("topology-parent", tunnel.DefaultReconnectPolicy())
+	defer reconnector.Close()
+
+	w := httptest.NewRecorder()
+	c.topologyHandler(w, httptest.NewRequest(http.MethodGet, topologyURI, nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	topo := make(map[string]interface{})
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &topo))
+	assert.Equal(t, "root", topo["name"])
+	assert.Equal(t, "t1", topo["subtree"].(map[string]interface{})["t2"])
+	assert.Contains(t, topo["links"], "c1")
+	assert.Equal(t, "connecting", topo["connections"].(map[string]interface{})["topology-parent"].(map[string]interface{})["state"])
+
+	w = httptest.NewRecorder()
+	c.topologyTreeHandler(w, httptest.NewRequest(http.MethodGet, topologyTreeURI, nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	tree := c.topologyTree()
+	assert.Equal(t, "root", tree.Name)
+	var t1 *clusterrouter.TreeNode
+	for _, child := range tree.Childs {
+		if child.Name == "t1" {
+			t1 = child
+		}
+	}
+	if assert.NotNil(t, t1) {
+		assert.Equal(t, 1, len(t1.Childs))
+		assert.Equal(t, "t2", t1.Childs[0].Name)
+	}
+}
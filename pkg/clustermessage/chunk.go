@@ -0,0 +1,203 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clustermessage
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/uuid"
+)
+
+/*
+A large message is split into Chunk messages, each of which carries a part of
+the serialized message as body, and ChunkIndex and ChunkTotal in head.
+Chunks of the same message share a MessageID generated when splitting,
+so chunks of different messages can be interleaved with each other and other messages.
+The receiver reassembles chunks by Reassembler.
+*/
+
+const (
+	// maxPartialMessages is the max number of messages being reassembled at the same time.
+	maxPartialMessages = 16
+	// minChunkSize is the min size of a chunk, which bounds the number of chunks of a message.
+	minChunkSize = 64
+)
+
+// SplitMessage splits serialized message data into serialized Chunk messages,
+// each of which carries at most size bytes of data, size less than minChunkSize is raised to it.
+// data is returned as the only one if it is not larger than size.
+func SplitMessage(data []byte, size int) ([][]byte, error) {
+	if size <= 0 || len(data) <= size {
+		return [][]byte{data}, nil
+	}
+	if size < minChunkSize {
+		size = minChunkSize
+		if len(data) <= size {
+			return [][]byte{data}, nil
+		}
+	}
+	total := (len(data) + size - 1) / size
+	if total > math.MaxInt32 {
+		return nil, fmt.Errorf("too many chunks: %d", total)
+	}
+
+	id := string(uuid.NewUUID())
+	chunks := make([][]byte, 0, total)
+	for i := 0; i < total; i++ {
+		end := (i + 1) * size
+		if end > len(data) {
+			end = len(data)
+		}
+		chunk := &ClusterMessage{
+			Head: &MessageHead{
+				MessageID:  id,
+				Command:    CommandType_Chunk,
+				ChunkIndex: int32(i),
+				ChunkTotal: int32(total),
+			},
+			Body: data[i*size : end],
+		}
+		c, err := chunk.Serialize()
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, c)
+	}
+	return chunks, nil
+}
+
+// chunkHead returns head of serialized message data if it is a Chunk.
+func chunkHead(data []byte) *MessageHead {
+	head := ParseHead(data)
+	if head == nil || head.Command != CommandType_Chunk {
+		return nil
+	}
+	return head
+}
+
+// partialMessage is a message whose chunks are being received.
+// chunks are kept by index as they are received, so a forged total allocates nothing.
+type partialMessage struct {
+	chunks  map[int32][]byte
+	total   int32
+	size    int
+	updated time.Time
+}
+
+// Reassembler reassembles Chunk messages to the messages split by SplitMessage.
+type Reassembler struct {
+	maxSize  int
+	timeout  time.Duration
+	mutex    sync.Mutex
+	partials map[string]*partialMessage
+}
+
+// NewReassembler returns a Reassembler which refuses messages larger than maxSize,
+// and drops messages not completed in timeout after their last chunk.
+func NewReassembler(maxSize int, timeout time.Duration) *Reassembler {
+	return &Reassembler{
+		maxSize:  maxSize,
+		timeout:  timeout,
+		partials: make(map[string]*partialMessage),
+	}
+}
+
+// Add adds serialized message data to the reassembler.
+// It returns data itself if it is not a Chunk, the reassembled message once all chunks of it are added,
+// or nil if more chunks are needed.
+func (r *Reassembler) Add(data []byte) ([]byte, error) {
+	head := chunkHead(data)
+	if head == nil {
+		return data, nil
+	}
+	chunk := &ClusterMessage{}
+	if err := chunk.Deserialize(data); err != nil {
+		return nil, err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := time.Now()
+	r.expire(now)
+
+	id := head.MessageID
+	p, ok := r.partials[id]
+	if !ok {
+		maxTotal := (int64(r.maxSize) + minChunkSize - 1) / minChunkSize
+		if head.ChunkTotal <= 0 || int64(head.ChunkTotal) > maxTotal {
+			return nil, fmt.Errorf("chunk of message %s has invalid total %d", id, head.ChunkTotal)
+		}
+		if len(r.partials) >= maxPartialMessages {
+			r.dropOldest()
+		}
+		p = &partialMessage{chunks: make(map[int32][]byte), total: head.ChunkTotal}
+		r.partials[id] = p
+	}
+	if head.ChunkTotal != p.total || head.ChunkIndex < 0 || head.ChunkIndex >= p.total {
+		delete(r.partials, id)
+		return nil, fmt.Errorf("chunk %d/%d of message %s is invalid", head.ChunkIndex, head.ChunkTotal, id)
+	}
+	p.updated = now
+	if _, ok := p.chunks[head.ChunkIndex]; ok {
+		// duplicated chunk.
+		return nil, nil
+	}
+	p.size += len(chunk.Body)
+	if p.size > r.maxSize {
+		delete(r.partials, id)
+		return nil, fmt.Errorf("message %s is larger than %d bytes", id, r.maxSize)
+	}
+	p.chunks[head.ChunkIndex] = chunk.Body
+	if int32(len(p.chunks)) < p.total {
+		return nil, nil
+	}
+
+	delete(r.partials, id)
+	msg := make([]byte, 0, p.size)
+	for i := int32(0); i < p.total; i++ {
+		msg = append(msg, p.chunks[i]...)
+	}
+	return msg, nil
+}
+
+// expire drops messages not updated in timeout.
+func (r *Reassembler) expire(now time.Time) {
+	if r.timeout <= 0 {
+		return
+	}
+	for id, p := range r.partials {
+		if now.Sub(p.updated) > r.timeout {
+			delete(r.partials, id)
+		}
+	}
+}
+
+// dropOldest drops the message updated earliest.
+func (r *Reassembler) dropOldest() {
+	var oldest string
+	var updated time.Time
+	for id, p := range r.partials {
+		if oldest == "" || p.updated.Before(updated) {
+			oldest, updated = id, p.updated
+		}
+	}
+	delete(r.partials, oldest)
+}
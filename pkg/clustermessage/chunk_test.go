@@ -0,0 +1,141 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clustermessage
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChunk(t *testing.T) {
+	msg := &ClusterMessage{
+		Head: &MessageHead{
+			MessageID: "m1",
+			Command:   CommandType_ControlReq,
+		},
+		Body: bytes.Repeat([]byte("0123456789"), 100),
+	}
+	data, err := msg.Serialize()
+	assert.Nil(t, err)
+
+	// small message is not split.
+	chunks, err := SplitMessage(data, len(data))
+	assert.Nil(t, err)
+	assert.Equal(t, [][]byte{data}, chunks)
+	assert.Nil(t, chunkHead(data))
+
+	chunks, err = SplitMessage(data, 100)
+	assert.Nil(t, err)
+	assert.Equal(t, (len(data)+99)/100, len(chunks))
+	head := chunkHead(chunks[1])
+	assert.NotNil(t, head)
+	assert.Equal(t, int32(1), head.ChunkIndex)
+	assert.Equal(t, int32(len(chunks)), head.ChunkTotal)
+
+	// chunks are reassembled in any order with other messages interleaved.
+	r := NewReassembler(len(data), time.Minute)
+	for i := len(chunks) - 1; i > 0; i-- {
+		m, err := r.Add(chunks[i])
+		assert.Nil(t, err)
+		assert.Nil(t, m)
+	}
+	m, err := r.Add(data)
+	assert.Nil(t, err)
+	assert.Equal(t, data, m)
+	// duplicated chunk is ignored.
+	m, err = r.Add(chunks[1])
+	assert.Nil(t, err)
+	assert.Nil(t, m)
+	m, err = r.Add(chunks[0])
+	assert.Nil(t, err)
+	assert.Equal(t, data, m)
+	assert.Empty(t, r.partials)
+
+	// message larger than max size is dropped.
+	r = NewReassembler(len(data)-1, time.Minute)
+	for i := 0; i < len(chunks)-1; i++ {
+		_, err = r.Add(chunks[i])
+		assert.Nil(t, err)
+	}
+	_, err = r.Add(chunks[len(chunks)-1])
+	assert.NotNil(t, err)
+	assert.Empty(t, r.partials)
+
+	// incomplete message expires.
+	r = NewReassembler(len(data), time.Millisecond)
+	_, err = r.Add(chunks[0])
+	assert.Nil(t, err)
+	time.Sleep(2 * time.Millisecond)
+	_, err = r.Add(chunks[1])
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(r.partials))
+	assert.Equal(t, 1, len(r.partials[head.MessageID].chunks))
+
+	// the oldest message is dropped if too many are being reassembled.
+	r = NewReassembler(len(data), time.Minute)
+	_, err = r.Add(chunks[0])
+	assert.Nil(t, err)
+	for i := 0; i < maxPartialMessages; i++ {
+		others, err := SplitMessage(data, 100)
+		assert.Nil(t, err)
+		_, err = r.Add(others[0])
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, maxPartialMessages, len(r.partials))
+	_, ok := r.partials[head.MessageID]
+	assert.False(t, ok)
+
+	// invalid chunk.
+	invalid := &ClusterMessage{Head: &MessageHead{MessageID: "x", Command: CommandType_Chunk, ChunkTotal: 0}}
+	data, err = invalid.Serialize()
+	assert.Nil(t, err)
+	_, err = r.Add(data)
+	assert.NotNil(t, err)
+
+	// a forged total more than chunks of the max size is refused, so it allocates nothing.
+	r = NewReassembler(64<<20, time.Minute)
+	oversized := &ClusterMessage{Head: &MessageHead{MessageID: "y", Command: CommandType_Chunk,
+		ChunkTotal: (64<<20)/minChunkSize + 1}}
+	data, err = oversized.Serialize()
+	assert.Nil(t, err)
+	_, err = r.Add(data)
+	assert.NotNil(t, err)
+	assert.Empty(t, r.partials)
+
+	// an empty chunk repeated does not complete a message.
+	empty := &ClusterMessage{Head: &MessageHead{MessageID: "z", Command: CommandType_Chunk, ChunkTotal: 2}}
+	data, err = empty.Serialize()
+	assert.Nil(t, err)
+	for i := 0; i < 2; i++ {
+		msg, err := r.Add(data)
+		assert.Nil(t, err)
+		assert.Nil(t, msg)
+	}
+}
+
+func TestSplitMessageMinChunkSize(t *testing.T) {
+	data := make([]byte, minChunkSize*2)
+	chunks, err := SplitMessage(data, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(chunks))
+	chunks, err = SplitMessage(data[:minChunkSize], 1)
+	assert.Nil(t, err)
+	assert.Equal(t, [][]byte{data[:minChunkSize]}, chunks)
+}
@@ -4,8 +4,12 @@
 package clustermessage
 
 import (
+	context "context"
 	fmt "fmt"
 	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
 	math "math"
 )
 
@@ -23,17 +27,21 @@ const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 type CommandType int32
 
 const (
-	CommandType_Reserved        CommandType = 0
-	CommandType_ClusterRegist   CommandType = 1
-	CommandType_ClusterUnregist CommandType = 2
-	CommandType_NeighborRoute   CommandType = 3
-	CommandType_SubTreeRoute    CommandType = 4
-	CommandType_DeployReq       CommandType = 5
-	CommandType_DeployResp      CommandType = 6
-	CommandType_ControlReq      CommandType = 7
-	CommandType_ControlResp     CommandType = 8
-	CommandType_EdgeReport      CommandType = 9
-	CommandType_ControlMultiReq CommandType = 10
+	CommandType_Reserved            CommandType = 0
+	CommandType_ClusterRegist       CommandType = 1
+	CommandType_ClusterUnregist     CommandType = 2
+	CommandType_NeighborRoute       CommandType = 3
+	CommandType_SubTreeRoute        CommandType = 4
+	CommandType_DeployReq           CommandType = 5
+	CommandType_DeployResp          CommandType = 6
+	CommandType_ControlReq          CommandType = 7
+	CommandType_ControlResp         CommandType = 8
+	CommandType_EdgeReport          CommandType = 9
+	CommandType_ControlMultiReq     CommandType = 10
+	CommandType_Chunk               CommandType = 11
+	CommandType_ShardAssign         CommandType = 12
+	CommandType_GoingAway           CommandType = 13
+	CommandType_ClusterNameConflict CommandType = 14
 )
 
 var CommandType_name = map[int32]string{
@@ -48,20 +56,28 @@ var CommandType_name = map[int32]string{
 	8:  "ControlResp",
 	9:  "EdgeReport",
 	10: "ControlMultiReq",
+	11: "Chunk",
+	12: "ShardAssign",
+	13: "GoingAway",
+	14: "ClusterNameConflict",
 }
 
 var CommandType_value = map[string]int32{
-	"Reserved":        0,
-	"ClusterRegist":   1,
-	"ClusterUnregist": 2,
-	"NeighborRoute":   3,
-	"SubTreeRoute":    4,
-	"DeployReq":       5,
-	"DeployResp":      6,
-	"ControlReq":      7,
-	"ControlResp":     8,
-	"EdgeReport":      9,
-	"ControlMultiReq": 10,
+	"Reserved":            0,
+	"ClusterRegist":       1,
+	"ClusterUnregist":     2,
+	"NeighborRoute":       3,
+	"SubTreeRoute":        4,
+	"DeployReq":           5,
+	"DeployResp":          6,
+	"ControlReq":          7,
+	"ControlResp":         8,
+	"EdgeReport":          9,
+	"ControlMultiReq":     10,
+	"Chunk":               11,
+	"ShardAssign":         12,
+	"GoingAway":           13,
+	"ClusterNameConflict": 14,
 }
 
 func (x CommandType) String() string {
@@ -72,6 +88,37 @@ func (CommandType) EnumDescriptor() ([]byte, []int) {
 	return fileDescriptor_cb5c8b0b58767cdb, []int{0}
 }
 
+type Priority int32
+
+const (
+	Priority_Auto    Priority = 0
+	Priority_Route   Priority = 1
+	Priority_Control Priority = 2
+	Priority_Bulk    Priority = 3
+)
+
+var Priority_name = map[int32]string{
+	0: "Auto",
+	1: "Route",
+	2: "Control",
+	3: "Bulk",
+}
+
+var Priority_value = map[string]int32{
+	"Auto":    0,
+	"Route":   1,
+	"Control": 2,
+	"Bulk":    3,
+}
+
+func (x Priority) String() string {
+	return proto.EnumName(Priority_name, int32(x))
+}
+
+func (Priority) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_cb5c8b0b58767cdb, []int{1}
+}
+
 // ClusterMessage is the message between cluster controllers and maybe cc and cluster shim.
 type ClusterMessage struct {
 	Head                 *MessageHead `protobuf:"bytes,1,opt,name=Head,proto3" json:"Head,omitempty"`
@@ -123,14 +170,29 @@ func (m *ClusterMessage) GetBody() []byte {
 type MessageHead struct {
 	// MessageID is the uuid of a cluster message.
 	// if the message comes from a crd, the messageid is the name of the crd.
-	MessageID            string      `protobuf:"bytes,1,opt,name=MessageID,proto3" json:"MessageID,omitempty"`
-	Command              CommandType `protobuf:"varint,2,opt,name=Command,proto3,enum=clustermessage.CommandType" json:"Command,omitempty"`
-	ClusterSelector      string      `protobuf:"bytes,3,opt,name=ClusterSelector,proto3" json:"ClusterSelector,omitempty"`
-	ClusterName          string      `protobuf:"bytes,4,opt,name=ClusterName,proto3" json:"ClusterName,omitempty"`
-	ParentClusterName    string      `protobuf:"bytes,5,opt,name=ParentClusterName,proto3" json:"ParentClusterName,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
-	XXX_unrecognized     []byte      `json:"-"`
-	XXX_sizecache        int32       `json:"-"`
+	MessageID         string      `protobuf:"bytes,1,opt,name=MessageID,proto3" json:"MessageID,omitempty"`
+	Command           CommandType `protobuf:"varint,2,opt,name=Command,proto3,enum=clustermessage.CommandType" json:"Command,omitempty"`
+	ClusterSelector   string      `protobuf:"bytes,3,opt,name=ClusterSelector,proto3" json:"ClusterSelector,omitempty"`
+	ClusterName       string      `protobuf:"bytes,4,opt,name=ClusterName,proto3" json:"ClusterName,omitempty"`
+	ParentClusterName string      `protobuf:"bytes,5,opt,name=ParentClusterName,proto3" json:"ParentClusterName,omitempty"`
+	// ExpireTime is the unix time after which the message is useless,
+	// 0 means it never expires.
+	ExpireTime int64 `protobuf:"varint,6,opt,name=ExpireTime,proto3" json:"ExpireTime,omitempty"`
+	// ChunkIndex and ChunkTotal are set if the message is a Chunk,
+	// MessageID of chunks of the same message is the same.
+	ChunkIndex int32 `protobuf:"varint,7,opt,name=ChunkIndex,proto3" json:"ChunkIndex,omitempty"`
+	ChunkTotal int32 `protobuf:"varint,8,opt,name=ChunkTotal,proto3" json:"ChunkTotal,omitempty"`
+	// Priority is the class by which the message is queued on a link,
+	// it is decided by Command if it is Auto.
+	Priority Priority `protobuf:"varint,9,opt,name=Priority,proto3,enum=clustermessage.Priority" json:"Priority,omitempty"`
+	// TTL is the number of hops the message can be transmitted further,
+	// 0 means it is not set and DefaultMessageTTL is taken by the first cluster transmitting it.
+	TTL int32 `protobuf:"varint,10,opt,name=TTL,proto3" json:"TTL,omitempty"`
+	// Path is the clusters the message has been transmitted by, in order.
+	Path                 []string `protobuf:"bytes,11,rep,name=Path,proto3" json:"Path,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *MessageHead) Reset()         { *m = MessageHead{} }
@@ -193,6 +255,48 @@ func (m *MessageHead) GetParentClusterName() string {
 	return ""
 }
 
+func (m *MessageHead) GetExpireTime() int64 {
+	if m != nil {
+		return m.ExpireTime
+	}
+	return 0
+}
+
+func (m *MessageHead) GetChunkIndex() int32 {
+	if m != nil {
+		return m.ChunkIndex
+	}
+	return 0
+}
+
+func (m *MessageHead) GetChunkTotal() int32 {
+	if m != nil {
+		return m.ChunkTotal
+	}
+	return 0
+}
+
+func (m *MessageHead) GetPriority() Priority {
+	if m != nil {
+		return m.Priority
+	}
+	return Priority_Auto
+}
+
+func (m *MessageHead) GetTTL() int32 {
+	if m != nil {
+		return m.TTL
+	}
+	return 0
+}
+
+func (m *MessageHead) GetPath() []string {
+	if m != nil {
+		return m.Path
+	}
+	return nil
+}
+
 type ControllerTask struct {
 	Destination          string   `protobuf:"bytes,1,opt,name=Destination,proto3" json:"Destination,omitempty"`
 	Method               string   `protobuf:"bytes,2,opt,name=Method,proto3" json:"Method,omitempty"`
@@ -429,8 +533,98 @@ func (m *ControlMultiTask) GetBody() [][]byte {
 	return nil
 }
 
+// ShardAssignment tells a controller manager its member name and all members of the shard ring.
+type ShardAssignment struct {
+	Member               string   `protobuf:"bytes,1,opt,name=Member,proto3" json:"Member,omitempty"`
+	Members              []string `protobuf:"bytes,2,rep,name=Members,proto3" json:"Members,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ShardAssignment) Reset()         { *m = ShardAssignment{} }
+func (m *ShardAssignment) String() string { return proto.CompactTextString(m) }
+func (*ShardAssignment) ProtoMessage()    {}
+func (*ShardAssignment) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cb5c8b0b58767cdb, []int{6}
+}
+
+func (m *ShardAssignment) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ShardAssignment.Unmarshal(m, b)
+}
+func (m *ShardAssignment) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ShardAssignment.Marshal(b, m, deterministic)
+}
+func (m *ShardAssignment) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ShardAssignment.Merge(m, src)
+}
+func (m *ShardAssignment) XXX_Size() int {
+	return xxx_messageInfo_ShardAssignment.Size(m)
+}
+func (m *ShardAssignment) XXX_DiscardUnknown() {
+	xxx_messageInfo_ShardAssignment.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ShardAssignment proto.InternalMessageInfo
+
+func (m *ShardAssignment) GetMember() string {
+	if m != nil {
+		return m.Member
+	}
+	return ""
+}
+
+func (m *ShardAssignment) GetMembers() []string {
+	if m != nil {
+		return m.Members
+	}
+	return nil
+}
+
+// TunnelFrame is a message of the Tunnel stream, Data is a serialized ClusterMessage,
+// or a frame of reliable delivery if it is negotiated by metadata, compressed if compression is negotiated.
+type TunnelFrame struct {
+	Data                 []byte   `protobuf:"bytes,1,opt,name=Data,proto3" json:"Data,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TunnelFrame) Reset()         { *m = TunnelFrame{} }
+func (m *TunnelFrame) String() string { return proto.CompactTextString(m) }
+func (*TunnelFrame) ProtoMessage()    {}
+func (*TunnelFrame) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cb5c8b0b58767cdb, []int{7}
+}
+
+func (m *TunnelFrame) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TunnelFrame.Unmarshal(m, b)
+}
+func (m *TunnelFrame) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TunnelFrame.Marshal(b, m, deterministic)
+}
+func (m *TunnelFrame) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TunnelFrame.Merge(m, src)
+}
+func (m *TunnelFrame) XXX_Size() int {
+	return xxx_messageInfo_TunnelFrame.Size(m)
+}
+func (m *TunnelFrame) XXX_DiscardUnknown() {
+	xxx_messageInfo_TunnelFrame.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TunnelFrame proto.InternalMessageInfo
+
+func (m *TunnelFrame) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterEnum("clustermessage.CommandType", CommandType_name, CommandType_value)
+	proto.RegisterEnum("clustermessage.Priority", Priority_name, Priority_value)
 	proto.RegisterType((*ClusterMessage)(nil), "clustermessage.ClusterMessage")
 	proto.RegisterType((*MessageHead)(nil), "clustermessage.MessageHead")
 	proto.RegisterType((*ControllerTask)(nil), "clustermessage.ControllerTask")
@@ -438,44 +632,172 @@ func init() {
 	proto.RegisterType((*DeployTask)(nil), "clustermessage.DeployTask")
 	proto.RegisterMapType((map[string]string)(nil), "clustermessage.DeployTask.PodParamsEntry")
 	proto.RegisterType((*ControlMultiTask)(nil), "clustermessage.ControlMultiTask")
+	proto.RegisterType((*ShardAssignment)(nil), "clustermessage.ShardAssignment")
+	proto.RegisterType((*TunnelFrame)(nil), "clustermessage.TunnelFrame")
 }
 
 func init() { proto.RegisterFile("clustermessage.proto", fileDescriptor_cb5c8b0b58767cdb) }
 
 var fileDescriptor_cb5c8b0b58767cdb = []byte{
-	// 530 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x54, 0xc1, 0x6e, 0xda, 0x40,
-	0x10, 0xad, 0x31, 0x24, 0xf1, 0x90, 0x38, 0xce, 0x34, 0x8a, 0x50, 0x5a, 0x55, 0x88, 0x13, 0xad,
-	0x2a, 0x2a, 0xa5, 0xaa, 0x54, 0x55, 0x3d, 0x15, 0xa2, 0x36, 0x07, 0x22, 0xb4, 0xc0, 0x07, 0x2c,
-	0x78, 0x44, 0xdc, 0xd8, 0x5e, 0x77, 0x77, 0x1d, 0x89, 0x2f, 0xec, 0xa9, 0x7f, 0xd0, 0x8f, 0xa9,
-	0x76, 0xbd, 0x80, 0x21, 0xe7, 0xde, 0xfc, 0xde, 0xbe, 0x9d, 0x37, 0xf3, 0x76, 0x64, 0xb8, 0x5c,
-	0xa6, 0xa5, 0xd2, 0x24, 0x33, 0x52, 0x8a, 0xaf, 0x68, 0x50, 0x48, 0xa1, 0x05, 0x86, 0xfb, 0x6c,
-	0x6f, 0x0e, 0xe1, 0xb0, 0x62, 0xc6, 0x15, 0x83, 0x1f, 0xa0, 0xf9, 0x83, 0x78, 0xdc, 0xf1, 0xba,
-	0x5e, 0xbf, 0x7d, 0xf3, 0x6a, 0x70, 0x50, 0xc6, 0xc9, 0x8c, 0x84, 0x59, 0x21, 0x22, 0x34, 0xbf,
-	0x89, 0x78, 0xdd, 0x69, 0x74, 0xbd, 0xfe, 0x29, 0xb3, 0xdf, 0xbd, 0xbf, 0x1e, 0xb4, 0x6b, 0x4a,
-	0x7c, 0x0d, 0x81, 0x83, 0x77, 0x23, 0x5b, 0x39, 0x60, 0x3b, 0x02, 0x3f, 0xc1, 0xf1, 0x50, 0x64,
-	0x19, 0xcf, 0x63, 0x5b, 0x24, 0x7c, 0xee, 0xea, 0x8e, 0x67, 0xeb, 0x82, 0xd8, 0x46, 0x8b, 0x7d,
-	0x38, 0x77, 0xbd, 0x4f, 0x29, 0xa5, 0xa5, 0x16, 0xb2, 0xe3, 0xdb, 0xd2, 0x87, 0x34, 0x76, 0xa1,
-	0xed, 0xa8, 0x7b, 0x9e, 0x51, 0xa7, 0x69, 0x55, 0x75, 0x0a, 0xdf, 0xc3, 0xc5, 0x84, 0x4b, 0xca,
-	0x75, 0x5d, 0xd7, 0xb2, 0xba, 0xe7, 0x07, 0xbd, 0x02, 0xc2, 0xa1, 0xc8, 0xb5, 0x14, 0x69, 0x4a,
-	0x72, 0xc6, 0xd5, 0xa3, 0x71, 0x18, 0x91, 0xd2, 0x49, 0xce, 0x75, 0x22, 0x72, 0x37, 0x62, 0x9d,
-	0xc2, 0x2b, 0x38, 0x1a, 0x93, 0x7e, 0x10, 0xd5, 0x8c, 0x01, 0x73, 0x08, 0x23, 0xf0, 0xe7, 0xec,
-	0xce, 0x75, 0x6e, 0x3e, 0xb7, 0x81, 0x36, 0x6b, 0x81, 0xfe, 0x84, 0xab, 0x7d, 0x47, 0x46, 0xaa,
-	0x10, 0xb9, 0x22, 0x13, 0xed, 0x2c, 0xc9, 0x48, 0x69, 0x9e, 0x15, 0xd6, 0xd7, 0x67, 0x3b, 0x02,
-	0xdf, 0x00, 0x4c, 0x35, 0xd7, 0xa5, 0x1a, 0x8a, 0x98, 0xac, 0x73, 0x8b, 0xd5, 0x98, 0xad, 0x97,
-	0x5f, 0xf3, 0xfa, 0xed, 0x01, 0x8c, 0xa8, 0x48, 0xc5, 0xda, 0x8e, 0x76, 0x0d, 0x27, 0x8c, 0x8a,
-	0x34, 0x59, 0x72, 0x65, 0xeb, 0xb7, 0xd8, 0x16, 0xe3, 0x77, 0x08, 0x26, 0x22, 0x9e, 0x70, 0xc9,
-	0x33, 0xd5, 0x69, 0x74, 0xfd, 0x7e, 0xfb, 0xe6, 0xed, 0xe1, 0xdb, 0xed, 0x4a, 0x0d, 0xb6, 0xda,
-	0xdb, 0x5c, 0xcb, 0x35, 0xdb, 0xdd, 0x35, 0xe9, 0x54, 0x5d, 0xb9, 0x20, 0x1c, 0xba, 0xfe, 0x0a,
-	0xe1, 0xfe, 0x25, 0x93, 0xd7, 0x23, 0xad, 0x5d, 0xc2, 0xe6, 0x13, 0x2f, 0xa1, 0xf5, 0xc4, 0xd3,
-	0x92, 0x5c, 0xb0, 0x15, 0xf8, 0xd2, 0xf8, 0xec, 0xf5, 0x24, 0x44, 0x2e, 0xb5, 0x71, 0x99, 0xea,
-	0xe4, 0x3f, 0xbe, 0x94, 0xbf, 0x49, 0xef, 0xdd, 0x1f, 0x0f, 0xda, 0xb5, 0x75, 0xc5, 0x53, 0x13,
-	0x9f, 0x22, 0xf9, 0x44, 0x71, 0xf4, 0x02, 0x2f, 0xe0, 0xcc, 0x2d, 0x12, 0xa3, 0x55, 0xa2, 0x74,
-	0xe4, 0xe1, 0xcb, 0xed, 0x1a, 0xcf, 0x73, 0x59, 0x91, 0x0d, 0xa3, 0xbb, 0xa7, 0x64, 0xf5, 0xb0,
-	0x10, 0x92, 0x89, 0x52, 0x53, 0xe4, 0x63, 0x04, 0xa7, 0xd3, 0x72, 0x31, 0x93, 0x44, 0x15, 0xd3,
-	0xc4, 0x33, 0x08, 0xaa, 0x70, 0x19, 0xfd, 0x8a, 0x5a, 0x18, 0x6e, 0x9e, 0xcd, 0xec, 0x46, 0x74,
-	0x64, 0xb0, 0x9b, 0xde, 0x9c, 0x1f, 0xe3, 0xb9, 0x69, 0xcc, 0x61, 0x55, 0x44, 0x27, 0x46, 0x70,
-	0x1b, 0xaf, 0x88, 0x51, 0x21, 0xa4, 0x8e, 0x02, 0xdb, 0x49, 0x2d, 0x2e, 0x73, 0x0b, 0x16, 0x47,
-	0xf6, 0xc7, 0xf1, 0xf1, 0x5f, 0x00, 0x00, 0x00, 0xff, 0xff, 0xc2, 0xdd, 0x04, 0x31, 0x50, 0x04,
-	0x00, 0x00,
+	// 761 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x55, 0xdb, 0x6e, 0xe3, 0x36,
+	0x10, 0x5d, 0x59, 0xbe, 0x69, 0xe4, 0x38, 0x5c, 0xee, 0x62, 0x2b, 0xa4, 0x45, 0xe1, 0xfa, 0xc9,
+	0x5d, 0x14, 0x69, 0x91, 0x5e, 0x50, 0x14, 0x7d, 0xc9, 0x3a, 0x69, 0x1a, 0xa0, 0x59, 0xb8, 0x8c,
+	0xf2, 0x01, 0x8c, 0x35, 0xb5, 0xd5, 0x48, 0xa4, 0x4a, 0x52, 0xdb, 0xf8, 0x33, 0xfa, 0xd6, 0x3f,
+	0xea, 0x6f, 0x15, 0xa4, 0x18, 0x59, 0x49, 0x80, 0xbe, 0xf5, 0x6d, 0xe6, 0xcc, 0xe1, 0x19, 0xfa,
+	0x0c, 0x47, 0x86, 0xd7, 0xeb, 0xa2, 0xd6, 0x06, 0x55, 0x89, 0x5a, 0xf3, 0x0d, 0x1e, 0x57, 0x4a,
+	0x1a, 0x49, 0xa7, 0x8f, 0xd1, 0xf9, 0x0d, 0x4c, 0x97, 0x0d, 0x72, 0xd5, 0x20, 0xf4, 0x4b, 0xe8,
+	0xff, 0x8c, 0x3c, 0x4b, 0x82, 0x59, 0xb0, 0x88, 0x4f, 0x3e, 0x3e, 0x7e, 0x22, 0xe3, 0x69, 0x96,
+	0xc2, 0x1c, 0x91, 0x52, 0xe8, 0xbf, 0x93, 0xd9, 0x2e, 0xe9, 0xcd, 0x82, 0xc5, 0x84, 0xb9, 0x78,
+	0xfe, 0x77, 0x08, 0x71, 0x87, 0x49, 0x3f, 0x81, 0xc8, 0xa7, 0x97, 0x67, 0x4e, 0x39, 0x62, 0x7b,
+	0x80, 0x7e, 0x0b, 0xa3, 0xa5, 0x2c, 0x4b, 0x2e, 0x32, 0x27, 0x32, 0x7d, 0xde, 0xd5, 0x97, 0xd3,
+	0x5d, 0x85, 0xec, 0x81, 0x4b, 0x17, 0x70, 0xe8, 0xef, 0x7e, 0x8d, 0x05, 0xae, 0x8d, 0x54, 0x49,
+	0xe8, 0xa4, 0x9f, 0xc2, 0x74, 0x06, 0xb1, 0x87, 0xde, 0xf3, 0x12, 0x93, 0xbe, 0x63, 0x75, 0x21,
+	0xfa, 0x05, 0xbc, 0x5c, 0x71, 0x85, 0xc2, 0x74, 0x79, 0x03, 0xc7, 0x7b, 0x5e, 0xa0, 0x9f, 0x02,
+	0x9c, 0xdf, 0x57, 0xb9, 0xc2, 0x34, 0x2f, 0x31, 0x19, 0xce, 0x82, 0x45, 0xc8, 0x3a, 0x88, 0xad,
+	0x2f, 0xb7, 0xb5, 0xb8, 0xbb, 0x14, 0x19, 0xde, 0x27, 0xa3, 0x59, 0xb0, 0x18, 0xb0, 0x0e, 0xd2,
+	0xd6, 0x53, 0x69, 0x78, 0x91, 0x8c, 0x3b, 0x75, 0x87, 0xd0, 0x6f, 0x60, 0xbc, 0x52, 0xb9, 0x54,
+	0xb9, 0xd9, 0x25, 0x91, 0x73, 0x24, 0x79, 0xea, 0xc8, 0x43, 0x9d, 0xb5, 0x4c, 0x4a, 0x20, 0x4c,
+	0xd3, 0x5f, 0x12, 0x70, 0x72, 0x36, 0xb4, 0xa3, 0x59, 0x71, 0xb3, 0x4d, 0xe2, 0x59, 0xb8, 0x88,
+	0x98, 0x8b, 0xe7, 0x15, 0x4c, 0x97, 0x52, 0x18, 0x25, 0x8b, 0x02, 0x55, 0xca, 0xf5, 0x9d, 0x75,
+	0xe7, 0x0c, 0xb5, 0xc9, 0x05, 0x37, 0xb9, 0x14, 0x7e, 0x3c, 0x5d, 0x88, 0xbe, 0x81, 0xe1, 0x15,
+	0x9a, 0xad, 0x6c, 0xe6, 0x13, 0x31, 0x9f, 0xd9, 0x8e, 0x37, 0xec, 0xd2, 0xbb, 0x6e, 0xc3, 0xf6,
+	0x31, 0xf4, 0x3b, 0x8f, 0xe1, 0x77, 0x78, 0xf3, 0xb8, 0x23, 0x43, 0x5d, 0x49, 0xa1, 0xd1, 0x3e,
+	0x0b, 0xeb, 0x97, 0x36, 0xbc, 0xac, 0x5c, 0xdf, 0x90, 0xed, 0x01, 0xeb, 0xd2, 0xb5, 0xe1, 0xa6,
+	0xd6, 0x4b, 0x99, 0xa1, 0xeb, 0x3c, 0x60, 0x1d, 0xa4, 0xed, 0x15, 0x76, 0x7a, 0xfd, 0x13, 0x00,
+	0x9c, 0x61, 0x55, 0xc8, 0x9d, 0xfb, 0x69, 0x47, 0x30, 0x66, 0x58, 0x15, 0xf9, 0x9a, 0x6b, 0xa7,
+	0x3f, 0x60, 0x6d, 0x4e, 0x2f, 0x20, 0x5a, 0xc9, 0x6c, 0xc5, 0x15, 0x2f, 0x75, 0xd2, 0x9b, 0x85,
+	0x8b, 0xf8, 0xe4, 0xf3, 0xa7, 0x2e, 0xef, 0xa5, 0x8e, 0x5b, 0xee, 0xb9, 0x30, 0x6a, 0xc7, 0xf6,
+	0x67, 0xad, 0x3b, 0xcd, 0xad, 0xbc, 0x11, 0x3e, 0x3b, 0xfa, 0x11, 0xa6, 0x8f, 0x0f, 0x59, 0xbf,
+	0xee, 0x70, 0xe7, 0x1d, 0xb6, 0x21, 0x7d, 0x0d, 0x83, 0x0f, 0xbc, 0xa8, 0xd1, 0x1b, 0xdb, 0x24,
+	0x3f, 0xf4, 0xbe, 0x0f, 0xe6, 0x0a, 0x88, 0x77, 0xed, 0xaa, 0x2e, 0x4c, 0xfe, 0x3f, 0x4e, 0x2a,
+	0x6c, 0xdd, 0x5b, 0xc2, 0xe1, 0xf5, 0x96, 0xab, 0xec, 0x54, 0xeb, 0x7c, 0x23, 0x4a, 0x14, 0xa6,
+	0x11, 0x2c, 0x6f, 0x51, 0xf9, 0x6e, 0x3e, 0xa3, 0x09, 0x8c, 0x9a, 0xa8, 0xf1, 0x2e, 0x62, 0x0f,
+	0xe9, 0xfc, 0x33, 0x88, 0xd3, 0x5a, 0x08, 0x2c, 0x7e, 0x52, 0x76, 0x57, 0x28, 0xf4, 0xcf, 0xb8,
+	0xe1, 0xee, 0xf8, 0x84, 0xb9, 0xf8, 0xed, 0x5f, 0x3d, 0x88, 0x3b, 0x2b, 0x4d, 0x27, 0x76, 0x4c,
+	0x1a, 0xd5, 0x07, 0xcc, 0xc8, 0x0b, 0xfa, 0x12, 0x0e, 0xfc, 0xb2, 0x31, 0xdc, 0xe4, 0xda, 0x90,
+	0x80, 0xbe, 0x6a, 0x57, 0xfd, 0x46, 0xa8, 0x06, 0xec, 0x59, 0xde, 0x7b, 0xcc, 0x37, 0xdb, 0x5b,
+	0xa9, 0x98, 0xac, 0x0d, 0x92, 0x90, 0x12, 0x98, 0x5c, 0xd7, 0xb7, 0xa9, 0x42, 0x6c, 0x90, 0x3e,
+	0x3d, 0x80, 0xa8, 0x19, 0x22, 0xc3, 0x3f, 0xc8, 0x80, 0x4e, 0x1f, 0x9e, 0x87, 0x7d, 0x83, 0x64,
+	0x68, 0x73, 0xef, 0xb2, 0xad, 0x8f, 0xe8, 0x21, 0xc4, 0x6d, 0xae, 0x2b, 0x32, 0xb6, 0x84, 0xf3,
+	0x6c, 0x83, 0x0c, 0x2b, 0xa9, 0x0c, 0x89, 0xdc, 0x4d, 0x3a, 0x63, 0xb1, 0xa7, 0x80, 0x46, 0x30,
+	0x70, 0xdb, 0x4b, 0x62, 0x2b, 0xd0, 0xb1, 0x90, 0x4c, 0xec, 0x05, 0x2e, 0x64, 0x2e, 0x36, 0xa7,
+	0x7f, 0xf2, 0x1d, 0x39, 0xa0, 0x1f, 0xc1, 0xab, 0xce, 0x97, 0x64, 0x29, 0xc5, 0x6f, 0x45, 0xbe,
+	0x36, 0x64, 0xfa, 0xf6, 0xbb, 0xfd, 0xce, 0xd3, 0x31, 0xf4, 0x4f, 0x6b, 0x23, 0xc9, 0x0b, 0xab,
+	0xdc, 0xfc, 0x92, 0x80, 0xc6, 0x30, 0xf2, 0x9d, 0x49, 0xcf, 0x32, 0xde, 0xd5, 0xc5, 0x1d, 0x09,
+	0x4f, 0x7e, 0x85, 0x61, 0x63, 0x37, 0xbd, 0x70, 0x04, 0x81, 0x6b, 0x43, 0x9f, 0x7d, 0x40, 0x3b,
+	0x13, 0x39, 0xfa, 0xaf, 0xe2, 0x22, 0xf8, 0x2a, 0xb8, 0x1d, 0xba, 0xff, 0x8a, 0xaf, 0xff, 0x1d,
+	0x00, 0xc5, 0x87, 0x65, 0x09, 0x43, 0x06, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// TunnelClient is the client API for Tunnel service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type TunnelClient interface {
+	Connect(ctx context.Context, opts ...grpc.CallOption) (Tunnel_ConnectClient, error)
+}
+
+type tunnelClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewTunnelClient(cc *grpc.ClientConn) TunnelClient {
+	return &tunnelClient{cc}
+}
+
+func (c *tunnelClient) Connect(ctx context.Context, opts ...grpc.CallOption) (Tunnel_ConnectClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Tunnel_serviceDesc.Streams[0], "/clustermessage.Tunnel/Connect", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &tunnelConnectClient{stream}
+	return x, nil
+}
+
+type Tunnel_ConnectClient interface {
+	Send(*TunnelFrame) error
+	Recv() (*TunnelFrame, error)
+	grpc.ClientStream
+}
+
+type tunnelConnectClient struct {
+	grpc.ClientStream
+}
+
+func (x *tunnelConnectClient) Send(m *TunnelFrame) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *tunnelConnectClient) Recv() (*TunnelFrame, error) {
+	m := new(TunnelFrame)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// TunnelServer is the server API for Tunnel service.
+type TunnelServer interface {
+	Connect(Tunnel_ConnectServer) error
+}
+
+// UnimplementedTunnelServer can be embedded to have forward compatible implementations.
+type UnimplementedTunnelServer struct {
+}
+
+func (*UnimplementedTunnelServer) Connect(srv Tunnel_ConnectServer) error {
+	return status.Errorf(codes.Unimplemented, "method Connect not implemented")
+}
+
+func RegisterTunnelServer(s *grpc.Server, srv TunnelServer) {
+	s.RegisterService(&_Tunnel_serviceDesc, srv)
+}
+
+func _Tunnel_Connect_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(TunnelServer).Connect(&tunnelConnectServer{stream})
+}
+
+type Tunnel_ConnectServer interface {
+	Send(*TunnelFrame) error
+	Recv() (*TunnelFrame, error)
+	grpc.ServerStream
+}
+
+type tunnelConnectServer struct {
+	grpc.ServerStream
+}
+
+func (x *tunnelConnectServer) Send(m *TunnelFrame) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *tunnelConnectServer) Recv() (*TunnelFrame, error) {
+	m := new(TunnelFrame)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+var _Tunnel_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "clustermessage.Tunnel",
+	HandlerType: (*TunnelServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Connect",
+			Handler:       _Tunnel_Connect_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "clustermessage.proto",
 }
@@ -0,0 +1,70 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clustermessage
+
+import (
+	"errors"
+)
+
+/*
+A message is transmitted up or down the tree hop by hop, while routes are updated asynchronously,
+so it may be bounced around in a transient routing loop after clusters fail over.
+Every cluster transmitting a message records itself in Path and decrements TTL,
+the message is dropped once TTL is exhausted or it comes back to a cluster in Path.
+Path of a response also tells which route it took.
+*/
+
+var (
+	// DefaultMessageTTL is the max hops of a message whose TTL is not set.
+	DefaultMessageTTL int32 = 32
+
+	// ErrTTLExceeded means the message is transmitted more than its TTL allows.
+	ErrTTLExceeded = errors.New("ttl exceeded")
+	// ErrRoutingLoop means the message comes back to a cluster it has been transmitted by.
+	ErrRoutingLoop = errors.New("routing loop")
+)
+
+/*
+Hop records that msg is transmitted by cluster, it returns error if msg should be dropped.
+Head of msg is replaced by a copy, so a head shared with others is not changed.
+*/
+func Hop(msg *ClusterMessage, cluster string) error {
+	if msg == nil || msg.Head == nil {
+		return nil
+	}
+	for _, c := range msg.Head.Path {
+		if c == cluster {
+			return ErrRoutingLoop
+		}
+	}
+	ttl := msg.Head.TTL
+	if ttl == 0 {
+		ttl = DefaultMessageTTL
+	}
+	// TTL of a message transmitted is never 0, which means it is not set.
+	ttl--
+	if ttl <= 0 {
+		return ErrTTLExceeded
+	}
+
+	head := *msg.Head
+	head.TTL = ttl
+	head.Path = make([]string, 0, len(msg.Head.Path)+1)
+	head.Path = append(append(head.Path, msg.Head.Path...), cluster)
+	msg.Head = &head
+	return nil
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clustermessage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHop(t *testing.T) {
+	ttl := DefaultMessageTTL
+	DefaultMessageTTL = 3
+	defer func() {
+		DefaultMessageTTL = ttl
+	}()
+
+	assert.Nil(t, Hop(nil, "c1"))
+	head := &MessageHead{MessageID: "1"}
+	msg := &ClusterMessage{Head: head}
+	assert.Nil(t, Hop(msg, "c1"))
+	assert.Equal(t, int32(2), msg.Head.TTL)
+	assert.Equal(t, []string{"c1"}, msg.Head.Path)
+	assert.Equal(t, "1", msg.Head.MessageID)
+	// the shared head is not changed
+	assert.Equal(t, int32(0), head.TTL)
+	assert.Empty(t, head.Path)
+
+	// loop
+	assert.Equal(t, ErrRoutingLoop, Hop(msg, "c1"))
+	assert.Nil(t, Hop(msg, "c2"))
+	assert.Equal(t, []string{"c1", "c2"}, msg.Head.Path)
+	assert.Equal(t, int32(1), msg.Head.TTL)
+	// ttl exhausted
+	assert.Equal(t, ErrTTLExceeded, Hop(msg, "c3"))
+	assert.Equal(t, []string{"c1", "c2"}, msg.Head.Path)
+}
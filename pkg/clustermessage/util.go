@@ -78,3 +78,64 @@ func (c *ControlMultiTask) ToClusterMessage(head *MessageHead) (*ClusterMessage,
 	}
 	return ret, nil
 }
+
+// PriorityOf returns the priority class of msg, which is Priority in head if it is set,
+// otherwise it is decided by Command.
+func PriorityOf(msg *ClusterMessage) Priority {
+	if msg == nil || msg.Head == nil {
+		return Priority_Control
+	}
+	if msg.Head.Priority != Priority_Auto {
+		return msg.Head.Priority
+	}
+	switch msg.Head.Command {
+	case CommandType_ClusterRegist, CommandType_ClusterUnregist,
+		CommandType_NeighborRoute, CommandType_SubTreeRoute, CommandType_GoingAway,
+		CommandType_ClusterNameConflict:
+		return Priority_Route
+	case CommandType_EdgeReport:
+		return Priority_Bulk
+	default:
+		return Priority_Control
+	}
+}
+
+// ParseHead returns head of serialized message data, nil if it has no valid head,
+// the body is skipped without copying.
+func ParseHead(data []byte) *MessageHead {
+	buf := proto.NewBuffer(data)
+	for {
+		key, err := buf.DecodeVarint()
+		if err != nil {
+			// end of data.
+			return nil
+		}
+		field, wireType := key>>3, key&7
+		switch wireType {
+		case proto.WireBytes:
+			b, err := buf.DecodeRawBytes(false)
+			if err != nil {
+				return nil
+			}
+			if field != 1 {
+				continue
+			}
+			head := &MessageHead{}
+			if err := proto.Unmarshal(b, head); err != nil {
+				return nil
+			}
+			return head
+		case proto.WireVarint:
+			_, err = buf.DecodeVarint()
+		case proto.WireFixed64:
+			_, err = buf.DecodeFixed64()
+		case proto.WireFixed32:
+			_, err = buf.DecodeFixed32()
+		default:
+			return nil
+		}
+		if err != nil {
+			return nil
+		}
+	}
+}
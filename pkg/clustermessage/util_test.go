@@ -62,3 +62,37 @@ func TestUtil(t *testing.T) {
 	assert.NotNil(t, m)
 	assert.Nil(t, err)
 }
+
+func TestPriorityOf(t *testing.T) {
+	assert.Equal(t, Priority_Control, PriorityOf(nil))
+	assert.Equal(t, Priority_Control, PriorityOf(&ClusterMessage{}))
+
+	msg := &ClusterMessage{
+		Head: &MessageHead{Command: CommandType_SubTreeRoute},
+	}
+	assert.Equal(t, Priority_Route, PriorityOf(msg))
+	msg.Head.Command = CommandType_ControlResp
+	assert.Equal(t, Priority_Control, PriorityOf(msg))
+	msg.Head.Command = CommandType_EdgeReport
+	assert.Equal(t, Priority_Bulk, PriorityOf(msg))
+
+	// priority in head overrides the one of command.
+	msg.Head.Priority = Priority_Control
+	assert.Equal(t, Priority_Control, PriorityOf(msg))
+}
+
+func TestParseHead(t *testing.T) {
+	msg := &ClusterMessage{
+		Head: &MessageHead{ClusterName: "c1", Command: CommandType_EdgeReport},
+		Body: []byte("body"),
+	}
+	data, err := msg.Serialize()
+	assert.Nil(t, err)
+	head := ParseHead(data)
+	assert.NotNil(t, head)
+	assert.Equal(t, "c1", head.ClusterName)
+	assert.Equal(t, CommandType_EdgeReport, head.Command)
+
+	assert.Nil(t, ParseHead(nil))
+	assert.Nil(t, ParseHead([]byte("not a message")))
+}
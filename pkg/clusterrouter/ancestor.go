@@ -0,0 +1,97 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterrouter
+
+import (
+	"reflect"
+)
+
+/*
+Every cluster keeps its ancestors from the parent up to root.
+The address of the parent is the one the cluster connects to,
+others are from route of the parent, which sends its name and its own ancestors.
+So a cluster can fail over to any ancestor if the whole tier of its parent is down.
+*/
+
+// Ancestor is a cluster on the path from a cluster to root.
+type Ancestor struct {
+	Name string
+	// Addr is the address the child of the ancestor connects to.
+	Addr string
+}
+
+// SetName sets the name of current cluster, which is sent to childs with route.
+func (cr *ClusterRouter) SetName(name string) {
+	cr.rwMutex.Lock()
+	defer cr.rwMutex.Unlock()
+
+	cr.Name = name
+}
+
+/*
+SetParentAddr sets the address connected to the parent.
+If it is an ancestor, the nearer ones are dropped,
+otherwise the parent is taken as a parent neighbor, whose ancestors are the same as the old parent.
+Ancestors are updated by route from the new parent later.
+*/
+func (cr *ClusterRouter) SetParentAddr(addr string) {
+	cr.rwMutex.Lock()
+	defer cr.rwMutex.Unlock()
+
+	for i, a := range cr.Ancestors {
+		if a.Addr == addr {
+			cr.Ancestors = append([]Ancestor{}, cr.Ancestors[i:]...)
+			return
+		}
+	}
+	parent := Ancestor{Addr: addr}
+	for name, listen := range cr.ParentNeighbor {
+		if listen == addr {
+			parent.Name = name
+		}
+	}
+	ancestors := []Ancestor{parent}
+	if len(cr.Ancestors) > 1 {
+		ancestors = append(ancestors, cr.Ancestors[1:]...)
+	}
+	cr.Ancestors = ancestors
+}
+
+// AncestorChain returns a copy of ancestors from the parent up to root.
+func (cr *ClusterRouter) AncestorChain() []Ancestor {
+	cr.rwMutex.RLock()
+	defer cr.rwMutex.RUnlock()
+
+	return append([]Ancestor{}, cr.Ancestors...)
+}
+
+// updateAncestors updates ancestors by route of parent, returns true if they changed.
+func (cr *ClusterRouter) updateAncestors(parentRouter *ClusterRouter) bool {
+	cr.rwMutex.Lock()
+	defer cr.rwMutex.Unlock()
+
+	parent := Ancestor{Name: parentRouter.Name}
+	if len(cr.Ancestors) > 0 {
+		parent.Addr = cr.Ancestors[0].Addr
+	}
+	ancestors := append([]Ancestor{parent}, parentRouter.Ancestors...)
+	if reflect.DeepEqual(cr.Ancestors, ancestors) {
+		return false
+	}
+	cr.Ancestors = ancestors
+	return true
+}
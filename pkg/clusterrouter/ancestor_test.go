@@ -0,0 +1,63 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterrouter
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAncestors(t *testing.T) {
+	// root r <- g <- p1 <- c1, p2 is neighbor of p1
+	r := ClusterRouter{rwMutex: &sync.RWMutex{}}
+	r.SetName("r")
+	g := ClusterRouter{rwMutex: &sync.RWMutex{}}
+	g.SetName("g")
+	g.SetParentAddr("r:8287")
+	assert.True(t, g.updateAncestors(neighborRouterFromClusterMessage(r.NeighborRouterMessage())))
+	assert.Equal(t, []Ancestor{{"r", "r:8287"}}, g.AncestorChain())
+
+	p := ClusterRouter{rwMutex: &sync.RWMutex{}}
+	p.SetName("p1")
+	p.SetParentAddr("g:8287")
+	assert.True(t, p.updateAncestors(neighborRouterFromClusterMessage(g.NeighborRouterMessage())))
+	assert.False(t, p.updateAncestors(neighborRouterFromClusterMessage(g.NeighborRouterMessage())))
+
+	c := ClusterRouter{
+		ParentNeighbor: map[string]string{"p2": "p2:8287"},
+		rwMutex:        &sync.RWMutex{},
+	}
+	c.SetParentAddr("p1:8287")
+	assert.True(t, c.updateAncestors(neighborRouterFromClusterMessage(p.NeighborRouterMessage())))
+	assert.Equal(t, []Ancestor{{"p1", "p1:8287"}, {"g", "g:8287"}, {"r", "r:8287"}}, c.AncestorChain())
+
+	// fail over to a parent neighbor keeps ancestors of the old parent until route comes
+	c.SetParentAddr("p2:8287")
+	assert.Equal(t, []Ancestor{{"p2", "p2:8287"}, {"g", "g:8287"}, {"r", "r:8287"}}, c.AncestorChain())
+
+	// fail over to an ancestor drops the nearer ones
+	c.SetParentAddr("r:8287")
+	assert.Equal(t, []Ancestor{{"r", "r:8287"}}, c.AncestorChain())
+	assert.False(t, c.updateAncestors(neighborRouterFromClusterMessage(r.NeighborRouterMessage())))
+
+	// return to the original parent
+	c.SetParentAddr("p1:8287")
+	assert.True(t, c.updateAncestors(neighborRouterFromClusterMessage(p.NeighborRouterMessage())))
+	assert.Equal(t, []Ancestor{{"p1", "p1:8287"}, {"g", "g:8287"}, {"r", "r:8287"}}, c.AncestorChain())
+}
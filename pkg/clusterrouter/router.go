@@ -29,6 +29,19 @@ besides, notify all other childs, so they can update their neighbor.
 
 If my parent neighbor changed, update my route,
 if my neighbor changed, notify my childs in addition.
+
+Labels of clusters in subtree are kept with route, so a label selector can be resolved
+at any level of the tree. Labels of a child are from its regist message,
+labels of its subtree are from its subtree route message.
+Labels are kept after the route is deleted, so messages can be queued for
+offline clusters selected by labels. Root overrides them by labels of Cluster crd.
+
+Parent of each cluster in subtree is kept the same way, so the whole tree
+can be assembled at root from subtree route messages.
+
+A route message from parent also carries the load of my neighbors and parent neighbors,
+which is the number of clusters in their subtree, and the ancestors of my parent up to root.
+They help to choose a parent to fail over to, at any level up.
 */
 package clusterrouter
 
@@ -48,6 +61,8 @@ var (
 	defaultClusterRouter = ClusterRouter{
 		Childs:        make(map[string]string),
 		subtreeRouter: make(map[string]string),
+		labels:        make(map[string]map[string]string),
+		parents:       make(map[string]string),
 		rwMutex:       &sync.RWMutex{},
 	}
 )
@@ -72,14 +87,45 @@ type ClusterRouter struct {
 	Childs         map[string]string // cluster name -> cluster tunnel listen address
 	Neighbor       map[string]string // same as above
 	ParentNeighbor map[string]string // same as above
+	// Name is the name of current cluster.
+	Name string
+	// Ancestors are clusters from the parent up to root.
+	Ancestors []Ancestor
+	// ChildLoad is the number of clusters in subtree of each child,
+	// NeighborLoad and ParentNeighborLoad are the same of neighbors and parent neighbors.
+	ChildLoad          map[string]int
+	NeighborLoad       map[string]int
+	ParentNeighborLoad map[string]int
 	// key is cluster name of node in subtree
 	// subtreeRouter should not serialized to json string to send to childs or parent
 	// value should be string if cluster name is universally unique
 	subtreeRouter SubTreeRouter
+	// labels are labels of clusters in subtree reported by childs,
+	// labelOverrides take precedence over them, e.g., labels edited in Cluster crd.
+	labels         map[string]map[string]string
+	labelOverrides map[string]map[string]string
+	// parents are parent cluster names of clusters in subtree.
+	parents map[string]string
 
 	rwMutex *sync.RWMutex
 }
 
+// SubtreeInfo is the body of a SubTreeRoute message with labels and parents of subtree clusters.
+// It is sent only to a parent understanding versions, others get a serialized SubTreeRouter.
+type SubtreeInfo struct {
+	Routes  SubTreeRouter                `json:"routes"`
+	Labels  map[string]map[string]string `json:"labels,omitempty"`
+	Parents map[string]string            `json:"parents,omitempty"`
+	// Version is the version of subtree of sender after this message, 0 if it is not versioned.
+	Version uint64 `json:"version,omitempty"`
+	// Base is the version a delta applies to, 0 if the message is a full sync.
+	Base uint64 `json:"base,omitempty"`
+	// Deleted are clusters removed from subtree since Base.
+	Deleted []string `json:"deleted,omitempty"`
+	// Checksum is checksum of clusters in subtree at Version, empty if it is not checked.
+	Checksum string `json:"checksum,omitempty"`
+}
+
 // Serialize serializes a ClusterRouter so as to send to neighbors.
 func (cr *ClusterRouter) Serialize() ([]byte, error) {
 	cr.rwMutex.RLock()
@@ -221,6 +267,15 @@ func (cr *ClusterRouter) HasRoute(to, port string) bool {
 	return false
 }
 
+// Port returns the child through which cluster named to is reached.
+func (cr *ClusterRouter) Port(to string) (string, bool) {
+	cr.rwMutex.RLock()
+	defer cr.rwMutex.RUnlock()
+
+	port, ok := cr.subtreeRouter[to]
+	return port, ok
+}
+
 /*
 PortsToSubtreeClusters get ports which can reach to clusters.
 return is a map whose key is cluster name of a port,
@@ -272,23 +327,96 @@ func (cr *ClusterRouter) SubTreeClusters() []string {
 	return ret
 }
 
+// SetLabels replaces labels of cluster reported by child, nil labels remove them.
+func (cr *ClusterRouter) SetLabels(cluster string, labels map[string]string) {
+	cr.rwMutex.Lock()
+	defer cr.rwMutex.Unlock()
+
+	if labels == nil {
+		delete(cr.labels, cluster)
+		return
+	}
+	if cr.labels == nil {
+		cr.labels = make(map[string]map[string]string)
+	}
+	cr.labels[cluster] = copyLabels(labels)
+}
+
+// OverrideLabels sets labels of cluster which take precedence over reported ones,
+// nil labels remove the override.
+func (cr *ClusterRouter) OverrideLabels(cluster string, labels map[string]string) {
+	cr.rwMutex.Lock()
+	defer cr.rwMutex.Unlock()
+
+	if labels == nil {
+		delete(cr.labelOverrides, cluster)
+		return
+	}
+	if cr.labelOverrides == nil {
+		cr.labelOverrides = make(map[string]map[string]string)
+	}
+	cr.labelOverrides[cluster] = copyLabels(labels)
+}
+
+// Labels returns labels of cluster, nil if unknown.
+func (cr *ClusterRouter) Labels(cluster string) map[string]string {
+	cr.rwMutex.RLock()
+	defer cr.rwMutex.RUnlock()
+
+	return cr.labelsOf(cluster)
+}
+
+// SetParent sets parent of cluster, empty parent is ignored.
+func (cr *ClusterRouter) SetParent(cluster, parent string) {
+	if parent == "" {
+		return
+	}
+	cr.rwMutex.Lock()
+	defer cr.rwMutex.Unlock()
+
+	if cr.parents == nil {
+		cr.parents = make(map[string]string)
+	}
+	cr.parents[cluster] = parent
+}
+
+// Parent returns parent of cluster, empty if unknown.
+func (cr *ClusterRouter) Parent(cluster string) string {
+	cr.rwMutex.RLock()
+	defer cr.rwMutex.RUnlock()
+
+	return cr.parents[cluster]
+}
+
+func copyLabels(labels map[string]string) map[string]string {
+	ret := make(map[string]string, len(labels))
+	for k, v := range labels {
+		ret[k] = v
+	}
+	return ret
+}
+
 // updateNeighbor update neighbor of current cluster.
-// return true if neighbor changed, return false otherwise.
+// return true if neighbor or its load changed, return false otherwise.
 func (cr *ClusterRouter) updateNeighbor(parentRouter *ClusterRouter) bool {
-	if reflect.DeepEqual(cr.Neighbor, parentRouter.Childs) {
+	if reflect.DeepEqual(cr.Neighbor, parentRouter.Childs) &&
+		reflect.DeepEqual(cr.NeighborLoad, parentRouter.ChildLoad) {
 		return false
 	}
 	cr.Neighbor = parentRouter.Childs
+	cr.NeighborLoad = parentRouter.ChildLoad
 	return true
 }
 
 // updateParentNeighbor update parent neighbor of current cluster.
-// return true if parent neighbor changed, return false otherwise
+// return true if parent neighbor or its load changed, return false otherwise
 func (cr *ClusterRouter) updateParentNeighbor(parentRouter *ClusterRouter) bool {
-	if reflect.DeepEqual(cr.ParentNeighbor, parentRouter.Neighbor) {
+	if reflect.DeepEqual(cr.ParentNeighbor, parentRouter.Neighbor) &&
+		reflect.DeepEqual(cr.ParentNeighborLoad, parentRouter.NeighborLoad) {
 		return false
 	}
 	cr.ParentNeighbor = parentRouter.Neighbor
+	cr.ParentNeighborLoad = parentRouter.NeighborLoad
 	return true
 }
 
@@ -298,52 +426,61 @@ func (cr *ClusterRouter) ParentNeighbors() map[string]string {
 	return cr.ParentNeighbor
 }
 
-// NeighborRouterMessage wrap router info to cluster message.
-func (cr *ClusterRouter) NeighborRouterMessage() *clustermessage.ClusterMessage {
+// ParentNeighborLoads returns number of clusters in subtree of each parent neighbor.
+func (cr *ClusterRouter) ParentNeighborLoads() map[string]int {
 	cr.rwMutex.RLock()
 	defer cr.rwMutex.RUnlock()
-	cbyte, err := cr.Serialize()
-	if err != nil {
-		klog.Errorf("serialize cluster router %v failed: %v", cr, err)
-		return nil
+
+	return cr.ParentNeighborLoad
+}
+
+// childLoad returns number of clusters in subtree of each child, the child itself included.
+func (cr *ClusterRouter) childLoad() map[string]int {
+	ret := make(map[string]int, len(cr.Childs))
+	for child := range cr.Childs {
+		ret[child] = 1
 	}
-	msg := &clustermessage.ClusterMessage{
-		Head: &clustermessage.MessageHead{
-			Command: clustermessage.CommandType_NeighborRoute,
-		},
-		Body: cbyte,
+	for to, port := range cr.subtreeRouter {
+		if _, ok := ret[port]; ok && to != port {
+			ret[port]++
+		}
 	}
-	return msg
+	return ret
 }
 
-// SubTreeMessage wrap subtree router info to cluster message.
-func (cr *ClusterRouter) SubTreeMessage() *clustermessage.ClusterMessage {
+// NeighborRouterMessage wrap router info to cluster message.
+func (cr *ClusterRouter) NeighborRouterMessage() *clustermessage.ClusterMessage {
 	cr.rwMutex.RLock()
 	defer cr.rwMutex.RUnlock()
-
-	cbyte, err := cr.subtreeRouter.Serialize()
+	r := *cr
+	r.ChildLoad = cr.childLoad()
+	cbyte, err := json.Marshal(&r)
 	if err != nil {
-		klog.Errorf("serialize subtree router %v failed: %v", cr, err)
+		klog.Errorf("serialize cluster router %v failed: %v", cr, err)
 		return nil
 	}
 	msg := &clustermessage.ClusterMessage{
 		Head: &clustermessage.MessageHead{
-			Command: clustermessage.CommandType_SubTreeRoute,
+			Command: clustermessage.CommandType_NeighborRoute,
 		},
 		Body: cbyte,
 	}
 	return msg
 }
 
-// SubtreeFromClusterController get subtree router info from a cluster message.
-func SubtreeFromClusterController(msg *clustermessage.ClusterMessage) SubTreeRouter {
+// SubtreeInfoFromClusterController get subtree router info with labels and parents from a cluster message.
+func SubtreeInfoFromClusterController(msg *clustermessage.ClusterMessage) *SubtreeInfo {
+	info := &SubtreeInfo{}
+	if err := json.Unmarshal(msg.Body, info); err == nil && info.Routes != nil {
+		return info
+	}
 	ret := SubTreeRouter{}
 	err := json.Unmarshal(msg.Body, &ret)
 	if err != nil {
 		klog.Errorf("deserialize cluster subtree failed: %v", err)
 		return nil
 	}
-	return ret
+	return &SubtreeInfo{Routes: ret}
 }
 
 func neighborRouterFromClusterMessage(msg *clustermessage.ClusterMessage) *ClusterRouter {
@@ -363,9 +500,11 @@ func UpdateRouter(msg *clustermessage.ClusterMessage, notifier RouterNotifier) {
 		return
 	}
 	// r is route of parent
-	if defaultClusterRouter.updateNeighbor(r) {
+	// childs are notified if my neighbor or ancestors changed.
+	neighborChanged := defaultClusterRouter.updateNeighbor(r)
+	defaultClusterRouter.updateParentNeighbor(r)
+	if defaultClusterRouter.updateAncestors(r) || neighborChanged {
 		notifier(defaultClusterRouter.NeighborRouterMessage())
 	}
-	defaultClusterRouter.updateParentNeighbor(r)
 	klog.Infof("cluster router updated: %#v", defaultClusterRouter)
 }
@@ -78,7 +78,7 @@ root
 func TestRoute(t *testing.T) {
 	r := Router()
 	// a empty router should has subtree router msg
-	msg := r.SubTreeMessage()
+	msg := r.Subtree().Message()
 	assert.NotNil(t, msg)
 
 	err := r.AddRoute("c1", "c1")
@@ -131,16 +131,82 @@ func TestRoute(t *testing.T) {
 		[]string{"c1", "c2", "c3", "cn", "cm"},
 		r.SubTreeClusters())
 
-	msg = r.SubTreeMessage()
+	msg = r.Subtree().Message()
+	assert.NotNil(t, msg)
+	assert.Equal(t, clustermessage.CommandType_SubTreeRoute, msg.Head.Command)
+	assert.Equal(t, r.subtreeRouter, SubtreeInfoFromClusterController(msg).Routes)
+
+	// bare routes are sent to old versions
+	msg = r.subtreeRouter.Message()
 	assert.NotNil(t, msg)
 	assert.Equal(t, clustermessage.CommandType_SubTreeRoute, msg.Head.Command)
 	serial, err := r.subtreeRouter.Serialize()
 	assert.Nil(t, err)
 	assert.Equal(t, serial, msg.Body)
-	subr := SubtreeFromClusterController(msg)
-	assert.Equal(t, r.subtreeRouter, subr)
+	assert.Equal(t, r.subtreeRouter, SubtreeInfoFromClusterController(msg).Routes)
 }
 
 func testRouterNotifier(msg *clustermessage.ClusterMessage, tos ...string) {
 	calledNotifier = true
 }
+
+func TestSubtreeLabels(t *testing.T) {
+	r := ClusterRouter{
+		subtreeRouter: SubTreeRouter{"c1": "c1", "c2": "c1"},
+		rwMutex:       &sync.RWMutex{},
+	}
+	r.SetLabels("c2", map[string]string{"region": "north"})
+	r.SetLabels("c3", map[string]string{"region": "south"})
+	r.SetLabels("c1", nil)
+	assert.Equal(t, map[string]string{"region": "north"}, r.Labels("c2"))
+	assert.Nil(t, r.Labels("c1"))
+	// labels not reported any more are removed
+	r.SetLabels("c3", map[string]string{"tier": "edge"})
+	assert.Equal(t, map[string]string{"tier": "edge"}, r.Labels("c3"))
+	r.SetLabels("c3", nil)
+	assert.Nil(t, r.Labels("c3"))
+
+	// labels of subtree clusters are sent with route
+	info := SubtreeInfoFromClusterController(r.Subtree().Message())
+	assert.Equal(t, r.subtreeRouter, info.Routes)
+	assert.Equal(t, map[string]map[string]string{"c2": {"region": "north"}}, info.Labels)
+
+	// route without labels is understood too
+	old, err := r.subtreeRouter.Serialize()
+	assert.Nil(t, err)
+	info = SubtreeInfoFromClusterController(&clustermessage.ClusterMessage{Body: old})
+	assert.Equal(t, r.subtreeRouter, info.Routes)
+	assert.Nil(t, info.Labels)
+
+	r.OverrideLabels("c2", map[string]string{"region": "east"})
+	assert.Equal(t, map[string]string{"region": "east"}, r.Labels("c2"))
+	r.OverrideLabels("c2", nil)
+	assert.Equal(t, map[string]string{"region": "north"}, r.Labels("c2"))
+}
+
+func TestNeighborLoad(t *testing.T) {
+	// route of grandparent g, childs of g are p1 and p2
+	g := ClusterRouter{
+		Childs:        map[string]string{"p1": "p1:8287", "p2": "p2:8287"},
+		subtreeRouter: SubTreeRouter{"p1": "p1", "p2": "p2", "c1": "p1", "c2": "p2", "c3": "p2"},
+		rwMutex:       &sync.RWMutex{},
+	}
+	// route of parent p1 connected to g
+	p := ClusterRouter{
+		Childs:        map[string]string{"c1": "c1:8287"},
+		subtreeRouter: SubTreeRouter{"c1": "c1"},
+		rwMutex:       &sync.RWMutex{},
+	}
+	p.SetParentAddr("g:8287")
+	assert.True(t, p.updateNeighbor(neighborRouterFromClusterMessage(g.NeighborRouterMessage())))
+	assert.Equal(t, map[string]int{"p1": 2, "p2": 3}, p.NeighborLoad)
+
+	// child c1 of p1 knows load of parent neighbors
+	c := ClusterRouter{rwMutex: &sync.RWMutex{}}
+	parentRouter := neighborRouterFromClusterMessage(p.NeighborRouterMessage())
+	assert.True(t, c.updateParentNeighbor(parentRouter))
+	assert.Equal(t, map[string]int{"p1": 2, "p2": 3}, c.ParentNeighborLoads())
+	assert.False(t, c.updateParentNeighbor(parentRouter))
+	assert.True(t, c.updateNeighbor(parentRouter))
+	assert.Equal(t, map[string]int{"c1": 1}, c.NeighborLoad)
+}
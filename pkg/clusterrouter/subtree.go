@@ -0,0 +1,238 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterrouter
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"sort"
+
+	"k8s.io/klog"
+
+	"github.com/baidu/ote-stack/pkg/clustermessage"
+)
+
+/*
+Subtree routes are reported to parent by versions instead of full dumps.
+
+A child sends bare SubTreeRouter as full syncs after connecting, which are understood by old versions.
+A parent understanding versions answers the first one with an ack of Versioned,
+then the child sends a versioned full sync with labels and parents,
+and deltas of added and deleted routes once parent acknowledges the version.
+Parent applies a delta only if its base is the version it has,
+otherwise it asks the child for a full sync, so does it if the checksum sent at a low frequency mismatches.
+A parent of old versions never answers, so it gets bare full syncs as before.
+*/
+
+// SubtreeAck is the body of a SubTreeRoute message from parent to child.
+type SubtreeAck struct {
+	// Version is the version of subtree applied by parent.
+	Version uint64 `json:"version,omitempty"`
+	// Resync asks the child for a full sync.
+	Resync bool `json:"resync,omitempty"`
+	// Versioned tells a child sending bare SubTreeRouter that parent understands versioned SubtreeInfo.
+	Versioned bool `json:"versioned,omitempty"`
+}
+
+// Subtree returns a copy of subtree routes with labels and parents.
+func (cr *ClusterRouter) Subtree() *SubtreeInfo {
+	cr.rwMutex.RLock()
+	defer cr.rwMutex.RUnlock()
+
+	info := &SubtreeInfo{
+		Routes:  SubTreeRouter(copyRoutes(cr.subtreeRouter)),
+		Labels:  make(map[string]map[string]string),
+		Parents: make(map[string]string),
+	}
+	for to := range cr.subtreeRouter {
+		if l, ok := cr.labels[to]; ok {
+			info.Labels[to] = l
+		}
+		if p, ok := cr.parents[to]; ok {
+			info.Parents[to] = p
+		}
+	}
+	return info
+}
+
+/*
+DiffSubtree returns the delta from old to cur, nil if nothing changed.
+Routes of the delta are the clusters added or whose route, labels or parent changed.
+*/
+func DiffSubtree(old, cur *SubtreeInfo) *SubtreeInfo {
+	delta := &SubtreeInfo{
+		Routes:  SubTreeRouter{},
+		Labels:  make(map[string]map[string]string),
+		Parents: make(map[string]string),
+	}
+	changed := false
+	for to, port := range cur.Routes {
+		oldPort, ok := old.Routes[to]
+		if ok && oldPort == port && old.Parents[to] == cur.Parents[to] &&
+			reflect.DeepEqual(old.Labels[to], cur.Labels[to]) {
+			continue
+		}
+		changed = true
+		delta.Routes[to] = port
+		if l, ok := cur.Labels[to]; ok {
+			delta.Labels[to] = l
+		}
+		if p, ok := cur.Parents[to]; ok {
+			delta.Parents[to] = p
+		}
+	}
+	for to := range old.Routes {
+		if _, ok := cur.Routes[to]; !ok {
+			changed = true
+			delta.Deleted = append(delta.Deleted, to)
+		}
+	}
+	if !changed {
+		return nil
+	}
+	sort.Strings(delta.Deleted)
+	return delta
+}
+
+// SubtreeChecksum returns checksum of cluster names in a subtree.
+func SubtreeChecksum(clusters []string) string {
+	names := append([]string{}, clusters...)
+	sort.Strings(names)
+	h := fnv.New64a()
+	for _, name := range names {
+		h.Write([]byte(name))
+		h.Write([]byte{0})
+	}
+	return fmt.Sprintf("%d-%x", len(names), h.Sum64())
+}
+
+// Clusters returns cluster names in the subtree.
+func (info *SubtreeInfo) Clusters() []string {
+	ret := make([]string, 0, len(info.Routes))
+	for to := range info.Routes {
+		ret = append(ret, to)
+	}
+	return ret
+}
+
+// Message wraps subtree info to cluster message.
+func (info *SubtreeInfo) Message() *clustermessage.ClusterMessage {
+	cbyte, err := json.Marshal(info)
+	if err != nil {
+		klog.Errorf("serialize subtree info %v failed: %v", info, err)
+		return nil
+	}
+	return &clustermessage.ClusterMessage{
+		Head: &clustermessage.MessageHead{
+			Command: clustermessage.CommandType_SubTreeRoute,
+		},
+		Body: cbyte,
+	}
+}
+
+// Message wraps bare subtree routes to cluster message, which is understood by old versions.
+func (s SubTreeRouter) Message() *clustermessage.ClusterMessage {
+	cbyte, err := s.Serialize()
+	if err != nil {
+		klog.Errorf("serialize subtree router %v failed: %v", s, err)
+		return nil
+	}
+	return &clustermessage.ClusterMessage{
+		Head: &clustermessage.MessageHead{
+			Command: clustermessage.CommandType_SubTreeRoute,
+		},
+		Body: cbyte,
+	}
+}
+
+// Message wraps subtree ack to cluster message.
+func (ack *SubtreeAck) Message() *clustermessage.ClusterMessage {
+	cbyte, err := json.Marshal(ack)
+	if err != nil {
+		klog.Errorf("serialize subtree ack %v failed: %v", ack, err)
+		return nil
+	}
+	return &clustermessage.ClusterMessage{
+		Head: &clustermessage.MessageHead{
+			Command: clustermessage.CommandType_SubTreeRoute,
+		},
+		Body: cbyte,
+	}
+}
+
+// SubtreeAckFromClusterMessage gets subtree ack from a cluster message.
+func SubtreeAckFromClusterMessage(msg *clustermessage.ClusterMessage) *SubtreeAck {
+	ack := &SubtreeAck{}
+	if err := json.Unmarshal(msg.Body, ack); err != nil {
+		klog.Errorf("deserialize subtree ack failed: %v", err)
+		return nil
+	}
+	return ack
+}
+
+/*
+MergeSubtree merges newer subtree info into older one, e.g., when both are pending to be sent.
+A delta based on older is applied to it, otherwise newer replaces older.
+*/
+func MergeSubtree(older, newer *SubtreeInfo) *SubtreeInfo {
+	if newer.Base == 0 || newer.Base != older.Version {
+		return newer
+	}
+	ret := &SubtreeInfo{
+		Routes:   SubTreeRouter(copyRoutes(older.Routes)),
+		Labels:   make(map[string]map[string]string, len(older.Labels)),
+		Parents:  copyRoutes(older.Parents),
+		Version:  newer.Version,
+		Base:     older.Base,
+		Checksum: newer.Checksum,
+	}
+	for to, l := range older.Labels {
+		ret.Labels[to] = l
+	}
+	deleted := make(map[string]bool, len(older.Deleted))
+	for _, to := range older.Deleted {
+		deleted[to] = true
+	}
+	for to, port := range newer.Routes {
+		ret.Routes[to] = port
+		delete(ret.Labels, to)
+		delete(ret.Parents, to)
+		if l, ok := newer.Labels[to]; ok {
+			ret.Labels[to] = l
+		}
+		if p, ok := newer.Parents[to]; ok {
+			ret.Parents[to] = p
+		}
+		delete(deleted, to)
+	}
+	for _, to := range newer.Deleted {
+		delete(ret.Routes, to)
+		delete(ret.Labels, to)
+		delete(ret.Parents, to)
+		// a full sync has nothing deleted.
+		if older.Base != 0 {
+			deleted[to] = true
+		}
+	}
+	for to := range deleted {
+		ret.Deleted = append(ret.Deleted, to)
+	}
+	sort.Strings(ret.Deleted)
+	return ret
+}
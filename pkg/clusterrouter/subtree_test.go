@@ -0,0 +1,81 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterrouter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffSubtree(t *testing.T) {
+	old := &SubtreeInfo{
+		Routes:  SubTreeRouter{"c2": "c2", "c3": "c2", "c4": "c4"},
+		Labels:  map[string]map[string]string{"c3": {"region": "north"}},
+		Parents: map[string]string{"c3": "c2"},
+	}
+	assert.Nil(t, DiffSubtree(old, old))
+
+	cur := &SubtreeInfo{
+		Routes:  SubTreeRouter{"c2": "c2", "c3": "c2", "c5": "c4"},
+		Labels:  map[string]map[string]string{"c3": {"region": "south"}},
+		Parents: map[string]string{"c3": "c2", "c5": "c4"},
+	}
+	delta := DiffSubtree(old, cur)
+	assert.Equal(t, SubTreeRouter{"c3": "c2", "c5": "c4"}, delta.Routes)
+	assert.Equal(t, map[string]map[string]string{"c3": {"region": "south"}}, delta.Labels)
+	assert.Equal(t, map[string]string{"c3": "c2", "c5": "c4"}, delta.Parents)
+	assert.Equal(t, []string{"c4"}, delta.Deleted)
+}
+
+func TestSubtreeChecksum(t *testing.T) {
+	assert.Equal(t, SubtreeChecksum([]string{"c1", "c2"}), SubtreeChecksum([]string{"c2", "c1"}))
+	assert.NotEqual(t, SubtreeChecksum([]string{"c1", "c2"}), SubtreeChecksum([]string{"c1c2"}))
+	assert.NotEqual(t, SubtreeChecksum(nil), SubtreeChecksum([]string{""}))
+}
+
+func TestMergeSubtree(t *testing.T) {
+	full := &SubtreeInfo{Routes: SubTreeRouter{"c2": "c2", "c3": "c2"}, Version: 1}
+	delta1 := &SubtreeInfo{Routes: SubTreeRouter{"c4": "c4"}, Deleted: []string{"c3"}, Base: 1, Version: 2}
+	delta2 := &SubtreeInfo{Routes: SubTreeRouter{"c3": "c4"}, Deleted: []string{"c2"}, Base: 2, Version: 3,
+		Parents: map[string]string{"c3": "c4"}, Checksum: "sum"}
+
+	// a delta is applied to a full sync
+	merged := MergeSubtree(full, delta1)
+	assert.Equal(t, &SubtreeInfo{
+		Routes:  SubTreeRouter{"c2": "c2", "c4": "c4"},
+		Labels:  map[string]map[string]string{},
+		Parents: map[string]string{},
+		Version: 2,
+	}, merged)
+
+	// deltas are merged into one
+	merged = MergeSubtree(delta1, delta2)
+	assert.Equal(t, &SubtreeInfo{
+		Routes:   SubTreeRouter{"c4": "c4", "c3": "c4"},
+		Labels:   map[string]map[string]string{},
+		Parents:  map[string]string{"c3": "c4"},
+		Deleted:  []string{"c2"},
+		Base:     1,
+		Version:  3,
+		Checksum: "sum",
+	}, merged)
+
+	// newer replaces older if it is a full sync or not based on older
+	assert.Equal(t, full, MergeSubtree(delta1, full))
+	assert.Equal(t, delta2, MergeSubtree(full, delta2))
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterrouter
+
+import (
+	"sort"
+)
+
+// Topology is a copy of route info of a cluster, which is safe to use without lock.
+type Topology struct {
+	Childs         map[string]string `json:"childs"`
+	Neighbor       map[string]string `json:"neighbor"`
+	ParentNeighbor map[string]string `json:"parentNeighbor"`
+	Subtree        SubTreeRouter     `json:"subtree"`
+}
+
+// TreeNode is a cluster in the tree assembled from subtree routes.
+type TreeNode struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+	// Link is state of the link from parent, it is set by the caller.
+	Link   interface{} `json:"link,omitempty"`
+	Childs []*TreeNode `json:"childs,omitempty"`
+}
+
+func copyRoutes(m map[string]string) map[string]string {
+	ret := make(map[string]string, len(m))
+	for k, v := range m {
+		ret[k] = v
+	}
+	return ret
+}
+
+// Topology returns a copy of route info of current cluster.
+func (cr *ClusterRouter) Topology() *Topology {
+	cr.rwMutex.RLock()
+	defer cr.rwMutex.RUnlock()
+
+	return &Topology{
+		Childs:         copyRoutes(cr.Childs),
+		Neighbor:       copyRoutes(cr.Neighbor),
+		ParentNeighbor: copyRoutes(cr.ParentNeighbor),
+		Subtree:        SubTreeRouter(copyRoutes(cr.subtreeRouter)),
+	}
+}
+
+/*
+Tree assembles the subtree of current cluster named name.
+A cluster is put under its parent reported by subtree routes,
+or under the child it is reached from if its parent is unknown or stale.
+*/
+func (cr *ClusterRouter) Tree(name string) *TreeNode {
+	cr.rwMutex.RLock()
+	defer cr.rwMutex.RUnlock()
+
+	// fallbacks are the childs clusters are reached from, childs are under current cluster.
+	fallbacks := make(map[string]string, len(cr.subtreeRouter))
+	parents := make(map[string]string, len(cr.subtreeRouter))
+	for to, port := range cr.subtreeRouter {
+		fallback := name
+		if to != port && cr.subtreeRouter[port] == port {
+			fallback = port
+		}
+		fallbacks[to] = fallback
+
+		parent := cr.parents[to]
+		if fallback == name || parent == to || (parent != name && !cr.hasSubtree(parent)) {
+			parent = fallback
+		}
+		parents[to] = parent
+	}
+	// break loops made of stale parents, in order of names so the tree is stable
+	names := make([]string, 0, len(parents))
+	for to := range parents {
+		names = append(names, to)
+	}
+	sort.Strings(names)
+	for _, to := range names {
+		p := parents[to]
+		for i := 0; p != name && i <= len(parents); i++ {
+			p = parents[p]
+		}
+		if p != name {
+			parents[to] = fallbacks[to]
+		}
+	}
+
+	nodes := map[string]*TreeNode{
+		name: {Name: name},
+	}
+	for to := range parents {
+		nodes[to] = &TreeNode{
+			Name:   to,
+			Labels: cr.labelsOf(to),
+		}
+	}
+	for to, parent := range parents {
+		nodes[parent].Childs = append(nodes[parent].Childs, nodes[to])
+	}
+	for _, node := range nodes {
+		sort.Slice(node.Childs, func(i, j int) bool {
+			return node.Childs[i].Name < node.Childs[j].Name
+		})
+	}
+	return nodes[name]
+}
+
+func (cr *ClusterRouter) hasSubtree(cluster string) bool {
+	_, ok := cr.subtreeRouter[cluster]
+	return ok
+}
+
+// labelsOf is Labels without lock.
+func (cr *ClusterRouter) labelsOf(cluster string) map[string]string {
+	if labels, ok := cr.labelOverrides[cluster]; ok {
+		return labels
+	}
+	return cr.labels[cluster]
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterrouter
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func treeNames(node *TreeNode) map[string][]string {
+	ret := make(map[string][]string)
+	var walk func(*TreeNode)
+	walk = func(n *TreeNode) {
+		for _, child := range n.Childs {
+			ret[n.Name] = append(ret[n.Name], child.Name)
+			walk(child)
+		}
+	}
+	walk(node)
+	return ret
+}
+
+/*
+TestTree assembles tree as following:
+root
+- c1
+  - c2
+  - c3
+
+- c4
+  - c5 (parent unknown)
+  - c6 (parent is stale)
+  - c7 (parents of c7 and c8 are a loop)
+  - c8
+*/
+func TestTree(t *testing.T) {
+	r := ClusterRouter{
+		Childs: map[string]string{"c1": "addr1", "c4": "addr4"},
+		subtreeRouter: SubTreeRouter{
+			"c1": "c1", "c2": "c1", "c3": "c1",
+			"c4": "c4", "c5": "c4", "c6": "c4", "c7": "c4", "c8": "c4",
+		},
+		rwMutex: &sync.RWMutex{},
+	}
+	r.SetParent("c1", "root")
+	r.SetParent("c2", "c1")
+	r.SetParent("c3", "c2")
+	r.SetParent("c6", "gone")
+	r.SetParent("c7", "c8")
+	r.SetParent("c8", "c7")
+	r.SetLabels("c3", map[string]string{"region": "north"})
+
+	tree := r.Tree("root")
+	assert.Equal(t, "root", tree.Name)
+	assert.Equal(t, map[string][]string{
+		"root": {"c1", "c4"},
+		"c1":   {"c2"},
+		"c2":   {"c3"},
+		"c4":   {"c5", "c6", "c7"},
+		"c7":   {"c8"},
+	}, treeNames(tree))
+	assert.Equal(t, map[string]string{"region": "north"}, tree.Childs[0].Childs[0].Childs[0].Labels)
+
+	topo := r.Topology()
+	assert.Equal(t, r.Childs, topo.Childs)
+	assert.Equal(t, r.subtreeRouter, topo.Subtree)
+	topo.Childs["c9"] = "addr9"
+	assert.NotContains(t, r.Childs, "c9")
+}
+
+func TestSubtreeParents(t *testing.T) {
+	r := ClusterRouter{
+		subtreeRouter: SubTreeRouter{"c1": "c1", "c2": "c1"},
+		rwMutex:       &sync.RWMutex{},
+	}
+	r.SetParent("c1", "root")
+	r.SetParent("c2", "c1")
+	r.SetParent("c3", "")
+	assert.Equal(t, "", r.Parent("c3"))
+
+	info := SubtreeInfoFromClusterController(r.Subtree().Message())
+	assert.Equal(t, r.subtreeRouter, info.Routes)
+	assert.Equal(t, map[string]string{"c1": "root", "c2": "c1"}, info.Parents)
+}
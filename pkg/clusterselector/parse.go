@@ -0,0 +1,178 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterselector
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+const (
+	excludePrefix = "-"
+	exactPrefix   = "="
+	escapeChar    = '\\'
+)
+
+// item is a parsed item of a name selector.
+type item struct {
+	exclude bool
+	// literal is set if the item matches exactly name.
+	literal bool
+	// plain is set if the item is a regular expression of plain name,
+	// it matches names containing name.
+	plain bool
+	name  string
+	re    *regexp.Regexp
+	err   error
+}
+
+// splitItems splits s by delimiters which are not escaped, escapes are kept in items.
+func splitItems(s string) []string {
+	ret := make([]string, 0)
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == escapeChar && i+1 < len(s):
+			b.WriteByte(s[i])
+			b.WriteByte(s[i+1])
+			i++
+		case strings.HasPrefix(s[i:], SelectorPatternDelimiter):
+			ret = append(ret, b.String())
+			b.Reset()
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return append(ret, b.String())
+}
+
+// unescape removes escape chars, an escape char escapes the next char.
+func unescape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == escapeChar && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func parseItem(raw string) *item {
+	raw = strings.TrimSpace(raw)
+	it := &item{}
+	if strings.HasPrefix(raw, excludePrefix) {
+		it.exclude = true
+		raw = raw[len(excludePrefix):]
+	}
+	if strings.HasPrefix(raw, exactPrefix) {
+		it.literal = true
+		it.name = unescape(raw[len(exactPrefix):])
+		return it
+	}
+
+	it.re, it.err = regexp.Compile(raw)
+	if it.err != nil {
+		return it
+	}
+	// an anchored regular expression of plain name matches exactly the name, e.g., one by QuoteName.
+	if len(raw) >= 2 && strings.HasPrefix(raw, "^") && strings.HasSuffix(raw, "$") {
+		if inner, err := regexp.Compile(raw[1 : len(raw)-1]); err == nil {
+			if name, complete := inner.LiteralPrefix(); complete && it.re.MatchString(name) {
+				it.literal = true
+				it.name = name
+				return it
+			}
+		}
+	}
+	if name, complete := it.re.LiteralPrefix(); complete {
+		it.plain = true
+		it.name = name
+	}
+	return it
+}
+
+// matcher matches names by precompiled items.
+type matcher struct {
+	items int
+	names map[string]bool
+	res   []*regexp.Regexp
+}
+
+// add adds an item to m, an invalid item counts but matches nothing.
+func (m *matcher) add(it *item) {
+	m.items++
+	switch {
+	case it.err != nil:
+	case it.literal:
+		if m.names == nil {
+			m.names = make(map[string]bool)
+		}
+		m.names[it.name] = true
+	default:
+		m.res = append(m.res, it.re)
+	}
+}
+
+func (m *matcher) match(name string) bool {
+	if m.names[name] {
+		return true
+	}
+	for _, re := range m.res {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// nameSelector selects clusters by include and exclude items of names.
+type nameSelector struct {
+	include matcher
+	exclude matcher
+}
+
+func parseNames(s string) (*nameSelector, error) {
+	ret := &nameSelector{}
+	var errs []error
+	for _, raw := range splitItems(s) {
+		it := parseItem(raw)
+		if it.err != nil {
+			errs = append(errs, fmt.Errorf("invalid item %q: %v", strings.TrimSpace(raw), it.err))
+		}
+		if it.exclude {
+			ret.exclude.add(it)
+		} else {
+			ret.include.add(it)
+		}
+	}
+	return ret, utilerrors.NewAggregate(errs)
+}
+
+func (s *nameSelector) Has(clusterName string) bool {
+	if s.include.items > 0 && !s.include.match(clusterName) {
+		return false
+	}
+	return !s.exclude.match(clusterName)
+}
+
+func (s *nameSelector) Match(clusterName string, _ map[string]string) bool {
+	return s.Has(clusterName)
+}
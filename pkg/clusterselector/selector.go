@@ -15,11 +15,34 @@ limitations under the License.
 */
 
 // Package clusterselector implements the routing of cluster messages.
+/*
+A cluster selector is either of:
+
+1. comma-separated items of cluster names, e.g., "^bj-,-bj-test-.*,=c1",
+"=name" matches the name exactly, any other item is a regular expression matching part of the name,
+an item prefixed by "-" excludes clusters it matches.
+A cluster is selected if it matches no exclude item, and matches any include item or there is no include item.
+"\," is a comma in an item rather than a delimiter, "\\" is a backslash in an exact name.
+
+2. a kubernetes label selector, e.g., "region=north,tier in (edge,mec),!gpu",
+a cluster is selected if its labels match all requirements.
+
+A selector is taken as a label selector if it has any label operator, that is "key=", "key!=",
+"in (", "notin (", or a requirement like "!key".
+A bare key like "gpu" is a name pattern, use "gpu in (true)" or similar to select by labels.
+
+Selectors are parsed once and matched by precompiled matchers.
+A selector made by ClustersToSelector matches exactly the clusters, whatever characters are in their names,
+it is made of anchored and quoted regular expressions, so it is understood by old versions too.
+*/
 package clusterselector
 
 import (
 	"regexp"
 	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog"
 )
 
 const (
@@ -27,35 +50,91 @@ const (
 	SelectorPatternDelimiter = ","
 )
 
+var labelOperatorPattern = regexp.MustCompile(
+	`(^|,)\s*[A-Za-z0-9][^,=!]*(=|!=)|(^|,)\s*![A-Za-z0-9]|\s(in|notin)\s*\(`)
+
 // Selector is the interface of cluster selector.
 type Selector interface {
 	// Has determines whether the name matchs the rules.
 	Has(string) bool
-}
-
-type selector struct {
-	pattern []string
+	// Match determines whether a cluster with name and labels matchs the rules.
+	Match(name string, labels map[string]string) bool
 }
 
 // NewSelector returns a new selector object with given routing rules.
+// Invalid items are logged and match no cluster.
 func NewSelector(s string) Selector {
-	ps := strings.Split(s, SelectorPatternDelimiter)
-	for i := range ps {
-		ps[i] = strings.TrimSpace(ps[i])
+	selector, err := Parse(s)
+	if err != nil {
+		klog.Errorf("parse cluster selector %q failed: %v", s, err)
 	}
-	return &selector{ps}
+	return selector
 }
 
-func (s *selector) Has(clusterName string) bool {
-	for _, p := range s.pattern {
-		if ok, _ := regexp.MatchString(p, clusterName); ok {
-			return true
+/*
+Parse parses s to a selector.
+If s is invalid, it returns the error with a selector in which invalid items match no cluster,
+and an invalid label selector is taken as name items.
+*/
+func Parse(s string) (Selector, error) {
+	if IsLabelSelector(s) {
+		ls, err := labels.Parse(s)
+		if err == nil {
+			return &labelSelector{ls}, nil
 		}
+		klog.Errorf("parse label selector %q failed, take it as name items: %v", s, err)
 	}
-	return false
+	return parseNames(s)
+}
+
+// labelSelector selects clusters by labels.
+type labelSelector struct {
+	selector labels.Selector
+}
+
+func (s *labelSelector) Has(clusterName string) bool {
+	return s.Match(clusterName, nil)
+}
+
+func (s *labelSelector) Match(_ string, clusterLabels map[string]string) bool {
+	return s.selector.Matches(labels.Set(clusterLabels))
+}
+
+// IsLabelSelector checks if s is a label selector rather than name items.
+func IsLabelSelector(s string) bool {
+	return labelOperatorPattern.MatchString(s)
 }
 
-// ClustersToSelector combines given clusters to routing rule.
+// ClustersToSelector combines given clusters to a selector matching exactly them.
 func ClustersToSelector(clusters *[]string) string {
-	return strings.Join(*clusters, SelectorPatternDelimiter)
+	items := make([]string, len(*clusters))
+	for i, c := range *clusters {
+		items[i] = QuoteName(c)
+	}
+	return strings.Join(items, SelectorPatternDelimiter)
+}
+
+// QuoteName returns a selector item matching exactly name.
+func QuoteName(name string) string {
+	quoted := regexp.QuoteMeta(name)
+	quoted = strings.Replace(quoted, SelectorPatternDelimiter, `\`+SelectorPatternDelimiter, -1)
+	return "^" + quoted + "$"
+}
+
+/*
+Clusters returns names of clusters in a selector made by ClustersToSelector,
+a regular expression of plain name is taken as the name, which is made by old versions.
+Exclude items, label selectors and items matching more than a name are ignored.
+*/
+func Clusters(s string) []string {
+	if s == "" || IsLabelSelector(s) {
+		return nil
+	}
+	var ret []string
+	for _, item := range splitItems(s) {
+		if it := parseItem(item); !it.exclude && (it.literal || it.plain) {
+			ret = append(ret, it.name)
+		}
+	}
+	return ret
 }
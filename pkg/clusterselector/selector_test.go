@@ -31,5 +31,87 @@ func TestSelector(t *testing.T) {
 	assert.True(t, selector.Has("d234"))
 	assert.False(t, selector.Has("d34"))
 
-	assert.Equal(t, "c1,c2,c3", ClustersToSelector(&[]string{"c1", "c2", "c3"}))
+	assert.Equal(t, "^c1$,^c2$,^c3$", ClustersToSelector(&[]string{"c1", "c2", "c3"}))
+}
+
+func TestNameSelectorGrammar(t *testing.T) {
+	// include by regex, exclude by regex
+	selector := NewSelector("^bj-,-bj-test-.*")
+	assert.True(t, selector.Has("bj-1"))
+	assert.False(t, selector.Has("bj-test-1"))
+	assert.False(t, selector.Has("sh-1"))
+
+	// exact match
+	selector = NewSelector("=c1, =c.2")
+	assert.True(t, selector.Has("c1"))
+	assert.True(t, selector.Has("c.2"))
+	assert.False(t, selector.Has("c12"))
+	assert.False(t, selector.Has("cx2"))
+
+	// exclude only selects all others
+	selector = NewSelector("-=c1")
+	assert.False(t, selector.Has("c1"))
+	assert.True(t, selector.Has("c12"))
+
+	// escaped delimiter
+	selector = NewSelector(`=a\,b,=c`)
+	assert.True(t, selector.Has("a,b"))
+	assert.True(t, selector.Has("c"))
+	assert.False(t, selector.Has("a"))
+	assert.False(t, selector.Has("b"))
+
+	// invalid item matches nothing
+	selector, err := Parse("c[")
+	assert.NotNil(t, err)
+	assert.False(t, selector.Has("c["))
+	assert.False(t, selector.Has("c1"))
+	selector, err = Parse("c[,c1")
+	assert.NotNil(t, err)
+	assert.True(t, selector.Has("c1"))
+
+	// empty selector selects all
+	assert.True(t, NewSelector("").Has("c1"))
+}
+
+func TestClustersToSelector(t *testing.T) {
+	names := []string{"c1", "bj.1", "a,b", `a\b`, "c(1|2)*", "x=y", "-c", "=c"}
+	s := ClustersToSelector(&names)
+	assert.False(t, IsLabelSelector(s))
+	assert.Equal(t, names, Clusters(s))
+
+	selector := NewSelector(s)
+	for _, name := range names {
+		assert.True(t, selector.Has(name), name)
+	}
+	for _, name := range []string{"c12", "bjx1", "a", "b", "c1|2", "c", "xx=y"} {
+		assert.False(t, selector.Has(name), name)
+	}
+
+	// selector made by old versions
+	assert.Equal(t, []string{"c1", "c2"}, Clusters("c1,c2"))
+	assert.Equal(t, []string{"c1"}, Clusters("c1,c\\d+,-c2"))
+	assert.Nil(t, Clusters(""))
+	assert.Nil(t, Clusters("region=north"))
+}
+
+func TestLabelSelector(t *testing.T) {
+	assert.True(t, IsLabelSelector("region=north"))
+	assert.True(t, IsLabelSelector("region!=north"))
+	assert.True(t, IsLabelSelector("tier in (edge,mec)"))
+	assert.True(t, IsLabelSelector("c1,!gpu"))
+	assert.False(t, IsLabelSelector("c\\d+,d2"))
+	assert.False(t, IsLabelSelector("c(1|2)"))
+
+	selector := NewSelector("region=north,tier in (edge,mec),!gpu")
+	assert.True(t, selector.Match("c1", map[string]string{"region": "north", "tier": "edge"}))
+	assert.True(t, selector.Match("c2", map[string]string{"region": "north", "tier": "mec", "zone": "a"}))
+	assert.False(t, selector.Match("c3", map[string]string{"region": "north", "tier": "edge", "gpu": "true"}))
+	assert.False(t, selector.Match("c4", map[string]string{"region": "south", "tier": "edge"}))
+	assert.False(t, selector.Match("c5", nil))
+	assert.False(t, selector.Has("c1"))
+
+	// name patterns ignore labels
+	selector = NewSelector("c1,c2")
+	assert.True(t, selector.Match("c1", map[string]string{"region": "north"}))
+	assert.False(t, selector.Match("c3", map[string]string{"region": "north"}))
 }
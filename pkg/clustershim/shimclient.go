@@ -19,11 +19,9 @@ package clustershim
 import (
 	"fmt"
 	"net/http"
-	"net/url"
 	"time"
 
 	"github.com/golang/protobuf/proto"
-	"github.com/gorilla/websocket"
 	"k8s.io/klog"
 
 	otev1 "github.com/baidu/ote-stack/pkg/apis/ote/v1"
@@ -54,6 +52,9 @@ type remoteShimClient struct {
 	shimAddr       string
 	shimClientName string
 	client         *tunnel.WSClient
+	transport      tunnel.Transport
+	proxy          *tunnel.ProxyConfig
+	reconnector    *tunnel.Reconnector
 	respChan       chan *clustermessage.ClusterMessage
 }
 
@@ -139,27 +140,29 @@ func (s *localShimClient) ReturnChan() <-chan *clustermessage.ClusterMessage {
 	return nil
 }
 
-// NewRemoteShimClient returns a remote shim client which is connecting to addr.
-func NewRemoteShimClient(shimClientName, addr string) ShimServiceClient {
+// NewRemoteShimClient returns a remote shim client which is connecting to addr through proxy.
+func NewRemoteShimClient(shimClientName, addr string, proxy *tunnel.ProxyConfig) ShimServiceClient {
 	var err error
 
 	ret := &remoteShimClient{
 		shimAddr:       addr,
 		shimClientName: shimClientName,
+		proxy:          proxy,
 		respChan:       make(chan *clustermessage.ClusterMessage, shimRespChanLen),
 	}
 
-	for i := 0; i < shimConnectedRetryTime; i++ {
-		if err = ret.connect(); err == nil {
-			break
-		}
-		time.Sleep(shimConnectedRetryDuration)
-	}
-
-	if err != nil {
+	// give up if shim server is not available in about a minute at first,
+	// and reconnect forever once connected.
+	policy := tunnel.DefaultReconnectPolicy()
+	policy.MaxDelay = shimConnectedRetryDuration
+	policy.MaxAttempts = shimConnectedRetryTime
+	ret.reconnector = tunnel.NewReconnector("shim:"+addr, policy)
+	if err = ret.reconnector.Run(nil, ret.connect); err != nil {
 		klog.Errorf("cc connects to shim server failed: %v", err)
+		ret.reconnector.Close()
 		return nil
 	}
+	ret.reconnector.SetPolicy(tunnel.DefaultReconnectPolicy())
 
 	go func() {
 		for {
@@ -167,6 +170,7 @@ func NewRemoteShimClient(shimClientName, addr string) ShimServiceClient {
 			klog.Errorf("cc disconnects to shim server")
 
 			ret.client.Close()
+			ret.reconnector.Disconnected(fmt.Errorf("disconnect from shim server %s", ret.shimAddr))
 			ret.reconnect()
 		}
 	}()
@@ -211,14 +215,16 @@ func (s *remoteShimClient) handleReceiveMessage() {
 }
 
 func (s *remoteShimClient) connect() error {
-	u := url.URL{
-		Scheme: "ws",
-		Host:   s.shimAddr,
-		Path:   fmt.Sprintf("/%s/%s", shimServerPathForClusterController, s.shimClientName),
+	if s.transport == nil {
+		transport, err := tunnel.NewTransport(nil, s.proxy)
+		if err != nil {
+			return err
+		}
+		s.transport = transport
 	}
 
-	header := http.Header{}
-	conn, resp, err := websocket.DefaultDialer.Dial(u.String(), header)
+	path := fmt.Sprintf("/%s/%s", shimServerPathForClusterController, s.shimClientName)
+	client, resp, err := tunnel.DialClient(s.transport, s.shimClientName, s.shimAddr, path, http.Header{})
 	if err != nil {
 		if resp != nil {
 			return fmt.Errorf("failed to connect to remote shim, code=%v", resp.StatusCode)
@@ -226,19 +232,12 @@ func (s *remoteShimClient) connect() error {
 		return fmt.Errorf("failed to connect to remote shim: %v", err)
 	}
 
-	s.client = tunnel.NewWSClient(s.shimClientName, conn)
+	s.client = client
 
 	return nil
 }
 
 func (s *remoteShimClient) reconnect() {
-	for {
-		if err := s.connect(); err != nil {
-			time.Sleep(shimConnectedRetryDuration)
-			continue
-		}
-
-		klog.Info("shim reconnect success")
-		break
-	}
+	s.reconnector.Run(nil, s.connect)
+	klog.Info("shim reconnect success")
 }
@@ -206,10 +206,10 @@ func TestRemoteShimClient(t *testing.T) {
 	testServer := NewShimServer()
 	go testServer.Serve("")
 
-	shimclient := NewRemoteShimClient("testshim", ":9999")
+	shimclient := NewRemoteShimClient("testshim", ":9999", nil)
 	assert.Nil(t, shimclient)
 
-	shimclient = NewRemoteShimClient("testshim", testServer.server.Addr)
+	shimclient = NewRemoteShimClient("testshim", testServer.server.Addr, nil)
 	require.NotNil(t, shimclient)
 	c, ok := shimclient.(*remoteShimClient)
 	require.True(t, ok)
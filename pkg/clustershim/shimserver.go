@@ -26,7 +26,6 @@ import (
 
 	"github.com/golang/protobuf/proto"
 	"github.com/gorilla/mux"
-	"github.com/gorilla/websocket"
 	"k8s.io/klog"
 
 	"github.com/baidu/ote-stack/pkg/clustermessage"
@@ -41,14 +40,11 @@ const (
 	signalBuffer                       = 1
 )
 
-var (
-	upgrader = websocket.Upgrader{}
-)
-
 // ShimServer handles requests and transmits to corresponding shim handler.
 type ShimServer struct {
 	handlers    map[string]handler.Handler
 	server      *http.Server
+	transport   tunnel.Transport
 	ccclient    *tunnel.WSClient
 	clientMutex *sync.RWMutex
 	clusterName string
@@ -141,16 +137,16 @@ func (s *ShimServer) do(w http.ResponseWriter, r *http.Request) {
 
 	s.clusterName = mux.Vars(r)[clusterNameParam]
 
-	conn, err := upgrader.Upgrade(w, r, nil)
+	client, err := tunnel.AcceptClient(s.transport, s.clusterName, w, r, nil)
 	if err != nil {
 		klog.Errorf("connect to cluster controller %s failed: %s", s.clusterName, err.Error())
-		http.Error(w, "fail to upgrade to websocket", http.StatusInternalServerError)
+		http.Error(w, "fail to accept connection", http.StatusInternalServerError)
 		return
 	}
 
 	s.clientMutex.Lock()
 	defer s.clientMutex.Unlock()
-	s.ccclient = tunnel.NewWSClient(s.clusterName, conn)
+	s.ccclient = client
 	// connected is a block function, must call it in goroutine to release http resources
 	go s.connected()
 }
@@ -208,8 +204,14 @@ func (s *ShimServer) handleReadMessage(msg []byte) {
 	s.ccclient.WriteMessage(respMsg)
 }
 
-// Serve starts a grpc server over unix socket.
+// Serve starts a server on addr with transport named by tunnel.TransportName.
 func (s *ShimServer) Serve(addr string) error {
+	transport, err := tunnel.NewTransport(nil, nil)
+	if err != nil {
+		return err
+	}
+	s.transport = transport
+
 	router := mux.NewRouter()
 	router.HandleFunc(fmt.Sprintf("/%s/{%s}",
 		shimServerPathForClusterController, clusterNameParam), s.do)
@@ -231,7 +233,7 @@ func (s *ShimServer) Serve(addr string) error {
 	stop := make(chan struct{})
 	go s.writeMessage(stop)
 
-	if err := s.server.Serve(ln); err != nil {
+	if err := s.transport.Serve(s.server, ln); err != nil {
 		klog.Errorf("fail to start shimserver: %s", err.Error())
 	}
 
@@ -243,7 +245,9 @@ func (s *ShimServer) Serve(addr string) error {
 func (s *ShimServer) Close() {
 	ctx, cancel := context.WithTimeout(context.Background(), tunnel.StopTimeout)
 	defer cancel()
-	s.server.Shutdown(ctx)
+	if s.transport != nil {
+		s.transport.Shutdown(ctx)
+	}
 }
 
 // ClusterName returns the cluster name.
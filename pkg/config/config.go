@@ -21,6 +21,9 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
 
 	"github.com/baidu/ote-stack/pkg/clustermessage"
 	oteclient "github.com/baidu/ote-stack/pkg/generated/clientset/versioned"
@@ -36,11 +39,38 @@ const (
 	ClusterConnectHeaderListenAddr = "listen-addr"
 	// ClusterConnectHeaderUserDefineName is the user-define name of the child
 	ClusterConnectHeaderUserDefineName = "name"
+	// ClusterConnectHeaderAuthorization is the header to post bootstrap token of the child,
+	// the value is "Bearer <token>".
+	ClusterConnectHeaderAuthorization = "Authorization"
+	// ClusterConnectHeaderDeliverySession is the header to negotiate reliable delivery,
+	// the dialer posts its session id and the server answers the session id to use.
+	ClusterConnectHeaderDeliverySession = "delivery-session"
+	// ClusterConnectHeaderCompression is the header to negotiate compression of messages,
+	// the dialer posts the compression it offers and the server answers it if accepted.
+	ClusterConnectHeaderCompression = "compression"
+	// ClusterConnectHeaderMaxMessageSize is the header to negotiate chunking of large messages,
+	// both the dialer and the server post the max size of messages they receive.
+	ClusterConnectHeaderMaxMessageSize = "max-message-size"
+	// ClusterConnectHeaderControllerID is the header to post id of a controller manager,
+	// which is its member name in the shard ring of controller managers.
+	ClusterConnectHeaderControllerID = "controller-id"
+	// ClusterConnectHeaderLabels is the header to post labels of the child, e.g., "region=north,tier=edge".
+	ClusterConnectHeaderLabels = "labels"
 
 	// K8sInformerSyncDuration defines k8s informer sync seconds.
 	K8sInformerSyncDuration = 10
 )
 
+// Policies on a cluster whose name is already routed to another cluster in the subtree.
+const (
+	// DuplicateNameRejectNew tells the new cluster to exit.
+	DuplicateNameRejectNew = "reject-new"
+	// DuplicateNameReplaceOld evicts the route to the old cluster and tells it to exit.
+	DuplicateNameReplaceOld = "replace-old"
+	// DuplicateNameAutoSuffix tells the new cluster to reconnect with a name suffixed by a number.
+	DuplicateNameAutoSuffix = "auto-suffix"
+)
+
 // ErrDuplicatedName is error message format.
 var (
 	ErrDuplicatedName = fmt.Errorf("cluster name duplicated")
@@ -56,6 +86,37 @@ type ClusterControllerConfig struct {
 	KubeConfig            string
 	HelmTillerAddr        string
 	RemoteShimAddr        string
+	// ClusterLabels are labels of this cluster posted to parent when connect,
+	// ClusterSelector of a ClusterController selects clusters by them.
+	ClusterLabels map[string]string
+	// TunnelCertFile and TunnelKeyFile are certificate of this cluster,
+	// used to serve cloud tunnel and as client certificate when connect to parent.
+	// TunnelCAFile is used to verify certificate of parent and childs.
+	// Tunnel works over tls only if these files are set.
+	TunnelCertFile string
+	TunnelKeyFile  string
+	TunnelCAFile   string
+	// TunnelProxyURL is the proxy through which parent and remote shim are dialed,
+	// TunnelProxyUsername and TunnelProxyPassword are its credentials.
+	// Hosts in TunnelNoProxy are dialed directly.
+	// Proxy from HTTPS_PROXY and NO_PROXY env is used if TunnelProxyURL is empty.
+	TunnelProxyURL      string
+	TunnelProxyUsername string
+	TunnelProxyPassword string
+	TunnelNoProxy       string
+	// BootstrapToken is presented to parent when connect to it.
+	BootstrapToken string
+	// TokenAuthEnable requires childs to present a valid bootstrap token,
+	// tokens are from BootstrapTokenFile and secrets watched by KubeClient.
+	TokenAuthEnable    bool
+	BootstrapTokenFile string
+	KubeClient         kubernetes.Interface
+	// OutboundQueueDir is the leveldb dir to persist messages to parent,
+	// messages are buffered in memory if it is empty.
+	// OutboundQueueMaxSize and OutboundQueueMaxAge limit messages in the queue, 0 means no limit.
+	OutboundQueueDir      string
+	OutboundQueueMaxSize  int
+	OutboundQueueMaxAge   time.Duration
 	K8sClient             oteclient.Interface
 	EdgeToClusterChan     chan clustermessage.ClusterMessage
 	ClusterToEdgeChan     chan clustermessage.ClusterMessage
@@ -70,6 +131,7 @@ type ClusterRegistry struct {
 	Listen         string
 	Time           int64
 	ParentName     string
+	Labels         map[string]string
 }
 
 // Serialize is for the ClusterRegistry serialization method.
@@ -107,6 +169,46 @@ func (cr *ClusterRegistry) WrapperToClusterMessage(
 	return msg, nil
 }
 
+// NameConflict tells a cluster that its name is used by another cluster in the tree.
+type NameConflict struct {
+	Name   string
+	Policy string
+	// NewName is the name the cluster reconnects with, it is set by auto-suffix policy.
+	NewName string
+	Reason  string
+}
+
+// Error returns the reason of the conflict.
+func (nc *NameConflict) Error() string {
+	return fmt.Sprintf("cluster name %s conflicts (%s): %s", nc.Name, nc.Policy, nc.Reason)
+}
+
+// WrapperToClusterMessage wraps NameConflict to a message to the cluster named Name.
+func (nc *NameConflict) WrapperToClusterMessage(parent string) (*clustermessage.ClusterMessage, error) {
+	b, err := json.Marshal(nc)
+	if err != nil {
+		return nil, fmt.Errorf("serialize name conflict(%v) failed: %v", nc, err)
+	}
+	msg := &clustermessage.ClusterMessage{
+		Head: &clustermessage.MessageHead{
+			Command:           clustermessage.CommandType_ClusterNameConflict,
+			ClusterName:       nc.Name,
+			ParentClusterName: parent,
+		},
+		Body: b,
+	}
+	return msg, nil
+}
+
+// NameConflictDeserialize deserializes a NameConflict.
+func NameConflictDeserialize(b []byte) (*NameConflict, error) {
+	nc := &NameConflict{}
+	if err := json.Unmarshal(b, nc); err != nil {
+		return nil, err
+	}
+	return nc, nil
+}
+
 // IsRoot check if clusterName is a root cluster.
 func IsRoot(clusterName string) bool {
 	return RootClusterName == clusterName
@@ -0,0 +1,164 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clustercontrollergc
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	otev1 "github.com/baidu/ote-stack/pkg/apis/ote/v1"
+)
+
+// ArchiveBodyLimit is the max bytes of a response body kept in archive, longer ones are truncated.
+var ArchiveBodyLimit = 1024
+
+// archiver keeps history of crds deleted.
+type archiver interface {
+	archive(cc *otev1.ClusterController) error
+}
+
+// archivedRequest is the compact history of a crd, the body of request is not kept.
+type archivedRequest struct {
+	Name            string                      `json:"name"`
+	CreateTimestamp int64                       `json:"created"`
+	FinishTimestamp int64                       `json:"finished,omitempty"`
+	Phase           string                      `json:"phase,omitempty"`
+	ClusterSelector string                      `json:"selector"`
+	Destination     string                      `json:"destination"`
+	Method          string                      `json:"method"`
+	URL             string                      `json:"url"`
+	Responses       map[string]archivedResponse `json:"responses,omitempty"`
+}
+
+type archivedResponse struct {
+	StatusCode int    `json:"code"`
+	State      string `json:"state,omitempty"`
+	Timestamp  int64  `json:"timestamp"`
+	Body       string `json:"body,omitempty"`
+	Size       int    `json:"size,omitempty"`
+	Hash       string `json:"hash,omitempty"`
+	// Truncated is true if body is not the whole one.
+	Truncated bool `json:"truncated,omitempty"`
+	// BodyRef refers to the whole body in the response store, which is deleted with the crd,
+	// it is kept with Size and Hash to identify the body, not to read it.
+	BodyRef string `json:"bodyRef,omitempty"`
+}
+
+func newArchivedRequest(cc *otev1.ClusterController) *archivedRequest {
+	ret := &archivedRequest{
+		Name:            cc.ObjectMeta.Name,
+		CreateTimestamp: cc.ObjectMeta.CreationTimestamp.Unix(),
+		FinishTimestamp: cc.FinishTimestamp,
+		Phase:           cc.Phase,
+		ClusterSelector: cc.Spec.ClusterSelector,
+		Destination:     cc.Spec.Destination,
+		Method:          cc.Spec.Method,
+		URL:             cc.Spec.URL,
+	}
+	if len(cc.Status) > 0 {
+		ret.Responses = make(map[string]archivedResponse, len(cc.Status))
+	}
+	for cluster, s := range cc.Status {
+		body, truncated := s.Body, s.Truncated
+		if len(body) > ArchiveBodyLimit {
+			body, truncated = body[:ArchiveBodyLimit], true
+		}
+		ret.Responses[cluster] = archivedResponse{
+			StatusCode: s.StatusCode,
+			State:      s.State,
+			Timestamp:  s.Timestamp,
+			Body:       body,
+			Size:       s.Size,
+			Hash:       s.Hash,
+			Truncated:  truncated,
+			BodyRef:    s.BodyRef,
+		}
+	}
+	return ret
+}
+
+/*
+fileArchiver appends crds to a file as json lines,
+the file is renamed with suffix .1 once it exceeds maxSize, so at most 2 files are kept.
+*/
+type fileArchiver struct {
+	mutex   sync.Mutex
+	path    string
+	maxSize int64
+	file    *os.File
+	size    int64
+}
+
+func newFileArchiver(path string, maxSize int64) (*fileArchiver, error) {
+	a := &fileArchiver{
+		path:    path,
+		maxSize: maxSize,
+	}
+	if err := a.open(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (a *fileArchiver) open() error {
+	f, err := os.OpenFile(a.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("open archive file %s failed: %v", a.path, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("stat archive file %s failed: %v", a.path, err)
+	}
+	a.file = f
+	a.size = info.Size()
+	return nil
+}
+
+func (a *fileArchiver) rotate() error {
+	a.file.Close()
+	renameErr := os.Rename(a.path, a.path+".1")
+	// reopen anyway, so it is rotated next time.
+	if err := a.open(); err != nil {
+		return err
+	}
+	if renameErr != nil {
+		return fmt.Errorf("rotate archive file %s failed: %v", a.path, renameErr)
+	}
+	return nil
+}
+
+func (a *fileArchiver) archive(cc *otev1.ClusterController) error {
+	line, err := json.Marshal(newArchivedRequest(cc))
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if a.maxSize > 0 && a.size > 0 && a.size+int64(len(line)) > a.maxSize {
+		if err := a.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := a.file.Write(line)
+	a.size += int64(n)
+	return err
+}
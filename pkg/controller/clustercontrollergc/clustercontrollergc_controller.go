@@ -0,0 +1,226 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package clustercontrollergc deletes finished ClusterController crds
+// after their ttl, or once there are more than the retention count of them.
+package clustercontrollergc
+
+import (
+	"sort"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	otev1 "github.com/baidu/ote-stack/pkg/apis/ote/v1"
+	"github.com/baidu/ote-stack/pkg/config"
+	"github.com/baidu/ote-stack/pkg/controllermanager"
+	oteclient "github.com/baidu/ote-stack/pkg/generated/clientset/versioned"
+	otelister "github.com/baidu/ote-stack/pkg/generated/listers/ote/v1"
+	"github.com/baidu/ote-stack/pkg/responsestore"
+)
+
+var (
+	// TTLAfterFinished is the ttl of a finished crd without ttlSecondsAfterFinished, 0 means no ttl.
+	TTLAfterFinished time.Duration
+	// Retention is the max number of finished crds kept, the earliest finished ones are deleted, 0 means no limit.
+	Retention = 1000
+	// ArchiveFile is the file to archive crds to before they are deleted, no crd is archived if it is empty.
+	ArchiveFile string
+	// ArchiveMaxSize is the max size in bytes of ArchiveFile, it is rotated once full.
+	ArchiveMaxSize int64 = 100 * 1024 * 1024
+	// ResponseStore is the kind of response store of root, bodies stored are deleted with crds, none if it is empty.
+	ResponseStore string
+	// ResponseStoreDir is the dir of file response store, it must be the one of root.
+	ResponseStoreDir string
+
+	gcPeriod = 1 * time.Minute
+	// legacyRequestTimeout is how long a crd without phase, created by an old version, is processed.
+	legacyRequestTimeout = 1 * time.Hour
+)
+
+// ClusterControllerGCController deletes finished ClusterController crds.
+type ClusterControllerGCController struct {
+	client   oteclient.Interface
+	lister   otelister.ClusterControllerLister
+	synced   cache.InformerSynced
+	archiver archiver
+	// archived are uids of crds archived but failed to delete, so they are not archived again.
+	archived map[types.UID]bool
+	// responseStore keeps bodies of responses truncated in status, they are deleted with crds, set if ResponseStore is set.
+	responseStore responsestore.Store
+	// owns checks if a cluster is in shard of this controller manager,
+	// crds are deleted by the owner of root cluster.
+	owns func(string) bool
+}
+
+// InitClusterControllerGCController inits clustercontroller gc controller.
+func InitClusterControllerGCController(ctx *controllermanager.ControllerContext) error {
+	informer := ctx.OteInformerFactory.Ote().V1().ClusterControllers()
+	gcController := &ClusterControllerGCController{
+		client: ctx.OteClient,
+		lister: informer.Lister(),
+		synced: informer.Informer().HasSynced,
+		owns:   ctx.Owns,
+	}
+	if ArchiveFile != "" {
+		a, err := newFileArchiver(ArchiveFile, ArchiveMaxSize)
+		if err != nil {
+			return err
+		}
+		gcController.archiver = a
+	}
+	if ResponseStore != "" {
+		store, err := responsestore.New(ResponseStore, ctx.K8sClient, ResponseStoreDir)
+		if err != nil {
+			return err
+		}
+		gcController.responseStore = store
+	}
+
+	go wait.Until(gcController.collect, gcPeriod, ctx.StopChan)
+	return nil
+}
+
+type finishedClusterController struct {
+	cc       *otev1.ClusterController
+	finished time.Time
+}
+
+// finishTime returns when cc finished, false if it is not finished at now.
+func finishTime(cc *otev1.ClusterController, now time.Time) (time.Time, bool) {
+	switch cc.Phase {
+	case otev1.ClusterControllerPhaseSucceeded, otev1.ClusterControllerPhasePartiallySucceeded,
+		otev1.ClusterControllerPhaseFailed, otev1.ClusterControllerPhaseTimedOut:
+		if cc.FinishTimestamp > 0 {
+			return time.Unix(cc.FinishTimestamp, 0), true
+		}
+	case "":
+		// processed by an old version, which gives up after legacyRequestTimeout.
+	default:
+		return time.Time{}, false
+	}
+	timeout := legacyRequestTimeout
+	if cc.Spec.TimeoutSeconds > 0 {
+		timeout = time.Duration(cc.Spec.TimeoutSeconds) * time.Second
+	}
+	finished := cc.ObjectMeta.CreationTimestamp.Add(timeout)
+	return finished, !finished.After(now)
+}
+
+// ttlAfterFinished returns ttl of cc, false if it has no ttl.
+func ttlAfterFinished(cc *otev1.ClusterController) (time.Duration, bool) {
+	if cc.Spec.TTLSecondsAfterFinished != nil {
+		return time.Duration(*cc.Spec.TTLSecondsAfterFinished) * time.Second, true
+	}
+	return TTLAfterFinished, TTLAfterFinished > 0
+}
+
+/*
+expiredClusterControllers returns finished crds to delete at now,
+those exceeding their ttl, then the earliest finished ones exceeding retention.
+*/
+func expiredClusterControllers(ccs []*otev1.ClusterController, now time.Time) []*otev1.ClusterController {
+	var ret []*otev1.ClusterController
+	var kept []finishedClusterController
+	for _, cc := range ccs {
+		finished, ok := finishTime(cc, now)
+		if !ok {
+			continue
+		}
+		if ttl, ok := ttlAfterFinished(cc); ok && !finished.Add(ttl).After(now) {
+			ret = append(ret, cc)
+			continue
+		}
+		kept = append(kept, finishedClusterController{cc, finished})
+	}
+	if Retention <= 0 || len(kept) <= Retention {
+		return ret
+	}
+	sort.Slice(kept, func(i, j int) bool {
+		if kept[i].finished.Equal(kept[j].finished) {
+			return kept[i].cc.ObjectMeta.Name < kept[j].cc.ObjectMeta.Name
+		}
+		return kept[i].finished.Before(kept[j].finished)
+	})
+	for _, f := range kept[:len(kept)-Retention] {
+		ret = append(ret, f.cc)
+	}
+	return ret
+}
+
+// collect deletes finished crds exceeding their ttl or retention, they are archived first if archive is enabled.
+func (c *ClusterControllerGCController) collect() {
+	if c.owns != nil && !c.owns(config.RootClusterName) {
+		klog.V(3).Infof("root cluster is not in shard of this controller manager")
+		return
+	}
+	if c.synced != nil && !c.synced() {
+		return
+	}
+	ccs, err := c.lister.ClusterControllers(otev1.ClusterNamespace).List(labels.Everything())
+	if err != nil {
+		klog.Errorf("list clustercontroller failed: %v", err)
+		return
+	}
+	// only crds still failing to delete are remembered, others are gone.
+	archived := make(map[types.UID]bool)
+	for _, cc := range expiredClusterControllers(ccs, time.Now()) {
+		uid := cc.ObjectMeta.UID
+		if c.archiver != nil && !c.archived[uid] {
+			if err := c.archiver.archive(cc); err != nil {
+				// keep it until it is archived.
+				klog.Errorf("archive clustercontroller %s failed: %v", cc.ObjectMeta.Name, err)
+				continue
+			}
+		}
+		err := c.client.OteV1().ClusterControllers(cc.ObjectMeta.Namespace).Delete(cc.ObjectMeta.Name,
+			&metav1.DeleteOptions{Preconditions: &metav1.Preconditions{UID: &uid}})
+		if err != nil && !errors.IsNotFound(err) {
+			klog.Errorf("delete clustercontroller %s failed: %v", cc.ObjectMeta.Name, err)
+			if c.archiver != nil {
+				archived[uid] = true
+			}
+			continue
+		}
+		c.deleteStoredBodies(cc)
+		klog.V(3).Infof("clustercontroller %s finished at %d is deleted", cc.ObjectMeta.Name, cc.FinishTimestamp)
+	}
+	c.archived = archived
+}
+
+/*
+deleteStoredBodies deletes bodies of responses to cc deleted from the response store,
+so they are not leaked if root, which deletes them on its informer event, misses the deletion.
+*/
+func (c *ClusterControllerGCController) deleteStoredBodies(cc *otev1.ClusterController) {
+	if c.responseStore == nil {
+		return
+	}
+	for _, s := range cc.Status {
+		if s.BodyRef == "" {
+			continue
+		}
+		if err := c.responseStore.Delete(s.BodyRef); err != nil {
+			klog.Errorf("delete response body %s of clustercontroller %s failed: %v", s.BodyRef, cc.ObjectMeta.Name, err)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clustercontrollergc
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+
+	otev1 "github.com/baidu/ote-stack/pkg/apis/ote/v1"
+	"github.com/baidu/ote-stack/pkg/controllermanager"
+	"github.com/baidu/ote-stack/pkg/generated/clientset/versioned/fake"
+	oteinformer "github.com/baidu/ote-stack/pkg/generated/informers/externalversions"
+	otelister "github.com/baidu/ote-stack/pkg/generated/listers/ote/v1"
+	"github.com/baidu/ote-stack/pkg/responsestore"
+)
+
+func newFinishedClusterController(name string, phase string, finished time.Time) *otev1.ClusterController {
+	return &otev1.ClusterController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         otev1.ClusterNamespace,
+			CreationTimestamp: metav1.NewTime(finished.Add(-time.Minute)),
+		},
+		Phase:           phase,
+		FinishTimestamp: finished.Unix(),
+	}
+}
+
+func names(ccs []*otev1.ClusterController) []string {
+	var ret []string
+	for _, cc := range ccs {
+		ret = append(ret, cc.ObjectMeta.Name)
+	}
+	return ret
+}
+
+func TestInitClusterControllerGCController(t *testing.T) {
+	stop := make(chan struct{})
+	defer close(stop)
+	ctx := &controllermanager.ControllerContext{
+		K8sContext: controllermanager.K8sContext{
+			OteClient:          fake.NewSimpleClientset(),
+			OteInformerFactory: oteinformer.NewSharedInformerFactory(fake.NewSimpleClientset(), 0),
+		},
+		StopChan: stop,
+	}
+	assert.Nil(t, InitClusterControllerGCController(ctx))
+
+	ArchiveFile = filepath.Join("notexist", "archive")
+	defer func() { ArchiveFile = "" }()
+	assert.NotNil(t, InitClusterControllerGCController(ctx))
+}
+
+func TestExpiredClusterControllers(t *testing.T) {
+	defer func(ttl time.Duration, retention int) {
+		TTLAfterFinished = ttl
+		Retention = retention
+	}(TTLAfterFinished, Retention)
+
+	now := time.Now()
+	ttl := int64(60)
+	withTTL := newFinishedClusterController("ttl", otev1.ClusterControllerPhaseSucceeded, now.Add(-2*time.Minute))
+	withTTL.Spec.TTLSecondsAfterFinished = &ttl
+	notExpired := newFinishedClusterController("notexpired", otev1.ClusterControllerPhaseFailed, now.Add(-30*time.Second))
+	notExpired.Spec.TTLSecondsAfterFinished = &ttl
+	running := newFinishedClusterController("running", otev1.ClusterControllerPhaseDispatched, now.Add(-time.Hour))
+	running.FinishTimestamp = 0
+	old := newFinishedClusterController("old", otev1.ClusterControllerPhaseTimedOut, now.Add(-time.Hour))
+	legacy := newFinishedClusterController("legacy", "", now)
+	legacy.ObjectMeta.CreationTimestamp = metav1.NewTime(now.Add(-3 * time.Hour))
+	newLegacy := newFinishedClusterController("newlegacy", "", now)
+	ccs := []*otev1.ClusterController{withTTL, notExpired, running, old, legacy, newLegacy}
+
+	// only crds with ttl are deleted without default ttl and retention.
+	TTLAfterFinished = 0
+	Retention = 0
+	assert.Equal(t, []string{"ttl"}, names(expiredClusterControllers(ccs, now)))
+
+	// default ttl applies to crds without ttl, unfinished ones are kept.
+	TTLAfterFinished = 30 * time.Minute
+	assert.Equal(t, []string{"ttl", "old", "legacy"}, names(expiredClusterControllers(ccs, now)))
+
+	// the earliest finished ones exceeding retention are deleted.
+	TTLAfterFinished = 0
+	Retention = 1
+	assert.Equal(t, []string{"ttl", "legacy", "old"}, names(expiredClusterControllers(ccs, now)))
+}
+
+func TestCollect(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ccgc")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	now := time.Now()
+	ttl := int64(0)
+	finished := newFinishedClusterController("finished", otev1.ClusterControllerPhasePartiallySucceeded, now)
+	finished.Spec.TTLSecondsAfterFinished = &ttl
+	finished.Spec.URL = "/api/v1/namespaces"
+	finished.Spec.Body = "request body"
+	finished.Status = map[string]otev1.ClusterControllerStatus{
+		"c1": {StatusCode: 200, State: otev1.ClusterControllerStateSucceeded, Body: strings.Repeat("a", ArchiveBodyLimit+1)},
+		"c2": {State: otev1.ClusterControllerStateNotReached},
+	}
+	running := newFinishedClusterController("running", otev1.ClusterControllerPhaseDispatched, now)
+	running.Spec.TTLSecondsAfterFinished = &ttl
+
+	client := fake.NewSimpleClientset(finished, running)
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc,
+		cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	indexer.Add(finished)
+	indexer.Add(running)
+	archiveFile := filepath.Join(dir, "archive")
+	a, err := newFileArchiver(archiveFile, 0)
+	assert.Nil(t, err)
+	c := &ClusterControllerGCController{
+		client:   client,
+		lister:   otelister.NewClusterControllerLister(indexer),
+		archiver: a,
+	}
+
+	exists := func(name string) bool {
+		_, err := client.OteV1().ClusterControllers(otev1.ClusterNamespace).Get(name, metav1.GetOptions{})
+		return err == nil
+	}
+
+	// nothing is done by controller manager not owning root.
+	c.owns = func(string) bool { return false }
+	c.collect()
+	assert.True(t, exists("finished"))
+
+	c.owns = nil
+	c.collect()
+	assert.False(t, exists("finished"))
+	assert.True(t, exists("running"))
+
+	f, err := os.Open(archiveFile)
+	assert.Nil(t, err)
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	assert.True(t, scanner.Scan())
+	archived := archivedRequest{}
+	assert.Nil(t, json.Unmarshal(scanner.Bytes(), &archived))
+	assert.Equal(t, "finished", archived.Name)
+	assert.Equal(t, otev1.ClusterControllerPhasePartiallySucceeded, archived.Phase)
+	assert.Equal(t, "/api/v1/namespaces", archived.URL)
+	assert.NotContains(t, scanner.Text(), "request body")
+	assert.Equal(t, ArchiveBodyLimit, len(archived.Responses["c1"].Body))
+	assert.Equal(t, otev1.ClusterControllerStateNotReached, archived.Responses["c2"].State)
+	assert.False(t, scanner.Scan())
+}
+
+func TestCollectArchiveOnce(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ccgc")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	ttl := int64(0)
+	cc := newFinishedClusterController("cc", otev1.ClusterControllerPhaseSucceeded, time.Now())
+	cc.ObjectMeta.UID = "cc-uid"
+	cc.Spec.TTLSecondsAfterFinished = &ttl
+
+	client := fake.NewSimpleClientset(cc)
+	deleteFailed := false
+	client.PrependReactor("delete", "clustercontrollers", func(k8stesting.Action) (bool, runtime.Object, error) {
+		if deleteFailed {
+			return false, nil, nil
+		}
+		deleteFailed = true
+		return true, nil, fmt.Errorf("delete failed")
+	})
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc,
+		cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	indexer.Add(cc)
+	archiveFile := filepath.Join(dir, "archive")
+	a, err := newFileArchiver(archiveFile, 0)
+	assert.Nil(t, err)
+	c := &ClusterControllerGCController{
+		client:   client,
+		lister:   otelister.NewClusterControllerLister(indexer),
+		archiver: a,
+	}
+
+	// crd failed to delete is deleted next time without archiving again.
+	c.collect()
+	assert.True(t, c.archived["cc-uid"])
+	c.collect()
+	_, err = client.OteV1().ClusterControllers(otev1.ClusterNamespace).Get("cc", metav1.GetOptions{})
+	assert.NotNil(t, err)
+	assert.Empty(t, c.archived)
+	content, err := ioutil.ReadFile(archiveFile)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, strings.Count(string(content), "\n"))
+}
+
+func TestCollectResponseStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ccgc")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	store, err := responsestore.New(responsestore.KindFile, nil, filepath.Join(dir, "store"))
+	assert.Nil(t, err)
+
+	ttl := int64(0)
+	cc := newFinishedClusterController("stored", otev1.ClusterControllerPhaseSucceeded, time.Now())
+	cc.Spec.TTLSecondsAfterFinished = &ttl
+	body := strings.Repeat("b", 10) + strings.Repeat("c", ArchiveBodyLimit)
+	ref, err := store.Put(responsestore.Key{Namespace: otev1.ClusterNamespace, Name: "stored", Cluster: "c1"}, []byte(body))
+	assert.Nil(t, err)
+	cc.Status = map[string]otev1.ClusterControllerStatus{
+		"c1": {StatusCode: 200, Body: body[:10], Size: len(body), Hash: responsestore.Hash([]byte(body)),
+			Truncated: true, BodyRef: ref},
+	}
+
+	client := fake.NewSimpleClientset(cc)
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc,
+		cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	indexer.Add(cc)
+	archiveFile := filepath.Join(dir, "archive")
+	a, err := newFileArchiver(archiveFile, 0)
+	assert.Nil(t, err)
+	c := &ClusterControllerGCController{
+		client:        client,
+		lister:        otelister.NewClusterControllerLister(indexer),
+		archiver:      a,
+		responseStore: store,
+	}
+
+	// the body stored is deleted with the crd, it is archived by reference and hash without reading it.
+	c.collect()
+	_, err = store.Get(ref)
+	assert.NotNil(t, err)
+	content, err := ioutil.ReadFile(archiveFile)
+	assert.Nil(t, err)
+	archived := archivedRequest{}
+	assert.Nil(t, json.Unmarshal(content, &archived))
+	assert.Equal(t, body[:10], archived.Responses["c1"].Body)
+	assert.True(t, archived.Responses["c1"].Truncated)
+	assert.Equal(t, ref, archived.Responses["c1"].BodyRef)
+	assert.Equal(t, responsestore.Hash([]byte(body)), archived.Responses["c1"].Hash)
+}
+
+func TestFileArchiverRotate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ccgc")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	archiveFile := filepath.Join(dir, "archive")
+	cc := newFinishedClusterController("cc", otev1.ClusterControllerPhaseSucceeded, time.Now())
+	line, err := json.Marshal(newArchivedRequest(cc))
+	assert.Nil(t, err)
+	a, err := newFileArchiver(archiveFile, int64(len(line)+1)*2)
+	assert.Nil(t, err)
+
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, a.archive(cc))
+	}
+	rotated, err := ioutil.ReadFile(archiveFile + ".1")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, strings.Count(string(rotated), "\n"))
+	current, err := ioutil.ReadFile(archiveFile)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, strings.Count(string(current), "\n"))
+}
@@ -37,6 +37,8 @@ import (
 type ClusterCrdController struct {
 	sendChan  chan clustermessage.ClusterMessage
 	k8sClient kubernetes.Interface
+	// owns checks if a cluster is in shard of this controller manager.
+	owns func(string) bool
 }
 
 //InitClusterCrdController inits clustercrd controller.
@@ -44,6 +46,7 @@ func InitClusterCrdController(ctx *controllermanager.ControllerContext) error {
 	clusterCrdController := &ClusterCrdController{
 		sendChan:  ctx.PublishChan,
 		k8sClient: ctx.K8sClient,
+		owns:      ctx.Owns,
 	}
 
 	ctx.OteInformerFactory.Ote().V1().Clusters().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -60,6 +63,10 @@ func InitClusterCrdController(ctx *controllermanager.ControllerContext) error {
 func (c *ClusterCrdController) handleAddedEvent(obj interface{}) {
 	cluster := obj.(*otev1.Cluster)
 	klog.V(3).Infof("new cluster added: %v", cluster.ObjectMeta.Name)
+	if c.owns != nil && !c.owns(cluster.Spec.Name) {
+		klog.V(3).Infof("cluster %s is not in shard of this controller manager", cluster.Spec.Name)
+		return
+	}
 
 	err := c.sendNamespaceToNewCluster(cluster)
 	if err != nil {
@@ -28,6 +28,7 @@ import (
 
 	otev1 "github.com/baidu/ote-stack/pkg/apis/ote/v1"
 	"github.com/baidu/ote-stack/pkg/clustermessage"
+	"github.com/baidu/ote-stack/pkg/config"
 	"github.com/baidu/ote-stack/pkg/controller"
 	"github.com/baidu/ote-stack/pkg/controllermanager"
 )
@@ -35,12 +36,16 @@ import (
 //NamespaceController is responsible for performing actions dependent upon a namespace phase.
 type NamespaceController struct {
 	sendChan chan clustermessage.ClusterMessage
+	// owns checks if a cluster is in shard of this controller manager,
+	// namespaces are sent to all clusters by the owner of root cluster.
+	owns func(string) bool
 }
 
 //InitNamespaceController inits namespace controller.
 func InitNamespaceController(ctx *controllermanager.ControllerContext) error {
 	namespaceController := &NamespaceController{
 		sendChan: ctx.PublishChan,
+		owns:     ctx.Owns,
 	}
 	ctx.InformerFactory.Core().V1().Namespaces().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: namespaceController.handleAddedEvent,
@@ -54,6 +59,10 @@ func InitNamespaceController(ctx *controllermanager.ControllerContext) error {
 func (c *NamespaceController) handleAddedEvent(obj interface{}) {
 	namespace := obj.(*v1.Namespace)
 	klog.V(3).Infof("new namespace added: %v", namespace.ObjectMeta.Name)
+	if c.owns != nil && !c.owns(config.RootClusterName) {
+		klog.V(3).Infof("root cluster is not in shard of this controller manager")
+		return
+	}
 
 	err := c.sendNamespaceToCluster(namespace)
 	if err != nil {
@@ -35,6 +35,9 @@ type ControllerContext struct {
 	PublishChan chan clustermessage.ClusterMessage
 	// a tunnel connected to root cluster controller
 	controllerTunnel tunnel.ControllerTunnel
+	// Shard is the clusters owned by this controller manager if sharding is enabled,
+	// nil means all clusters.
+	Shard *tunnel.Shard
 	//StopChan is the stop channel
 	StopChan <-chan struct{}
 }
@@ -50,3 +53,8 @@ type K8sContext struct {
 	K8sClient       kubernetes.Interface
 	InformerFactory informers.SharedInformerFactory
 }
+
+// Owns checks if cluster should be processed by this controller manager.
+func (ctx *ControllerContext) Owns(cluster string) bool {
+	return ctx.Shard == nil || ctx.Shard.Owns(cluster)
+}
@@ -19,11 +19,14 @@ limitations under the License.
 package edgehandler
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/golang/protobuf/proto"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/klog"
 
 	"github.com/baidu/ote-stack/pkg/clustermessage"
@@ -31,20 +34,28 @@ import (
 	"github.com/baidu/ote-stack/pkg/clusterselector"
 	"github.com/baidu/ote-stack/pkg/clustershim"
 	"github.com/baidu/ote-stack/pkg/config"
+	"github.com/baidu/ote-stack/pkg/priorityqueue"
 	"github.com/baidu/ote-stack/pkg/tunnel"
 )
 
 var (
 	subtreeReportDuration       = 1 * time.Second
-	sendToParentTimeout         = 1 * time.Second
-	sendToParentChan            = make(chan []byte, 10000)
+	sendToParentQueueSize       = 10000
 	sendToClusterHandlerTimeout = 1 * time.Second
 )
 
+const (
+	// parentLink is the link name of parent in priority queues.
+	parentLink = "parent"
+)
+
 // EdgeHandler is edgehandler interface that process messages from tunnel and transmit to shim.
 type EdgeHandler interface {
 	// Start will start edgehandler.
 	Start() error
+	// Stop flushes messages to parent and disconnects from parent,
+	// messages not sent before ctx is done are dropped, or kept in outbound queue dir if it is set.
+	Stop(ctx context.Context) error
 }
 
 // edgeHandler processes message from tunnel and transmit to shim.
@@ -54,6 +65,13 @@ type edgeHandler struct {
 	shimClient        clustershim.ShimServiceClient
 	stopReportSubtree chan struct{}
 	rootClusterEnable bool
+	// queue persists messages to parent if it is not nil.
+	queue *outboundQueue
+	// memQueue buffers messages to parent in memory if queue is nil.
+	memQueue     *priorityqueue.Queue
+	memQueueOnce sync.Once
+	// subtree decides what to report of subtree routes.
+	subtree subtreeReporter
 }
 
 // NewEdgeHandler returns a edgeHandler object.
@@ -102,7 +120,7 @@ func (e *edgeHandler) Start() error {
 
 	if e.isRemoteShim() {
 		klog.Infof("init remote shim client")
-		e.shimClient = clustershim.NewRemoteShimClient(e.conf.ClusterName, e.conf.RemoteShimAddr)
+		e.shimClient = clustershim.NewRemoteShimClient(e.conf.ClusterName, e.conf.RemoteShimAddr, tunnel.NewProxyConfig(e.conf))
 	} else if !e.isRoot() {
 		klog.Infof("init local shim client")
 		e.shimClient = clustershim.NewlocalShimClient(e.conf)
@@ -117,12 +135,24 @@ func (e *edgeHandler) Start() error {
 		e.edgeTunnel.RegistReceiveMessageHandler(e.receiveMessageFromTunnel)
 		e.edgeTunnel.RegistAfterConnectToHook(e.afterConnect)
 		e.edgeTunnel.RegistAfterDisconnectHook(e.afterDisconnect)
+		if e.conf.OutboundQueueDir != "" {
+			queue, err := newOutboundQueue(e.conf.OutboundQueueDir,
+				e.conf.OutboundQueueMaxSize, e.conf.OutboundQueueMaxAge, e.edgeTunnel.Send)
+			if err != nil {
+				return err
+			}
+			e.queue = queue
+		}
 		if err := e.edgeTunnel.Start(); err != nil {
 			return err
 		}
 
 		// send the msg from cluster handler and shim.
-		go e.sendMessageToParent()
+		if e.queue != nil {
+			go e.queue.Run(wait.NeverStop)
+		} else {
+			go e.sendMessageToParent()
+		}
 
 		// handle the msg from cluster handler.
 		go e.sendMessageToTunnel()
@@ -143,13 +173,43 @@ func (e *edgeHandler) Start() error {
 	return nil
 }
 
-// sendMessageToParent has multi tasks for reading the message from sendToParentChan,
-// and sending the message to parent cluster.
-func (e *edgeHandler) sendMessageToParent() {
-	for {
-		data := <-sendToParentChan
-		e.edgeTunnel.Send(data)
+func (e *edgeHandler) Stop(ctx context.Context) error {
+	if e.edgeTunnel == nil {
+		return nil
 	}
+
+	var err error
+	if e.queue != nil {
+		err = e.queue.Flush(ctx)
+	} else {
+		err = e.memoryQueue().Flush(ctx)
+	}
+	if err != nil {
+		klog.Errorf("flush messages to parent failed: %v", err)
+	}
+
+	err = e.edgeTunnel.Stop(ctx)
+	if e.queue != nil {
+		if closeErr := e.queue.Close(); closeErr != nil {
+			klog.Errorf("close outbound queue failed: %v", closeErr)
+		}
+	}
+	return err
+}
+
+// memoryQueue returns the in-memory queue of messages to parent.
+func (e *edgeHandler) memoryQueue() *priorityqueue.Queue {
+	e.memQueueOnce.Do(func() {
+		e.memQueue = priorityqueue.NewQueue(parentLink, sendToParentQueueSize, func(data []byte) error {
+			return e.edgeTunnel.Send(data)
+		})
+	})
+	return e.memQueue
+}
+
+// sendMessageToParent sends messages in the in-memory queue to parent cluster by priority.
+func (e *edgeHandler) sendMessageToParent() {
+	e.memoryQueue().Run(wait.NeverStop)
 }
 
 func (e *edgeHandler) sendMessageToTunnel() {
@@ -173,7 +233,7 @@ func (e *edgeHandler) receiveMessageFromTunnel(client string, data []byte) (ret
 	e.conf.EdgeToClusterChan <- *msg
 
 	selector := clusterselector.NewSelector(msg.Head.ClusterSelector)
-	if selector.Has(e.conf.ClusterName) {
+	if selector.Match(e.conf.ClusterName, e.conf.ClusterLabels) {
 		e.handleMessage(msg)
 	}
 
@@ -207,6 +267,8 @@ func (e *edgeHandler) handleMessage(msg *clustermessage.ClusterMessage) error {
 			}
 
 			resp.Head.ClusterName = e.conf.ClusterName
+			// path of the response starts from this cluster.
+			clustermessage.Hop(resp, e.conf.ClusterName)
 			// send to cloudtunnel.
 			err = e.sendToParent(resp)
 		} else {
@@ -222,6 +284,11 @@ func (e *edgeHandler) handleMessage(msg *clustermessage.ClusterMessage) error {
 			klog.Errorf("handleTask error: %s", err.Error())
 		}
 		return err
+	case clustermessage.CommandType_SubTreeRoute:
+		if ack := clusterrouter.SubtreeAckFromClusterMessage(msg); ack != nil {
+			e.subtree.ack(ack)
+		}
+		return nil
 	default:
 		klog.Errorf("command %s is not supported by edge handler", msg.Head.Command.String())
 		return nil
@@ -254,6 +321,9 @@ func (e *edgeHandler) handleRespFromShimClient() {
 				klog.V(5).Info("send report msg to cluster handler timeout")
 			}
 		} else {
+			// path of the response starts from this cluster,
+			// that of root is recorded by cluster handler.
+			clustermessage.Hop(resp, e.conf.ClusterName)
 			// send to cloudtunnel.
 			e.sendToParent(resp)
 		}
@@ -262,11 +332,19 @@ func (e *edgeHandler) handleRespFromShimClient() {
 }
 
 func (e *edgeHandler) afterConnect() {
-	// start subtree report goroutine
+	// drain messages queued during disconnection
+	if e.queue != nil {
+		e.queue.Resume()
+	}
+	// start subtree report goroutine, which starts from a full sync
+	e.subtree.reset()
 	go e.reportSubTreeTimer()
 }
 
 func (e *edgeHandler) afterDisconnect() {
+	if e.queue != nil {
+		e.queue.Pause()
+	}
 	// stop subtree report goroutine
 	e.stopReportSubtree <- struct{}{}
 }
@@ -290,7 +368,14 @@ func (e *edgeHandler) reportSubTreeTimer() {
 }
 
 func (e *edgeHandler) reportSubTree() {
-	msg := clusterrouter.Router().SubTreeMessage()
+	info := e.subtree.next(clusterrouter.Router().Subtree(), time.Now())
+	if info == nil {
+		return
+	}
+	msg := info.Message()
+	if info.Version == 0 {
+		msg = info.Routes.Message()
+	}
 	if msg == nil {
 		return
 	}
@@ -299,18 +384,30 @@ func (e *edgeHandler) reportSubTree() {
 }
 
 func (e *edgeHandler) sendToParent(msg *clustermessage.ClusterMessage) error {
+	if e.queue != nil {
+		if err := e.queue.Push(msg); err != nil {
+			klog.Errorf("push message to outbound queue failed: %v", err)
+			return err
+		}
+		return nil
+	}
+
 	data, err := proto.Marshal(msg)
 	if err != nil {
 		klog.Errorf("marshal cluster message error: %s", err.Error())
 		return err
 	}
 
-	select {
-	case sendToParentChan <- data:
-		klog.V(5).Info("send msg to parent success")
-	case <-time.After(sendToParentTimeout):
-		klog.V(5).Info("send msg to parent timeout")
+	err = e.memoryQueue().Push(clustermessage.PriorityOf(msg), data, func(err error) {
+		// message is not retried without outbound queue.
+		if err != nil {
+			klog.Warningf("message(%s) %s to parent is dropped, set --outbound-queue-dir to keep it: %v",
+				msg.GetHead().GetMessageID(), msg.GetHead().GetCommand().String(), err)
+		}
+	})
+	if err != nil {
+		klog.Errorf("push message to parent queue failed: %v", err)
+		return err
 	}
-
 	return nil
 }
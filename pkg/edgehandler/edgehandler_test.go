@@ -17,6 +17,7 @@ limitations under the License.
 package edgehandler
 
 import (
+	"context"
 	"fmt"
 	"testing"
 	"time"
@@ -78,10 +79,14 @@ func (f *fakeEdgeTunnel) Start() error {
 	return nil
 }
 
-func (f *fakeEdgeTunnel) Stop() error {
+func (f *fakeEdgeTunnel) Stop(ctx context.Context) error {
 	return nil
 }
 
+func (f *fakeEdgeTunnel) State() tunnel.ConnState {
+	return tunnel.StateConnected
+}
+
 func (f *fakeShimHandler) Do(in *clustermessage.ClusterMessage) (*clustermessage.ClusterMessage, error) {
 	head := &clustermessage.MessageHead{
 		MessageID:         in.Head.MessageID,
@@ -305,6 +310,7 @@ func TestReceiveMessageFromTunnel(t *testing.T) {
 		edgeTunnel: &fakeEdgeTunnel{},
 		shimClient: newFakeShim(),
 	}
+	go edge.sendMessageToParent()
 
 	controllerAPITask := &clustermessage.ControllerTask{
 		Destination: otev1.ClusterControllerDestAPI,
@@ -376,6 +382,7 @@ func TestHandleMessage(t *testing.T) {
 		edgeTunnel: &fakeEdgeTunnel{},
 		shimClient: newFakeShim(),
 	}
+	go edge.sendMessageToParent()
 
 	casetest := []struct {
 		Name         string
@@ -549,7 +556,7 @@ func TestStart(t *testing.T) {
 		},
 	}
 
-	ctInter := tunnel.NewCloudTunnel("127.0.0.1:8287")
+	ctInter := tunnel.NewCloudTunnel("127.0.0.1:8287", nil)
 
 	err := ctInter.Start()
 	if err != nil {
@@ -0,0 +1,580 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package edgehandler
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog"
+
+	"github.com/baidu/ote-stack/pkg/clustermessage"
+	clusterrouter "github.com/baidu/ote-stack/pkg/clusterrouter"
+	"github.com/baidu/ote-stack/pkg/priorityqueue"
+	"github.com/baidu/ote-stack/pkg/reporter"
+)
+
+// RetentionPolicy defines how messages of a command are kept in outbound queue.
+type RetentionPolicy int
+
+const (
+	// RetentionKeep keeps every message until it is sent.
+	RetentionKeep RetentionPolicy = iota
+	// RetentionCoalesce keeps only one pending message for each cluster,
+	// a new message is merged into the pending one.
+	RetentionCoalesce
+
+	queueKeyPrefix = "msg-"
+	// enqueue time in unix nano before message data.
+	queueRecordHeaderLength = 8
+)
+
+var (
+	// RetentionPolicies defines retention policy of each command, RetentionKeep if not defined.
+	RetentionPolicies = map[clustermessage.CommandType]RetentionPolicy{
+		clustermessage.CommandType_ControlResp:  RetentionKeep,
+		clustermessage.CommandType_EdgeReport:   RetentionCoalesce,
+		clustermessage.CommandType_SubTreeRoute: RetentionCoalesce,
+	}
+
+	outboundQueueRetryDuration = 1 * time.Second
+	outboundQueueFlushPeriod   = 20 * time.Millisecond
+)
+
+/*
+outboundQueue persists messages to parent in leveldb,
+so messages produced during disconnection or before a restart are sent after connecting.
+
+Messages are sent by priority class, and in order of enqueue in the same class.
+The oldest message of the lowest class is evicted once the queue reaches max size,
+and messages older than max age are dropped.
+*/
+type outboundQueue struct {
+	db      *leveldb.DB
+	maxSize int
+	maxAge  time.Duration
+	send    func([]byte) error
+
+	mutex sync.Mutex
+	// nextSeq is the sequence of next message to enqueue.
+	nextSeq uint64
+	size    int
+	// pending coalesced message of a command and a cluster -> its sequence.
+	coalesced map[string]uint64
+	// sequences of each class in order, and class of each sequence.
+	index     map[clustermessage.Priority][]uint64
+	classes   map[uint64]clustermessage.Priority
+	sched     *priorityqueue.Scheduler
+	connected bool
+	closed    bool
+	wake      chan struct{}
+}
+
+// newOutboundQueue opens the queue in dir, messages are sent by send once connected.
+func newOutboundQueue(dir string, maxSize int, maxAge time.Duration, send func([]byte) error) (*outboundQueue, error) {
+	db, err := leveldb.OpenFile(dir, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open outbound queue %s: %v", dir, err)
+	}
+
+	q := &outboundQueue{
+		db:        db,
+		maxSize:   maxSize,
+		maxAge:    maxAge,
+		send:      send,
+		nextSeq:   1,
+		coalesced: make(map[string]uint64),
+		index:     make(map[clustermessage.Priority][]uint64),
+		classes:   make(map[uint64]clustermessage.Priority),
+		sched:     priorityqueue.NewScheduler(parentLink),
+		wake:      make(chan struct{}, 1),
+	}
+	q.recover()
+	return q, nil
+}
+
+func queueKey(seq uint64) []byte {
+	return []byte(fmt.Sprintf("%s%020d", queueKeyPrefix, seq))
+}
+
+func queueSeq(key []byte) uint64 {
+	var seq uint64
+	fmt.Sscanf(string(key[len(queueKeyPrefix):]), "%d", &seq)
+	return seq
+}
+
+func encodeRecord(t time.Time, data []byte) []byte {
+	record := make([]byte, queueRecordHeaderLength+len(data))
+	binary.BigEndian.PutUint64(record, uint64(t.UnixNano()))
+	copy(record[queueRecordHeaderLength:], data)
+	return record
+}
+
+func decodeRecord(record []byte) (time.Time, []byte, error) {
+	if len(record) < queueRecordHeaderLength {
+		return time.Time{}, nil, fmt.Errorf("record too short: %d bytes", len(record))
+	}
+	t := time.Unix(0, int64(binary.BigEndian.Uint64(record)))
+	return t, record[queueRecordHeaderLength:], nil
+}
+
+// coalesceKey returns the key to coalesce msg, empty if msg is kept.
+func coalesceKey(msg *clustermessage.ClusterMessage) string {
+	if msg.Head == nil || RetentionPolicies[msg.Head.Command] != RetentionCoalesce {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s", msg.Head.Command.String(), msg.Head.ClusterName)
+}
+
+// recover loads state of messages left in the queue.
+func (q *outboundQueue) recover() {
+	iter := q.db.NewIterator(util.BytesPrefix([]byte(queueKeyPrefix)), nil)
+	defer iter.Release()
+
+	for iter.Next() {
+		seq := queueSeq(iter.Key())
+		q.size++
+		if seq >= q.nextSeq {
+			q.nextSeq = seq + 1
+		}
+		msg := &clustermessage.ClusterMessage{}
+		_, data, err := decodeRecord(iter.Value())
+		if err == nil {
+			err = proto.Unmarshal(data, msg)
+		}
+		if err != nil {
+			// it is dropped once peeked.
+			q.addIndex(seq, clustermessage.Priority_Route)
+			continue
+		}
+		q.addIndex(seq, clustermessage.PriorityOf(msg))
+		if key := coalesceKey(msg); key != "" {
+			q.coalesced[key] = seq
+		}
+	}
+	if q.size > 0 {
+		klog.Infof("%d messages left in outbound queue", q.size)
+	}
+}
+
+// Push puts msg to the queue.
+func (q *outboundQueue) Push(msg *clustermessage.ClusterMessage) error {
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.closed {
+		return leveldb.ErrClosed
+	}
+
+	key := coalesceKey(msg)
+	if seq, ok := q.coalesced[key]; ok && key != "" {
+		// the merged message keeps the enqueue time of the pending one, so it still expires by max age.
+		enqueued, merged, err := q.merge(seq, msg)
+		if err == nil {
+			return q.db.Put(queueKey(seq), encodeRecord(enqueued, merged), nil)
+		}
+		klog.Warningf("coalesce %s failed, enqueue it: %v", key, err)
+	}
+
+	if q.maxSize > 0 && q.size >= q.maxSize {
+		q.evictOldest()
+	}
+	seq := q.nextSeq
+	if err := q.db.Put(queueKey(seq), encodeRecord(time.Now(), data), nil); err != nil {
+		return err
+	}
+	q.nextSeq++
+	q.size++
+	q.addIndex(seq, clustermessage.PriorityOf(msg))
+	if key != "" {
+		q.coalesced[key] = seq
+	}
+	q.notify()
+	return nil
+}
+
+// merge merges msg into pending message of seq, it returns enqueue time of the pending message and the merged one.
+func (q *outboundQueue) merge(seq uint64, msg *clustermessage.ClusterMessage) (time.Time, []byte, error) {
+	record, err := q.db.Get(queueKey(seq), nil)
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+	enqueued, data, err := decodeRecord(record)
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+	pending := &clustermessage.ClusterMessage{}
+	if err := proto.Unmarshal(data, pending); err != nil {
+		return time.Time{}, nil, err
+	}
+
+	if msg.Head.Command == clustermessage.CommandType_EdgeReport {
+		body, err := mergeReports(pending.Body, msg.Body)
+		if err != nil {
+			return time.Time{}, nil, err
+		}
+		pending.Body = body
+	} else if msg.Head.Command == clustermessage.CommandType_SubTreeRoute {
+		body, err := mergeSubtrees(pending.Body, msg.Body)
+		if err != nil {
+			return time.Time{}, nil, err
+		}
+		pending.Body = body
+	} else {
+		pending.Body = msg.Body
+	}
+	pending.Head = msg.Head
+	merged, err := proto.Marshal(pending)
+	return enqueued, merged, err
+}
+
+// addIndex appends seq to the sequences of class, it is called with mutex held.
+func (q *outboundQueue) addIndex(seq uint64, class clustermessage.Priority) {
+	q.index[class] = append(q.index[class], seq)
+	q.classes[seq] = class
+}
+
+// removeIndex removes seq from the sequences of its class, it is called with mutex held.
+func (q *outboundQueue) removeIndex(seq uint64) {
+	class, ok := q.classes[seq]
+	if !ok {
+		return
+	}
+	delete(q.classes, seq)
+	seqs := q.index[class]
+	for i, s := range seqs {
+		if s == seq {
+			q.index[class] = append(seqs[:i], seqs[i+1:]...)
+			return
+		}
+	}
+}
+
+// evictOldest deletes the oldest message of the lowest class to make room for a new one.
+func (q *outboundQueue) evictOldest() {
+	for i := len(priorityqueue.Classes) - 1; i >= 0; i-- {
+		seqs := q.index[priorityqueue.Classes[i]]
+		if len(seqs) > 0 {
+			klog.Warningf("outbound queue is full, drop message %d", seqs[0])
+			q.delete(seqs[0])
+			return
+		}
+	}
+}
+
+// delete removes message of seq, it is called with mutex held.
+func (q *outboundQueue) delete(seq uint64) {
+	q.forget(seq)
+	q.removeIndex(seq)
+	if ok, _ := q.db.Has(queueKey(seq), nil); !ok {
+		// it has been evicted.
+		return
+	}
+	if err := q.db.Delete(queueKey(seq), nil); err != nil {
+		klog.Errorf("delete message %d from outbound queue failed: %v", seq, err)
+		return
+	}
+	q.size--
+}
+
+// forget stops coalescing into message of seq.
+func (q *outboundQueue) forget(seq uint64) {
+	for key, s := range q.coalesced {
+		if s == seq {
+			delete(q.coalesced, key)
+		}
+	}
+}
+
+/*
+peek returns the oldest message not expired of the class to send next,
+and stops coalescing into it since it may be sent.
+If classes pending exceed their bandwidth, it returns how long to wait.
+*/
+func (q *outboundQueue) peek() (uint64, []byte, time.Duration, bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.closed {
+		return 0, nil, 0, false
+	}
+
+	for {
+		class, wait, ok := q.sched.Next(func(c clustermessage.Priority) bool {
+			return len(q.index[c]) > 0
+		})
+		if !ok {
+			return 0, nil, wait, false
+		}
+		seq := q.index[class][0]
+		record, err := q.db.Get(queueKey(seq), nil)
+		if err == leveldb.ErrNotFound {
+			q.removeIndex(seq)
+			continue
+		}
+		var t time.Time
+		var data []byte
+		if err == nil {
+			t, data, err = decodeRecord(record)
+		}
+		if err != nil {
+			klog.Errorf("drop invalid message %d in outbound queue: %v", seq, err)
+			q.delete(seq)
+			continue
+		}
+		if q.maxAge > 0 && time.Since(t) > q.maxAge {
+			klog.Warningf("drop message %d in outbound queue enqueued at %v", seq, t)
+			q.delete(seq)
+			continue
+		}
+		q.forget(seq)
+		return seq, data, 0, true
+	}
+}
+
+// sent records that message of seq with n bytes is sent.
+func (q *outboundQueue) sent(seq uint64, n int) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if class, ok := q.classes[seq]; ok {
+		q.sched.Sent(class, n)
+	}
+}
+
+// remove deletes a sent message, it is sent again after restart if the queue is closed.
+func (q *outboundQueue) remove(seq uint64) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.closed {
+		return
+	}
+	q.delete(seq)
+}
+
+// Len returns number of messages in the queue.
+func (q *outboundQueue) Len() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.size
+}
+
+// Flush waits until messages in the queue are sent or ctx is done,
+// messages left are sent after restart.
+func (q *outboundQueue) Flush(ctx context.Context) error {
+	return wait.PollImmediateUntil(outboundQueueFlushPeriod, func() (bool, error) {
+		return q.Len() == 0, nil
+	}, ctx.Done())
+}
+
+func (q *outboundQueue) notify() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Resume starts to drain the queue, it is called after connecting to parent.
+func (q *outboundQueue) Resume() {
+	q.mutex.Lock()
+	q.connected = true
+	q.mutex.Unlock()
+	q.notify()
+}
+
+// Pause stops sending messages, it is called after disconnecting from parent.
+func (q *outboundQueue) Pause() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.connected = false
+}
+
+func (q *outboundQueue) isConnected() bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.connected
+}
+
+func (q *outboundQueue) isClosed() bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.closed
+}
+
+// Run sends messages in the queue to parent until stop or the queue is closed.
+func (q *outboundQueue) Run(stop <-chan struct{}) {
+	var retry <-chan time.Time
+	for {
+		if q.isClosed() {
+			return
+		}
+		if q.isConnected() {
+			seq, data, wait, ok := q.peek()
+			if ok {
+				err := q.send(data)
+				if err == nil {
+					q.sent(seq, len(data))
+					q.remove(seq)
+					continue
+				}
+				klog.Errorf("send message %d in outbound queue failed: %v", seq, err)
+				retry = time.After(outboundQueueRetryDuration)
+			} else if wait > 0 {
+				retry = time.After(wait)
+			}
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-q.wake:
+		case <-retry:
+		}
+		retry = nil
+	}
+}
+
+// Close stops sending and closes the queue, messages not sent are kept.
+func (q *outboundQueue) Close() error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.closed {
+		return nil
+	}
+	q.closed = true
+	q.notify()
+	return q.db.Close()
+}
+
+// isDeltaReport checks if a report body is made of update map, delete map and full list.
+func isDeltaReport(body []byte) bool {
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return false
+	}
+	for _, f := range []string{"updateMap", "delMap", "fullList"} {
+		if _, ok := fields[f]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// deltaReport is the common form of resource status reports.
+type deltaReport struct {
+	UpdateMap map[string]json.RawMessage `json:"updateMap"`
+	DelMap    map[string]json.RawMessage `json:"delMap"`
+	FullList  json.RawMessage            `json:"fullList"`
+}
+
+// mergeReport merges a newer report body of the same resource type into older.
+func mergeReport(older, newer []byte) ([]byte, error) {
+	if !isDeltaReport(older) || !isDeltaReport(newer) {
+		// the report is a snapshot.
+		return newer, nil
+	}
+
+	o, n := &deltaReport{}, &deltaReport{}
+	if err := json.Unmarshal(older, o); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(newer, n); err != nil {
+		return nil, err
+	}
+	if o.UpdateMap == nil {
+		o.UpdateMap = make(map[string]json.RawMessage)
+	}
+	if o.DelMap == nil {
+		o.DelMap = make(map[string]json.RawMessage)
+	}
+	for k, v := range n.UpdateMap {
+		o.UpdateMap[k] = v
+		delete(o.DelMap, k)
+	}
+	for k, v := range n.DelMap {
+		o.DelMap[k] = v
+		delete(o.UpdateMap, k)
+	}
+	// a delta of reporters carries an empty full list, which must not replace a pending one.
+	if hasFullList(n.FullList) {
+		o.FullList = n.FullList
+	}
+	return json.Marshal(o)
+}
+
+// hasFullList checks if fullList of a report is a non-empty list.
+func hasFullList(fullList json.RawMessage) bool {
+	var list []json.RawMessage
+	if len(fullList) == 0 || json.Unmarshal(fullList, &list) != nil {
+		return false
+	}
+	return len(list) > 0
+}
+
+// mergeReports merges a newer EdgeReport body into older one by resource type.
+func mergeReports(older, newer []byte) ([]byte, error) {
+	var o, n reporter.Reports
+	if err := json.Unmarshal(older, &o); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(newer, &n); err != nil {
+		return nil, err
+	}
+
+	index := make(map[int]int)
+	for i, r := range o {
+		index[r.ResourceType] = i
+	}
+	for _, r := range n {
+		i, ok := index[r.ResourceType]
+		if !ok {
+			index[r.ResourceType] = len(o)
+			o = append(o, r)
+			continue
+		}
+		body, err := mergeReport(o[i].Body, r.Body)
+		if err != nil {
+			return nil, err
+		}
+		o[i].Body = body
+	}
+	return json.Marshal(o)
+}
+
+// mergeSubtrees merges a newer SubTreeRoute body into older one, so deltas of subtree are not lost.
+func mergeSubtrees(older, newer []byte) ([]byte, error) {
+	o, n := &clusterrouter.SubtreeInfo{}, &clusterrouter.SubtreeInfo{}
+	if err := json.Unmarshal(older, o); err != nil || o.Routes == nil {
+		// older is a bare SubTreeRouter sent before parent understands versions.
+		return newer, nil
+	}
+	if err := json.Unmarshal(newer, n); err != nil || n.Routes == nil {
+		// newer is a bare SubTreeRouter, e.g., after reconnecting, it is a full sync.
+		return newer, nil
+	}
+	return json.Marshal(clusterrouter.MergeSubtree(o, n))
+}
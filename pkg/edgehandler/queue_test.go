@@ -0,0 +1,372 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package edgehandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/baidu/ote-stack/pkg/clustermessage"
+	clusterrouter "github.com/baidu/ote-stack/pkg/clusterrouter"
+	"github.com/baidu/ote-stack/pkg/reporter"
+)
+
+func newTestMessage(command clustermessage.CommandType, id, cluster string, body []byte) *clustermessage.ClusterMessage {
+	return &clustermessage.ClusterMessage{
+		Head: &clustermessage.MessageHead{
+			MessageID:   id,
+			Command:     command,
+			ClusterName: cluster,
+		},
+		Body: body,
+	}
+}
+
+func newTestReport(t *testing.T, resourceType int, body interface{}) []byte {
+	b, err := json.Marshal(body)
+	assert.Nil(t, err)
+	data, err := json.Marshal(reporter.Reports{{ResourceType: resourceType, Body: b}})
+	assert.Nil(t, err)
+	return data
+}
+
+func popTestMessage(t *testing.T, q *outboundQueue) *clustermessage.ClusterMessage {
+	seq, data, _, ok := q.peek()
+	if !ok {
+		return nil
+	}
+	q.remove(seq)
+	msg := &clustermessage.ClusterMessage{}
+	assert.Nil(t, proto.Unmarshal(data, msg))
+	return msg
+}
+
+func TestOutboundQueue(t *testing.T) {
+	dir, err := ioutil.TempDir("", "outbound-queue")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	q, err := newOutboundQueue(dir, 3, 0, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, q.Push(newTestMessage(clustermessage.CommandType_ControlResp, "1", "c1", nil)))
+	assert.Nil(t, q.Push(newTestMessage(clustermessage.CommandType_ControlResp, "2", "c1", nil)))
+	assert.Equal(t, 2, q.Len())
+
+	// messages are kept after restart.
+	assert.Nil(t, q.Close())
+	q, err = newOutboundQueue(dir, 3, 0, nil)
+	assert.Nil(t, err)
+	defer q.Close()
+	assert.Equal(t, 2, q.Len())
+	assert.Nil(t, q.Push(newTestMessage(clustermessage.CommandType_ControlResp, "3", "c1", nil)))
+
+	// the oldest is evicted once full.
+	assert.Nil(t, q.Push(newTestMessage(clustermessage.CommandType_ControlResp, "4", "c1", nil)))
+	assert.Equal(t, 3, q.Len())
+	for _, id := range []string{"2", "3", "4"} {
+		msg := popTestMessage(t, q)
+		assert.NotNil(t, msg)
+		assert.Equal(t, id, msg.Head.MessageID)
+	}
+	assert.Nil(t, popTestMessage(t, q))
+	assert.Equal(t, 0, q.Len())
+
+	// expired messages are dropped.
+	q.maxAge = time.Millisecond
+	assert.Nil(t, q.Push(newTestMessage(clustermessage.CommandType_ControlResp, "5", "c1", nil)))
+	time.Sleep(10 * time.Millisecond)
+	assert.Nil(t, popTestMessage(t, q))
+	assert.Equal(t, 0, q.Len())
+}
+
+func TestOutboundQueueCoalesce(t *testing.T) {
+	dir, err := ioutil.TempDir("", "outbound-queue")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	q, err := newOutboundQueue(dir, 0, 0, nil)
+	assert.Nil(t, err)
+	defer q.Close()
+
+	assert.Nil(t, q.Push(newTestMessage(clustermessage.CommandType_EdgeReport, "", "c1",
+		newTestReport(t, reporter.ResourceTypePod, map[string]interface{}{
+			"updateMap": map[string]string{"p1": "v1", "p2": "v1"},
+		}))))
+	assert.Nil(t, q.Push(newTestMessage(clustermessage.CommandType_ControlResp, "1", "c1", nil)))
+	assert.Nil(t, q.Push(newTestMessage(clustermessage.CommandType_EdgeReport, "", "c1",
+		newTestReport(t, reporter.ResourceTypePod, map[string]interface{}{
+			"updateMap": map[string]string{"p1": "v2"},
+			"delMap":    map[string]string{"p2": "v1"},
+			"fullList":  []string{"p1"},
+		}))))
+	assert.Nil(t, q.Push(newTestMessage(clustermessage.CommandType_EdgeReport, "", "c1",
+		newTestReport(t, reporter.ResourceTypeClusterStatus, map[string]string{"status": "ok"}))))
+	// reports of other clusters are not coalesced.
+	assert.Nil(t, q.Push(newTestMessage(clustermessage.CommandType_EdgeReport, "", "c2",
+		newTestReport(t, reporter.ResourceTypeNode, map[string]interface{}{}))))
+	assert.Equal(t, 3, q.Len())
+
+	// control message is sent before reports.
+	msg := popTestMessage(t, q)
+	assert.Equal(t, "1", msg.Head.MessageID)
+
+	msg = popTestMessage(t, q)
+	assert.Equal(t, clustermessage.CommandType_EdgeReport, msg.Head.Command)
+	reports := reporter.Reports{}
+	assert.Nil(t, json.Unmarshal(msg.Body, &reports))
+	assert.Equal(t, 2, len(reports))
+	assert.Equal(t, reporter.ResourceTypePod, reports[0].ResourceType)
+	assert.JSONEq(t, `{"updateMap":{"p1":"v2"},"delMap":{"p2":"v1"},"fullList":["p1"]}`, string(reports[0].Body))
+	assert.Equal(t, reporter.ResourceTypeClusterStatus, reports[1].ResourceType)
+	assert.JSONEq(t, `{"status":"ok"}`, string(reports[1].Body))
+
+	// report being sent is not coalesced.
+	seq, _, _, ok := q.peek()
+	assert.True(t, ok)
+	assert.Nil(t, q.Push(newTestMessage(clustermessage.CommandType_EdgeReport, "", "c2",
+		newTestReport(t, reporter.ResourceTypeNode, map[string]interface{}{}))))
+	assert.Equal(t, 2, q.Len())
+	q.remove(seq)
+	assert.Equal(t, 1, q.Len())
+
+	// coalesced report keeps enqueue time of the first one, so it still expires.
+	q.maxAge = 30 * time.Millisecond
+	for i := 0; i < 5; i++ {
+		assert.Nil(t, q.Push(newTestMessage(clustermessage.CommandType_EdgeReport, "", "c2",
+			newTestReport(t, reporter.ResourceTypeNode, map[string]interface{}{}))))
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Nil(t, popTestMessage(t, q))
+	assert.Equal(t, 0, q.Len())
+}
+
+func newTestPodReport(t *testing.T, status *reporter.PodResourceStatus) *clustermessage.ClusterMessage {
+	b, err := json.Marshal(status)
+	assert.Nil(t, err)
+	data, err := json.Marshal(reporter.Reports{{ResourceType: reporter.ResourceTypePod, Body: b}})
+	assert.Nil(t, err)
+	return newTestMessage(clustermessage.CommandType_EdgeReport, "", "c1", data)
+}
+
+func TestOutboundQueueCoalescePodFullList(t *testing.T) {
+	dir, err := ioutil.TempDir("", "outbound-queue")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	q, err := newOutboundQueue(dir, 0, 0, nil)
+	assert.Nil(t, err)
+	defer q.Close()
+
+	// bodies as PodReporter sends, a delta has an empty full list.
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "p2", Namespace: "default"}}
+	assert.Nil(t, q.Push(newTestPodReport(t, &reporter.PodResourceStatus{
+		UpdateMap: map[string]*corev1.Pod{},
+		DelMap:    map[string]*corev1.Pod{},
+		FullList:  []string{"default/p1"},
+	})))
+	assert.Nil(t, q.Push(newTestPodReport(t, &reporter.PodResourceStatus{
+		UpdateMap: map[string]*corev1.Pod{"default/p2": pod},
+		DelMap:    map[string]*corev1.Pod{},
+		FullList:  make([]string, 0),
+	})))
+	assert.Equal(t, 1, q.Len())
+
+	// the full list pending is kept, so pods deleted while offline are cleaned up.
+	msg := popTestMessage(t, q)
+	reports := reporter.Reports{}
+	assert.Nil(t, json.Unmarshal(msg.Body, &reports))
+	assert.Equal(t, 1, len(reports))
+	status := reporter.PodResourceStatus{}
+	assert.Nil(t, json.Unmarshal(reports[0].Body, &status))
+	assert.Equal(t, []string{"default/p1"}, status.FullList)
+	assert.Contains(t, status.UpdateMap, "default/p2")
+}
+
+func TestOutboundQueueCoalesceSubtree(t *testing.T) {
+	dir, err := ioutil.TempDir("", "outbound-queue")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	q, err := newOutboundQueue(dir, 0, 0, nil)
+	assert.Nil(t, err)
+	defer q.Close()
+
+	for _, info := range []*clusterrouter.SubtreeInfo{
+		{Routes: clusterrouter.SubTreeRouter{"c2": "c2"}, Deleted: []string{"c3"}, Base: 1, Version: 2},
+		{Routes: clusterrouter.SubTreeRouter{"c3": "c2"}, Deleted: []string{"c2"}, Base: 2, Version: 3},
+	} {
+		msg := info.Message()
+		msg.Head.ClusterName = "c1"
+		assert.Nil(t, q.Push(msg))
+	}
+	assert.Equal(t, 1, q.Len())
+
+	// deltas pending are merged into one, so none is lost.
+	msg := popTestMessage(t, q)
+	assert.Equal(t, clustermessage.CommandType_SubTreeRoute, msg.Head.Command)
+	info := clusterrouter.SubtreeInfoFromClusterController(msg)
+	assert.Equal(t, clusterrouter.SubTreeRouter{"c3": "c2"}, info.Routes)
+	assert.Equal(t, []string{"c2"}, info.Deleted)
+	assert.Equal(t, uint64(1), info.Base)
+	assert.Equal(t, uint64(3), info.Version)
+
+	// bare routes after reconnecting replace a pending delta.
+	info = &clusterrouter.SubtreeInfo{Routes: clusterrouter.SubTreeRouter{"c2": "c2"}, Base: 3, Version: 4}
+	msg = info.Message()
+	msg.Head.ClusterName = "c1"
+	assert.Nil(t, q.Push(msg))
+	bare := clusterrouter.SubTreeRouter{"c4": "c4"}
+	msg = bare.Message()
+	msg.Head.ClusterName = "c1"
+	assert.Nil(t, q.Push(msg))
+	assert.Equal(t, 1, q.Len())
+	msg = popTestMessage(t, q)
+	serial, err := bare.Serialize()
+	assert.Nil(t, err)
+	assert.Equal(t, serial, msg.Body)
+}
+
+func TestOutboundQueuePriority(t *testing.T) {
+	dir, err := ioutil.TempDir("", "outbound-queue")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	q, err := newOutboundQueue(dir, 3, 0, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, q.Push(newTestMessage(clustermessage.CommandType_EdgeReport, "", "c1",
+		newTestReport(t, reporter.ResourceTypeNode, map[string]interface{}{}))))
+	assert.Nil(t, q.Push(newTestMessage(clustermessage.CommandType_ControlResp, "1", "c1", nil)))
+	assert.Nil(t, q.Push(newTestMessage(clustermessage.CommandType_SubTreeRoute, "", "c1", nil)))
+
+	// classes are recovered after restart.
+	assert.Nil(t, q.Close())
+	q, err = newOutboundQueue(dir, 3, 0, nil)
+	assert.Nil(t, err)
+	defer q.Close()
+
+	// the report is evicted before control messages once full.
+	assert.Nil(t, q.Push(newTestMessage(clustermessage.CommandType_ControlResp, "2", "c1", nil)))
+	assert.Equal(t, 3, q.Len())
+	msg := popTestMessage(t, q)
+	assert.Equal(t, clustermessage.CommandType_SubTreeRoute, msg.Head.Command)
+	for _, id := range []string{"1", "2"} {
+		msg := popTestMessage(t, q)
+		assert.NotNil(t, msg)
+		assert.Equal(t, id, msg.Head.MessageID)
+	}
+	assert.Nil(t, popTestMessage(t, q))
+}
+
+func TestOutboundQueueRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "outbound-queue")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	retry := outboundQueueRetryDuration
+	outboundQueueRetryDuration = 10 * time.Millisecond
+	defer func() {
+		outboundQueueRetryDuration = retry
+	}()
+
+	var mutex sync.Mutex
+	sent := make([]string, 0)
+	fail := true
+	send := func(data []byte) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if fail {
+			fail = false
+			return fmt.Errorf("send failed")
+		}
+		msg := &clustermessage.ClusterMessage{}
+		proto.Unmarshal(data, msg)
+		sent = append(sent, msg.Head.MessageID)
+		return nil
+	}
+	sentIDs := func() []string {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return append([]string{}, sent...)
+	}
+
+	q, err := newOutboundQueue(dir, 0, 0, send)
+	assert.Nil(t, err)
+	defer q.Close()
+	stop := make(chan struct{})
+	defer close(stop)
+	go q.Run(stop)
+
+	// nothing is sent before connected.
+	assert.Nil(t, q.Push(newTestMessage(clustermessage.CommandType_ControlResp, "1", "c1", nil)))
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 0, len(sentIDs()))
+
+	// drain after connected, failed message is retried.
+	q.Resume()
+	assert.Nil(t, q.Push(newTestMessage(clustermessage.CommandType_ControlResp, "2", "c1", nil)))
+	eventually(t, func() bool {
+		return len(sentIDs()) == 2
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"1", "2"}, sentIDs())
+	assert.Equal(t, 0, q.Len())
+
+	q.Pause()
+	assert.Nil(t, q.Push(newTestMessage(clustermessage.CommandType_ControlResp, "3", "c1", nil)))
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 2, len(sentIDs()))
+	q.Resume()
+	eventually(t, func() bool {
+		return len(sentIDs()) == 3
+	}, time.Second, 10*time.Millisecond)
+
+	// it stops sending once closed, messages pushed are rejected.
+	done := make(chan struct{})
+	go func() {
+		q.Run(make(chan struct{}))
+		close(done)
+	}()
+	assert.Nil(t, q.Close())
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("queue is still running after closed")
+	}
+	assert.NotNil(t, q.Push(newTestMessage(clustermessage.CommandType_ControlResp, "4", "c1", nil)))
+}
+
+// eventually is assert.Eventually checking condition in the calling goroutine,
+// testify v1.4 may panic once a check started by a late tick ends after it returns.
+func eventually(t *testing.T, condition func() bool, waitFor, tick time.Duration, msgAndArgs ...interface{}) bool {
+	deadline := time.Now().Add(waitFor)
+	for !condition() {
+		if time.Now().After(deadline) {
+			return assert.Fail(t, "Condition never satisfied", msgAndArgs...)
+		}
+		time.Sleep(tick)
+	}
+	return true
+}
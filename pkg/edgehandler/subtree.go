@@ -0,0 +1,122 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package edgehandler
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/klog"
+
+	clusterrouter "github.com/baidu/ote-stack/pkg/clusterrouter"
+)
+
+var (
+	// SubtreeChecksumInterval is the interval to send checksum of subtree to parent, 0 disables it.
+	SubtreeChecksumInterval = 1 * time.Minute
+)
+
+// subtreeReporter decides what to report of subtree routes to parent.
+type subtreeReporter struct {
+	mutex sync.Mutex
+	// versioned is true if parent understands versions, bare full syncs are reported otherwise.
+	versioned bool
+	// version is the version of subtree last reported.
+	version uint64
+	// acked is true if parent applied the full sync of version, deltas are reported then.
+	acked bool
+	// reported is subtree last reported.
+	reported *clusterrouter.SubtreeInfo
+	// checksumAt is the time checksum is last reported.
+	checksumAt time.Time
+}
+
+// reset makes next report a bare full sync, since the parent connected may be of old versions.
+func (r *subtreeReporter) reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.versioned = false
+	r.acked = false
+}
+
+/*
+next returns subtree info to report of subtree cur, nil if nothing to report.
+It is a full sync of version 0 until parent tells it understands versions, which is sent as bare SubTreeRouter,
+so a parent not knowing versions gets full syncs as before.
+It is a versioned full sync then until parent acknowledges it.
+*/
+func (r *subtreeReporter) next(cur *clusterrouter.SubtreeInfo, now time.Time) *clusterrouter.SubtreeInfo {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if !r.versioned {
+		return &clusterrouter.SubtreeInfo{Routes: cur.Routes}
+	}
+	if !r.acked || r.reported == nil {
+		r.version++
+		r.reported = cur
+		r.checksumAt = now
+		full := *cur
+		full.Version = r.version
+		return &full
+	}
+
+	checksumDue := SubtreeChecksumInterval > 0 && now.Sub(r.checksumAt) >= SubtreeChecksumInterval
+	delta := clusterrouter.DiffSubtree(r.reported, cur)
+	if delta == nil && !checksumDue {
+		return nil
+	}
+	base := r.version
+	if delta == nil {
+		delta = &clusterrouter.SubtreeInfo{Routes: clusterrouter.SubTreeRouter{}}
+	} else {
+		r.version++
+	}
+	delta.Base = base
+	delta.Version = r.version
+	if checksumDue {
+		delta.Checksum = clusterrouter.SubtreeChecksum(cur.Clusters())
+		r.checksumAt = now
+	}
+	r.reported = cur
+	return delta
+}
+
+// ack handles ack of parent.
+func (r *subtreeReporter) ack(ack *clusterrouter.SubtreeAck) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if ack.Versioned {
+		if !r.versioned {
+			klog.Infof("parent understands versions of subtree")
+			r.versioned = true
+			r.acked = false
+		}
+		return
+	}
+	if ack.Resync {
+		klog.Infof("parent asks for full sync of subtree")
+		r.acked = false
+		return
+	}
+	// ack of a full sync sent before is ignored.
+	if ack.Version == r.version {
+		r.acked = true
+	}
+}
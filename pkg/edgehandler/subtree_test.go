@@ -0,0 +1,83 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package edgehandler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	clusterrouter "github.com/baidu/ote-stack/pkg/clusterrouter"
+)
+
+func TestSubtreeReporter(t *testing.T) {
+	now := time.Now()
+	r := subtreeReporter{}
+	sub1 := &clusterrouter.SubtreeInfo{Routes: clusterrouter.SubTreeRouter{"c2": "c2"}}
+	sub2 := &clusterrouter.SubtreeInfo{Routes: clusterrouter.SubTreeRouter{"c3": "c3"}}
+
+	// bare full syncs are sent until parent tells it understands versions
+	info := r.next(sub1, now)
+	assert.Equal(t, uint64(0), info.Version)
+	assert.Equal(t, sub1.Routes, info.Routes)
+	assert.Equal(t, uint64(0), r.next(sub1, now).Version)
+	r.ack(&clusterrouter.SubtreeAck{Versioned: true})
+
+	// versioned full syncs are sent until parent acknowledges one
+	info = r.next(sub1, now)
+	assert.Equal(t, uint64(1), info.Version)
+	assert.Equal(t, uint64(0), info.Base)
+	info = r.next(sub1, now)
+	assert.Equal(t, uint64(2), info.Version)
+	assert.Equal(t, sub1.Routes, info.Routes)
+	r.ack(&clusterrouter.SubtreeAck{Version: 1})
+	assert.Equal(t, uint64(3), r.next(sub1, now).Version)
+	r.ack(&clusterrouter.SubtreeAck{Version: 3})
+
+	// then deltas are sent only if subtree changes
+	assert.Nil(t, r.next(sub1, now))
+	info = r.next(sub2, now)
+	assert.Equal(t, uint64(3), info.Base)
+	assert.Equal(t, uint64(4), info.Version)
+	assert.Equal(t, sub2.Routes, info.Routes)
+	assert.Equal(t, []string{"c2"}, info.Deleted)
+	assert.Empty(t, info.Checksum)
+
+	// checksum is sent at a low frequency
+	info = r.next(sub2, now.Add(SubtreeChecksumInterval))
+	assert.Equal(t, uint64(4), info.Base)
+	assert.Equal(t, uint64(4), info.Version)
+	assert.Empty(t, info.Routes)
+	assert.Equal(t, clusterrouter.SubtreeChecksum([]string{"c3"}), info.Checksum)
+	assert.Nil(t, r.next(sub2, now.Add(SubtreeChecksumInterval)))
+
+	// full sync on request of parent or after reconnecting
+	r.ack(&clusterrouter.SubtreeAck{Resync: true})
+	info = r.next(sub2, now)
+	assert.Equal(t, uint64(0), info.Base)
+	assert.Equal(t, uint64(5), info.Version)
+	r.ack(&clusterrouter.SubtreeAck{Version: 5})
+	r.reset()
+	info = r.next(sub2, now)
+	assert.Equal(t, uint64(0), info.Version)
+	assert.Equal(t, sub2.Routes, info.Routes)
+	r.ack(&clusterrouter.SubtreeAck{Versioned: true})
+	info = r.next(sub2, now)
+	assert.Equal(t, uint64(0), info.Base)
+	assert.Equal(t, uint64(6), info.Version)
+}
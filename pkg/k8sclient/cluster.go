@@ -19,6 +19,7 @@ package k8sclient
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
 
 	jsonpatch "github.com/evanphx/json-patch"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -94,6 +95,81 @@ func (c *ClusterCRD) UpdateStatus(newcluster *otev1.Cluster) error {
 	})
 }
 
+// ReportedLabelsAnnotation is the annotation of Cluster crd keeping labels reported by the cluster lastly,
+// so labels no longer reported are removed from the crd, while others edited in crd are kept.
+const ReportedLabelsAnnotation = "ote.baidu.com/reported-labels"
+
+// AnnotateReportedLabels records labels of cluster as reported by the cluster, it is called before creating cluster.
+func AnnotateReportedLabels(cluster *otev1.Cluster) {
+	setReportedLabels(cluster, cluster.Spec.Labels)
+}
+
+func setReportedLabels(cluster *otev1.Cluster, labels map[string]string) {
+	if len(labels) == 0 {
+		delete(cluster.ObjectMeta.Annotations, ReportedLabelsAnnotation)
+		return
+	}
+	data, err := json.Marshal(labels)
+	if err != nil {
+		klog.Errorf("marshal labels of cluster %s failed: %v", cluster.Name, err)
+		return
+	}
+	if cluster.ObjectMeta.Annotations == nil {
+		cluster.ObjectMeta.Annotations = make(map[string]string)
+	}
+	cluster.ObjectMeta.Annotations[ReportedLabelsAnnotation] = string(data)
+}
+
+// reportedLabels returns labels reported by the cluster lastly, nil if unknown.
+func reportedLabels(cluster *otev1.Cluster) map[string]string {
+	data, ok := cluster.ObjectMeta.Annotations[ReportedLabelsAnnotation]
+	if !ok {
+		return nil
+	}
+	labels := make(map[string]string)
+	if err := json.Unmarshal([]byte(data), &labels); err != nil {
+		klog.Errorf("unmarshal reported labels of cluster %s failed: %v", cluster.Name, err)
+		return nil
+	}
+	return labels
+}
+
+// ReplaceReportedLabels replaces labels reported by the cluster lastly with labels of newcluster,
+// labels edited in the existing cluster and not reported are kept.
+func (c *ClusterCRD) ReplaceReportedLabels(newcluster *otev1.Cluster) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		oldcluster, err := c.client.OteV1().Clusters(newcluster.Namespace).Get(newcluster.Name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("get original cluster(%s-%s) failed: %v",
+				newcluster.Namespace, newcluster.Name, err)
+		}
+
+		labels := make(map[string]string, len(oldcluster.Spec.Labels))
+		for k, v := range oldcluster.Spec.Labels {
+			labels[k] = v
+		}
+		for k := range reportedLabels(oldcluster) {
+			delete(labels, k)
+		}
+		for k, v := range newcluster.Spec.Labels {
+			labels[k] = v
+		}
+		oldAnnotation := oldcluster.ObjectMeta.Annotations[ReportedLabelsAnnotation]
+		if len(labels) == 0 {
+			labels = nil
+		}
+		oldLabels := oldcluster.Spec.Labels
+		oldcluster.Spec.Labels = labels
+		setReportedLabels(oldcluster, newcluster.Spec.Labels)
+		if reflect.DeepEqual(oldLabels, labels) &&
+			oldAnnotation == oldcluster.ObjectMeta.Annotations[ReportedLabelsAnnotation] {
+			return nil
+		}
+		_, err = c.client.OteV1().Clusters(oldcluster.Namespace).Update(oldcluster)
+		return err
+	})
+}
+
 // PatchStatus patches status of an existing cluster.
 func (c *ClusterCRD) PatchStatus(newcluster *otev1.Cluster) error {
 
@@ -44,10 +44,11 @@ func (c *ClusterControllerCRD) Get(namespace, name string) *otev1.ClusterControl
 	return cc
 }
 
-// Update update a ClusterControllers.
-func (c *ClusterControllerCRD) Update(cc *otev1.ClusterController) {
+// Update update a ClusterControllers, the error is logged and returned.
+func (c *ClusterControllerCRD) Update(cc *otev1.ClusterController) error {
 	_, err := c.client.OteV1().ClusterControllers(cc.ObjectMeta.Namespace).Update(cc)
 	if err != nil {
 		klog.Errorf("update clustercontroller(%v) failed: %v", cc, err)
 	}
+	return err
 }
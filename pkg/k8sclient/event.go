@@ -0,0 +1,66 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8sclient
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	otev1 "github.com/baidu/ote-stack/pkg/apis/ote/v1"
+)
+
+// ClusterEvents records k8s events of Cluster crds.
+type ClusterEvents struct {
+	client kubernetes.Interface
+	// source is the component reporting events.
+	source string
+}
+
+// NewClusterEvents new a ClusterEvents with k8s client.
+func NewClusterEvents(client kubernetes.Interface, source string) *ClusterEvents {
+	return &ClusterEvents{client, source}
+}
+
+// Record records an event of the Cluster named name, the Cluster need not exist.
+func (e *ClusterEvents) Record(name, eventType, reason, message string) error {
+	now := metav1.NewTime(time.Now())
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%v.%x", name, now.UnixNano()),
+			Namespace: otev1.ClusterNamespace,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			Kind:       "Cluster",
+			APIVersion: otev1.SchemeGroupVersion.String(),
+			Namespace:  otev1.ClusterNamespace,
+			Name:       name,
+		},
+		Reason:         reason,
+		Message:        message,
+		Type:           eventType,
+		Source:         corev1.EventSource{Component: e.source},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+	_, err := e.client.CoreV1().Events(otev1.ClusterNamespace).Create(event)
+	return err
+}
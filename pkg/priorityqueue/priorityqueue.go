@@ -0,0 +1,186 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package priorityqueue schedules messages of a link by their priority class.
+
+Each link keeps a queue for each class of clustermessage.Priority.
+Classes are served in order of Route, Control and Bulk, a class with weight n
+sends at most n messages in a round before classes of lower priority,
+and a class with weight 0 is only served if no class with weight is pending,
+so with the default weights routes and control messages always go out before bulk reports.
+
+A class may be capped to a bandwidth in bytes per second on a link,
+it is skipped while it exceeds the cap, so other classes are not blocked by it.
+*/
+package priorityqueue
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/baidu/ote-stack/pkg/clustermessage"
+)
+
+const (
+	// AnyLink is the link name of bandwidth caps applied to links without their own.
+	AnyLink = "*"
+)
+
+var (
+	// Classes are the priority classes in order of precedence.
+	Classes = []clustermessage.Priority{
+		clustermessage.Priority_Route,
+		clustermessage.Priority_Control,
+		clustermessage.Priority_Bulk,
+	}
+
+	// Weights is the weight of each class, e.g., "route=4,control=2,bulk=0".
+	Weights = "route=4,control=2,bulk=0"
+	// LinkBandwidth is the bandwidth cap in bytes per second of classes on links,
+	// e.g., "bulk=1048576,c1:bulk=65536,parent:control=0", a link is a child name or "parent",
+	// caps without link apply to links without their own, 0 means no cap.
+	LinkBandwidth = ""
+
+	mutex    sync.RWMutex
+	weights  = map[clustermessage.Priority]int{}
+	linkCaps = map[string]map[clustermessage.Priority]int64{}
+)
+
+func init() {
+	if err := Load(); err != nil {
+		panic(err)
+	}
+}
+
+// Load parses Weights and LinkBandwidth, it is called after they are changed.
+func Load() error {
+	w, err := ParseWeights(Weights)
+	if err != nil {
+		return fmt.Errorf("invalid priority weights %q: %v", Weights, err)
+	}
+	caps, err := ParseLinkBandwidth(LinkBandwidth)
+	if err != nil {
+		return fmt.Errorf("invalid link bandwidth %q: %v", LinkBandwidth, err)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	weights = w
+	linkCaps = caps
+	return nil
+}
+
+// ParseClass returns the class of name, which is case insensitive.
+func ParseClass(name string) (clustermessage.Priority, error) {
+	for _, class := range Classes {
+		if strings.EqualFold(class.String(), name) {
+			return class, nil
+		}
+	}
+	return clustermessage.Priority_Auto, fmt.Errorf("unknown priority class %q", name)
+}
+
+// ParseWeights parses "<class>=<weight>,...", classes not set have weight 1.
+func ParseWeights(s string) (map[clustermessage.Priority]int, error) {
+	ret := make(map[clustermessage.Priority]int)
+	for _, class := range Classes {
+		ret[class] = 1
+	}
+	err := parseList(s, func(key, value string) error {
+		class, err := ParseClass(key)
+		if err != nil {
+			return err
+		}
+		weight, err := strconv.Atoi(value)
+		if err != nil || weight < 0 {
+			return fmt.Errorf("invalid weight %q of %s", value, key)
+		}
+		ret[class] = weight
+		return nil
+	})
+	return ret, err
+}
+
+// ParseLinkBandwidth parses "[<link>:]<class>=<bytes per second>,...".
+func ParseLinkBandwidth(s string) (map[string]map[clustermessage.Priority]int64, error) {
+	ret := make(map[string]map[clustermessage.Priority]int64)
+	err := parseList(s, func(key, value string) error {
+		link := AnyLink
+		if i := strings.LastIndex(key, ":"); i >= 0 {
+			link, key = key[:i], key[i+1:]
+		}
+		class, err := ParseClass(key)
+		if err != nil {
+			return err
+		}
+		rate, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || rate < 0 {
+			return fmt.Errorf("invalid bandwidth %q of %s", value, key)
+		}
+		if ret[link] == nil {
+			ret[link] = make(map[clustermessage.Priority]int64)
+		}
+		ret[link][class] = rate
+		return nil
+	})
+	return ret, err
+}
+
+func parseList(s string, fn func(key, value string) error) error {
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		kv := strings.SplitN(item, "=", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("%q is not in form of key=value", item)
+		}
+		if err := fn(strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// classOf returns the class to queue a message of priority p.
+func classOf(p clustermessage.Priority) clustermessage.Priority {
+	for _, class := range Classes {
+		if class == p {
+			return p
+		}
+	}
+	return clustermessage.Priority_Control
+}
+
+// bandwidthOf returns the bandwidth cap of class on link, 0 if it is not capped.
+func bandwidthOf(link string, class clustermessage.Priority) int64 {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	if rate, ok := linkCaps[link][class]; ok {
+		return rate
+	}
+	return linkCaps[AnyLink][class]
+}
+
+func weightOf(class clustermessage.Priority) int {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	return weights[class]
+}
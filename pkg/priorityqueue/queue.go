@@ -0,0 +1,217 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package priorityqueue
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog"
+
+	"github.com/baidu/ote-stack/pkg/clustermessage"
+)
+
+const (
+	// flushCheckPeriod is the interval to check if a queue is flushed.
+	flushCheckPeriod = 20 * time.Millisecond
+)
+
+var (
+	// ErrQueueFull is returned if a message is pushed to a full queue.
+	ErrQueueFull = fmt.Errorf("queue is full")
+	// ErrQueueClosed is returned if a message is pushed to a queue stopped running.
+	ErrQueueClosed = fmt.Errorf("queue is closed")
+)
+
+type item struct {
+	data []byte
+	done func(error)
+}
+
+// Queue is an in-memory queue of a link, which sends messages by priority class.
+type Queue struct {
+	link  string
+	size  int
+	send  func([]byte) error
+	sched *Scheduler
+
+	mutex  sync.Mutex
+	items  map[clustermessage.Priority][]*item
+	length int
+	closed bool
+	wake   chan struct{}
+}
+
+// NewQueue returns a queue of link holding at most size messages, 0 means no limit,
+// messages are sent by send once it runs.
+func NewQueue(link string, size int, send func([]byte) error) *Queue {
+	return &Queue{
+		link:  link,
+		size:  size,
+		send:  send,
+		sched: NewScheduler(link),
+		items: make(map[clustermessage.Priority][]*item),
+		wake:  make(chan struct{}, 1),
+	}
+}
+
+/*
+Push puts data of priority p to the queue, done is called with result of sending if it is not nil.
+If the queue is full, the oldest message of the lowest class lower than p is dropped,
+or ErrQueueFull is returned if there is not.
+*/
+func (q *Queue) Push(p clustermessage.Priority, data []byte, done func(error)) error {
+	class := classOf(p)
+
+	q.mutex.Lock()
+	if q.closed {
+		q.mutex.Unlock()
+		return ErrQueueClosed
+	}
+	var dropped *item
+	if q.size > 0 && q.length >= q.size {
+		dropped = q.dropLower(class)
+		if dropped == nil {
+			q.mutex.Unlock()
+			return ErrQueueFull
+		}
+	}
+	q.items[class] = append(q.items[class], &item{data: data, done: done})
+	q.length++
+	q.mutex.Unlock()
+
+	if dropped != nil {
+		klog.Warningf("queue of %s is full, drop a message of lower priority", q.link)
+		dropped.finish(ErrQueueFull)
+	}
+	q.notify()
+	return nil
+}
+
+// dropLower removes the oldest message of the lowest class lower than class, it is called with mutex held.
+func (q *Queue) dropLower(class clustermessage.Priority) *item {
+	for i := len(Classes) - 1; i >= 0 && Classes[i] != class; i-- {
+		lower := Classes[i]
+		if len(q.items[lower]) > 0 {
+			it := q.items[lower][0]
+			q.items[lower] = q.items[lower][1:]
+			q.length--
+			return it
+		}
+	}
+	return nil
+}
+
+// Len returns number of messages in the queue.
+func (q *Queue) Len() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.length
+}
+
+// Flush waits until messages in the queue are sent or ctx is done.
+func (q *Queue) Flush(ctx context.Context) error {
+	return wait.PollImmediateUntil(flushCheckPeriod, func() (bool, error) {
+		return q.Len() == 0, nil
+	}, ctx.Done())
+}
+
+func (q *Queue) notify() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// pop returns the next message to send, or how long to wait if classes pending exceed their bandwidth.
+func (q *Queue) pop() (clustermessage.Priority, *item, time.Duration) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	class, wait, ok := q.sched.Next(func(c clustermessage.Priority) bool {
+		return len(q.items[c]) > 0
+	})
+	if !ok {
+		return class, nil, wait
+	}
+	it := q.items[class][0]
+	q.items[class][0] = nil
+	q.items[class] = q.items[class][1:]
+	q.length--
+	return class, it, 0
+}
+
+// Run sends messages in the queue until stop is closed,
+// messages left are finished with ErrQueueClosed then.
+func (q *Queue) Run(stop <-chan struct{}) {
+	defer q.close()
+
+	for {
+		var retry <-chan time.Time
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+
+			class, it, wait := q.pop()
+			if it == nil {
+				if wait > 0 {
+					retry = time.After(wait)
+				}
+				break
+			}
+			err := q.send(it.data)
+			if err == nil {
+				q.sched.Sent(class, len(it.data))
+			}
+			it.finish(err)
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-q.wake:
+		case <-retry:
+		}
+	}
+}
+
+func (q *Queue) close() {
+	q.mutex.Lock()
+	q.closed = true
+	items := q.items
+	q.items = make(map[clustermessage.Priority][]*item)
+	q.length = 0
+	q.mutex.Unlock()
+
+	for _, class := range Classes {
+		for _, it := range items[class] {
+			it.finish(ErrQueueClosed)
+		}
+	}
+}
+
+func (it *item) finish(err error) {
+	if it.done != nil {
+		it.done(err)
+	}
+}
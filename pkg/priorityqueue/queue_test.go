@@ -0,0 +1,109 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package priorityqueue
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/baidu/ote-stack/pkg/clustermessage"
+)
+
+func TestQueue(t *testing.T) {
+	defer setConfig(t, "route=4,control=2,bulk=0", "")()
+	sent := make(chan string, 10)
+	q := NewQueue("c1", 3, func(data []byte) error {
+		if string(data) == "fail" {
+			return fmt.Errorf("fail")
+		}
+		sent <- string(data)
+		return nil
+	})
+
+	results := make(chan error, 10)
+	done := func(err error) {
+		results <- err
+	}
+	assert.Nil(t, q.Push(clustermessage.Priority_Bulk, []byte("b1"), done))
+	assert.Nil(t, q.Push(clustermessage.Priority_Bulk, []byte("b2"), done))
+	assert.Nil(t, q.Push(clustermessage.Priority_Control, []byte("c1"), done))
+	// the oldest bulk message is dropped for a higher one if it is full.
+	assert.Nil(t, q.Push(clustermessage.Priority_Route, []byte("r1"), done))
+	assert.Equal(t, ErrQueueFull, <-results)
+	assert.Equal(t, ErrQueueFull, q.Push(clustermessage.Priority_Bulk, []byte("b3"), nil))
+	assert.Equal(t, 3, q.Len())
+
+	stop := make(chan struct{})
+	go q.Run(stop)
+	for _, expect := range []string{"r1", "c1", "b2"} {
+		select {
+		case got := <-sent:
+			assert.Equal(t, expect, got)
+		case <-time.After(time.Second):
+			assert.Fail(t, "message is not sent", expect)
+		}
+		assert.Nil(t, <-results)
+	}
+
+	// failure of send is returned by done.
+	assert.Nil(t, q.Push(clustermessage.Priority_Auto, []byte("fail"), done))
+	assert.NotNil(t, <-results)
+
+	// messages left are finished after stop.
+	close(stop)
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, ErrQueueClosed, q.Push(clustermessage.Priority_Route, []byte("r2"), done))
+	assert.Equal(t, 0, q.Len())
+}
+
+func TestQueueClose(t *testing.T) {
+	q := NewQueue("c1", 0, func(data []byte) error {
+		return nil
+	})
+	results := make(chan error, 1)
+	assert.Nil(t, q.Push(clustermessage.Priority_Control, []byte("c1"), func(err error) {
+		results <- err
+	}))
+	stop := make(chan struct{})
+	close(stop)
+	q.Run(stop)
+	assert.Equal(t, ErrQueueClosed, <-results)
+}
+
+func TestQueueFlush(t *testing.T) {
+	q := NewQueue("c1", 0, func(data []byte) error {
+		return nil
+	})
+	assert.Nil(t, q.Flush(context.Background()))
+
+	assert.Nil(t, q.Push(clustermessage.Priority_Control, []byte("c1"), nil))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.NotNil(t, q.Flush(ctx))
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go q.Run(stop)
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.Nil(t, q.Flush(ctx))
+	assert.Equal(t, 0, q.Len())
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package priorityqueue
+
+import (
+	"time"
+
+	"github.com/baidu/ote-stack/pkg/clustermessage"
+)
+
+// tokenBucket limits bandwidth of a class, tokens are bytes allowed to send.
+type tokenBucket struct {
+	rate   int64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate int64, now time.Time) *tokenBucket {
+	return &tokenBucket{
+		rate:   rate,
+		tokens: float64(rate),
+		last:   now,
+	}
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	b.tokens += now.Sub(b.last).Seconds() * float64(b.rate)
+	// burst is the bandwidth of a second.
+	if b.tokens > float64(b.rate) {
+		b.tokens = float64(b.rate)
+	}
+	b.last = now
+}
+
+// delay returns how long to wait until there are tokens.
+func (b *tokenBucket) delay(now time.Time) time.Duration {
+	b.refill(now)
+	if b.tokens > 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens/float64(b.rate)*float64(time.Second)) + time.Millisecond
+}
+
+// take consumes n tokens, a message larger than tokens left is allowed and paid back later.
+func (b *tokenBucket) take(n int, now time.Time) {
+	b.refill(now)
+	b.tokens -= float64(n)
+}
+
+// Scheduler decides which class of a link to send next, it is not safe for concurrent use.
+type Scheduler struct {
+	link    string
+	credits map[clustermessage.Priority]int
+	buckets map[clustermessage.Priority]*tokenBucket
+}
+
+// NewScheduler returns a scheduler of link with bandwidth caps of it in LinkBandwidth.
+func NewScheduler(link string) *Scheduler {
+	s := &Scheduler{
+		link:    link,
+		credits: make(map[clustermessage.Priority]int),
+		buckets: make(map[clustermessage.Priority]*tokenBucket),
+	}
+	now := time.Now()
+	for _, class := range Classes {
+		if rate := bandwidthOf(link, class); rate > 0 {
+			s.buckets[class] = newTokenBucket(rate, now)
+		}
+	}
+	return s
+}
+
+/*
+Next returns the class to send next among the classes for which ready returns true.
+If all ready classes exceed their bandwidth, it returns false and how long to wait,
+and if no class is ready, it returns false and 0.
+*/
+func (s *Scheduler) Next(ready func(clustermessage.Priority) bool) (clustermessage.Priority, time.Duration, bool) {
+	now := time.Now()
+	var wait time.Duration
+	var candidates []clustermessage.Priority
+	for _, class := range Classes {
+		if !ready(class) {
+			continue
+		}
+		if b, ok := s.buckets[class]; ok {
+			if d := b.delay(now); d > 0 {
+				if wait == 0 || d < wait {
+					wait = d
+				}
+				continue
+			}
+		}
+		candidates = append(candidates, class)
+	}
+	if len(candidates) == 0 {
+		return clustermessage.Priority_Auto, wait, false
+	}
+
+	weighted := candidates[:0:0]
+	for _, class := range candidates {
+		if weightOf(class) > 0 {
+			weighted = append(weighted, class)
+		}
+	}
+	if len(weighted) == 0 {
+		return candidates[0], 0, true
+	}
+
+	// start a new round if weighted classes have used up their credits.
+	for round := 0; round < 2; round++ {
+		for _, class := range weighted {
+			if s.credits[class] > 0 {
+				s.credits[class]--
+				return class, 0, true
+			}
+		}
+		for _, class := range Classes {
+			s.credits[class] = weightOf(class)
+		}
+	}
+	return weighted[0], 0, true
+}
+
+// Sent records that a message of n bytes of class is sent.
+func (s *Scheduler) Sent(class clustermessage.Priority, n int) {
+	if b, ok := s.buckets[class]; ok {
+		b.take(n, time.Now())
+	}
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package priorityqueue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/baidu/ote-stack/pkg/clustermessage"
+)
+
+func setConfig(t *testing.T, w, bandwidth string) func() {
+	oldWeights, oldBandwidth := Weights, LinkBandwidth
+	Weights, LinkBandwidth = w, bandwidth
+	assert.Nil(t, Load())
+	return func() {
+		Weights, LinkBandwidth = oldWeights, oldBandwidth
+		assert.Nil(t, Load())
+	}
+}
+
+func TestParse(t *testing.T) {
+	w, err := ParseWeights("Route=3, bulk=0")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, w[clustermessage.Priority_Route])
+	assert.Equal(t, 1, w[clustermessage.Priority_Control])
+	assert.Equal(t, 0, w[clustermessage.Priority_Bulk])
+	_, err = ParseWeights("auto=1")
+	assert.NotNil(t, err)
+	_, err = ParseWeights("bulk=-1")
+	assert.NotNil(t, err)
+	_, err = ParseWeights("bulk")
+	assert.NotNil(t, err)
+
+	caps, err := ParseLinkBandwidth("bulk=100,c1:bulk=10,parent:control=20")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(100), caps[AnyLink][clustermessage.Priority_Bulk])
+	assert.Equal(t, int64(10), caps["c1"][clustermessage.Priority_Bulk])
+	assert.Equal(t, int64(20), caps["parent"][clustermessage.Priority_Control])
+	_, err = ParseLinkBandwidth("c1:bulk=fast")
+	assert.NotNil(t, err)
+
+	defer setConfig(t, "", "bulk=100,c1:bulk=10,c2:bulk=0")()
+	assert.Equal(t, int64(10), bandwidthOf("c1", clustermessage.Priority_Bulk))
+	assert.Equal(t, int64(0), bandwidthOf("c2", clustermessage.Priority_Bulk))
+	assert.Equal(t, int64(100), bandwidthOf("c3", clustermessage.Priority_Bulk))
+	assert.Equal(t, int64(0), bandwidthOf("c3", clustermessage.Priority_Route))
+}
+
+func TestSchedulerWeights(t *testing.T) {
+	ready := func(classes ...clustermessage.Priority) func(clustermessage.Priority) bool {
+		return func(c clustermessage.Priority) bool {
+			for _, class := range classes {
+				if c == class {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	next := func(s *Scheduler, r func(clustermessage.Priority) bool) clustermessage.Priority {
+		class, _, ok := s.Next(r)
+		assert.True(t, ok)
+		return class
+	}
+
+	// bulk is not sent while routes and control messages are pending.
+	defer setConfig(t, "route=2,control=1,bulk=0", "")()
+	s := NewScheduler("c1")
+	all := ready(Classes...)
+	var got []clustermessage.Priority
+	for i := 0; i < 6; i++ {
+		got = append(got, next(s, all))
+	}
+	assert.Equal(t, []clustermessage.Priority{
+		clustermessage.Priority_Route, clustermessage.Priority_Route, clustermessage.Priority_Control,
+		clustermessage.Priority_Route, clustermessage.Priority_Route, clustermessage.Priority_Control,
+	}, got)
+	assert.Equal(t, clustermessage.Priority_Bulk, next(s, ready(clustermessage.Priority_Bulk)))
+	_, wait, ok := s.Next(ready())
+	assert.False(t, ok)
+	assert.Equal(t, time.Duration(0), wait)
+
+	// bulk with weight is sent among others.
+	defer setConfig(t, "route=1,control=1,bulk=1", "")()
+	s = NewScheduler("c1")
+	got = got[:0]
+	for i := 0; i < 3; i++ {
+		got = append(got, next(s, all))
+	}
+	assert.Equal(t, Classes, got)
+}
+
+func TestSchedulerBandwidth(t *testing.T) {
+	defer setConfig(t, "route=1,control=1,bulk=1", "c1:bulk=1000")()
+	s := NewScheduler("c1")
+	bulk := func(c clustermessage.Priority) bool {
+		return c == clustermessage.Priority_Bulk
+	}
+	class, _, ok := s.Next(bulk)
+	assert.True(t, ok)
+	assert.Equal(t, clustermessage.Priority_Bulk, class)
+	s.Sent(class, 1500)
+
+	// bulk exceeds its cap until tokens are refilled.
+	_, wait, ok := s.Next(bulk)
+	assert.False(t, ok)
+	assert.True(t, wait > 400*time.Millisecond && wait <= 600*time.Millisecond, wait)
+	// other classes are not capped.
+	class, _, ok = s.Next(func(c clustermessage.Priority) bool { return true })
+	assert.True(t, ok)
+	assert.NotEqual(t, clustermessage.Priority_Bulk, class)
+
+	// other links are not capped.
+	s = NewScheduler("c2")
+	s.Sent(clustermessage.Priority_Bulk, 1500)
+	_, _, ok = s.Next(bulk)
+	assert.True(t, ok)
+}
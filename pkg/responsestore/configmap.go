@@ -0,0 +1,147 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package responsestore
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	otev1 "github.com/baidu/ote-stack/pkg/apis/ote/v1"
+)
+
+const (
+	// configMapBodyKey is the key of body in data of a configmap.
+	configMapBodyKey = "body"
+	// annotations of a configmap telling whose response it is.
+	clusterControllerAnnotation = "ote.baidu.com/clustercontroller"
+	clusterAnnotation           = "ote.baidu.com/cluster"
+
+	maxConfigMapNameLength = 253
+)
+
+/*
+configMapStore keeps a response in a configmap in the namespace of the crd, named by the crd, the cluster and the timestamp.
+the configmap is owned by the crd, so it is deleted with the crd by k8s garbage collector.
+a configmap is limited to 1MiB, so is a body in it.
+*/
+type configMapStore struct {
+	client kubernetes.Interface
+}
+
+func newConfigMapStore(client kubernetes.Interface) *configMapStore {
+	return &configMapStore{client}
+}
+
+func configMapName(key Key) string {
+	id := responseID(key)
+	name := key.Name
+	if len(name)+len(id)+1 > maxConfigMapNameLength {
+		name = name[:maxConfigMapNameLength-len(id)-1]
+	}
+	return name + "-" + id
+}
+
+func (s *configMapStore) configMap(key Key, body []byte) *corev1.ConfigMap {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      configMapName(key),
+			Namespace: key.Namespace,
+			Annotations: map[string]string{
+				clusterControllerAnnotation: key.Name,
+				clusterAnnotation:           key.Cluster,
+			},
+		},
+	}
+	if key.UID != "" {
+		cm.ObjectMeta.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: otev1.SchemeGroupVersion.String(),
+			Kind:       "ClusterController",
+			Name:       key.Name,
+			UID:        key.UID,
+		}}
+	}
+	if utf8.Valid(body) {
+		cm.Data = map[string]string{configMapBodyKey: string(body)}
+	} else {
+		cm.BinaryData = map[string][]byte{configMapBodyKey: body}
+	}
+	return cm
+}
+
+func (s *configMapStore) Put(key Key, body []byte) (string, error) {
+	cm := s.configMap(key, body)
+	configMaps := s.client.CoreV1().ConfigMaps(cm.ObjectMeta.Namespace)
+	_, err := configMaps.Create(cm)
+	if errors.IsAlreadyExists(err) {
+		var old *corev1.ConfigMap
+		old, err = configMaps.Get(cm.ObjectMeta.Name, metav1.GetOptions{})
+		if err == nil {
+			cm.ObjectMeta.ResourceVersion = old.ObjectMeta.ResourceVersion
+			_, err = configMaps.Update(cm)
+		}
+	}
+	if err != nil {
+		return "", fmt.Errorf("store response of %s to configmap %s failed: %v", key.Cluster, cm.ObjectMeta.Name, err)
+	}
+	return KindConfigMap + ":" + cm.ObjectMeta.Namespace + "/" + cm.ObjectMeta.Name, nil
+}
+
+// location returns namespace and name of the configmap referred by ref.
+func (s *configMapStore) location(ref string) (string, string, error) {
+	kind, loc, err := parseRef(ref)
+	if err != nil {
+		return "", "", err
+	}
+	i := strings.Index(loc, "/")
+	if kind != KindConfigMap || i < 0 {
+		return "", "", fmt.Errorf("invalid configmap reference %q", ref)
+	}
+	return loc[:i], loc[i+1:], nil
+}
+
+func (s *configMapStore) Get(ref string) ([]byte, error) {
+	namespace, name, err := s.location(ref)
+	if err != nil {
+		return nil, err
+	}
+	cm, err := s.client.CoreV1().ConfigMaps(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if body, ok := cm.BinaryData[configMapBodyKey]; ok {
+		return body, nil
+	}
+	return []byte(cm.Data[configMapBodyKey]), nil
+}
+
+func (s *configMapStore) Delete(ref string) error {
+	namespace, name, err := s.location(ref)
+	if err != nil {
+		return err
+	}
+	err = s.client.CoreV1().ConfigMaps(namespace).Delete(name, &metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
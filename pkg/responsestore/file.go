@@ -0,0 +1,103 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package responsestore
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+/*
+fileStore keeps a response in a file on local filesystem, dir/<namespace>/<crd name>/<cluster id>-<timestamp>.
+a file is written to a temp file and renamed, so a reader never gets a partial body.
+*/
+type fileStore struct {
+	dir string
+}
+
+func newFileStore(dir string) (*fileStore, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("create response store dir %s failed: %v", dir, err)
+	}
+	return &fileStore{dir}, nil
+}
+
+func (s *fileStore) Put(key Key, body []byte) (string, error) {
+	dir := filepath.Join(s.dir, key.Namespace, key.Name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("create dir %s failed: %v", dir, err)
+	}
+	path := filepath.Join(dir, responseID(key))
+
+	f, err := ioutil.TempFile(dir, ".tmp-")
+	if err != nil {
+		return "", err
+	}
+	_, err = f.Write(body)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("store response of %s to %s failed: %v", key.Cluster, path, err)
+	}
+	return KindFile + ":" + path, nil
+}
+
+// path returns the file referred by ref, which must be in dir of the store.
+func (s *fileStore) path(ref string) (string, error) {
+	kind, path, err := parseRef(ref)
+	if err != nil {
+		return "", err
+	}
+	path = filepath.Clean(path)
+	if kind != KindFile || !strings.HasPrefix(path, s.dir+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid file reference %q", ref)
+	}
+	return path, nil
+}
+
+func (s *fileStore) Get(ref string) ([]byte, error) {
+	path, err := s.path(ref)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadFile(path)
+}
+
+func (s *fileStore) Delete(ref string) error {
+	path, err := s.path(ref)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	// remove dir of the crd once it has no response, it fails if there is any.
+	os.Remove(filepath.Dir(path))
+	return nil
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package responsestore keeps response bodies of clusters to a ClusterController crd out of its status,
+// the status refers to the body stored by a reference.
+package responsestore
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Store kinds.
+const (
+	KindConfigMap = "configmap"
+	KindFile      = "file"
+)
+
+// Key identifies the response of a cluster to a ClusterController crd.
+type Key struct {
+	Namespace string
+	Name      string
+	UID       types.UID
+	Cluster   string
+	// Timestamp of the response, responses of a cluster at different timestamps are stored apart,
+	// so a stale one never replaces the body referred by status.
+	Timestamp int64
+}
+
+// Store keeps response bodies.
+type Store interface {
+	// Put stores body of the response of key, the one stored before is replaced,
+	// it returns the reference to get body.
+	Put(key Key, body []byte) (string, error)
+	// Get returns body referred by ref.
+	Get(ref string) ([]byte, error)
+	// Delete deletes body referred by ref, it is not an error if there is no such body.
+	Delete(ref string) error
+}
+
+// New news a Store of kind, configmap store needs client, file store keeps bodies in dir.
+func New(kind string, client kubernetes.Interface, dir string) (Store, error) {
+	switch kind {
+	case KindConfigMap:
+		if client == nil {
+			return nil, fmt.Errorf("configmap response store needs k8s client")
+		}
+		return newConfigMapStore(client), nil
+	case KindFile:
+		if dir == "" {
+			return nil, fmt.Errorf("file response store needs a dir")
+		}
+		return newFileStore(dir)
+	default:
+		return nil, fmt.Errorf("unknown response store %q, configmap or file", kind)
+	}
+}
+
+// Hash returns hash of body shown in status.
+func Hash(body []byte) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(body))
+}
+
+// clusterID returns a short id of cluster usable in names of any kind.
+func clusterID(cluster string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(cluster)))[:16]
+}
+
+// responseID returns an id of the response of key usable in names of any kind.
+func responseID(key Key) string {
+	return fmt.Sprintf("%s-%d", clusterID(key.Cluster), key.Timestamp)
+}
+
+// parseRef splits ref to kind and the location in store.
+func parseRef(ref string) (string, string, error) {
+	i := strings.Index(ref, ":")
+	if i <= 0 {
+		return "", "", fmt.Errorf("invalid response reference %q", ref)
+	}
+	return ref[:i], ref[i+1:], nil
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package responsestore
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestNew(t *testing.T) {
+	_, err := New(KindConfigMap, nil, "")
+	assert.Error(t, err)
+	_, err = New(KindFile, nil, "")
+	assert.Error(t, err)
+	_, err = New("s3", fake.NewSimpleClientset(), "dir")
+	assert.Error(t, err)
+
+	s, err := New(KindConfigMap, fake.NewSimpleClientset(), "")
+	assert.NoError(t, err)
+	assert.IsType(t, &configMapStore{}, s)
+}
+
+func testStore(t *testing.T, s Store) {
+	key := Key{Namespace: "kube-system", Name: "cc1", UID: "uid1", Cluster: "c1"}
+
+	ref, err := s.Put(key, []byte("first"))
+	assert.NoError(t, err)
+	body, err := s.Get(ref)
+	assert.NoError(t, err)
+	assert.Equal(t, "first", string(body))
+
+	// put again replaces the body.
+	binary := []byte{0xff, 0xfe, 0x00}
+	ref2, err := s.Put(key, binary)
+	assert.NoError(t, err)
+	assert.Equal(t, ref, ref2)
+	body, err = s.Get(ref)
+	assert.NoError(t, err)
+	assert.Equal(t, binary, body)
+
+	// another cluster has its own body.
+	ref3, err := s.Put(Key{Namespace: "kube-system", Name: "cc1", UID: "uid1", Cluster: "c2"}, []byte("c2"))
+	assert.NoError(t, err)
+	assert.NotEqual(t, ref, ref3)
+	// so does a response at another timestamp.
+	ref4, err := s.Put(Key{Namespace: "kube-system", Name: "cc1", UID: "uid1", Cluster: "c1", Timestamp: 1}, []byte("new"))
+	assert.NoError(t, err)
+	assert.NotEqual(t, ref, ref4)
+	assert.NoError(t, s.Delete(ref4))
+
+	assert.NoError(t, s.Delete(ref))
+	_, err = s.Get(ref)
+	assert.Error(t, err)
+	assert.NoError(t, s.Delete(ref))
+	body, err = s.Get(ref3)
+	assert.NoError(t, err)
+	assert.Equal(t, "c2", string(body))
+
+	_, err = s.Get("unknown")
+	assert.Error(t, err)
+	assert.Error(t, s.Delete("unknown:ref"))
+}
+
+func TestConfigMapStore(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	s := newConfigMapStore(client)
+	testStore(t, s)
+
+	ref, err := s.Put(Key{Namespace: "kube-system", Name: "cc2", UID: "uid2", Cluster: "c1"}, []byte("body"))
+	assert.NoError(t, err)
+	_, name, err := s.location(ref)
+	assert.NoError(t, err)
+	cm, err := client.CoreV1().ConfigMaps("kube-system").Get(name, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "c1", cm.ObjectMeta.Annotations[clusterAnnotation])
+	assert.Equal(t, "cc2", cm.ObjectMeta.Annotations[clusterControllerAnnotation])
+	if assert.Len(t, cm.ObjectMeta.OwnerReferences, 1) {
+		assert.Equal(t, "uid2", string(cm.ObjectMeta.OwnerReferences[0].UID))
+		assert.Equal(t, "ClusterController", cm.ObjectMeta.OwnerReferences[0].Kind)
+	}
+	assert.Equal(t, "body", cm.Data[configMapBodyKey])
+}
+
+func TestFileStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "responsestore")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	s, err := New(KindFile, nil, dir)
+	assert.NoError(t, err)
+	testStore(t, s)
+
+	// a file out of dir is never touched.
+	outside := filepath.Join(dir, "..", filepath.Base(dir)+"-outside")
+	assert.NoError(t, ioutil.WriteFile(outside, []byte("keep"), 0644))
+	defer os.Remove(outside)
+	_, err = s.Get(KindFile + ":" + outside)
+	assert.Error(t, err)
+	assert.Error(t, s.Delete(KindFile+":"+filepath.Join(dir, "..", "..", outside)))
+	_, err = os.Stat(outside)
+	assert.NoError(t, err)
+}
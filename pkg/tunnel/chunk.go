@@ -0,0 +1,120 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/baidu/ote-stack/pkg/config"
+)
+
+/*
+Messages larger than MessageChunkSize are split into chunks by WSClient,
+so they are interleaved with other messages instead of blocking them,
+and the peer reassembles them by clustermessage.Reassembler.
+
+Chunking is negotiated per connection: both ends post their MaxMessageSize in header,
+messages are split only if the peer posts it, and refused if they are larger than it.
+*/
+
+const (
+	// maxFrameOverhead is the max size added to a message by reliable delivery, compression and grpc framing.
+	maxFrameOverhead = 1024
+)
+
+var (
+	// MaxMessageSize is the max size of a message received or sent on a link.
+	MaxMessageSize = 64 << 20
+	// MessageChunkSize is the max size of each chunk of a large message, chunking is disabled if it is 0.
+	MessageChunkSize = 256 << 10
+	// ChunkReassembleTimeout is how long the chunks of an incomplete message are kept after the last one.
+	ChunkReassembleTimeout = time.Minute
+
+	// ErrMessageTooLarge is returned if a message is larger than the max size of the link.
+	ErrMessageTooLarge = fmt.Errorf("message is too large")
+)
+
+// maxFrameSize returns the max size of a frame read from a connection.
+func maxFrameSize() int64 {
+	return int64(MaxMessageSize) + maxFrameOverhead
+}
+
+// offerChunking posts MaxMessageSize in header of the dialer.
+func offerChunking(header http.Header) {
+	header.Set(config.ClusterConnectHeaderMaxMessageSize, strconv.Itoa(MaxMessageSize))
+}
+
+// negotiateChunking returns max message size posted by the dialer of r, 0 if it does not support chunking,
+// and answers MaxMessageSize in respHeader if it does.
+func negotiateChunking(r *http.Request, respHeader http.Header) int {
+	size := parseMaxMessageSize(r.Header)
+	if size > 0 {
+		respHeader.Set(config.ClusterConnectHeaderMaxMessageSize, strconv.Itoa(MaxMessageSize))
+	}
+	return size
+}
+
+// acceptedChunking returns max message size answered in resp, 0 if the server does not support chunking.
+func acceptedChunking(resp *http.Response) int {
+	if resp == nil {
+		return 0
+	}
+	return parseMaxMessageSize(resp.Header)
+}
+
+func parseMaxMessageSize(header http.Header) int {
+	size, err := strconv.Atoi(header.Get(config.ClusterConnectHeaderMaxMessageSize))
+	if err != nil || size < 0 {
+		return 0
+	}
+	return size
+}
+
+// DialClient dials path of the server at addr by transport, and returns the client named name of the connection.
+// Chunking of large messages is negotiated with the server.
+func DialClient(transport Transport, name, addr, path string, header http.Header) (*WSClient, *http.Response, error) {
+	if header == nil {
+		header = http.Header{}
+	}
+	offerChunking(header)
+	conn, resp, err := transport.Dial(addr, path, header)
+	if err != nil {
+		return nil, resp, err
+	}
+	client := NewClient(name, conn)
+	client.peerMaxSize = acceptedChunking(resp)
+	return client, resp, nil
+}
+
+// AcceptClient accepts r by transport, and returns the client named name of the connection.
+// Chunking of large messages is negotiated with the dialer.
+func AcceptClient(transport Transport, name string, w http.ResponseWriter, r *http.Request, header http.Header) (*WSClient, error) {
+	if header == nil {
+		header = http.Header{}
+	}
+	peerMaxSize := negotiateChunking(r, header)
+	conn, err := transport.Accept(w, r, header)
+	if err != nil {
+		return nil, err
+	}
+	client := NewClient(name, conn)
+	client.peerMaxSize = peerMaxSize
+	return client, nil
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/baidu/ote-stack/pkg/config"
+)
+
+func TestChunkedTunnel(t *testing.T) {
+	transportName, maxSize, chunkSize := TransportName, MaxMessageSize, MessageChunkSize
+	defer func() {
+		TransportName, MaxMessageSize, MessageChunkSize = transportName, maxSize, chunkSize
+	}()
+	MaxMessageSize = 1 << 20
+	MessageChunkSize = 1 << 10
+
+	for _, transport := range []string{TransportWebsocket, TransportGRPC} {
+		TransportName = transport
+
+		received := make(chan []byte, 2)
+		ct := NewCloudTunnel("127.0.0.1:0", nil).(*cloudTunnel)
+		ct.RegistReturnMessageFunc(func(client string, msg []byte) error {
+			received <- msg
+			return nil
+		})
+		assert.Nil(t, ct.Start())
+
+		e := &edgeTunnel{
+			name:               "c1",
+			cloudAddr:          ct.server.Addr,
+			listenAddr:         "fake",
+			conf:               &config.ClusterControllerConfig{},
+			afterConnectToHook: func() {},
+		}
+		assert.Nil(t, e.connect(), transport)
+		assert.Equal(t, MaxMessageSize, e.wsclient.peerMaxSize)
+
+		// large message is sent in chunks, and small message is not blocked by it.
+		large := bytes.Repeat([]byte("0123456789"), 100<<10)
+		done := make(chan error)
+		go func() {
+			done <- e.Send(large)
+		}()
+		assert.Nil(t, e.Send([]byte("small")))
+		assert.Nil(t, <-done)
+		for i := 0; i < 2; i++ {
+			select {
+			case m := <-received:
+				if len(m) != len(large) {
+					assert.Equal(t, "small", string(m))
+					continue
+				}
+				assert.Equal(t, large, m)
+			case <-time.After(5 * time.Second):
+				assert.Fail(t, "message is not received", transport)
+			}
+		}
+		assert.True(t, e.wsclient.Stats().SentMessages > 100)
+
+		// large message from server is reassembled too.
+		go func() {
+			done <- ct.Send("c1", large)
+		}()
+		m, err := e.wsclient.ReadMessage()
+		assert.Nil(t, err)
+		assert.Equal(t, large, m)
+		assert.Nil(t, <-done)
+
+		// message larger than max size is refused.
+		assert.Equal(t, ErrMessageTooLarge, e.Send(make([]byte, MaxMessageSize+1)))
+
+		e.wsclient.Close()
+		ct.Stop()
+	}
+}
@@ -18,17 +18,20 @@ package tunnel
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
-	"math/rand"
 	"net"
 	"net/http"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/mux"
-	"github.com/gorilla/websocket"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/klog"
 
+	"github.com/baidu/ote-stack/pkg/clustermessage"
 	"github.com/baidu/ote-stack/pkg/config"
 )
 
@@ -39,9 +42,10 @@ const (
 
 	// uri for ote controller manager
 	controllerURI = "/controller"
-)
 
-var upgrader = websocket.Upgrader{}
+	// prefix of authorization header value with bootstrap token
+	bearerTokenPrefix = "Bearer "
+)
 
 // ControllerManagerMsgHandleFunc is a function handle msg from controller manager,
 // string is remote address of the controller manager,
@@ -54,14 +58,21 @@ type CloudTunnel interface {
 	Start() error
 	// Stop will shut down the server and close all websocket connection.
 	Stop() error
+	// Shutdown rejects new connections, sends a going away notice to childs and controller managers,
+	// closes their connections and shuts down the server until ctx is done.
+	Shutdown(ctx context.Context) error
 	// Send sends binary message to the given wsclient.
 	Send(clusterName string, msg []byte) error
 	// Broadcast sends binary message to all connected wsclient.
 	Broadcast(msg []byte)
-	// SendToControllerManager sends msg to anyone of controller manager.
+	// SendToControllerManager sends msg to the controller manager owning ClusterName of msg.
 	SendToControllerManager([]byte) error
 	// RegistRedirectFunc registers a func which calls before CheckNameValidFunc.
 	RegistRedirectFunc(fn RedirectFunc)
+	// RegistAuthenticateFunc registers ClusterAuthenticator.
+	RegistAuthenticateFunc(fn ClusterAuthenticator)
+	// RegistControllerManagerAuthenticateFunc registers ControllerManagerAuthenticator.
+	RegistControllerManagerAuthenticateFunc(fn ControllerManagerAuthenticator)
 	// RegistCheckNameValidFunc registers ClusterNameChecker.
 	RegistCheckNameValidFunc(fn ClusterNameChecker)
 	// RegistAfterConnectHook registers AfterConnectHook.
@@ -72,6 +83,11 @@ type CloudTunnel interface {
 	RegistClientCloseHandler(fn ClientCloseHandleFunc)
 	// RegistControllerManagerMsgHandler regists ControllerManagerMsgHandleFunc.
 	RegistControllerManagerMsgHandler(fn ControllerManagerMsgHandleFunc)
+	// RegistHTTPHandler registers fn to serve path on the tunnel listener, it must be called before Start.
+	// Requests are authenticated as a cluster connecting, by bootstrap token and cluster name in header.
+	RegistHTTPHandler(path string, fn http.HandlerFunc)
+	// ChildLinks returns state of links to childs, keyed by cluster name.
+	ChildLinks() map[string]LinkInfo
 }
 
 // cloudTunnel handles all communications with edgetunnel.
@@ -79,26 +95,42 @@ type cloudTunnel struct {
 	clients               sync.Map
 	address               string
 	redirect              RedirectFunc
+	authenticate          ClusterAuthenticator
+	authenticateManager   ControllerManagerAuthenticator
 	clusterNameCheck      ClusterNameChecker
 	receiveMessageHandler TunnelReadMessageFunc
 	notifyClientClosed    ClientCloseHandleFunc
 	afterConnectHook      AfterConnectHook
 	server                *http.Server
-	controllers           sync.Map // remoteAddr -> wsclient
-	controllersKey        []string
+	transport             Transport
+	controllers           *controllerShards
 	controlMsgHandler     ControllerManagerMsgHandleFunc
+	sessions              reliableSessions
+	httpHandlers          map[string]http.HandlerFunc
+	// closing is set once it is shutting down.
+	closing int32
 }
 
 // NewCloudTunnel returns a new cloudTunnel object.
-func NewCloudTunnel(address string) CloudTunnel {
+// tlsConfig is used to serve with tls, cloudTunnel serves without tls if it is nil.
+// It returns nil if TransportName is unknown.
+func NewCloudTunnel(address string, tlsConfig *tls.Config) CloudTunnel {
+	transport, err := NewTransport(tlsConfig, nil)
+	if err != nil {
+		klog.Errorf("new cloud tunnel failed: %v", err)
+		return nil
+	}
 	tunnel := &cloudTunnel{
-		address:            address,
-		redirect:           func() string { return "" },
-		clusterNameCheck:   defaultClusterNameChecker,
-		notifyClientClosed: func(*config.ClusterRegistry) { return },
-		afterConnectHook:   defaultAfterConnectHook,
-		controlMsgHandler:  defaultControlMsgHandler,
-		controllersKey:     make([]string, 0),
+		address:             address,
+		transport:           transport,
+		redirect:            func() string { return "" },
+		authenticate:        defaultClusterAuthenticator,
+		authenticateManager: func(string) error { return nil },
+		clusterNameCheck:    defaultClusterNameChecker,
+		notifyClientClosed:  func(*config.ClusterRegistry) { return },
+		afterConnectHook:    defaultAfterConnectHook,
+		controlMsgHandler:   defaultControlMsgHandler,
+		controllers:         newControllerShards(),
 	}
 
 	tunnel.receiveMessageHandler = func(client string, msg []byte) error {
@@ -128,8 +160,12 @@ func (t *cloudTunnel) Send(clusterName string, msg []byte) error {
 }
 
 func (t *cloudTunnel) SendToControllerManager(msg []byte) error {
-	// select a controller and send the msg
-	client := findAController(t.controllers, t.controllersKey)
+	// select the controller by cluster name and send the msg
+	cluster := ""
+	if head := clustermessage.ParseHead(msg); head != nil {
+		cluster = head.ClusterName
+	}
+	client := t.controllers.pick(cluster)
 	if client == nil {
 		return fmt.Errorf("cannot find a controller to send msg to")
 	}
@@ -140,6 +176,14 @@ func (t *cloudTunnel) RegistRedirectFunc(fn RedirectFunc) {
 	t.redirect = fn
 }
 
+func (t *cloudTunnel) RegistAuthenticateFunc(fn ClusterAuthenticator) {
+	t.authenticate = fn
+}
+
+func (t *cloudTunnel) RegistControllerManagerAuthenticateFunc(fn ControllerManagerAuthenticator) {
+	t.authenticateManager = fn
+}
+
 func (t *cloudTunnel) RegistCheckNameValidFunc(fn ClusterNameChecker) {
 	t.clusterNameCheck = fn
 }
@@ -160,6 +204,39 @@ func (t *cloudTunnel) RegistControllerManagerMsgHandler(fn ControllerManagerMsgH
 	t.controlMsgHandler = fn
 }
 
+func (t *cloudTunnel) RegistHTTPHandler(path string, fn http.HandlerFunc) {
+	if t.httpHandlers == nil {
+		t.httpHandlers = make(map[string]http.HandlerFunc)
+	}
+	t.httpHandlers[path] = fn
+}
+
+// authorized wraps fn to serve requests authenticated as a cluster connecting.
+func (t *cloudTunnel) authorized(fn http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cr := config.ClusterRegistry{
+			Name: r.Header.Get(config.ClusterConnectHeaderUserDefineName),
+		}
+		if err := t.authenticate(tokenFromRequest(r), &cr); err != nil {
+			klog.V(1).Infof("request %s from %s authenticate failed: %v", r.URL.Path, r.RemoteAddr, err)
+			http.Error(w, "authenticate failed: "+err.Error(), http.StatusUnauthorized)
+			return
+		}
+		fn(w, r)
+	}
+}
+
+func (t *cloudTunnel) ChildLinks() map[string]LinkInfo {
+	ret := make(map[string]LinkInfo)
+	t.clients.Range(func(key, value interface{}) bool {
+		if client, ok := value.(*WSClient); ok {
+			ret[key.(string)] = client.Info()
+		}
+		return true
+	})
+	return ret
+}
+
 func (t *cloudTunnel) handleReceiveMessage(client *WSClient) {
 	if client == nil {
 		return
@@ -172,20 +249,32 @@ func (t *cloudTunnel) handleReceiveMessage(client *WSClient) {
 			klog.Errorf("wsclient %s read msg error, err:%s", client.Name, err.Error())
 			break
 		}
+		if isGoingAway(msg) {
+			klog.Infof("cluster %s is going away", client.Name)
+			break
+		}
 		t.receiveMessageHandler(client.Name, msg)
 	}
 }
 
-func (t *cloudTunnel) connect(cr *config.ClusterRegistry, conn *websocket.Conn) {
-	wsclient := NewWSClient(cr.Name, conn)
+func (t *cloudTunnel) connect(cr *config.ClusterRegistry, wsclient *WSClient, session *reliableSession) {
 	_, ok := t.clients.LoadOrStore(cr.Name, wsclient)
 	if ok {
 		klog.Infof("cluster %s is already connected", cr.Name)
 		if err := wsclient.Close(); err != nil {
 			klog.Errorf("close websocket connection failed: %s", err.Error())
 		}
+		if session != nil {
+			t.sessions.release(session, wsclient)
+		}
 		return
 	}
+	if session != nil {
+		if err := session.attach(wsclient); err != nil {
+			klog.Errorf("retransmit to cluster %s failed: %v", cr.Name, err)
+		}
+		defer t.sessions.release(session, wsclient)
+	}
 
 	klog.Infof("cluster %s is connected", cr.Name)
 	t.afterConnectHook(cr)
@@ -203,6 +292,9 @@ func (t *cloudTunnel) connect(cr *config.ClusterRegistry, conn *websocket.Conn)
 
 // handler for child cluster controller
 func (t *cloudTunnel) accessHandler(w http.ResponseWriter, r *http.Request) {
+	if t.isClosing(w) {
+		return
+	}
 	// redirect to another server if it is specified
 	redirectAddr := t.redirect()
 	if redirectAddr != "" {
@@ -214,6 +306,13 @@ func (t *cloudTunnel) accessHandler(w http.ResponseWriter, r *http.Request) {
 
 	cluster := mux.Vars(r)[accessURIParam]
 
+	// check cluster name with client certificate if it is presented.
+	if err := checkClusterCertificate(r, cluster); err != nil {
+		klog.V(1).Infof("cluster %s certificate check failed: %v", cluster, err)
+		http.Error(w, "certificate does not match cluster name", http.StatusForbidden)
+		return
+	}
+
 	// get cluster listen addr from header.
 	// TODO if listen addr is duplicated, refuse to connect.
 	listenAddr := r.Header.Get(config.ClusterConnectHeaderListenAddr)
@@ -230,6 +329,14 @@ func (t *cloudTunnel) accessHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// get labels of the child
+	clusterLabels, err := labels.ConvertSelectorToLabelsMap(r.Header.Get(config.ClusterConnectHeaderLabels))
+	if err != nil {
+		klog.V(1).Infof("cluster %s labels are invalid: %v", cluster, err)
+		http.Error(w, "labels are invalid: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	_, ok := t.clients.Load(cluster)
 	if ok {
 		klog.V(1).Infof("cluster %s is already connected", cluster)
@@ -243,6 +350,15 @@ func (t *cloudTunnel) accessHandler(w http.ResponseWriter, r *http.Request) {
 		Listen:         listenAddr,
 		Time:           time.Now().Unix(),
 	}
+	if len(clusterLabels) > 0 {
+		cr.Labels = clusterLabels
+	}
+
+	if err := t.authenticate(tokenFromRequest(r), &cr); err != nil {
+		klog.V(1).Infof("cluster %s authenticate failed: %v", cluster, err)
+		http.Error(w, "authenticate failed: "+err.Error(), http.StatusUnauthorized)
+		return
+	}
 
 	if !t.clusterNameCheck(&cr) {
 		klog.V(1).Infof("cluster %s has been registered", cluster)
@@ -250,17 +366,23 @@ func (t *cloudTunnel) accessHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	conn, err := upgrader.Upgrade(w, r, nil)
+	respHeader := http.Header{}
+	session := t.sessions.negotiate(cluster, r, respHeader)
+	codec := negotiateCompression(r, respHeader)
+	wsclient, err := AcceptClient(t.transport, cr.Name, w, r, respHeader)
 	if err != nil {
 		klog.Errorf("connect to cluster %s failed: %s", cluster, err.Error())
-		http.Error(w, "fail to upgrade to websocket", http.StatusInternalServerError)
+		http.Error(w, "fail to accept connection", http.StatusInternalServerError)
 		return
 	}
-
-	go t.connect(&cr, conn)
+	wsclient.codec = codec
+	go t.connect(&cr, wsclient, session)
 }
 
 func (t *cloudTunnel) controllerHandler(w http.ResponseWriter, r *http.Request) {
+	if t.isClosing(w) {
+		return
+	}
 	// redirect to another server if it is specified
 	redirectAddr := t.redirect()
 	if redirectAddr != "" {
@@ -270,28 +392,48 @@ func (t *cloudTunnel) controllerHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	conn, err := upgrader.Upgrade(w, r, nil)
+	if err := t.authenticateManager(tokenFromRequest(r)); err != nil {
+		klog.V(1).Infof("controller %s authenticate failed: %v", r.RemoteAddr, err)
+		http.Error(w, "authenticate failed: "+err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	respHeader := http.Header{}
+	session := t.sessions.negotiate(controllerURI, r, respHeader)
+	codec := negotiateCompression(r, respHeader)
+	wsclient, err := AcceptClient(t.transport, r.RemoteAddr, w, r, respHeader)
 	if err != nil {
 		klog.Errorf("connect to controller %s failed: %s", r.RemoteAddr, err.Error())
-		http.Error(w, "fail to upgrade to websocket", http.StatusInternalServerError)
+		http.Error(w, "fail to accept connection", http.StatusInternalServerError)
 		return
 	}
-	wsclient := NewWSClient(r.RemoteAddr, conn)
-	_, ok := t.controllers.LoadOrStore(r.RemoteAddr, wsclient)
-	if ok {
-		klog.Infof("controller %s is already connected", r.RemoteAddr)
+	wsclient.codec = codec
+	// controller is a member of the shard ring by its id, or remote address if it has no id.
+	member := r.Header.Get(config.ClusterConnectHeaderControllerID)
+	if member == "" {
+		member = r.RemoteAddr
+	}
+	if session != nil {
+		if err := session.attach(wsclient); err != nil {
+			klog.Errorf("retransmit to controller %s failed: %v", r.RemoteAddr, err)
+		}
+	}
+	if !t.controllers.add(member, wsclient) {
+		klog.Infof("controller %s is already connected", member)
 		if err := wsclient.Close(); err != nil {
 			klog.Errorf("close websocket connection failed: %s", err.Error())
 		}
+		if session != nil {
+			t.sessions.release(session, wsclient)
+		}
 		return
 	}
-	klog.Infof("controller %s is connected", r.RemoteAddr)
-	t.controllersKey = append(t.controllersKey, r.RemoteAddr)
+	klog.Infof("controller %s(%s) is connected", member, r.RemoteAddr)
 	// root cluster controller get msg from controllers and publish to clusters
-	go t.handleControlMsg(wsclient)
+	go t.handleControlMsg(member, wsclient)
 }
 
-func (t *cloudTunnel) handleControlMsg(client *WSClient) {
+func (t *cloudTunnel) handleControlMsg(member string, client *WSClient) {
 	if client == nil {
 		return
 	}
@@ -309,16 +451,65 @@ func (t *cloudTunnel) handleControlMsg(client *WSClient) {
 	klog.Infof("cluster %s is disconnected", client.Name)
 
 	// close websocket.
-	t.controllers.Delete(client.Name)
-	t.controllersKey = removeFromSliceByValue(t.controllersKey, client.Name)
+	t.controllers.remove(member, client)
 	client.Close()
+	if client.session != nil {
+		t.sessions.release(client.session, client)
+	}
+}
+
+// isClosing answers the request with 503 if the tunnel is shutting down.
+func (t *cloudTunnel) isClosing(w http.ResponseWriter) bool {
+	if atomic.LoadInt32(&t.closing) == 0 {
+		return false
+	}
+	http.Error(w, "cloud tunnel is shutting down", http.StatusServiceUnavailable)
+	return true
 }
 
 func (t *cloudTunnel) Stop() error {
 	// gradeful stop cloudtunnel.
 	ctx, cancel := context.WithTimeout(context.Background(), StopTimeout)
 	defer cancel()
-	return t.server.Shutdown(ctx)
+	return t.Shutdown(ctx)
+}
+
+func (t *cloudTunnel) Shutdown(ctx context.Context) error {
+	atomic.StoreInt32(&t.closing, 1)
+
+	// tell childs and controller managers to fail over before closing, so they do not wait for this one.
+	var clients []*WSClient
+	t.clients.Range(func(key, value interface{}) bool {
+		if client, ok := value.(*WSClient); ok {
+			clients = append(clients, client)
+		}
+		return true
+	})
+	clients = append(clients, t.controllers.all()...)
+	notice := goingAwayMessage("")
+	var wg sync.WaitGroup
+	for _, client := range clients {
+		wg.Add(1)
+		go func(client *WSClient) {
+			defer wg.Done()
+			if err := client.WriteMessage(notice); err != nil {
+				klog.V(1).Infof("send going away to %s failed: %v", client.Name, err)
+			}
+			client.Close()
+		}(client)
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+	klog.Infof("cloud tunnel closed %d connections", len(clients))
+
+	return t.transport.Shutdown(ctx)
 }
 
 func (t *cloudTunnel) Start() error {
@@ -328,12 +519,14 @@ func (t *cloudTunnel) Start() error {
 	router.HandleFunc(uri, t.accessHandler)
 	// add handler for ote controller manager
 	router.HandleFunc(controllerURI, t.controllerHandler)
+	for path, fn := range t.httpHandlers {
+		router.HandleFunc(path, t.authorized(fn)).Methods(http.MethodGet)
+	}
 
 	ln, err := net.Listen("tcp", t.address)
 	if err != nil {
 		return err
 	}
-
 	t.server = &http.Server{
 		Addr:         ln.Addr().String(),
 		Handler:      router,
@@ -343,7 +536,7 @@ func (t *cloudTunnel) Start() error {
 	}
 
 	go func() {
-		if err := t.server.Serve(ln); err != nil {
+		if err := t.transport.Serve(t.server, ln); err != nil {
 			klog.Fatalf("fail to start cloudtunnel: %s", err.Error())
 		}
 	}()
@@ -351,43 +544,25 @@ func (t *cloudTunnel) Start() error {
 	return nil
 }
 
-func defaultClusterNameChecker(cr *config.ClusterRegistry) bool {
-	return true
-}
-
-func defaultAfterConnectHook(cr *config.ClusterRegistry) {}
-
-func defaultControlMsgHandler(remote string, msg []byte) error {
+func defaultClusterAuthenticator(token string, cr *config.ClusterRegistry) error {
 	return nil
 }
 
-func removeFromSliceByValue(slice []string, s string) []string {
-	n := -1
-	for i, v := range slice {
-		if v == s {
-			n = i
-			break
-		}
+// tokenFromRequest gets bearer token from authorization header of the request.
+func tokenFromRequest(r *http.Request) string {
+	auth := r.Header.Get(config.ClusterConnectHeaderAuthorization)
+	if !strings.HasPrefix(auth, bearerTokenPrefix) {
+		return ""
 	}
-	if n < 0 {
-		klog.Warningf("not found %s in slice %v", s, slice)
-		return slice
-	}
-	return append(slice[:n], slice[n+1:]...)
+	return strings.TrimPrefix(auth, bearerTokenPrefix)
 }
 
-func findAController(clientMap sync.Map, clientKey []string) *WSClient {
-	if clientKey == nil || len(clientKey) == 0 {
-		klog.Errorf("client map and key are empty to find a controller")
-		return nil
-	}
+func defaultClusterNameChecker(cr *config.ClusterRegistry) bool {
+	return true
+}
 
-	clientName := clientKey[rand.Intn(len(clientKey))]
-	client, ok := clientMap.Load(clientName)
-	if ok {
-		return client.(*WSClient)
-	}
+func defaultAfterConnectHook(cr *config.ClusterRegistry) {}
 
-	klog.Warningf("client %s is in client key but has no client(%v)", clientName, clientMap)
-	return findAController(clientMap, removeFromSliceByValue(clientKey, clientName))
+func defaultControlMsgHandler(remote string, msg []byte) error {
+	return nil
 }
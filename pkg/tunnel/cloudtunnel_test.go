@@ -22,10 +22,10 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
-	"sync"
 	"testing"
 	"time"
 
+	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
 
@@ -36,35 +36,8 @@ var (
 	ipAddrForTest = &net.IPAddr{[]byte{}, ""}
 )
 
-func TestRemoveFromSliceByValue(t *testing.T) {
-	slice := []string{"a", "b", "c", "d"}
-	// value not in slice
-	r := removeFromSliceByValue(slice, "e")
-	assert.ElementsMatch(t, slice, r)
-	// value in slice
-	r = removeFromSliceByValue(slice, "c")
-	assert.ElementsMatch(t, r, []string{"a", "b", "d"})
-}
-
-func TestFindAController(t *testing.T) {
-	clientMap := sync.Map{}
-	var clientKey []string
-	// clientKey is empty
-	ws := findAController(clientMap, clientKey)
-	assert.Nil(t, ws)
-	// find one in clientKey, but not in clientMap, finally find nothing
-	clientKey = append(clientKey, "a")
-	ws = findAController(clientMap, clientKey)
-	assert.Nil(t, ws)
-	oWs := &WSClient{}
-	clientMap.Store("a", oWs)
-	ws = findAController(clientMap, clientKey)
-	assert.NotNil(t, ws)
-	assert.Equal(t, oWs, ws)
-}
-
 func TestListenedCloudTunnel(t *testing.T) {
-	ctInter := NewCloudTunnel("")
+	ctInter := NewCloudTunnel("", nil)
 	ct := ctInter.(*cloudTunnel)
 	err := ct.Start()
 	addr := ct.server.Addr
@@ -123,6 +96,79 @@ func TestAccessHandler(t *testing.T) {
 	assert.Equal(t, "http://"+redirectAddr, l[0])
 }
 
+func TestAccessHandlerAuthenticate(t *testing.T) {
+	ct := NewCloudTunnel("", nil).(*cloudTunnel)
+	ct.RegistAuthenticateFunc(func(token string, cr *config.ClusterRegistry) error {
+		if token != "abcdef.0123456789abcdef" {
+			return fmt.Errorf("invalid token")
+		}
+		return nil
+	})
+	checked := false
+	ct.RegistCheckNameValidFunc(func(cr *config.ClusterRegistry) bool {
+		checked = true
+		return false
+	})
+	router := mux.NewRouter()
+	router.HandleFunc(fmt.Sprintf(accessURIPattern, accessURIParam), ct.accessHandler)
+
+	newRequest := func(token string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "http://origin"+accessURI+"c1", nil)
+		r.Header.Add(config.ClusterConnectHeaderListenAddr, "fake")
+		r.Header.Add(config.ClusterConnectHeaderUserDefineName, "c1")
+		if token != "" {
+			r.Header.Add(config.ClusterConnectHeaderAuthorization, bearerTokenPrefix+token)
+		}
+		return r
+	}
+
+	// no token
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, newRequest(""))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.False(t, checked)
+	// wrong token
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, newRequest("abcdef.aaaaaaaaaaaaaaaa"))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.False(t, checked)
+	// valid token, then check cluster name
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, newRequest("abcdef.0123456789abcdef"))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.True(t, checked)
+}
+
+func TestAccessHandlerLabels(t *testing.T) {
+	ct := NewCloudTunnel("", nil).(*cloudTunnel)
+	var registered *config.ClusterRegistry
+	ct.RegistCheckNameValidFunc(func(cr *config.ClusterRegistry) bool {
+		registered = cr
+		return false
+	})
+	router := mux.NewRouter()
+	router.HandleFunc(fmt.Sprintf(accessURIPattern, accessURIParam), ct.accessHandler)
+
+	newRequest := func(labels string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "http://origin"+accessURI+"c1", nil)
+		r.Header.Add(config.ClusterConnectHeaderListenAddr, "fake")
+		r.Header.Add(config.ClusterConnectHeaderUserDefineName, "c1")
+		r.Header.Add(config.ClusterConnectHeaderLabels, labels)
+		return r
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, newRequest("region=north,tier=edge"))
+	assert.NotNil(t, registered)
+	assert.Equal(t, map[string]string{"region": "north", "tier": "edge"}, registered.Labels)
+
+	registered = nil
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, newRequest("region=north,=edge"))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Nil(t, registered)
+}
+
 func TestControllerHandler(t *testing.T) {
 	// redirect to leader
 	redirectAddr := "redirect"
@@ -139,8 +185,37 @@ func TestControllerHandler(t *testing.T) {
 	assert.Equal(t, "http://"+redirectAddr, l[0])
 }
 
+func TestControllerHandlerAuthenticate(t *testing.T) {
+	ct := NewCloudTunnel("", nil).(*cloudTunnel)
+	ct.RegistControllerManagerAuthenticateFunc(func(token string) error {
+		if token != "abcdef.0123456789abcdef" {
+			return fmt.Errorf("invalid token")
+		}
+		return nil
+	})
+	newRequest := func(token string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "http://origin"+controllerURI, nil)
+		if token != "" {
+			r.Header.Add(config.ClusterConnectHeaderAuthorization, bearerTokenPrefix+token)
+		}
+		return r
+	}
+
+	// unauthenticated controller is rejected before connected
+	w := httptest.NewRecorder()
+	ct.controllerHandler(w, newRequest(""))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w = httptest.NewRecorder()
+	ct.controllerHandler(w, newRequest("abcdef.aaaaaaaaaaaaaaaa"))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	// valid token, then it is not a websocket request
+	w = httptest.NewRecorder()
+	ct.controllerHandler(w, newRequest("abcdef.0123456789abcdef"))
+	assert.NotEqual(t, http.StatusUnauthorized, w.Code)
+}
+
 func TestHandleReceieveMsg(t *testing.T) {
-	ctInter := NewCloudTunnel("")
+	ctInter := NewCloudTunnel("", nil)
 	ct := ctInter.(*cloudTunnel)
 	err := ct.Start()
 	addr := ct.server.Addr
@@ -0,0 +1,249 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"k8s.io/klog"
+
+	"github.com/baidu/ote-stack/pkg/config"
+)
+
+/*
+Compression of tunnels is negotiated per connection, and offered by the dialer as set by Compression.
+
+With deflate, messages are compressed by the transport,
+that is permessage-deflate of websocket or gzip of grpc.
+
+With zstd, messages are compressed by WSClient with a dictionary shared by both ends,
+which is trained on EdgeReport messages of k8s objects, so small messages are compressed well too.
+The dialer posts id of its dictionary in header, and the server answers the same value
+if its dictionary has the same id, otherwise the link works without zstd.
+Each message is prefixed by a byte of its encoding once zstd is negotiated.
+*/
+
+// Compression* are the compression algorithms of tunnels, set by Compression.
+const (
+	CompressionNone    = "none"
+	CompressionDeflate = "deflate"
+	CompressionZstd    = "zstd"
+
+	encodingRaw  byte = 0
+	encodingZstd byte = 1
+
+	// messages shorter than it are not worth compressing.
+	minCompressLength = 64
+	// max size of a decompressed message.
+	maxDecompressedSize = 1 << 30
+
+	zstdDictMagic = 0xEC30A437
+	zstdDictParam = "dict"
+)
+
+var (
+	// Compression is the compression offered by the dialer,
+	// a server accepts any compression it supports.
+	Compression = CompressionNone
+	// CompressionDictFile is the zstd dictionary file, the builtin dictionary is used if it is empty.
+	// Both ends of a tunnel must use the same dictionary for zstd.
+	CompressionDictFile = ""
+
+	zstdCodecOnce   sync.Once
+	sharedZstdCodec *messageCodec
+	zstdCodecErr    error
+)
+
+// messageCodec compresses messages of a link with zstd and a dictionary.
+type messageCodec struct {
+	dictID  uint32
+	encoder *zstd.Encoder
+	decoder *zstd.Decoder
+}
+
+// newMessageCodec returns a zstd codec with dict in format of zstd dictionary.
+func newMessageCodec(dict []byte) (*messageCodec, error) {
+	if len(dict) < 8 || binary.LittleEndian.Uint32(dict[:4]) != zstdDictMagic {
+		return nil, fmt.Errorf("invalid zstd dictionary")
+	}
+	encoder, err := zstd.NewWriter(nil, zstd.WithEncoderDict(dict))
+	if err != nil {
+		return nil, err
+	}
+	decoder, err := zstd.NewReader(nil, zstd.WithDecoderDicts(dict), zstd.WithDecoderMaxMemory(maxDecompressedSize))
+	if err != nil {
+		encoder.Close()
+		return nil, err
+	}
+	return &messageCodec{
+		dictID:  binary.LittleEndian.Uint32(dict[4:8]),
+		encoder: encoder,
+		decoder: decoder,
+	}, nil
+}
+
+// loadCompression checks Compression, and loads the zstd codec if it is offered.
+func loadCompression() error {
+	switch Compression {
+	case CompressionNone, CompressionDeflate, "":
+		return nil
+	case CompressionZstd:
+		_, err := zstdCodec()
+		return err
+	default:
+		return fmt.Errorf("unknown tunnel compression %s", Compression)
+	}
+}
+
+// zstdCodec returns the zstd codec with dictionary of CompressionDictFile, it is loaded once.
+func zstdCodec() (*messageCodec, error) {
+	zstdCodecOnce.Do(func() {
+		dict := builtinZstdDict
+		if CompressionDictFile != "" {
+			dict, zstdCodecErr = ioutil.ReadFile(CompressionDictFile)
+			if zstdCodecErr != nil {
+				return
+			}
+		}
+		sharedZstdCodec, zstdCodecErr = newMessageCodec(dict)
+		if zstdCodecErr != nil {
+			zstdCodecErr = fmt.Errorf("load zstd dictionary failed: %v", zstdCodecErr)
+		}
+	})
+	return sharedZstdCodec, zstdCodecErr
+}
+
+// value returns the value of compression header to negotiate the codec.
+func (c *messageCodec) value() string {
+	return fmt.Sprintf("%s; %s=%d", CompressionZstd, zstdDictParam, c.dictID)
+}
+
+// encode compresses msg, it is kept raw if compression does not save.
+// A nil codec returns msg as is.
+func (c *messageCodec) encode(msg []byte) []byte {
+	if c == nil {
+		return msg
+	}
+	if len(msg) >= minCompressLength {
+		compressed := c.encoder.EncodeAll(msg, []byte{encodingZstd})
+		if len(compressed) <= len(msg) {
+			return compressed
+		}
+	}
+	return append([]byte{encodingRaw}, msg...)
+}
+
+// decode decompresses data encoded by the peer.
+// A nil codec returns data as is.
+func (c *messageCodec) decode(data []byte) ([]byte, error) {
+	if c == nil {
+		return data, nil
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("message without encoding")
+	}
+	switch data[0] {
+	case encodingRaw:
+		return data[1:], nil
+	case encodingZstd:
+		return c.decoder.DecodeAll(data[1:], nil)
+	default:
+		return nil, fmt.Errorf("unknown message encoding %d", data[0])
+	}
+}
+
+// offerCompression adds compression header to header of the dialer if zstd is offered.
+func offerCompression(header http.Header) {
+	if Compression != CompressionZstd {
+		return
+	}
+	codec, err := zstdCodec()
+	if err != nil {
+		klog.Errorf("tunnel is connected without compression: %v", err)
+		return
+	}
+	header.Set(config.ClusterConnectHeaderCompression, codec.value())
+}
+
+// negotiateCompression returns the zstd codec if it is offered by r with the same dictionary,
+// and answers it in respHeader. It returns nil if zstd is not used.
+func negotiateCompression(r *http.Request, respHeader http.Header) *messageCodec {
+	offer := r.Header.Get(config.ClusterConnectHeaderCompression)
+	if offer == "" {
+		return nil
+	}
+	dictID, ok := parseCompression(offer)
+	if !ok {
+		klog.Warningf("unsupported compression %q from %s", offer, r.RemoteAddr)
+		return nil
+	}
+	codec, err := zstdCodec()
+	if err != nil {
+		klog.Errorf("refuse compression from %s: %v", r.RemoteAddr, err)
+		return nil
+	}
+	if codec.dictID != dictID {
+		klog.Warningf("refuse compression from %s: dictionary %d is different from %d",
+			r.RemoteAddr, dictID, codec.dictID)
+		return nil
+	}
+	respHeader.Set(config.ClusterConnectHeaderCompression, codec.value())
+	return codec
+}
+
+// acceptedCompression returns the zstd codec if the server answers it in resp.
+func acceptedCompression(resp *http.Response) *messageCodec {
+	if resp == nil || Compression != CompressionZstd {
+		return nil
+	}
+	dictID, ok := parseCompression(resp.Header.Get(config.ClusterConnectHeaderCompression))
+	if !ok {
+		return nil
+	}
+	codec, err := zstdCodec()
+	if err != nil || codec.dictID != dictID {
+		return nil
+	}
+	return codec
+}
+
+// parseCompression returns the dictionary id of compression header value like "zstd; dict=123".
+func parseCompression(value string) (uint32, bool) {
+	parts := strings.Split(value, ";")
+	if strings.TrimSpace(parts[0]) != CompressionZstd {
+		return 0, false
+	}
+	for _, param := range parts[1:] {
+		kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+		if len(kv) != 2 || kv[0] != zstdDictParam {
+			continue
+		}
+		id, err := strconv.ParseUint(kv[1], 10, 32)
+		if err != nil {
+			return 0, false
+		}
+		return uint32(id), true
+	}
+	return 0, false
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/baidu/ote-stack/pkg/config"
+)
+
+func fakePodsReport(n int) []byte {
+	pods := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		pods = append(pods, fmt.Sprintf(`"default/nginx-%d":{"kind":"Pod","apiVersion":"v1",`+
+			`"metadata":{"name":"nginx-%d","namespace":"default","labels":{"app":"nginx"}},`+
+			`"status":{"phase":"Running","podIP":"10.0.0.%d"}}`, i, i, i%256))
+	}
+	return []byte(`{"UpdateMap":{` + strings.Join(pods, ",") + `}}`)
+}
+
+func TestMessageCodec(t *testing.T) {
+	codec, err := newMessageCodec(builtinZstdDict)
+	assert.Nil(t, err)
+
+	// large message is compressed.
+	msg := fakePodsReport(10)
+	data := codec.encode(msg)
+	assert.Equal(t, encodingZstd, data[0])
+	assert.True(t, len(data) < len(msg)/2)
+	decoded, err := codec.decode(data)
+	assert.Nil(t, err)
+	assert.Equal(t, msg, decoded)
+
+	// small message is kept raw.
+	data = codec.encode([]byte("small"))
+	assert.Equal(t, append([]byte{encodingRaw}, "small"...), data)
+	decoded, err = codec.decode(data)
+	assert.Nil(t, err)
+	assert.Equal(t, "small", string(decoded))
+
+	// invalid message.
+	_, err = codec.decode(nil)
+	assert.NotNil(t, err)
+	_, err = codec.decode([]byte{9, 1})
+	assert.NotNil(t, err)
+
+	// nil codec keeps message as is.
+	var none *messageCodec
+	assert.Equal(t, msg, none.encode(msg))
+	decoded, err = none.decode(msg)
+	assert.Nil(t, err)
+	assert.Equal(t, msg, decoded)
+
+	// invalid dictionary.
+	_, err = newMessageCodec([]byte("not a dictionary"))
+	assert.NotNil(t, err)
+}
+
+func TestNegotiateCompression(t *testing.T) {
+	compression := Compression
+	defer func() {
+		Compression = compression
+	}()
+	codec, err := zstdCodec()
+	assert.Nil(t, err)
+
+	// nothing offered without zstd.
+	Compression = CompressionDeflate
+	header := http.Header{}
+	offerCompression(header)
+	assert.Empty(t, header.Get(config.ClusterConnectHeaderCompression))
+
+	Compression = CompressionZstd
+	offerCompression(header)
+	assert.Equal(t, codec.value(), header.Get(config.ClusterConnectHeaderCompression))
+
+	// the same dictionary is accepted.
+	r := &http.Request{Header: header}
+	respHeader := http.Header{}
+	assert.Equal(t, codec, negotiateCompression(r, respHeader))
+	assert.Equal(t, codec, acceptedCompression(&http.Response{Header: respHeader}))
+
+	// different dictionary is refused.
+	r.Header = http.Header{}
+	r.Header.Set(config.ClusterConnectHeaderCompression, fmt.Sprintf("zstd; dict=%d", codec.dictID+1))
+	respHeader = http.Header{}
+	assert.Nil(t, negotiateCompression(r, respHeader))
+	assert.Empty(t, respHeader.Get(config.ClusterConnectHeaderCompression))
+	assert.Nil(t, acceptedCompression(&http.Response{Header: respHeader}))
+
+	// unknown compression is refused.
+	r.Header.Set(config.ClusterConnectHeaderCompression, "br")
+	assert.Nil(t, negotiateCompression(r, respHeader))
+
+	id, ok := parseCompression("zstd;dict=12")
+	assert.True(t, ok)
+	assert.Equal(t, uint32(12), id)
+	_, ok = parseCompression("zstd")
+	assert.False(t, ok)
+	_, ok = parseCompression("zstd; dict=abc")
+	assert.False(t, ok)
+
+	Compression = "unknown"
+	_, err = NewTransport(nil, nil)
+	assert.NotNil(t, err)
+}
+
+func TestCompressedTunnel(t *testing.T) {
+	transportName, compression := TransportName, Compression
+	defer func() {
+		TransportName, Compression = transportName, compression
+	}()
+
+	for _, transport := range []string{TransportWebsocket, TransportGRPC} {
+		for _, c := range []string{CompressionDeflate, CompressionZstd} {
+			TransportName, Compression = transport, c
+
+			received := make(chan []byte, 1)
+			ct := NewCloudTunnel("127.0.0.1:0", nil).(*cloudTunnel)
+			ct.RegistReturnMessageFunc(func(client string, msg []byte) error {
+				received <- msg
+				return nil
+			})
+			assert.Nil(t, ct.Start())
+
+			e := &edgeTunnel{
+				name:               "c1",
+				cloudAddr:          ct.server.Addr,
+				listenAddr:         "fake",
+				conf:               &config.ClusterControllerConfig{},
+				afterConnectToHook: func() {},
+			}
+			assert.Nil(t, e.connect(), "%s with %s", transport, c)
+			assert.Equal(t, c == CompressionZstd, e.wsclient.codec != nil)
+
+			msg := fakePodsReport(100)
+			assert.Nil(t, e.Send(msg))
+			select {
+			case m := <-received:
+				assert.Equal(t, msg, m)
+			case <-time.After(time.Second):
+				assert.Fail(t, "message is not received", "%s with %s", transport, c)
+			}
+
+			// bytes on wire are much less than the message.
+			stats := e.wsclient.Stats()
+			assert.Equal(t, uint64(1), stats.SentMessages)
+			assert.Equal(t, uint64(len(msg)), stats.SentBytes)
+			assert.True(t, stats.SentRatio() < 0.5, "%s with %s: %s", transport, c, stats)
+			v, ok := ct.clients.Load("c1")
+			assert.True(t, ok)
+			stats = v.(*WSClient).Stats()
+			assert.Equal(t, uint64(len(msg)), stats.ReceivedBytes)
+			assert.True(t, stats.ReceivedRatio() < 0.5, "%s with %s: %s", transport, c, stats)
+
+			e.wsclient.Close()
+			ct.Stop()
+		}
+	}
+}
@@ -17,21 +17,36 @@ limitations under the License.
 package tunnel
 
 import (
+	"crypto/tls"
 	"fmt"
+	"hash/fnv"
 	"net/http"
-	"net/url"
+	"os"
 	"sync"
-	"time"
 
 	proto "github.com/golang/protobuf/proto"
-	"github.com/gorilla/websocket"
+	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/klog"
 
 	"github.com/baidu/ote-stack/pkg/clustermessage"
+	"github.com/baidu/ote-stack/pkg/config"
 )
 
 var (
 	ControllerSendChanBufferSize = 1000
+	// ControllerReceiveWorkers is the number of goroutines handling received messages,
+	// messages of the same cluster are handled by the same one in order.
+	ControllerReceiveWorkers = 16
+
+	// ControllerToken is the bootstrap token of usage controller-manager presented to root cluster controller.
+	ControllerToken string
+
+	controllerReceiveBufferSize = 100
+)
+
+const (
+	// rootConnName is the name of the connection to root cluster controller in ReconnectStatuses.
+	rootConnName = "root-cluster-controller"
 )
 
 // ControllerTunnel is a iterface for controllerTunnel.
@@ -46,13 +61,23 @@ type ControllerTunnel interface {
 	// Regist registers receive message handler.
 	RegistReceiveMessageHandler(TunnelReadMessageFunc)
 	RegistAfterConnectToHook(fn AfterConnectToHook)
+	// Shard returns the clusters assigned to this controller manager by root cluster controller.
+	Shard() *Shard
+	// State returns state of the connection to root cluster controller.
+	State() ConnState
 }
 
 // controllerTunnel is responsible for communication with cloudTunnel.
 type controllerTunnel struct {
+	// id is the member name of this controller manager in the shard ring.
+	id              string
+	shard           *Shard
 	cloudAddr       string
 	originCloudAddr string // set to setting cloud addr when redirect to another
 	wsclient        *WSClient
+	transport       Transport
+	delivery        dialSession
+	reconnector     *Reconnector
 
 	receiveMessageHandler TunnelReadMessageFunc
 	afterConnectToHook    AfterConnectToHook
@@ -64,9 +89,21 @@ type controllerTunnel struct {
 }
 
 // NewControllerTunnel returns a new controllerTunnel object.
-func NewControllerTunnel(remoteAddr string) ControllerTunnel {
+// tlsConfig is used to dial with tls, controllerTunnel dials without tls if it is nil.
+// proxy is used to dial, proxy from environment is used if it is nil.
+// It returns nil if TransportName is unknown.
+func NewControllerTunnel(remoteAddr string, tlsConfig *tls.Config, proxy *ProxyConfig) ControllerTunnel {
+	transport, err := NewTransport(tlsConfig, proxy)
+	if err != nil {
+		klog.Errorf("new controller tunnel failed: %v", err)
+		return nil
+	}
+	hostname, _ := os.Hostname()
 	return &controllerTunnel{
+		id:        hostname + "_" + string(uuid.NewUUID()),
+		shard:     &Shard{},
 		cloudAddr: remoteAddr,
+		transport: transport,
 		receiveMessageHandler: func(client string, msg []byte) error {
 			fmt.Println(string(msg))
 			return nil
@@ -81,12 +118,26 @@ func NewControllerTunnel(remoteAddr string) ControllerTunnel {
 }
 
 func (e *controllerTunnel) connect() error {
-	u := url.URL{Scheme: "ws", Host: e.cloudAddr, Path: controllerURI}
+	if e.transport == nil {
+		transport, err := NewTransport(nil, nil)
+		if err != nil {
+			return err
+		}
+		e.transport = transport
+	}
 	header := http.Header{}
+	if e.id != "" {
+		header.Set(config.ClusterConnectHeaderControllerID, e.id)
+	}
+	if ControllerToken != "" {
+		header.Set(config.ClusterConnectHeaderAuthorization, bearerTokenPrefix+ControllerToken)
+	}
+
+	offerCompression(header)
+	e.delivery.header(controllerURI, header)
 
-	klog.Infof("connecting to cloudtunnel %s", u.String())
-	// TODO https connection.
-	conn, resp, err := websocket.DefaultDialer.Dial(u.String(), header)
+	klog.Infof("connecting to cloudtunnel %s", e.cloudAddr)
+	wsclient, resp, err := DialClient(e.transport, e.cloudAddr, e.cloudAddr, controllerURI, header)
 	if err != nil {
 		if resp != nil {
 			if resp.StatusCode == http.StatusFound {
@@ -106,7 +157,11 @@ func (e *controllerTunnel) connect() error {
 	}
 
 	// TODO gradeful new wsclient.
-	e.wsclient = NewWSClient(e.cloudAddr, conn)
+	wsclient.codec = acceptedCompression(resp)
+	if err := e.delivery.attach(resp, wsclient); err != nil {
+		klog.Errorf("retransmit to %s failed: %v", e.cloudAddr, err)
+	}
+	e.wsclient = wsclient
 
 	go e.afterConnectToHook()
 
@@ -138,43 +193,48 @@ func (e *controllerTunnel) RegistAfterConnectToHook(fn AfterConnectToHook) {
 	e.afterConnectToHook = fn
 }
 
+func (e *controllerTunnel) Shard() *Shard {
+	return e.shard
+}
+
 func (e *controllerTunnel) Stop() error {
 	//TODO: graceful stop.
 	return nil
 }
 
+func (e *controllerTunnel) State() ConnState {
+	if e.reconnector == nil {
+		return StateConnecting
+	}
+	return e.reconnector.State()
+}
+
 func (e *controllerTunnel) reconnect() {
-	for {
+	e.reconnector.Run(nil, func() error {
 		e.connectionHealthCond.L.Lock()
+		defer e.connectionHealthCond.Signal()
+		defer e.connectionHealthCond.L.Unlock()
 		e.connectionHealth = false
 		if err := e.connect(); err != nil {
 			// if it has be redirected, try the origin parent first
 			if e.originCloudAddr != "" {
-				// wait for leader elect
-				time.Sleep(1 * time.Second)
-
 				klog.Infof("reconnect to origin parent %s", e.originCloudAddr)
 				e.cloudAddr = e.originCloudAddr
 				e.originCloudAddr = ""
-				e.connectionHealthCond.L.Unlock()
-				e.connectionHealthCond.Signal()
-				continue
+				e.reconnector.Switch()
 			}
-			klog.Errorf("connect to %s failed, try again after %ds: %s",
-				e.cloudAddr, waitConnection, err.Error())
-			e.connectionHealthCond.L.Unlock()
-			e.connectionHealthCond.Signal()
-			time.Sleep(time.Duration(waitConnection) * time.Second)
-			continue
+			return err
 		}
 		e.connectionHealth = true
-		e.connectionHealthCond.L.Unlock()
-		e.connectionHealthCond.Signal()
-		break
-	}
+		return nil
+	})
 }
+
 func (e *controllerTunnel) Start() error {
-	if err := e.connect(); err != nil {
+	if e.reconnector == nil {
+		e.reconnector = NewReconnector(rootConnName, DefaultReconnectPolicy())
+	}
+	if err := e.reconnector.Connect(e.connect); err != nil {
 		return err
 	}
 	e.connectionHealth = true
@@ -188,6 +248,8 @@ func (e *controllerTunnel) Start() error {
 			klog.Errorf("connection to %s failed, try to reconnect", e.cloudAddr)
 
 			e.wsclient.Close()
+			e.delivery.release(e.wsclient)
+			e.reconnector.Disconnected(fmt.Errorf("disconnect from %s", e.cloudAddr))
 			e.reconnect()
 		}
 	}()
@@ -240,6 +302,26 @@ func (e *controllerTunnel) recoverAndReSend(resendData []byte) error {
 
 func (e *controllerTunnel) handleReceiveMessage() {
 	klog.V(1).Infof("start handle receive message")
+	name := e.wsclient.Name
+	n := ControllerReceiveWorkers
+	if n < 1 {
+		n = 1
+	}
+	workers := make([]chan []byte, n)
+	for i := range workers {
+		workers[i] = make(chan []byte, controllerReceiveBufferSize)
+		go func(msgs chan []byte) {
+			for msg := range msgs {
+				e.receiveMessageHandler(name, msg)
+			}
+		}(workers[i])
+	}
+	defer func() {
+		for _, w := range workers {
+			close(w)
+		}
+	}()
+
 	for {
 		msg, err := e.wsclient.ReadMessage()
 		if err != nil {
@@ -247,6 +329,37 @@ func (e *controllerTunnel) handleReceiveMessage() {
 			break
 		}
 
-		go e.receiveMessageHandler(e.wsclient.Name, msg)
+		cluster := ""
+		if head := clustermessage.ParseHead(msg); head != nil {
+			switch head.Command {
+			case clustermessage.CommandType_ShardAssign:
+				e.handleShardAssign(msg)
+				continue
+			case clustermessage.CommandType_GoingAway:
+				klog.Infof("root cluster controller %s is going away", e.cloudAddr)
+				return
+			}
+			cluster = head.ClusterName
+		}
+		h := fnv.New32a()
+		h.Write([]byte(cluster))
+		workers[h.Sum32()%uint32(len(workers))] <- msg
+	}
+}
+
+func (e *controllerTunnel) handleShardAssign(data []byte) {
+	msg := &clustermessage.ClusterMessage{}
+	assignment := &clustermessage.ShardAssignment{}
+	if err := msg.Deserialize(data); err != nil {
+		klog.Errorf("deserialize shard assignment failed: %v", err)
+		return
+	}
+	if err := proto.Unmarshal(msg.Body, assignment); err != nil {
+		klog.Errorf("deserialize shard assignment failed: %v", err)
+		return
+	}
+	if e.shard == nil {
+		e.shard = &Shard{}
 	}
+	e.shard.update(assignment)
 }
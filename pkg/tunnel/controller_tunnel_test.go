@@ -78,7 +78,7 @@ func TestControllerTunnelConnect(t *testing.T) {
 	assert.Nil(t, err)
 
 	redirectAddr := "redirect"
-	ctInter := NewCloudTunnel("")
+	ctInter := NewCloudTunnel("", nil)
 	ctInter.RegistRedirectFunc(func() string {
 		return redirectAddr
 	})
@@ -151,8 +151,8 @@ func TestControllerTunnelInterface(t *testing.T) {
 	testServerWillStop.Start()
 	// make a tunnel with 10 buffer size and 3 second reconnect interval, and connect it to server
 	ControllerSendChanBufferSize = 1
-	waitConnection = 1
-	tun := NewControllerTunnel(testServerWillStop.Listener.Addr().String()).(*controllerTunnel)
+	ReconnectBaseDelay = time.Second
+	tun := NewControllerTunnel(testServerWillStop.Listener.Addr().String(), nil, nil).(*controllerTunnel)
 	tun.Start()
 
 	// lock connection health cond
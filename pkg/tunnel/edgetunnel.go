@@ -18,12 +18,14 @@ package tunnel
 
 import (
 	"container/list"
+	"context"
 	"fmt"
 	"net/http"
-	"net/url"
+	"sync"
 	"time"
 
-	"github.com/gorilla/websocket"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/klog"
 
 	clusterrouter "github.com/baidu/ote-stack/pkg/clusterrouter"
@@ -31,22 +33,29 @@ import (
 )
 
 var (
-	waitConnection   = 1
 	blacklistSeconds = 10
 )
 
+const (
+	// parentConnName is the name of the connection to parent in ReconnectStatuses.
+	parentConnName = "parent"
+)
+
 // EdgeTunnel is a iterface for edgeTunnel.
 type EdgeTunnel interface {
 	// Start will start edgeTunnel.
 	Start() error
-	// Stop will close the connection of edgeTunnel.
-	Stop() error
+	// Stop tells parent this cluster is going away and closes the connection,
+	// it does not reconnect then. ctx limits how long to wait for the notice.
+	Stop(ctx context.Context) error
 	// Send sends binary message to websocket connection.
 	Send(msg []byte) error
 	// Regist registers receive message handler.
 	RegistReceiveMessageHandler(TunnelReadMessageFunc)
 	RegistAfterConnectToHook(fn AfterConnectToHook)
 	RegistAfterDisconnectHook(fn AfterDisconnectHook)
+	// State returns state of the connection to parent.
+	State() ConnState
 }
 
 // edgeTunnel is responsible for communication with cloudTunnel.
@@ -58,6 +67,18 @@ type edgeTunnel struct {
 	uuid            string
 	listenAddr      string
 	wsclient        *WSClient
+	transport       Transport
+	delivery        dialSession
+	reconnector     *Reconnector
+	// parentPreference is parsed from ParentPreference.
+	parentPreference map[string]int
+	// parentGoingAway is set if parent sends going away before disconnection.
+	parentGoingAway bool
+	// recovery keeps the original ancestors to return to after failing over.
+	recovery parentRecovery
+	stop     chan struct{}
+	stopInit sync.Once
+	stopOnce sync.Once
 
 	receiveMessageHandler TunnelReadMessageFunc
 	afterConnectToHook    AfterConnectToHook
@@ -81,16 +102,40 @@ func NewEdgeTunnel(conf *config.ClusterControllerConfig) EdgeTunnel {
 
 }
 
+// initDialer makes transport with tls and proxy config of edge tunnel.
+func (e *edgeTunnel) initDialer() error {
+	tlsConfig, err := NewClientTLSConfig(e.conf.TunnelCertFile, e.conf.TunnelKeyFile, e.conf.TunnelCAFile)
+	if err != nil {
+		return err
+	}
+	e.transport, err = NewTransport(tlsConfig, NewProxyConfig(e.conf))
+	return err
+}
+
 func (e *edgeTunnel) connect() error {
 	e.uuid = e.name
-	u := url.URL{Scheme: "ws", Host: e.cloudAddr, Path: accessURI + e.uuid}
+	if e.transport == nil {
+		transport, err := NewTransport(nil, NewProxyConfig(e.conf))
+		if err != nil {
+			return err
+		}
+		e.transport = transport
+	}
 	header := http.Header{}
 	header.Add(config.ClusterConnectHeaderListenAddr, e.listenAddr)
 	header.Add(config.ClusterConnectHeaderUserDefineName, e.name)
+	if e.conf != nil && e.conf.BootstrapToken != "" {
+		header.Add(config.ClusterConnectHeaderAuthorization, bearerTokenPrefix+e.conf.BootstrapToken)
+	}
+	if e.conf != nil && len(e.conf.ClusterLabels) > 0 {
+		header.Add(config.ClusterConnectHeaderLabels, labels.Set(e.conf.ClusterLabels).String())
+	}
 
-	klog.Infof("connecting to cloudtunnel %s", u.String())
-	// TODO https connection.
-	conn, resp, err := websocket.DefaultDialer.Dial(u.String(), header)
+	offerCompression(header)
+	e.delivery.header(e.name, header)
+
+	klog.Infof("connecting to cloudtunnel %s", e.cloudAddr)
+	wsclient, resp, err := DialClient(e.transport, e.uuid, e.cloudAddr, accessURI+e.uuid, header)
 	if err != nil {
 		if resp != nil {
 			if resp.StatusCode == http.StatusFound {
@@ -110,9 +155,16 @@ func (e *edgeTunnel) connect() error {
 	}
 
 	e.conf.ClusterName = e.uuid
+	clusterrouter.Router().SetName(e.uuid)
+	clusterrouter.Router().SetParentAddr(e.cloudAddr)
 
 	// TODO gradeful new wsclient.
-	e.wsclient = NewWSClient(e.uuid, conn)
+	wsclient.codec = acceptedCompression(resp)
+	if err := e.delivery.attach(resp, wsclient); err != nil {
+		klog.Errorf("retransmit to %s failed: %v", e.cloudAddr, err)
+	}
+	e.wsclient = wsclient
+	e.recovery.connected(wsclient, e.cloudAddr)
 
 	go e.afterConnectToHook()
 
@@ -143,54 +195,136 @@ func (e *edgeTunnel) RegistAfterDisconnectHook(fn AfterDisconnectHook) {
 	e.afterDisconnectHook = fn
 }
 
-func (e *edgeTunnel) Stop() error {
-	//TODO: graceful stop.
-	return nil
+func (e *edgeTunnel) stopChan() chan struct{} {
+	e.stopInit.Do(func() {
+		e.stop = make(chan struct{})
+	})
+	return e.stop
+}
+
+func (e *edgeTunnel) stopped() bool {
+	select {
+	case <-e.stopChan():
+		return true
+	default:
+		return false
+	}
+}
+
+func (e *edgeTunnel) Stop(ctx context.Context) error {
+	e.stopOnce.Do(func() {
+		close(e.stopChan())
+	})
+	client := e.wsclient
+	if client == nil {
+		return nil
+	}
+
+	// tell parent to remove this child at once.
+	sent := make(chan error, 1)
+	go func() {
+		sent <- client.WriteMessage(goingAwayMessage(e.name))
+	}()
+	select {
+	case err := <-sent:
+		if err != nil {
+			klog.Errorf("send going away to %s failed: %v", e.cloudAddr, err)
+		}
+	case <-ctx.Done():
+		klog.Errorf("send going away to %s failed: %v", e.cloudAddr, ctx.Err())
+	}
+	klog.Infof("close connection to %s", e.cloudAddr)
+	return client.Close()
+}
+
+func (e *edgeTunnel) State() ConnState {
+	if e.reconnector == nil {
+		return StateConnecting
+	}
+	return e.reconnector.State()
 }
 
 func (e *edgeTunnel) reconnect() {
-	for {
-		if err := e.connect(); err != nil {
-			// if it has be redirected, try the origin parent first
-			if e.originCloudAddr != "" {
-				// wait for leader elect
-				time.Sleep(1 * time.Second)
-
-				klog.Infof("reconnect to origin parent %s", e.originCloudAddr)
-				e.cloudAddr = e.originCloudAddr
-				e.originCloudAddr = ""
-				continue
-			}
-			// if disconnect to parent, choose a parent neighbor to connect.
-			if !e.chooseParentNeighbor() {
-				// wait and connect to current parent.
-				klog.Errorf("connect to %s failed, try again after %ds: %s",
-					e.cloudAddr, waitConnection, err.Error())
-				time.Sleep(time.Duration(waitConnection) * time.Second)
-			}
+	// a nearer ancestor recovered, return to it.
+	if addr := e.recovery.takeReturnTo(); addr != "" {
+		klog.Infof("return to recovered ancestor %s", addr)
+		e.cloudAddr = addr
+		e.originCloudAddr = ""
+		e.parentGoingAway = false
+		e.reconnector.Reset()
+	}
+	// parent going away is not dialed again until it is out of blacklist.
+	if e.parentGoingAway {
+		e.parentGoingAway = false
+		if e.chooseParentNeighbor() {
+			klog.Infof("parent is going away, connect to new parent %s", e.cloudAddr)
+			e.reconnector.Reset()
+		}
+	}
 
-			// connect to new parent immediately.
+	e.reconnector.Run(e.stopChan(), func() error {
+		err := e.connect()
+		if err == nil {
+			return nil
+		}
+		// if it has be redirected, try the origin parent first
+		if e.originCloudAddr != "" {
+			klog.Infof("reconnect to origin parent %s", e.originCloudAddr)
+			e.cloudAddr = e.originCloudAddr
+			e.originCloudAddr = ""
+			e.reconnector.Switch()
+			return err
+		}
+		// if disconnect to parent, choose a parent neighbor to connect at once,
+		// and backoff of the new parent starts from the base delay.
+		if e.chooseParentNeighbor() {
 			klog.Errorf("connect to new parent %s", e.cloudAddr)
-			continue
+			e.reconnector.Switch()
 		}
-		break
-	}
+		return err
+	})
 
 	// cloud address is not needed in black list after connecting to a parent.
 	defaultCloudBlackList.Clear()
 }
+
 func (e *edgeTunnel) Start() error {
-	if err := e.connect(); err != nil {
+	if err := e.initDialer(); err != nil {
 		return err
 	}
+	preference, err := ParseParentPreference(ParentPreference)
+	if err != nil {
+		return fmt.Errorf("invalid parent preference %q: %v", ParentPreference, err)
+	}
+	e.parentPreference = preference
+	if e.reconnector == nil {
+		e.reconnector = NewReconnector(parentConnName, DefaultReconnectPolicy())
+	}
+	if err := e.reconnector.Connect(e.connect); err != nil {
+		return err
+	}
+	if ParentRecoverInterval > 0 {
+		go wait.Until(e.recoverParent, ParentRecoverInterval, e.stopChan())
+	}
 
-	// TODO exit if name is duplicate.
 	go func() {
+		defer e.reconnector.Close()
 		for {
 			e.handleReceiveMessage()
 
+			e.recovery.connected(nil, "")
 			e.wsclient.Close()
+			e.delivery.release(e.wsclient)
+			if e.stopped() {
+				return
+			}
+			e.reconnector.Disconnected(fmt.Errorf("disconnect from %s", e.cloudAddr))
 			e.reconnect()
+			if e.stopped() {
+				// connected just before stopping.
+				e.wsclient.Close()
+				return
+			}
 		}
 	}()
 	return nil
@@ -207,6 +341,15 @@ func (e *edgeTunnel) handleReceiveMessage() {
 			klog.Errorf("read msg failed: %s", err.Error())
 			break
 		}
+		if isGoingAway(msg) {
+			klog.Warningf("parent %s is going away", e.cloudAddr)
+			e.parentGoingAway = true
+			break
+		}
+		if conflict := nameConflictOf(msg, e.name); conflict != nil {
+			e.handleNameConflict(conflict)
+			break
+		}
 
 		e.receiveMessageHandler(e.wsclient.Name, msg)
 	}
@@ -219,14 +362,17 @@ func (e *edgeTunnel) handleReceiveMessage() {
 func (e *edgeTunnel) chooseParentNeighbor() bool {
 	// push current parent to blacklist.
 	defaultCloudBlackList.Push(e.cloudAddr)
-	// find first parent neighbor not in blacklist.
+	// find the best parent neighbor not in blacklist.
 	var choose string
-	// TODO choose from parent neighbor or parent's parent.
-	for _, addr := range clusterrouter.Router().ParentNeighbors() {
-		if !defaultCloudBlackList.Find(addr) {
-			choose = addr
-			break
-		}
+	candidates := rankParents(clusterrouter.Router().ParentNeighbors(),
+		clusterrouter.Router().ParentNeighborLoads(), e.parentPreference, defaultCloudBlackList.Find)
+	if len(candidates) > 0 {
+		klog.Infof("parent neighbors to fail over to: %v", candidates)
+		choose = candidates[0].addr
+	}
+	// ancestors of parent are the last resort, the nearest first.
+	if choose == "" {
+		choose = nearestAncestor(clusterrouter.Router().AncestorChain(), defaultCloudBlackList.Find)
 	}
 	if choose == "" {
 		// pop from blacklist
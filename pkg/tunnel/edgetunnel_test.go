@@ -44,7 +44,7 @@ func TestConnect(t *testing.T) {
 	assert.Nil(t, err)
 
 	redirectAddr := "redirect"
-	ctInter := NewCloudTunnel("")
+	ctInter := NewCloudTunnel("", nil)
 	ctInter.RegistRedirectFunc(func() string {
 		return redirectAddr
 	})
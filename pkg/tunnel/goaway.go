@@ -0,0 +1,46 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"github.com/golang/protobuf/proto"
+
+	"github.com/baidu/ote-stack/pkg/clustermessage"
+)
+
+/*
+A cluster controller stopping gracefully sends GoingAway to its peers before closing connections.
+A child receiving it from parent puts the parent to blacklist and connects to a parent neighbor at once,
+instead of backing off and dialing the parent going away again.
+*/
+
+// goingAwayMessage returns the notice that cluster is going away.
+func goingAwayMessage(cluster string) []byte {
+	data, _ := proto.Marshal(&clustermessage.ClusterMessage{
+		Head: &clustermessage.MessageHead{
+			Command:     clustermessage.CommandType_GoingAway,
+			ClusterName: cluster,
+		},
+	})
+	return data
+}
+
+// isGoingAway checks if data is a going away notice.
+func isGoingAway(data []byte) bool {
+	head := clustermessage.ParseHead(data)
+	return head != nil && head.Command == clustermessage.CommandType_GoingAway
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	clusterrouter "github.com/baidu/ote-stack/pkg/clusterrouter"
+	"github.com/baidu/ote-stack/pkg/config"
+)
+
+func TestGoingAway(t *testing.T) {
+	defaultCloudBlackList.Clear()
+	defer defaultCloudBlackList.Clear()
+
+	ct1 := NewCloudTunnel("127.0.0.1:0", nil).(*cloudTunnel)
+	assert.Nil(t, ct1.Start())
+	ct2 := NewCloudTunnel("127.0.0.1:0", nil).(*cloudTunnel)
+	connected := make(chan string, 10)
+	closed := make(chan string, 10)
+	ct2.RegistAfterConnectHook(func(cr *config.ClusterRegistry) {
+		connected <- cr.Name
+	})
+	ct2.RegistClientCloseHandler(func(cr *config.ClusterRegistry) {
+		closed <- cr.Name
+	})
+	assert.Nil(t, ct2.Start())
+	defer ct2.Stop()
+
+	parents := clusterrouter.Router().ParentNeighbor
+	clusterrouter.Router().ParentNeighbor = map[string]string{
+		"p1": ct1.server.Addr,
+		"p2": ct2.server.Addr,
+	}
+	defer func() {
+		clusterrouter.Router().ParentNeighbor = parents
+	}()
+
+	e := NewEdgeTunnel(&config.ClusterControllerConfig{
+		ClusterUserDefineName: "c1",
+		ParentCluster:         ct1.server.Addr,
+		TunnelListenAddr:      "fake",
+	}).(*edgeTunnel)
+	assert.Nil(t, e.Start())
+	eventually(t, func() bool {
+		_, ok := ct1.clients.Load("c1")
+		return ok
+	}, time.Second, 10*time.Millisecond)
+
+	// child fails over to the parent neighbor at once once parent is going away.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.Nil(t, ct1.Shutdown(ctx))
+	select {
+	case name := <-connected:
+		assert.Equal(t, "c1", name)
+	case <-time.After(2 * time.Second):
+		t.Fatal("child does not fail over to parent neighbor")
+	}
+	assert.True(t, defaultCloudBlackList.Find(ct1.server.Addr))
+	eventually(t, func() bool {
+		return e.State() == StateConnected
+	}, time.Second, 10*time.Millisecond)
+
+	// parent removes child at once once child is going away, and child does not reconnect.
+	assert.Nil(t, e.Stop(ctx))
+	select {
+	case name := <-closed:
+		assert.Equal(t, "c1", name)
+	case <-time.After(time.Second):
+		t.Fatal("parent does not remove child going away")
+	}
+	time.Sleep(100 * time.Millisecond)
+	_, ok := ct2.clients.Load("c1")
+	assert.False(t, ok)
+}
+
+func TestCloudTunnelRejectWhileClosing(t *testing.T) {
+	ct := NewCloudTunnel("127.0.0.1:0", nil).(*cloudTunnel)
+	assert.Nil(t, ct.Shutdown(context.Background()))
+
+	w := httptest.NewRecorder()
+	ct.accessHandler(w, httptest.NewRequest(http.MethodGet, accessURI+"c1", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	w = httptest.NewRecorder()
+	ct.controllerHandler(w, httptest.NewRequest(http.MethodGet, controllerURI, nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
@@ -0,0 +1,440 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog"
+
+	"github.com/baidu/ote-stack/pkg/clustermessage"
+)
+
+/*
+grpcTransport carries messages by Connect stream of the Tunnel service, each message in a TunnelFrame.
+
+The connection request is sent as metadata of the stream with path in grpcPathKey,
+the server accepts it by sending header metadata with grpcStatusKey set to 101,
+or refuses it by ending the stream with status code and header of the answer in trailer metadata.
+*/
+
+const (
+	grpcPathKey   = "tunnel-path"
+	grpcStatusKey = "tunnel-status"
+
+	// grpcKeepaliveMinTime is the min ping interval the server permits,
+	// grpc clients never ping more frequently than 10s.
+	grpcKeepaliveMinTime = 5 * time.Second
+)
+
+// grpcStream is the common part of client and server stream.
+type grpcStream interface {
+	SendMsg(m interface{}) error
+	RecvMsg(m interface{}) error
+}
+
+// grpcConn is a connection over a grpc stream.
+type grpcConn struct {
+	stream    grpcStream
+	wire      *wireCounter
+	close     func()
+	closeOnce sync.Once
+}
+
+func (c *grpcConn) ReadMessage() ([]byte, error) {
+	frame := &clustermessage.TunnelFrame{}
+	if err := c.stream.RecvMsg(frame); err != nil {
+		return nil, err
+	}
+	return frame.Data, nil
+}
+
+func (c *grpcConn) WriteMessage(msg []byte) error {
+	return c.stream.SendMsg(&clustermessage.TunnelFrame{Data: msg})
+}
+
+func (c *grpcConn) wireCounter() *wireCounter {
+	return c.wire
+}
+
+func (c *grpcConn) Close() error {
+	c.closeOnce.Do(c.close)
+	return nil
+}
+
+// grpcAcceptance is the http.ResponseWriter of a connection request,
+// it records the answer unless the request is accepted.
+type grpcAcceptance struct {
+	stream clustermessage.Tunnel_ConnectServer
+	header http.Header
+	code   int
+	body   bytes.Buffer
+	// done is closed once the accepted connection is closed.
+	done chan struct{}
+}
+
+func (a *grpcAcceptance) Header() http.Header {
+	return a.header
+}
+
+func (a *grpcAcceptance) Write(data []byte) (int, error) {
+	if a.code == 0 {
+		a.code = http.StatusOK
+	}
+	return a.body.Write(data)
+}
+
+func (a *grpcAcceptance) WriteHeader(code int) {
+	if a.code == 0 {
+		a.code = code
+	}
+}
+
+// grpcTransport carries messages by grpc bidi stream over http2.
+type grpcTransport struct {
+	tlsConfig *tls.Config
+	proxy     *ProxyConfig
+	mutex     sync.Mutex
+	handler   http.Handler
+	server    *grpc.Server
+	listener  *countingListener
+	// mux dispatches connections of listener to server and httpServer.
+	mux        *protocolMux
+	httpServer *http.Server
+	shutdown   bool
+}
+
+func newGRPCTransport(tlsConfig *tls.Config, proxy *ProxyConfig) *grpcTransport {
+	return &grpcTransport{
+		tlsConfig: tlsConfig,
+		proxy:     proxy,
+	}
+}
+
+func heartbeatEnabled() bool {
+	return HeartbeatInterval > 0 && HeartbeatMissThreshold > 0
+}
+
+// dialOptions returns options to dial with, bytes of the network connection are counted by counter.
+func (t *grpcTransport) dialOptions(counter *wireCounter) []grpc.DialOption {
+	callOpts := []grpc.CallOption{grpc.MaxCallRecvMsgSize(int(maxFrameSize()))}
+	if Compression == CompressionDeflate {
+		// the server answers with the same compressor.
+		callOpts = append(callOpts, grpc.UseCompressor(gzip.Name))
+	}
+	opts := []grpc.DialOption{
+		grpc.WithBlock(),
+		// fail fast like websocket if the server is not reachable.
+		grpc.FailOnNonTempDialError(true),
+		grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			conn, err := t.proxy.dial(ctx, addr)
+			if err != nil {
+				return nil, err
+			}
+			return newCountingConn(conn, counter), nil
+		}),
+		grpc.WithDefaultCallOptions(callOpts...),
+	}
+	if t.tlsConfig != nil {
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(t.tlsConfig)))
+	} else {
+		opts = append(opts, grpc.WithInsecure())
+	}
+	if heartbeatEnabled() {
+		opts = append(opts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                HeartbeatInterval,
+			Timeout:             HeartbeatInterval * time.Duration(HeartbeatMissThreshold),
+			PermitWithoutStream: true,
+		}))
+	}
+	return opts
+}
+
+func (t *grpcTransport) Dial(addr, path string, header http.Header) (Conn, *http.Response, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), handshakeTimeout)
+	defer cancel()
+	counter := &wireCounter{}
+	cc, err := grpc.DialContext(ctx, addr, t.dialOptions(counter)...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	md := headerToMetadata(header)
+	md.Set(grpcPathKey, path)
+	streamCtx, streamCancel := context.WithCancel(metadata.NewOutgoingContext(context.Background(), md))
+	closeAll := func() {
+		streamCancel()
+		cc.Close()
+	}
+	stream, err := clustermessage.NewTunnelClient(cc).Connect(streamCtx)
+	if err != nil {
+		closeAll()
+		return nil, nil, err
+	}
+
+	// the server may not answer at all, give up after handshake timeout.
+	timer := time.AfterFunc(handshakeTimeout, streamCancel)
+	answer, err := stream.Header()
+	timer.Stop()
+	if err == nil {
+		resp := newGRPCResponse(addr, path, answer)
+		if resp.StatusCode == http.StatusSwitchingProtocols {
+			return &grpcConn{stream: stream, wire: counter, close: closeAll}, resp, nil
+		}
+	}
+
+	// the connection is refused, the answer is in trailer.
+	if err == nil {
+		_, err = stream.Recv()
+		if err == nil || err == io.EOF {
+			err = fmt.Errorf("tunnel to %s is closed by server", addr)
+		}
+	}
+	resp := newGRPCResponse(addr, path, stream.Trailer())
+	closeAll()
+	if resp.StatusCode == 0 {
+		return nil, nil, err
+	}
+	return nil, resp, err
+}
+
+func (t *grpcTransport) Accept(w http.ResponseWriter, r *http.Request, header http.Header) (Conn, error) {
+	a, ok := w.(*grpcAcceptance)
+	if !ok {
+		return nil, fmt.Errorf("request from %s is not a grpc tunnel request", r.RemoteAddr)
+	}
+	md := headerToMetadata(header)
+	md.Set(grpcStatusKey, strconv.Itoa(http.StatusSwitchingProtocols))
+	if err := a.stream.SendHeader(md); err != nil {
+		return nil, err
+	}
+	a.done = make(chan struct{})
+	t.mutex.Lock()
+	counter := t.listener.counter(r.RemoteAddr)
+	t.mutex.Unlock()
+	return &grpcConn{
+		stream: a.stream,
+		wire:   counter,
+		close:  func() { close(a.done) },
+	}, nil
+}
+
+// Connect implements clustermessage.TunnelServer,
+// it handles the connection request by handler of the http server, and blocks until the connection is closed.
+func (t *grpcTransport) Connect(stream clustermessage.Tunnel_ConnectServer) error {
+	ctx := stream.Context()
+	r, err := newGRPCRequest(ctx)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	a := &grpcAcceptance{
+		stream: stream,
+		header: http.Header{},
+	}
+	t.handler.ServeHTTP(a, r)
+	if a.done != nil {
+		select {
+		case <-a.done:
+		case <-ctx.Done():
+		}
+		return nil
+	}
+
+	if a.code == 0 {
+		a.code = http.StatusOK
+	}
+	md := headerToMetadata(a.header)
+	md.Set(grpcStatusKey, strconv.Itoa(a.code))
+	stream.SetTrailer(md)
+	return status.Error(httpStatusToCode(a.code), strings.TrimSpace(a.body.String()))
+}
+
+func (t *grpcTransport) Serve(server *http.Server, ln net.Listener) error {
+	opts := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(int(maxFrameSize())),
+	}
+	if t.tlsConfig != nil {
+		klog.Infof("grpc transport serves with tls")
+		// tls is handshaked by protocolMux.
+		opts = append(opts, grpc.Creds(muxedTLSCredentials{}))
+	}
+	if heartbeatEnabled() {
+		opts = append(opts,
+			grpc.KeepaliveParams(keepalive.ServerParameters{
+				Time:    HeartbeatInterval,
+				Timeout: HeartbeatInterval * time.Duration(HeartbeatMissThreshold),
+			}),
+			grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+				MinTime:             grpcKeepaliveMinTime,
+				PermitWithoutStream: true,
+			}))
+	}
+
+	t.mutex.Lock()
+	if t.shutdown {
+		t.mutex.Unlock()
+		return ln.Close()
+	}
+	t.handler = server.Handler
+	t.listener = newCountingListener(ln)
+	t.mux = newProtocolMux(t.listener, t.tlsConfig)
+	t.httpServer = server
+	t.server = grpc.NewServer(opts...)
+	clustermessage.RegisterTunnelServer(t.server, t)
+	grpcServer, mux := t.server, t.mux
+	t.mutex.Unlock()
+
+	// other http handlers of server are served by http/1.1 on the same listener.
+	go func() {
+		if err := server.Serve(mux.http); err != nil && err != http.ErrServerClosed {
+			klog.Errorf("serve http on %s failed: %v", mux.http.Addr(), err)
+		}
+	}()
+	go mux.serve()
+	return grpcServer.Serve(mux.grpc)
+}
+
+func (t *grpcTransport) Shutdown(ctx context.Context) error {
+	t.mutex.Lock()
+	server, httpServer, mux := t.server, t.httpServer, t.mux
+	t.shutdown = true
+	t.mutex.Unlock()
+
+	if server == nil {
+		return nil
+	}
+	defer mux.Close()
+	if err := httpServer.Shutdown(ctx); err != nil {
+		klog.Errorf("shutdown http server failed: %v", err)
+	}
+	// streams of tunnels never end by themselves, stop them if not closed in time.
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		server.Stop()
+		return ctx.Err()
+	}
+}
+
+// newGRPCRequest makes the connection request from metadata of the stream.
+func newGRPCRequest(ctx context.Context) (*http.Request, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	header := metadataToHeader(md)
+	path := header.Get(grpcPathKey)
+	if path == "" {
+		return nil, fmt.Errorf("%s is not specified", grpcPathKey)
+	}
+	header.Del(grpcPathKey)
+
+	r, err := http.NewRequest(http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	r = r.WithContext(ctx)
+	r.Header = header
+	if p, ok := peer.FromContext(ctx); ok {
+		r.RemoteAddr = p.Addr.String()
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			r.TLS = &info.State
+		}
+	}
+	return r, nil
+}
+
+// newGRPCResponse makes the answer of a connection request from metadata of the stream.
+func newGRPCResponse(addr, path string, md metadata.MD) *http.Response {
+	header := metadataToHeader(md)
+	code, _ := strconv.Atoi(header.Get(grpcStatusKey))
+	header.Del(grpcStatusKey)
+	return &http.Response{
+		StatusCode: code,
+		Status:     http.StatusText(code),
+		Header:     header,
+		// request is needed to resolve relative location of redirect.
+		Request: &http.Request{
+			Method: http.MethodGet,
+			URL:    &url.URL{Host: addr, Path: path},
+		},
+	}
+}
+
+func headerToMetadata(header http.Header) metadata.MD {
+	md := metadata.MD{}
+	for k, v := range header {
+		md.Append(k, v...)
+	}
+	return md
+}
+
+func metadataToHeader(md metadata.MD) http.Header {
+	header := http.Header{}
+	for k, v := range md {
+		// skip pseudo headers of http2.
+		if strings.HasPrefix(k, ":") {
+			continue
+		}
+		for _, value := range v {
+			header.Add(k, value)
+		}
+	}
+	return header
+}
+
+func httpStatusToCode(code int) codes.Code {
+	switch code {
+	case http.StatusFound:
+		return codes.Unavailable
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	}
+	if code >= http.StatusInternalServerError {
+		return codes.Internal
+	}
+	return codes.Unknown
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/baidu/ote-stack/pkg/clustermessage"
+	"github.com/baidu/ote-stack/pkg/config"
+)
+
+func TestGRPCTransport(t *testing.T) {
+	name := TransportName
+	TransportName = TransportGRPC
+	defer func() {
+		TransportName = name
+	}()
+
+	var mutex sync.Mutex
+	received := make([]string, 0)
+	ct := NewCloudTunnel("127.0.0.1:0", nil).(*cloudTunnel)
+	ct.RegistReturnMessageFunc(func(client string, msg []byte) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		received = append(received, client+":"+string(msg))
+		return nil
+	})
+	ct.RegistCheckNameValidFunc(func(cr *config.ClusterRegistry) bool {
+		return cr.Name != "bad"
+	})
+	assert.Nil(t, ct.Start())
+	defer ct.Stop()
+
+	// messages are carried in both directions.
+	e := &edgeTunnel{
+		name:               "c1",
+		cloudAddr:          ct.server.Addr,
+		listenAddr:         "fake",
+		conf:               &config.ClusterControllerConfig{},
+		afterConnectToHook: func() {},
+	}
+	assert.Nil(t, e.connect())
+	assert.Nil(t, e.Send([]byte("m1")))
+	eventually(t, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return len(received) == 1 && received[0] == "c1:m1"
+	}, time.Second, 10*time.Millisecond)
+	assert.Nil(t, ct.Send("c1", []byte("m2")))
+	msg, err := e.wsclient.ReadMessage()
+	assert.Nil(t, err)
+	assert.Equal(t, "m2", string(msg))
+
+	// a client generated from clustermessage.proto talks by TunnelFrame.
+	cc, err := grpc.Dial(ct.server.Addr, grpc.WithInsecure())
+	assert.Nil(t, err)
+	defer cc.Close()
+	ctx, cancel := context.WithCancel(metadata.NewOutgoingContext(context.Background(), metadata.Pairs(
+		grpcPathKey, accessURI+"c2",
+		config.ClusterConnectHeaderListenAddr, "fake",
+		config.ClusterConnectHeaderUserDefineName, "c2")))
+	defer cancel()
+	stream, err := clustermessage.NewTunnelClient(cc).Connect(ctx)
+	assert.Nil(t, err)
+	answer, err := stream.Header()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"101"}, answer.Get(grpcStatusKey))
+	assert.Nil(t, stream.Send(&clustermessage.TunnelFrame{Data: []byte("m3")}))
+	eventually(t, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return len(received) == 2 && received[1] == "c2:m3"
+	}, time.Second, 10*time.Millisecond)
+	assert.Nil(t, ct.Send("c2", []byte("m4")))
+	frame, err := stream.Recv()
+	assert.Nil(t, err)
+	assert.Equal(t, "m4", string(frame.Data))
+
+	// the child is disconnected once the connection is closed.
+	e.wsclient.Close()
+	eventually(t, func() bool {
+		_, ok := ct.clients.Load("c1")
+		return !ok
+	}, time.Second, 10*time.Millisecond)
+
+	// refused request is answered with http status.
+	transport, err := NewTransport(nil, nil)
+	assert.Nil(t, err)
+	header := http.Header{}
+	header.Set(config.ClusterConnectHeaderListenAddr, "fake")
+	header.Set(config.ClusterConnectHeaderUserDefineName, "bad")
+	conn, resp, err := transport.Dial(ct.server.Addr, accessURI+"bad", header)
+	assert.NotNil(t, err)
+	assert.Nil(t, conn)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	// redirect location is resolved.
+	ct.RegistRedirectFunc(func() string {
+		return "127.0.0.1:1"
+	})
+	conn, resp, err = transport.Dial(ct.server.Addr, controllerURI, http.Header{})
+	assert.NotNil(t, err)
+	assert.Nil(t, conn)
+	assert.Equal(t, http.StatusFound, resp.StatusCode)
+	location, err := resp.Location()
+	assert.Nil(t, err)
+	assert.Equal(t, "127.0.0.1:1", location.Host)
+}
+
+func TestGRPCTransportHTTPHandler(t *testing.T) {
+	name := TransportName
+	TransportName = TransportGRPC
+	defer func() {
+		TransportName = name
+	}()
+
+	ct := NewCloudTunnel("127.0.0.1:0", nil).(*cloudTunnel)
+	ct.RegistAuthenticateFunc(func(token string, cr *config.ClusterRegistry) error {
+		if token != "abcdef.0123456789abcdef" {
+			return fmt.Errorf("invalid token")
+		}
+		return nil
+	})
+	ct.RegistHTTPHandler("/hello", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello"))
+	})
+	assert.Nil(t, ct.Start())
+	defer ct.Stop()
+
+	// http handlers are served on the listener of grpc, behind authentication.
+	url := "http://" + ct.server.Addr + "/hello"
+	resp, err := http.Get(url)
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	r, err := http.NewRequest(http.MethodGet, url, nil)
+	assert.Nil(t, err)
+	r.Header.Set(config.ClusterConnectHeaderAuthorization, bearerTokenPrefix+"abcdef.0123456789abcdef")
+	resp, err = http.DefaultClient.Do(r)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := ioutil.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, "hello", string(body))
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"github.com/golang/protobuf/proto"
+	"k8s.io/klog"
+
+	"github.com/baidu/ote-stack/pkg/clustermessage"
+	"github.com/baidu/ote-stack/pkg/config"
+)
+
+/*
+A cluster whose name conflicts with another one in the tree is told by the ancestor finding it.
+It reconnects with the new name if one is given, otherwise it exits with the reason.
+*/
+
+// exitOnNameConflict exits the process once the cluster is rejected for its name.
+var exitOnNameConflict = func(err error) {
+	klog.Exitf("exit since %v", err)
+}
+
+// nameConflictOf returns the name conflict if data is the notice to cluster, otherwise nil.
+func nameConflictOf(data []byte, cluster string) *config.NameConflict {
+	head := clustermessage.ParseHead(data)
+	if head == nil || head.Command != clustermessage.CommandType_ClusterNameConflict ||
+		head.ClusterName != cluster {
+		return nil
+	}
+	msg := &clustermessage.ClusterMessage{}
+	if err := proto.Unmarshal(data, msg); err != nil {
+		klog.Errorf("deserialize name conflict failed: %v", err)
+		return nil
+	}
+	conflict, err := config.NameConflictDeserialize(msg.Body)
+	if err != nil {
+		klog.Errorf("deserialize name conflict failed: %v", err)
+		return nil
+	}
+	return conflict
+}
+
+// handleNameConflict renames edge tunnel or stops it by conflict.
+func (e *edgeTunnel) handleNameConflict(conflict *config.NameConflict) {
+	if conflict.NewName != "" {
+		klog.Warningf("%v, reconnect as %s", conflict, conflict.NewName)
+		e.name = conflict.NewName
+		return
+	}
+	e.stopOnce.Do(func() {
+		close(e.stopChan())
+	})
+	exitOnNameConflict(conflict)
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/baidu/ote-stack/pkg/config"
+)
+
+func nameConflictData(t *testing.T, conflict *config.NameConflict) []byte {
+	msg, err := conflict.WrapperToClusterMessage("root")
+	assert.Nil(t, err)
+	data, err := proto.Marshal(msg)
+	assert.Nil(t, err)
+	return data
+}
+
+func TestNameConflict(t *testing.T) {
+	exited := make(chan error, 1)
+	exit := exitOnNameConflict
+	exitOnNameConflict = func(err error) {
+		exited <- err
+	}
+	defer func() {
+		exitOnNameConflict = exit
+	}()
+
+	ct := NewCloudTunnel("127.0.0.1:0", nil).(*cloudTunnel)
+	connected := make(chan string, 10)
+	ct.RegistAfterConnectHook(func(cr *config.ClusterRegistry) {
+		connected <- cr.Name
+	})
+	assert.Nil(t, ct.Start())
+	defer ct.Stop()
+
+	e := NewEdgeTunnel(&config.ClusterControllerConfig{
+		ClusterUserDefineName: "c1",
+		ParentCluster:         ct.server.Addr,
+		TunnelListenAddr:      "fake",
+	}).(*edgeTunnel)
+	assert.Nil(t, e.Start())
+	assert.Equal(t, "c1", <-connected)
+
+	// notice to another cluster is passed to handler.
+	assert.Nil(t, nameConflictOf(nameConflictData(t, &config.NameConflict{Name: "c2"}), "c1"))
+	assert.Nil(t, nameConflictOf(goingAwayMessage("c1"), "c1"))
+
+	// reconnect with the new name.
+	assert.Nil(t, ct.Send("c1", nameConflictData(t, &config.NameConflict{
+		Name:    "c1",
+		Policy:  config.DuplicateNameAutoSuffix,
+		NewName: "c1-1",
+	})))
+	select {
+	case name := <-connected:
+		assert.Equal(t, "c1-1", name)
+	case <-time.After(3 * time.Second):
+		t.Fatal("cluster does not reconnect with new name")
+	}
+
+	// exit once rejected.
+	assert.Nil(t, ct.Send("c1-1", nameConflictData(t, &config.NameConflict{
+		Name:   "c1-1",
+		Policy: config.DuplicateNameRejectNew,
+		Reason: "used by another cluster",
+	})))
+	select {
+	case err := <-exited:
+		assert.Contains(t, err.Error(), "used by another cluster")
+	case <-time.After(time.Second):
+		t.Fatal("cluster does not exit")
+	}
+	assert.True(t, e.stopped())
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/klog"
+
+	"github.com/baidu/ote-stack/pkg/clusterrouter"
+)
+
+/*
+A cluster failed over to a parent neighbor or an ancestor returns to the original topology
+once a nearer ancestor recovers.
+The ancestors are recorded while connected to the first parent, which is home.
+Every ParentRecoverInterval, the home ancestors nearer than current parent are probed, the nearest first,
+if one is reachable, the cluster tells current parent it is going away and reconnects to it.
+*/
+
+var (
+	// ParentRecoverInterval is the interval to probe nearer ancestors after failing over, 0 disables it.
+	ParentRecoverInterval = 30 * time.Second
+)
+
+// parentRecovery records the connection to parent and the home ancestors for edgeTunnel.
+type parentRecovery struct {
+	mutex sync.Mutex
+	// client is the connection to parent, nil if disconnected.
+	client *WSClient
+	// addr is the address of current parent.
+	addr string
+	// homeAddr is the address of the first parent.
+	homeAddr string
+	// home is ancestors from the first parent up to root.
+	home []clusterrouter.Ancestor
+	// returnTo is the address of a recovered ancestor to reconnect to.
+	returnTo string
+}
+
+// connected records the connection to parent at addr, client is nil on disconnection.
+func (r *parentRecovery) connected(client *WSClient, addr string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.client = client
+	r.addr = addr
+	if r.homeAddr == "" {
+		r.homeAddr = addr
+	}
+}
+
+// takeReturnTo returns and clears the address to return to.
+func (r *parentRecovery) takeReturnTo() string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	addr := r.returnTo
+	r.returnTo = ""
+	return addr
+}
+
+/*
+nearer returns home ancestors nearer than current parent, the nearest first.
+If current parent is not a home ancestor, it is a parent neighbor and only the first parent is nearer.
+The home ancestors are refreshed by chain if connected to the first parent.
+*/
+func (r *parentRecovery) nearer(chain []clusterrouter.Ancestor) []clusterrouter.Ancestor {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.client == nil || r.returnTo != "" {
+		return nil
+	}
+	if r.addr == r.homeAddr {
+		if len(chain) > 0 && chain[0].Addr == r.homeAddr {
+			r.home = chain
+		}
+		return nil
+	}
+	if len(r.home) == 0 {
+		return []clusterrouter.Ancestor{{Addr: r.homeAddr}}
+	}
+	for i, a := range r.home {
+		if a.Addr == r.addr {
+			return r.home[:i]
+		}
+	}
+	return r.home[:1]
+}
+
+// leave sets the address to return to and returns the connection to current parent,
+// it returns nil if disconnected in the meantime.
+func (r *parentRecovery) leave(returnTo string) *WSClient {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.client == nil {
+		return nil
+	}
+	r.returnTo = returnTo
+	return r.client
+}
+
+// recoverParent returns to the nearest recovered home ancestor if failed over.
+func (e *edgeTunnel) recoverParent() {
+	for _, a := range e.recovery.nearer(clusterrouter.Router().AncestorChain()) {
+		if a.Addr == "" {
+			continue
+		}
+		if _, err := probeParent(a.Addr); err != nil {
+			klog.V(3).Infof("ancestor %s(%s) is not recovered: %v", a.Name, a.Addr, err)
+			continue
+		}
+
+		client := e.recovery.leave(a.Addr)
+		if client == nil {
+			return
+		}
+		klog.Infof("ancestor %s(%s) recovered, leave current parent", a.Name, a.Addr)
+		if err := client.WriteMessage(goingAwayMessage(e.name)); err != nil {
+			klog.Errorf("send going away to current parent failed: %v", err)
+		}
+		client.Close()
+		return
+	}
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/baidu/ote-stack/pkg/clusterrouter"
+	"github.com/baidu/ote-stack/pkg/config"
+)
+
+func TestNearerAncestors(t *testing.T) {
+	home := []clusterrouter.Ancestor{{Name: "p1", Addr: "a1"}, {Name: "g", Addr: "g1"}, {Name: "r", Addr: "r1"}}
+	r := parentRecovery{}
+	assert.Empty(t, r.nearer(home))
+
+	// home ancestors are recorded while connected to the first parent
+	r.connected(&WSClient{}, "a1")
+	assert.Empty(t, r.nearer(home))
+	assert.Equal(t, home, r.home)
+
+	// failed over to a parent neighbor
+	r.connected(&WSClient{}, "a2")
+	assert.Equal(t, home[:1], r.nearer(home[1:]))
+	assert.Equal(t, home, r.home)
+	// failed over to root
+	r.connected(&WSClient{}, "r1")
+	assert.Equal(t, home[:2], r.nearer(home[2:]))
+
+	// nothing to probe while returning or disconnected
+	assert.NotNil(t, r.leave("g1"))
+	assert.Empty(t, r.nearer(home[2:]))
+	assert.Equal(t, "g1", r.takeReturnTo())
+	assert.Equal(t, "", r.takeReturnTo())
+	r.connected(nil, "")
+	assert.Empty(t, r.nearer(home[2:]))
+	assert.Nil(t, r.leave("g1"))
+}
+
+func TestReturnToRecoveredParent(t *testing.T) {
+	defaultCloudBlackList.Clear()
+	defer defaultCloudBlackList.Clear()
+	interval := ParentRecoverInterval
+	ParentRecoverInterval = 50 * time.Millisecond
+	defer func() {
+		ParentRecoverInterval = interval
+	}()
+
+	ct1 := NewCloudTunnel("127.0.0.1:0", nil).(*cloudTunnel)
+	assert.Nil(t, ct1.Start())
+	ct2 := NewCloudTunnel("127.0.0.1:0", nil).(*cloudTunnel)
+	closed := make(chan string, 10)
+	ct2.RegistClientCloseHandler(func(cr *config.ClusterRegistry) {
+		closed <- cr.Name
+	})
+	assert.Nil(t, ct2.Start())
+	defer ct2.Stop()
+
+	// ct2 is parent of ct1
+	parents, ancestors := clusterrouter.Router().ParentNeighbor, clusterrouter.Router().Ancestors
+	clusterrouter.Router().ParentNeighbor = map[string]string{}
+	clusterrouter.Router().Ancestors = []clusterrouter.Ancestor{
+		{Name: "p1", Addr: ct1.server.Addr}, {Name: "g", Addr: ct2.server.Addr},
+	}
+	defer func() {
+		clusterrouter.Router().ParentNeighbor = parents
+		clusterrouter.Router().Ancestors = ancestors
+	}()
+
+	e := NewEdgeTunnel(&config.ClusterControllerConfig{
+		ClusterUserDefineName: "c1",
+		ParentCluster:         ct1.server.Addr,
+		TunnelListenAddr:      "fake",
+	}).(*edgeTunnel)
+	assert.Nil(t, e.Start())
+	defer e.Stop(context.Background())
+
+	// child fails over to parent of parent.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.Nil(t, ct1.Shutdown(ctx))
+	eventually(t, func() bool {
+		_, ok := ct2.clients.Load("c1")
+		return ok
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// child returns to parent once it recovers, and leaves parent of parent at once.
+	ct3 := NewCloudTunnel(ct1.server.Addr, nil).(*cloudTunnel)
+	assert.Nil(t, ct3.Start())
+	defer ct3.Stop()
+	eventually(t, func() bool {
+		_, ok := ct3.clients.Load("c1")
+		return ok
+	}, 2*time.Second, 10*time.Millisecond)
+	select {
+	case name := <-closed:
+		assert.Equal(t, "c1", name)
+	case <-time.After(time.Second):
+		t.Fatal("child does not leave parent of parent")
+	}
+}
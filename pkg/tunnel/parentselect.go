@@ -0,0 +1,167 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog"
+
+	"github.com/baidu/ote-stack/pkg/clusterrouter"
+)
+
+/*
+Once disconnected from parent, a cluster fails over to a parent neighbor.
+Parent neighbors not in the blacklist are probed by dialing their listen addresses at the same time,
+and ranked by:
+
+1. preference set by administrator in ParentPreference, higher first,
+2. reachability, the ones failing the probe go last,
+3. score, which is the probed rtt plus ParentLoadPenalty for each cluster in its subtree.
+
+Ancestors of parent up to root are the last resort, the nearest first,
+then the blacklist is popped in FIFO.
+*/
+
+var (
+	// ParentPreference is the preference of parent neighbors to fail over to, e.g., "c2=10,c3=5".
+	// A parent neighbor not set has preference 0.
+	ParentPreference = ""
+	// ParentProbeTimeout is the max time to probe a parent neighbor.
+	ParentProbeTimeout = 1 * time.Second
+	// ParentLoadPenalty is added to the score of a parent neighbor for each cluster in its subtree.
+	ParentLoadPenalty = 5 * time.Millisecond
+
+	// probeParent returns rtt of dialing addr.
+	probeParent = func(addr string) (time.Duration, error) {
+		start := time.Now()
+		conn, err := net.DialTimeout("tcp", addr, ParentProbeTimeout)
+		if err != nil {
+			return 0, err
+		}
+		conn.Close()
+		return time.Since(start), nil
+	}
+)
+
+// ParseParentPreference parses "<cluster>=<preference>,...".
+func ParseParentPreference(s string) (map[string]int, error) {
+	ret := make(map[string]int)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		kv := strings.SplitN(item, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, fmt.Errorf("invalid item %q, should be <cluster>=<preference>", item)
+		}
+		preference, err := strconv.Atoi(strings.TrimSpace(kv[1]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid preference %q of %s", kv[1], kv[0])
+		}
+		ret[strings.TrimSpace(kv[0])] = preference
+	}
+	return ret, nil
+}
+
+// parentCandidate is a parent neighbor to fail over to.
+type parentCandidate struct {
+	name       string
+	addr       string
+	preference int
+	load       int
+	rtt        time.Duration
+	err        error
+}
+
+func (c *parentCandidate) score() time.Duration {
+	return c.rtt + time.Duration(c.load)*ParentLoadPenalty
+}
+
+func (c *parentCandidate) String() string {
+	if c.err != nil {
+		return fmt.Sprintf("%s(%s) preference %d load %d unreachable: %v", c.name, c.addr, c.preference, c.load, c.err)
+	}
+	return fmt.Sprintf("%s(%s) preference %d load %d rtt %v", c.name, c.addr, c.preference, c.load, c.rtt)
+}
+
+/*
+rankParents probes parents and returns them ranked, the first is the best to fail over to.
+parents are cluster name to listen address, loads are number of clusters in their subtree,
+a parent whose address is skipped is not a candidate.
+*/
+func rankParents(parents map[string]string, loads map[string]int,
+	preference map[string]int, skip func(string) bool) []*parentCandidate {
+	ret := make([]*parentCandidate, 0, len(parents))
+	for name, addr := range parents {
+		if skip(addr) {
+			continue
+		}
+		ret = append(ret, &parentCandidate{
+			name:       name,
+			addr:       addr,
+			preference: preference[name],
+			load:       loads[name],
+		})
+	}
+
+	var wg sync.WaitGroup
+	for _, c := range ret {
+		wg.Add(1)
+		go func(c *parentCandidate) {
+			defer wg.Done()
+			c.rtt, c.err = probeParent(c.addr)
+		}(c)
+	}
+	wg.Wait()
+
+	sort.Slice(ret, func(i, j int) bool {
+		a, b := ret[i], ret[j]
+		if a.preference != b.preference {
+			return a.preference > b.preference
+		}
+		if (a.err == nil) != (b.err == nil) {
+			return a.err == nil
+		}
+		if a.score() != b.score() {
+			return a.score() < b.score()
+		}
+		return a.name < b.name
+	})
+	return ret
+}
+
+// nearestAncestor returns address of the nearest ancestor of parent not skipped, empty if none.
+func nearestAncestor(ancestors []clusterrouter.Ancestor, skip func(string) bool) string {
+	if len(ancestors) < 2 {
+		return ""
+	}
+	for _, a := range ancestors[1:] {
+		if a.Addr != "" && !skip(a.Addr) {
+			klog.Infof("no parent neighbor to fail over to, try ancestor %s(%s)", a.Name, a.Addr)
+			return a.Addr
+		}
+	}
+	return ""
+}
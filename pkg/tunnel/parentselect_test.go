@@ -0,0 +1,119 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/baidu/ote-stack/pkg/clusterrouter"
+)
+
+func TestParseParentPreference(t *testing.T) {
+	preference, err := ParseParentPreference(" c2=10, c3=-1,")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]int{"c2": 10, "c3": -1}, preference)
+
+	preference, err = ParseParentPreference("")
+	assert.Nil(t, err)
+	assert.Empty(t, preference)
+
+	_, err = ParseParentPreference("c2")
+	assert.NotNil(t, err)
+	_, err = ParseParentPreference("c2=high")
+	assert.NotNil(t, err)
+}
+
+func fakeProbeParent(rtts map[string]time.Duration) func() {
+	probe := probeParent
+	probeParent = func(addr string) (time.Duration, error) {
+		rtt, ok := rtts[addr]
+		if !ok {
+			return 0, fmt.Errorf("connection refused")
+		}
+		return rtt, nil
+	}
+	return func() {
+		probeParent = probe
+	}
+}
+
+func TestRankParents(t *testing.T) {
+	defer fakeProbeParent(map[string]time.Duration{
+		"a1": 30 * time.Millisecond,
+		"a2": 10 * time.Millisecond,
+		"a3": 20 * time.Millisecond,
+		"a5": 50 * time.Millisecond,
+	})()
+	parents := map[string]string{"c1": "a1", "c2": "a2", "c3": "a3", "c4": "a4", "c5": "a5"}
+	names := func(candidates []*parentCandidate) []string {
+		ret := make([]string, 0, len(candidates))
+		for _, c := range candidates {
+			ret = append(ret, c.name)
+		}
+		return ret
+	}
+	noSkip := func(string) bool { return false }
+
+	// by rtt, unreachable ones go last
+	assert.Equal(t, []string{"c2", "c3", "c1", "c5", "c4"}, names(rankParents(parents, nil, nil, noSkip)))
+
+	// load adds to rtt
+	loads := map[string]int{"c2": 5, "c3": 1}
+	assert.Equal(t, []string{"c3", "c1", "c2", "c5", "c4"}, names(rankParents(parents, loads, nil, noSkip)))
+
+	// preference goes first, even if it is unreachable
+	preference := map[string]int{"c5": 1, "c4": 2, "c1": -1}
+	assert.Equal(t, []string{"c4", "c5", "c3", "c2", "c1"}, names(rankParents(parents, loads, preference, noSkip)))
+
+	// skipped ones are not probed
+	ranked := rankParents(parents, loads, preference, func(addr string) bool {
+		return addr != "a1"
+	})
+	assert.Equal(t, []string{"c1"}, names(ranked))
+	assert.Equal(t, 30*time.Millisecond, ranked[0].rtt)
+}
+
+func TestFailoverToAncestors(t *testing.T) {
+	defer fakeProbeParent(map[string]time.Duration{"a2": time.Millisecond})()
+	defaultCloudBlackList.Clear()
+	defer defaultCloudBlackList.Clear()
+	parents, ancestors := clusterrouter.Router().ParentNeighbor, clusterrouter.Router().Ancestors
+	defer func() {
+		clusterrouter.Router().ParentNeighbor = parents
+		clusterrouter.Router().Ancestors = ancestors
+	}()
+	clusterrouter.Router().ParentNeighbor = map[string]string{"c2": "a2"}
+	clusterrouter.Router().Ancestors = []clusterrouter.Ancestor{
+		{Name: "c1", Addr: "a1"}, {Name: "g", Addr: "g1"}, {Name: "r", Addr: "r1"},
+	}
+
+	e := &edgeTunnel{cloudAddr: "a1"}
+	assert.True(t, e.chooseParentNeighbor())
+	assert.Equal(t, "a2", e.cloudAddr)
+	// ancestors are tried once all parent neighbors are in blacklist, the nearest first
+	assert.True(t, e.chooseParentNeighbor())
+	assert.Equal(t, "g1", e.cloudAddr)
+	assert.True(t, e.chooseParentNeighbor())
+	assert.Equal(t, "r1", e.cloudAddr)
+	// then blacklist in FIFO, which keeps the ones blacklisted recently
+	assert.False(t, e.chooseParentNeighbor())
+	assert.Equal(t, "r1", e.cloudAddr)
+}
@@ -0,0 +1,239 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+	"k8s.io/klog"
+)
+
+/*
+protocolMux shares a listener of the grpc transport between grpc and http/1.1,
+so the http handlers of the tunnel, e.g., /topology, are served in both transports.
+
+A connection starting with the http/2 client preface is grpc, others are http/1.1.
+With tls, the connection is decrypted before its first bytes are checked,
+so an http client must not negotiate http/2, e.g., curl --http1.1.
+*/
+
+const (
+	http2ClientPreface = "PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n"
+)
+
+type protocolMux struct {
+	ln        net.Listener
+	tlsConfig *tls.Config
+	grpc      *muxListener
+	http      *muxListener
+	closeOnce sync.Once
+}
+
+func newProtocolMux(ln net.Listener, tlsConfig *tls.Config) *protocolMux {
+	if tlsConfig != nil {
+		tlsConfig = tlsConfig.Clone()
+		tlsConfig.NextProtos = appendProto(tlsConfig.NextProtos, "h2", "http/1.1")
+	}
+	return &protocolMux{
+		ln:        ln,
+		tlsConfig: tlsConfig,
+		grpc:      newMuxListener(ln.Addr()),
+		http:      newMuxListener(ln.Addr()),
+	}
+}
+
+func appendProto(protos []string, more ...string) []string {
+	ret := append([]string{}, protos...)
+	for _, p := range more {
+		found := false
+		for _, q := range ret {
+			if p == q {
+				found = true
+				break
+			}
+		}
+		if !found {
+			ret = append(ret, p)
+		}
+	}
+	return ret
+}
+
+// serve accepts connections and dispatches them until the listener is closed.
+func (m *protocolMux) serve() {
+	for {
+		conn, err := m.ln.Accept()
+		if err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Temporary() {
+				time.Sleep(10 * time.Millisecond)
+				continue
+			}
+			m.grpc.close(err)
+			m.http.close(err)
+			return
+		}
+		go m.dispatch(conn)
+	}
+}
+
+func (m *protocolMux) dispatch(conn net.Conn) {
+	conn.SetDeadline(time.Now().Add(ReadTimeout))
+	if m.tlsConfig != nil {
+		tlsConn := tls.Server(conn, m.tlsConfig)
+		if err := tlsConn.Handshake(); err != nil {
+			klog.V(1).Infof("tls handshake with %s failed: %v", conn.RemoteAddr(), err)
+			conn.Close()
+			return
+		}
+		conn = tlsConn
+	}
+	reader := bufio.NewReader(conn)
+	isGRPC := true
+	for i := 1; i <= len(http2ClientPreface); i++ {
+		b, err := reader.Peek(i)
+		if err != nil {
+			conn.Close()
+			return
+		}
+		if b[i-1] != http2ClientPreface[i-1] {
+			isGRPC = false
+			break
+		}
+	}
+	conn.SetDeadline(time.Time{})
+
+	peeked := &peekedConn{Conn: conn, reader: reader}
+	target := m.http
+	if isGRPC {
+		target = m.grpc
+	}
+	if !target.put(peeked) {
+		conn.Close()
+	}
+}
+
+// Close closes the listener shared.
+func (m *protocolMux) Close() error {
+	var err error
+	m.closeOnce.Do(func() {
+		err = m.ln.Close()
+	})
+	return err
+}
+
+// muxListener is a listener of connections of one protocol dispatched by protocolMux.
+type muxListener struct {
+	addr      net.Addr
+	conns     chan net.Conn
+	done      chan struct{}
+	closeOnce sync.Once
+	err       error
+}
+
+func newMuxListener(addr net.Addr) *muxListener {
+	return &muxListener{
+		addr:  addr,
+		conns: make(chan net.Conn),
+		done:  make(chan struct{}),
+	}
+}
+
+func (l *muxListener) put(conn net.Conn) bool {
+	select {
+	case l.conns <- conn:
+		return true
+	case <-l.done:
+		return false
+	}
+}
+
+func (l *muxListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.done:
+		return nil, l.err
+	}
+}
+
+func (l *muxListener) close(err error) {
+	l.closeOnce.Do(func() {
+		l.err = err
+		close(l.done)
+	})
+}
+
+func (l *muxListener) Close() error {
+	l.close(fmt.Errorf("listener %s is closed", l.addr))
+	return nil
+}
+
+func (l *muxListener) Addr() net.Addr {
+	return l.addr
+}
+
+// peekedConn is a connection whose first bytes are peeked.
+type peekedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *peekedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
+
+// tlsInfo returns tls state of the connection, nil if it is not tls.
+func (c *peekedConn) tlsInfo() credentials.AuthInfo {
+	if tlsConn, ok := c.Conn.(*tls.Conn); ok {
+		return credentials.TLSInfo{State: tlsConn.ConnectionState()}
+	}
+	return nil
+}
+
+// muxedTLSCredentials are server credentials of grpc for connections decrypted by protocolMux,
+// they report the tls state of a connection without handshaking again.
+type muxedTLSCredentials struct{}
+
+func (muxedTLSCredentials) ClientHandshake(context.Context, string, net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return nil, nil, fmt.Errorf("client handshake is not supported")
+}
+
+func (muxedTLSCredentials) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	if c, ok := conn.(*peekedConn); ok {
+		return conn, c.tlsInfo(), nil
+	}
+	return conn, nil, nil
+}
+
+func (muxedTLSCredentials) Info() credentials.ProtocolInfo {
+	return credentials.ProtocolInfo{SecurityProtocol: "tls"}
+}
+
+func (c muxedTLSCredentials) Clone() credentials.TransportCredentials {
+	return c
+}
+
+func (muxedTLSCredentials) OverrideServerName(string) error {
+	return nil
+}
@@ -0,0 +1,371 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"k8s.io/klog"
+)
+
+/*
+Every loop dialing a websocket reconnects by a Reconnector.
+After the nth failure it waits a random time in [0, min(ReconnectMaxDelay, ReconnectBaseDelay*2^(n-1))),
+which is exponential backoff with full jitter, so thousands of clusters do not
+reconnect in lockstep when their parent restarts.
+*/
+
+var (
+	// ReconnectBaseDelay is the max delay after the first failure of a connection.
+	ReconnectBaseDelay = 1 * time.Second
+	// ReconnectMaxDelay is the max delay between two attempts of a connection.
+	ReconnectMaxDelay = 60 * time.Second
+
+	// ErrReconnectStopped is returned if a Reconnector is stopped before connected.
+	ErrReconnectStopped = fmt.Errorf("reconnect is stopped")
+
+	reconnectors = reconnectorRegistry{
+		items: make(map[string]*Reconnector),
+	}
+)
+
+// ConnState is the state of a connection dialed by a Reconnector.
+type ConnState int
+
+const (
+	// StateConnecting means the connection is being dialed.
+	StateConnecting ConnState = iota
+	// StateConnected means the connection is established.
+	StateConnected
+	// StateBackingOff means the last attempt failed and it is waiting for the next.
+	StateBackingOff
+	// StateFailed means it gives up after max attempts or is stopped.
+	StateFailed
+)
+
+func (s ConnState) String() string {
+	switch s {
+	case StateConnecting:
+		return "connecting"
+	case StateConnected:
+		return "connected"
+	case StateBackingOff:
+		return "backing-off"
+	case StateFailed:
+		return "failed"
+	default:
+		return fmt.Sprintf("ConnState(%d)", int(s))
+	}
+}
+
+// MarshalText marshals the state as its name, e.g., in json of ReconnectStatuses.
+func (s ConnState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// ReconnectPolicy decides how long to wait between attempts of a connection.
+type ReconnectPolicy struct {
+	// BaseDelay is the max delay after the first failure.
+	BaseDelay time.Duration
+	// MaxDelay is the max delay between two attempts.
+	MaxDelay time.Duration
+	// MaxAttempts is the number of failed attempts after which it gives up, 0 means never.
+	MaxAttempts int
+}
+
+// DefaultReconnectPolicy returns the policy of ReconnectBaseDelay and ReconnectMaxDelay, it never gives up.
+func DefaultReconnectPolicy() ReconnectPolicy {
+	return ReconnectPolicy{
+		BaseDelay: ReconnectBaseDelay,
+		MaxDelay:  ReconnectMaxDelay,
+	}
+}
+
+// ceiling returns the max delay after failures, which is at least 1ns.
+func (p ReconnectPolicy) ceiling(failures int) time.Duration {
+	d := p.BaseDelay
+	if d <= 0 {
+		d = time.Nanosecond
+	}
+	for i := 1; i < failures; i++ {
+		if p.MaxDelay > 0 && d >= p.MaxDelay {
+			break
+		}
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	return d
+}
+
+// ReconnectStatus is the status of a Reconnector.
+type ReconnectStatus struct {
+	State ConnState `json:"state"`
+	// Since is the time it enters State.
+	Since time.Time `json:"since"`
+	// Failures is the number of failed attempts since last connected.
+	Failures int `json:"failures"`
+	// Reconnects is the number of times it connects after the first time.
+	Reconnects uint64 `json:"reconnects"`
+	// LastError is the error of last failed attempt.
+	LastError error `json:"-"`
+}
+
+// MarshalJSON marshals the status with LastError as a string.
+func (s ReconnectStatus) MarshalJSON() ([]byte, error) {
+	type status ReconnectStatus
+	lastError := ""
+	if s.LastError != nil {
+		lastError = s.LastError.Error()
+	}
+	return json.Marshal(struct {
+		status
+		LastError string `json:"lastError,omitempty"`
+	}{status(s), lastError})
+}
+
+func (s ReconnectStatus) String() string {
+	ret := fmt.Sprintf("%s since %s, %d failures, %d reconnects",
+		s.State, s.Since.Format(time.RFC3339), s.Failures, s.Reconnects)
+	if s.LastError != nil {
+		ret += fmt.Sprintf(", last error: %v", s.LastError)
+	}
+	return ret
+}
+
+// Reconnector dials a connection again and again by its policy until it is connected.
+type Reconnector struct {
+	name   string
+	policy ReconnectPolicy
+
+	mutex     sync.Mutex
+	status    ReconnectStatus
+	connected bool
+	// switched is set if the next attempt is to another address, which is made at once.
+	switched bool
+	rand     *rand.Rand
+}
+
+// NewReconnector returns a Reconnector of connection named name, which is registered for ReconnectStatuses.
+func NewReconnector(name string, policy ReconnectPolicy) *Reconnector {
+	r := &Reconnector{
+		name:   name,
+		policy: policy,
+		status: ReconnectStatus{
+			State: StateConnecting,
+			Since: time.Now(),
+		},
+		rand: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	reconnectors.add(r)
+	return r
+}
+
+// SetPolicy changes the policy of following attempts.
+func (r *Reconnector) SetPolicy(policy ReconnectPolicy) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.policy = policy
+}
+
+// State returns current state of the connection.
+func (r *Reconnector) State() ConnState {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.status.State
+}
+
+// Status returns current status of the connection.
+func (r *Reconnector) Status() ReconnectStatus {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.status
+}
+
+// Reset clears failures, so the next delay starts from BaseDelay again, e.g., after switching to another address.
+func (r *Reconnector) Reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.status.Failures = 0
+}
+
+// Switch clears failures and makes the next attempt at once without backoff,
+// it is called in connect of Run after changing to another address, e.g., redirected or failing over.
+func (r *Reconnector) Switch() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.status.Failures = 0
+	r.switched = true
+}
+
+// Disconnected marks the connection lost, it is called before dialing again.
+func (r *Reconnector) Disconnected(err error) {
+	r.mutex.Lock()
+	r.status.LastError = err
+	r.mutex.Unlock()
+	r.setState(StateConnecting)
+}
+
+func (r *Reconnector) setState(state ConnState) {
+	r.mutex.Lock()
+	from := r.status.State
+	if from == state {
+		r.mutex.Unlock()
+		return
+	}
+	r.status.State = state
+	r.status.Since = time.Now()
+	r.mutex.Unlock()
+
+	klog.V(1).Infof("connection %s: %s -> %s", r.name, from, state)
+}
+
+// delay records a failure and returns how long to wait before the next attempt,
+// it returns false if the policy gives up.
+// A failure before switching to another address is not counted and the next attempt is made at once.
+func (r *Reconnector) delay(err error) (time.Duration, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.status.LastError = err
+	if r.switched {
+		r.switched = false
+		return 0, true
+	}
+	r.status.Failures++
+	if r.policy.MaxAttempts > 0 && r.status.Failures >= r.policy.MaxAttempts {
+		return 0, false
+	}
+	return time.Duration(r.rand.Int63n(int64(r.policy.ceiling(r.status.Failures)))), true
+}
+
+// Connect calls connect once, it is used for the first connection which fails fast.
+func (r *Reconnector) Connect(connect func() error) error {
+	r.setState(StateConnecting)
+	if err := connect(); err != nil {
+		r.mutex.Lock()
+		r.status.Failures++
+		r.status.LastError = err
+		r.mutex.Unlock()
+		r.setState(StateFailed)
+		return err
+	}
+	r.connectedOK()
+	return nil
+}
+
+// connectedOK records a successful attempt.
+func (r *Reconnector) connectedOK() {
+	r.mutex.Lock()
+	if r.connected {
+		r.status.Reconnects++
+	}
+	r.connected = true
+	r.status.Failures = 0
+	r.status.LastError = nil
+	r.mutex.Unlock()
+	r.setState(StateConnected)
+}
+
+/*
+Run calls connect until it succeeds, and waits by the policy after each failure.
+It returns the last error if the policy gives up, or ErrReconnectStopped if stop is closed.
+*/
+func (r *Reconnector) Run(stop <-chan struct{}, connect func() error) error {
+	for {
+		select {
+		case <-stop:
+			r.setState(StateFailed)
+			return ErrReconnectStopped
+		default:
+		}
+
+		r.setState(StateConnecting)
+		err := connect()
+		if err == nil {
+			r.connectedOK()
+			return nil
+		}
+
+		d, ok := r.delay(err)
+		if !ok {
+			klog.Errorf("connect %s failed, give up after %d attempts: %v", r.name, r.Status().Failures, err)
+			r.setState(StateFailed)
+			return err
+		}
+		if d == 0 {
+			klog.Errorf("connect %s failed, try again at once: %v", r.name, err)
+			continue
+		}
+		klog.Errorf("connect %s failed, try again after %v: %v", r.name, d, err)
+		r.setState(StateBackingOff)
+
+		timer := time.NewTimer(d)
+		select {
+		case <-stop:
+			timer.Stop()
+			r.setState(StateFailed)
+			return ErrReconnectStopped
+		case <-timer.C:
+		}
+	}
+}
+
+// Close unregisters the Reconnector from ReconnectStatuses.
+func (r *Reconnector) Close() {
+	reconnectors.remove(r)
+}
+
+// reconnectorRegistry keeps reconnectors for ReconnectStatuses.
+type reconnectorRegistry struct {
+	mutex sync.Mutex
+	items map[string]*Reconnector
+}
+
+func (g *reconnectorRegistry) add(r *Reconnector) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.items[r.name] = r
+}
+
+func (g *reconnectorRegistry) remove(r *Reconnector) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if g.items[r.name] == r {
+		delete(g.items, r.name)
+	}
+}
+
+// ReconnectStatuses returns status of every connection dialed by a Reconnector, keyed by name.
+func ReconnectStatuses() map[string]ReconnectStatus {
+	reconnectors.mutex.Lock()
+	items := make([]*Reconnector, 0, len(reconnectors.items))
+	for _, r := range reconnectors.items {
+		items = append(items, r)
+	}
+	reconnectors.mutex.Unlock()
+
+	ret := make(map[string]ReconnectStatus, len(items))
+	for _, r := range items {
+		ret[r.name] = r.Status()
+	}
+	return ret
+}
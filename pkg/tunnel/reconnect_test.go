@@ -0,0 +1,147 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReconnectPolicyCeiling(t *testing.T) {
+	p := ReconnectPolicy{BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+	assert.Equal(t, time.Second, p.ceiling(1))
+	assert.Equal(t, 2*time.Second, p.ceiling(2))
+	assert.Equal(t, 8*time.Second, p.ceiling(4))
+	assert.Equal(t, 10*time.Second, p.ceiling(5))
+	assert.Equal(t, 10*time.Second, p.ceiling(1000))
+
+	p = ReconnectPolicy{}
+	assert.Equal(t, time.Nanosecond, p.ceiling(1))
+}
+
+func TestReconnectorJitter(t *testing.T) {
+	r := NewReconnector("test-jitter", ReconnectPolicy{BaseDelay: time.Second, MaxDelay: 4 * time.Second})
+	defer r.Close()
+
+	seen := make(map[time.Duration]bool)
+	for i := 0; i < 100; i++ {
+		r.Reset()
+		r.delay(nil)
+		r.delay(nil)
+		d, ok := r.delay(nil)
+		assert.True(t, ok)
+		assert.True(t, d >= 0 && d < 4*time.Second, "delay %v", d)
+		seen[d] = true
+	}
+	assert.True(t, len(seen) > 1, "delays are not jittered")
+}
+
+func TestReconnectorRun(t *testing.T) {
+	r := NewReconnector("test-run", ReconnectPolicy{BaseDelay: time.Millisecond, MaxDelay: time.Millisecond})
+	defer r.Close()
+
+	attempts := 0
+	err := r.Run(nil, func() error {
+		attempts++
+		if attempts < 3 {
+			return fmt.Errorf("refused")
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, StateConnected, r.State())
+	assert.Equal(t, 0, r.Status().Failures)
+	assert.Equal(t, StateConnected, ReconnectStatuses()["test-run"].State)
+
+	r.Disconnected(fmt.Errorf("closed"))
+	assert.Equal(t, StateConnecting, r.State())
+	assert.Nil(t, r.Run(nil, func() error { return nil }))
+	assert.Equal(t, uint64(1), r.Status().Reconnects)
+}
+
+func TestReconnectorSwitch(t *testing.T) {
+	r := NewReconnector("test-switch", ReconnectPolicy{BaseDelay: time.Hour, MaxDelay: time.Hour})
+	defer r.Close()
+
+	// the attempt after switching to another address is made at once.
+	attempts := 0
+	done := make(chan error)
+	go func() {
+		done <- r.Run(nil, func() error {
+			attempts++
+			if attempts < 3 {
+				r.Switch()
+				return fmt.Errorf("refused")
+			}
+			return nil
+		})
+	}()
+	select {
+	case err := <-done:
+		assert.Nil(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("switching address waits backoff")
+	}
+	assert.Equal(t, 3, attempts)
+
+	r.Disconnected(fmt.Errorf("closed"))
+	data, err := json.Marshal(ReconnectStatuses()["test-switch"])
+	assert.Nil(t, err)
+	status := make(map[string]interface{})
+	assert.Nil(t, json.Unmarshal(data, &status))
+	assert.Equal(t, "connecting", status["state"])
+	assert.Equal(t, "closed", status["lastError"])
+}
+
+func TestReconnectorGiveUp(t *testing.T) {
+	r := NewReconnector("test-give-up", ReconnectPolicy{BaseDelay: time.Millisecond, MaxAttempts: 3})
+	defer r.Close()
+
+	attempts := 0
+	err := r.Run(nil, func() error {
+		attempts++
+		return fmt.Errorf("refused")
+	})
+	assert.EqualError(t, err, "refused")
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, StateFailed, r.State())
+	assert.EqualError(t, r.Status().LastError, "refused")
+
+	r.Close()
+	_, ok := ReconnectStatuses()["test-give-up"]
+	assert.False(t, ok)
+}
+
+func TestReconnectorStop(t *testing.T) {
+	r := NewReconnector("test-stop", ReconnectPolicy{BaseDelay: time.Hour, MaxDelay: time.Hour})
+	defer r.Close()
+
+	stop := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- r.Run(stop, func() error { return fmt.Errorf("refused") })
+	}()
+	eventually(t, func() bool { return r.State() == StateBackingOff }, time.Second, 10*time.Millisecond)
+	close(stop)
+	assert.Equal(t, ErrReconnectStopped, <-done)
+	assert.Equal(t, StateFailed, r.State())
+}
@@ -0,0 +1,327 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/klog"
+
+	"github.com/baidu/ote-stack/pkg/config"
+)
+
+/*
+Reliable delivery gives at-least-once delivery of messages on a link
+between a child and its parent, or a controller manager and the root.
+
+It is negotiated per connection: the dialer posts its session id in header,
+and the server answers the session id to use if it supports reliable delivery.
+If the answer is empty, the link works as before.
+
+Once negotiated, each message is framed with a sequence number of the link,
+and kept in a bounded retransmit window until the peer acknowledges it.
+A session lives across reconnections, so unacknowledged messages are
+retransmitted on the new connection, and the receiver drops duplicates by sequence.
+*/
+
+const (
+	frameData byte = 1
+	frameAck  byte = 2
+
+	// frame type and sequence number.
+	frameHeaderLength = 9
+)
+
+var (
+	// ReliableDelivery enables reliable delivery on links if the peer supports it too.
+	ReliableDelivery = false
+	// ReliableWindowSize is the max number of unacknowledged messages of a link.
+	ReliableWindowSize = 1000
+	// ReliableSessionTimeout is how long a server keeps a disconnected session for resuming.
+	ReliableSessionTimeout = 5 * time.Minute
+
+	// ErrWindowFull is returned if too many messages are not acknowledged by the peer.
+	ErrWindowFull = fmt.Errorf("retransmit window is full")
+)
+
+type pendingFrame struct {
+	seq   uint64
+	frame []byte
+}
+
+// reliableSession keeps delivery state of a link across connections.
+type reliableSession struct {
+	id string
+	// owner is the name of the peer which the session belongs to.
+	owner string
+	mutex sync.Mutex
+	// nextSeq is the sequence of next message to send.
+	nextSeq uint64
+	// window keeps unacknowledged messages ordered by sequence.
+	window []*pendingFrame
+	// received is the last sequence received.
+	received uint64
+	// fresh is true if nothing received yet, so the first sequence is accepted as start.
+	fresh  bool
+	client *WSClient
+	expire *time.Timer
+}
+
+func newReliableSession(owner string) *reliableSession {
+	return &reliableSession{
+		id:      string(uuid.NewUUID()),
+		owner:   owner,
+		nextSeq: 1,
+		window:  make([]*pendingFrame, 0),
+		fresh:   true,
+	}
+}
+
+func encodeFrame(typ byte, seq uint64, payload []byte) []byte {
+	frame := make([]byte, frameHeaderLength+len(payload))
+	frame[0] = typ
+	binary.BigEndian.PutUint64(frame[1:frameHeaderLength], seq)
+	copy(frame[frameHeaderLength:], payload)
+	return frame
+}
+
+func decodeFrame(frame []byte) (byte, uint64, []byte, error) {
+	if len(frame) < frameHeaderLength {
+		return 0, 0, nil, fmt.Errorf("frame too short: %d bytes", len(frame))
+	}
+	return frame[0], binary.BigEndian.Uint64(frame[1:frameHeaderLength]), frame[frameHeaderLength:], nil
+}
+
+// attach binds the session to client, and retransmits unacknowledged messages over it.
+func (s *reliableSession) attach(client *WSClient) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.expire != nil {
+		s.expire.Stop()
+		s.expire = nil
+	}
+	s.client = client
+	client.session = s
+	if len(s.window) > 0 {
+		klog.Infof("retransmit %d messages to %s", len(s.window), s.owner)
+	}
+	for _, p := range s.window {
+		if err := client.writeRaw(p.frame); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+detach unbinds the session from client, and calls expired after timeout if it is not attached again.
+A session negotiated but never attached, e.g., the peer is rejected, expires as well.
+*/
+func (s *reliableSession) detach(client *WSClient, timeout time.Duration, expired func()) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.client != nil && s.client != client {
+		return
+	}
+	s.client = nil
+	if expired != nil && s.expire == nil {
+		s.expire = time.AfterFunc(timeout, expired)
+	}
+}
+
+// attached returns if the session is bound to a connection.
+func (s *reliableSession) attached() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.client != nil
+}
+
+// reset forgets received sequence, it is called when the peer starts a new session.
+func (s *reliableSession) reset(id string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.id = id
+	s.received = 0
+	s.fresh = true
+}
+
+/*
+send puts msg to retransmit window and writes it to current connection.
+msg is accepted once it is in the window, it will be retransmitted
+on next connection even if it fails to write now.
+*/
+func (s *reliableSession) send(msg []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if len(s.window) >= ReliableWindowSize {
+		return ErrWindowFull
+	}
+	p := &pendingFrame{
+		seq:   s.nextSeq,
+		frame: encodeFrame(frameData, s.nextSeq, msg),
+	}
+	s.nextSeq++
+	s.window = append(s.window, p)
+
+	if s.client == nil {
+		return nil
+	}
+	if err := s.client.writeRaw(p.frame); err != nil {
+		klog.Warningf("write message %d to %s failed, retransmit it later: %v", p.seq, s.owner, err)
+	}
+	return nil
+}
+
+// acked removes messages acknowledged by the peer from retransmit window.
+func (s *reliableSession) acked(seq uint64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	n := 0
+	for n < len(s.window) && s.window[n].seq <= seq {
+		n++
+	}
+	s.window = s.window[n:]
+}
+
+/*
+receive handles a frame from the peer.
+It returns the payload and true if the payload should be delivered,
+and the sequence to acknowledge if it is a data frame.
+*/
+func (s *reliableSession) receive(frame []byte) ([]byte, bool, uint64, error) {
+	typ, seq, payload, err := decodeFrame(frame)
+	if err != nil {
+		return nil, false, 0, err
+	}
+
+	switch typ {
+	case frameAck:
+		s.acked(seq)
+		return nil, false, 0, nil
+	case frameData:
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		if s.fresh {
+			s.fresh = false
+			s.received = seq - 1
+		}
+		if seq <= s.received {
+			klog.V(3).Infof("drop duplicated message %d from %s", seq, s.owner)
+			return nil, false, s.received, nil
+		}
+		if seq > s.received+1 {
+			klog.Warningf("messages %d-%d from %s are lost", s.received+1, seq-1, s.owner)
+		}
+		s.received = seq
+		return payload, true, seq, nil
+	default:
+		return nil, false, 0, fmt.Errorf("unknown frame type %d", typ)
+	}
+}
+
+// reliableSessions keeps sessions of a server by session id.
+type reliableSessions struct {
+	sessions sync.Map
+}
+
+/*
+negotiate finds the session to use for the peer named owner.
+It returns nil if the peer does not ask for reliable delivery or it is disabled,
+otherwise the session id is set in header to answer the peer.
+*/
+func (rs *reliableSessions) negotiate(owner string, r *http.Request, header http.Header) *reliableSession {
+	id := r.Header.Get(config.ClusterConnectHeaderDeliverySession)
+	if !ReliableDelivery || id == "" {
+		return nil
+	}
+
+	if value, ok := rs.sessions.Load(id); ok {
+		session := value.(*reliableSession)
+		if session.owner == owner && !session.attached() {
+			klog.Infof("resume reliable delivery session %s of %s", id, owner)
+			header.Set(config.ClusterConnectHeaderDeliverySession, id)
+			return session
+		}
+	}
+
+	session := newReliableSession(owner)
+	rs.sessions.Store(session.id, session)
+	klog.Infof("new reliable delivery session %s of %s", session.id, owner)
+	header.Set(config.ClusterConnectHeaderDeliverySession, session.id)
+	return session
+}
+
+// release detaches session from client and removes it if it is not resumed in time.
+func (rs *reliableSessions) release(session *reliableSession, client *WSClient) {
+	session.detach(client, ReliableSessionTimeout, func() {
+		if session.attached() {
+			return
+		}
+		klog.Infof("reliable delivery session %s of %s expired", session.id, session.owner)
+		rs.sessions.Delete(session.id)
+	})
+}
+
+// dialSession is the session kept by a dialer across reconnections.
+type dialSession struct {
+	session *reliableSession
+}
+
+// header sets session id to ask for reliable delivery if it is enabled.
+func (d *dialSession) header(owner string, header http.Header) {
+	if !ReliableDelivery {
+		return
+	}
+	if d.session == nil {
+		d.session = newReliableSession(owner)
+	}
+	header.Set(config.ClusterConnectHeaderDeliverySession, d.session.id)
+}
+
+// attach binds the session to client if the server answers a session id.
+func (d *dialSession) attach(resp *http.Response, client *WSClient) error {
+	if d.session == nil || resp == nil {
+		return nil
+	}
+	id := resp.Header.Get(config.ClusterConnectHeaderDeliverySession)
+	if id == "" {
+		klog.Infof("peer of %s does not support reliable delivery", client.Name)
+		return nil
+	}
+	if id != d.session.id {
+		// the server starts a new session, messages in window are sent in it.
+		d.session.reset(id)
+	}
+	return d.session.attach(client)
+}
+
+// release detaches the session from client, messages sent later are kept in window until next attach.
+func (d *dialSession) release(client *WSClient) {
+	if d.session != nil {
+		d.session.detach(client, 0, nil)
+	}
+}
@@ -0,0 +1,227 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/baidu/ote-stack/pkg/config"
+)
+
+func TestReliableSessionReceive(t *testing.T) {
+	s := newReliableSession("c1")
+
+	_, _, _, err := s.receive([]byte{frameData})
+	assert.NotNil(t, err)
+	_, _, _, err = s.receive(encodeFrame(3, 1, nil))
+	assert.NotNil(t, err)
+
+	// first sequence of a fresh session is accepted as start.
+	msg, deliver, ack, err := s.receive(encodeFrame(frameData, 5, []byte("m5")))
+	assert.Nil(t, err)
+	assert.True(t, deliver)
+	assert.Equal(t, "m5", string(msg))
+	assert.Equal(t, uint64(5), ack)
+
+	// duplicated message is acked but not delivered.
+	_, deliver, ack, err = s.receive(encodeFrame(frameData, 5, []byte("m5")))
+	assert.Nil(t, err)
+	assert.False(t, deliver)
+	assert.Equal(t, uint64(5), ack)
+
+	msg, deliver, ack, err = s.receive(encodeFrame(frameData, 6, []byte("m6")))
+	assert.Nil(t, err)
+	assert.True(t, deliver)
+	assert.Equal(t, "m6", string(msg))
+	assert.Equal(t, uint64(6), ack)
+
+	// ack removes messages from window.
+	assert.Nil(t, s.send([]byte("a")))
+	assert.Nil(t, s.send([]byte("b")))
+	assert.Nil(t, s.send([]byte("c")))
+	assert.Equal(t, 3, len(s.window))
+	_, deliver, ack, err = s.receive(encodeFrame(frameAck, 2, nil))
+	assert.Nil(t, err)
+	assert.False(t, deliver)
+	assert.Equal(t, uint64(0), ack)
+	assert.Equal(t, 1, len(s.window))
+	assert.Equal(t, uint64(3), s.window[0].seq)
+}
+
+func TestReliableSessionWindow(t *testing.T) {
+	size := ReliableWindowSize
+	ReliableWindowSize = 2
+	defer func() {
+		ReliableWindowSize = size
+	}()
+
+	s := newReliableSession("c1")
+	assert.Nil(t, s.send([]byte("a")))
+	assert.Nil(t, s.send([]byte("b")))
+	assert.Equal(t, ErrWindowFull, s.send([]byte("c")))
+	s.acked(1)
+	assert.Nil(t, s.send([]byte("c")))
+}
+
+func TestReliableSessionsRelease(t *testing.T) {
+	ReliableDelivery = true
+	oldTimeout := ReliableSessionTimeout
+	defer func() {
+		ReliableDelivery = false
+		ReliableSessionTimeout = oldTimeout
+	}()
+	ReliableSessionTimeout = 10 * time.Millisecond
+
+	rs := &reliableSessions{}
+	negotiate := func() *reliableSession {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set(config.ClusterConnectHeaderDeliverySession, "new")
+		return rs.negotiate("c1", r, http.Header{})
+	}
+	exists := func(s *reliableSession) bool {
+		_, ok := rs.sessions.Load(s.id)
+		return ok
+	}
+
+	// an attached session is kept while another client is released.
+	attached := negotiate()
+	client := &WSClient{}
+	attached.mutex.Lock()
+	attached.client = client
+	attached.mutex.Unlock()
+	rs.release(attached, &WSClient{})
+	time.Sleep(50 * time.Millisecond)
+	assert.True(t, exists(attached))
+
+	// a session never attached, e.g., of a duplicated connection, expires.
+	rejected := negotiate()
+	rs.release(rejected, &WSClient{})
+	eventually(t, func() bool { return !exists(rejected) }, time.Second, 10*time.Millisecond)
+
+	rs.release(attached, client)
+	eventually(t, func() bool { return !exists(attached) }, time.Second, 10*time.Millisecond)
+}
+
+func TestReliableDelivery(t *testing.T) {
+	ReliableDelivery = true
+	defer func() {
+		ReliableDelivery = false
+	}()
+
+	var mutex sync.Mutex
+	received := make([]string, 0)
+	ct := NewCloudTunnel("127.0.0.1:0", nil).(*cloudTunnel)
+	ct.RegistReturnMessageFunc(func(client string, msg []byte) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		received = append(received, string(msg))
+		return nil
+	})
+	assert.Nil(t, ct.Start())
+	defer ct.Stop()
+
+	e := &edgeTunnel{
+		name:                "c1",
+		cloudAddr:           ct.server.Addr,
+		listenAddr:          "fake",
+		conf:                &config.ClusterControllerConfig{},
+		afterConnectToHook:  func() {},
+		afterDisconnectHook: func() {},
+		receiveMessageHandler: func(client string, msg []byte) error {
+			return nil
+		},
+	}
+	assert.Nil(t, e.connect())
+	assert.NotNil(t, e.wsclient.session)
+	id := e.delivery.session.id
+
+	receivedCount := func(n int) func() bool {
+		return func() bool {
+			mutex.Lock()
+			defer mutex.Unlock()
+			return len(received) == n
+		}
+	}
+	assert.Nil(t, e.Send([]byte("m1")))
+	go e.handleReceiveMessage()
+	eventually(t, receivedCount(1), time.Second, 10*time.Millisecond)
+	eventually(t, func() bool {
+		e.delivery.session.mutex.Lock()
+		defer e.delivery.session.mutex.Unlock()
+		return len(e.delivery.session.window) == 0
+	}, time.Second, 10*time.Millisecond)
+
+	// message sent while disconnected is retransmitted after reconnecting.
+	e.wsclient.Close()
+	e.delivery.release(e.wsclient)
+	assert.Nil(t, e.Send([]byte("m2")))
+	eventually(t, func() bool {
+		_, ok := ct.clients.Load("c1")
+		return !ok
+	}, time.Second, 10*time.Millisecond)
+	assert.Nil(t, e.connect())
+	assert.Equal(t, id, e.delivery.session.id)
+	go e.handleReceiveMessage()
+	eventually(t, receivedCount(2), time.Second, 10*time.Millisecond)
+
+	// duplicated message is dropped by the receiver.
+	e.delivery.session.mutex.Lock()
+	e.delivery.session.window = []*pendingFrame{{seq: 2, frame: encodeFrame(frameData, 2, []byte("m2"))}}
+	e.delivery.session.mutex.Unlock()
+	assert.Nil(t, e.delivery.session.attach(e.wsclient))
+	assert.Nil(t, e.Send([]byte("m3")))
+	eventually(t, receivedCount(3), time.Second, 10*time.Millisecond)
+	mutex.Lock()
+	assert.Equal(t, []string{"m1", "m2", "m3"}, received)
+	mutex.Unlock()
+}
+
+func TestReliableDeliveryWithOldPeer(t *testing.T) {
+	ReliableDelivery = true
+	defer func() {
+		ReliableDelivery = false
+	}()
+
+	// test server does not answer delivery session.
+	e := newTestEdgeTunnel()
+	assert.Nil(t, e.connect())
+	assert.NotNil(t, e.delivery.session)
+	assert.Nil(t, e.wsclient.session)
+	assert.Nil(t, e.Send([]byte("test")))
+	msg, err := e.wsclient.ReadMessage()
+	assert.Nil(t, err)
+	assert.Equal(t, "test", string(msg))
+}
+
+// eventually is assert.Eventually checking condition in the calling goroutine,
+// testify v1.4 may panic once a check started by a late tick ends after it returns.
+func eventually(t *testing.T, condition func() bool, waitFor, tick time.Duration, msgAndArgs ...interface{}) bool {
+	deadline := time.Now().Add(waitFor)
+	for !condition() {
+		if time.Now().After(deadline) {
+			return assert.Fail(t, "Condition never satisfied", msgAndArgs...)
+		}
+		time.Sleep(tick)
+	}
+	return true
+}
@@ -0,0 +1,273 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"k8s.io/klog"
+
+	"github.com/baidu/ote-stack/pkg/clustermessage"
+)
+
+/*
+Messages from clusters are routed to controller managers by consistent hash of
+ClusterName in message head, so messages of a cluster always go to the same manager in order.
+When a manager connects or disconnects, only clusters of its shard move,
+and root cluster controller sends ShardAssign to every manager with the members of the ring,
+so a manager knows which clusters it owns by the same ring.
+*/
+
+const (
+	// hashRingReplicas is the number of points of each member on the ring.
+	hashRingReplicas = 100
+)
+
+// HashRing is a consistent hash ring of members, it is not safe for concurrent use.
+type HashRing struct {
+	points  []uint64
+	owners  map[uint64]string
+	members map[string]struct{}
+}
+
+// NewHashRing returns a ring of members.
+func NewHashRing(members ...string) *HashRing {
+	r := &HashRing{
+		owners:  make(map[uint64]string),
+		members: make(map[string]struct{}),
+	}
+	for _, m := range members {
+		r.Add(m)
+	}
+	return r
+}
+
+// hashKey hashes key by md5, which spreads similar keys well.
+func hashKey(key string) uint64 {
+	sum := md5.Sum([]byte(key))
+	return binary.BigEndian.Uint64(sum[:8])
+}
+
+// Add puts member to the ring.
+func (r *HashRing) Add(member string) {
+	if _, ok := r.members[member]; ok {
+		return
+	}
+	r.members[member] = struct{}{}
+	for i := 0; i < hashRingReplicas; i++ {
+		point := hashKey(fmt.Sprintf("%s#%d", member, i))
+		if owner, ok := r.owners[point]; ok && owner < member {
+			// the smaller one wins a collision, so it is the same on every ring.
+			continue
+		}
+		if _, ok := r.owners[point]; !ok {
+			r.points = append(r.points, point)
+		}
+		r.owners[point] = member
+	}
+	sort.Slice(r.points, func(i, j int) bool { return r.points[i] < r.points[j] })
+}
+
+// Remove deletes member from the ring.
+func (r *HashRing) Remove(member string) {
+	if _, ok := r.members[member]; !ok {
+		return
+	}
+	delete(r.members, member)
+	members := r.Members()
+	r.points = nil
+	r.owners = make(map[uint64]string)
+	r.members = make(map[string]struct{})
+	for _, m := range members {
+		r.Add(m)
+	}
+}
+
+// Get returns the member owning key, empty if the ring is empty.
+func (r *HashRing) Get(key string) string {
+	if len(r.points) == 0 {
+		return ""
+	}
+	h := hashKey(key)
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i] >= h })
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.owners[r.points[i]]
+}
+
+// Members returns members of the ring in order.
+func (r *HashRing) Members() []string {
+	ret := make([]string, 0, len(r.members))
+	for m := range r.members {
+		ret = append(ret, m)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// Len returns number of members.
+func (r *HashRing) Len() int {
+	return len(r.members)
+}
+
+// controllerShards keeps connected controller managers and routes messages to them by cluster name.
+type controllerShards struct {
+	mutex   sync.RWMutex
+	ring    *HashRing
+	clients map[string]*WSClient // member -> client
+	// rebalanceMutex keeps assignments to a manager in order.
+	rebalanceMutex sync.Mutex
+}
+
+func newControllerShards() *controllerShards {
+	return &controllerShards{
+		ring:    NewHashRing(),
+		clients: make(map[string]*WSClient),
+	}
+}
+
+// add puts client of member to the ring, it returns false if member is already connected.
+func (s *controllerShards) add(member string, client *WSClient) bool {
+	s.mutex.Lock()
+	if _, ok := s.clients[member]; ok {
+		s.mutex.Unlock()
+		return false
+	}
+	s.clients[member] = client
+	s.ring.Add(member)
+	s.mutex.Unlock()
+
+	s.rebalance()
+	return true
+}
+
+// remove deletes member if its client is client.
+func (s *controllerShards) remove(member string, client *WSClient) {
+	s.mutex.Lock()
+	if c, ok := s.clients[member]; !ok || c != client {
+		s.mutex.Unlock()
+		return
+	}
+	delete(s.clients, member)
+	s.ring.Remove(member)
+	s.mutex.Unlock()
+
+	s.rebalance()
+}
+
+// pick returns the client owning cluster, nil if there is no one.
+func (s *controllerShards) pick(cluster string) *WSClient {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.clients[s.ring.Get(cluster)]
+}
+
+// all returns clients of connected controller managers.
+func (s *controllerShards) all() []*WSClient {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	ret := make([]*WSClient, 0, len(s.clients))
+	for _, c := range s.clients {
+		ret = append(ret, c)
+	}
+	return ret
+}
+
+// len returns number of connected controller managers.
+func (s *controllerShards) len() int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.ring.Len()
+}
+
+// rebalance sends members of the ring to every controller manager.
+func (s *controllerShards) rebalance() {
+	s.rebalanceMutex.Lock()
+	defer s.rebalanceMutex.Unlock()
+
+	s.mutex.RLock()
+	members := s.ring.Members()
+	clients := make(map[string]*WSClient, len(s.clients))
+	for m, c := range s.clients {
+		clients[m] = c
+	}
+	s.mutex.RUnlock()
+
+	klog.Infof("controller managers are rebalanced: %v", members)
+	for member, client := range clients {
+		data, err := shardAssignMessage(member, members)
+		if err != nil {
+			klog.Errorf("make shard assignment failed: %v", err)
+			return
+		}
+		if err := client.WriteMessage(data); err != nil {
+			klog.Errorf("send shard assignment to controller %s failed: %v", member, err)
+		}
+	}
+}
+
+func shardAssignMessage(member string, members []string) ([]byte, error) {
+	body, err := proto.Marshal(&clustermessage.ShardAssignment{
+		Member:  member,
+		Members: members,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return proto.Marshal(&clustermessage.ClusterMessage{
+		Head: &clustermessage.MessageHead{
+			Command: clustermessage.CommandType_ShardAssign,
+		},
+		Body: body,
+	})
+}
+
+// Shard tells which clusters a controller manager owns.
+type Shard struct {
+	mutex  sync.RWMutex
+	member string
+	ring   *HashRing
+}
+
+// update applies a shard assignment.
+func (s *Shard) update(assignment *clustermessage.ShardAssignment) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.member = assignment.Member
+	s.ring = NewHashRing(assignment.Members...)
+	klog.Infof("shard of %s is assigned among %v", s.member, assignment.Members)
+}
+
+// Assigned checks if a shard is assigned by root cluster controller.
+func (s *Shard) Assigned() bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.ring != nil
+}
+
+// Owns checks if cluster is in the shard, it is false before the shard is assigned.
+func (s *Shard) Owns(cluster string) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.ring != nil && s.ring.Get(cluster) == s.member
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/baidu/ote-stack/pkg/clustermessage"
+)
+
+func TestHashRing(t *testing.T) {
+	r := NewHashRing()
+	assert.Equal(t, "", r.Get("c1"))
+
+	r = NewHashRing("m1", "m2", "m3")
+	assert.Equal(t, []string{"m1", "m2", "m3"}, r.Members())
+	// ring is the same regardless of order of members.
+	other := NewHashRing("m3", "m1", "m2")
+	owned := make(map[string]int)
+	before := make(map[string]string)
+	for i := 0; i < 1000; i++ {
+		key := fmt.Sprintf("c%d", i)
+		assert.Equal(t, r.Get(key), other.Get(key))
+		before[key] = r.Get(key)
+		owned[before[key]]++
+	}
+	for _, m := range r.Members() {
+		assert.True(t, owned[m] > 100, "%s owns %d keys", m, owned[m])
+	}
+
+	// only keys of the removed member move.
+	r.Remove("m2")
+	assert.Equal(t, 2, r.Len())
+	for key, m := range before {
+		if m != "m2" {
+			assert.Equal(t, m, r.Get(key))
+		} else {
+			assert.NotEqual(t, "m2", r.Get(key))
+		}
+	}
+	// and they are back once it is added.
+	r.Add("m2")
+	for key, m := range before {
+		assert.Equal(t, m, r.Get(key))
+	}
+}
+
+func TestShardedControllerManagers(t *testing.T) {
+	ct := NewCloudTunnel("127.0.0.1:0", nil).(*cloudTunnel)
+	assert.Nil(t, ct.Start())
+	defer ct.Stop()
+
+	received := make(map[string]chan string)
+	tunnels := make(map[string]*controllerTunnel)
+	for _, id := range []string{"m1", "m2"} {
+		id := id
+		received[id] = make(chan string, 100)
+		tun := &controllerTunnel{
+			id:                 id,
+			shard:              &Shard{},
+			cloudAddr:          ct.server.Addr,
+			afterConnectToHook: func() {},
+			receiveMessageHandler: func(client string, data []byte) error {
+				msg := &clustermessage.ClusterMessage{}
+				assert.Nil(t, proto.Unmarshal(data, msg))
+				received[id] <- msg.Head.ClusterName
+				return nil
+			},
+		}
+		assert.Nil(t, tun.connect())
+		go tun.handleReceiveMessage()
+		tunnels[id] = tun
+	}
+	eventually(t, func() bool {
+		return ct.controllers.len() == 2
+	}, time.Second, 10*time.Millisecond)
+
+	// messages of a cluster go to the manager owning it.
+	for i := 0; i < 20; i++ {
+		cluster := fmt.Sprintf("c%d", i)
+		data, err := proto.Marshal(&clustermessage.ClusterMessage{
+			Head: &clustermessage.MessageHead{ClusterName: cluster},
+		})
+		assert.Nil(t, err)
+		assert.Nil(t, ct.SendToControllerManager(data))
+
+		var got string
+		select {
+		case got = <-received["m1"]:
+			assert.True(t, tunnels["m1"].Shard().Owns(cluster))
+		case got = <-received["m2"]:
+			assert.True(t, tunnels["m2"].Shard().Owns(cluster))
+		case <-time.After(time.Second):
+			assert.Fail(t, "message is not received", cluster)
+		}
+		assert.Equal(t, cluster, got)
+	}
+
+	// all clusters are rebalanced to the manager left.
+	tunnels["m2"].wsclient.Close()
+	eventually(t, func() bool {
+		return ct.controllers.len() == 1 && tunnels["m1"].Shard().Owns("c0") && tunnels["m1"].Shard().Owns("c1")
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,203 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// LinkStatsPeriod is the interval to log traffic stats of each link, 0 to disable.
+	LinkStatsPeriod = 10 * time.Minute
+)
+
+// LinkStats is traffic of a link.
+// Bytes are sizes of messages, and WireBytes are sizes sent or received on the network connection,
+// which include compression and overhead of the transport.
+// If the network connection is not counted, WireBytes are sizes after zstd compression.
+type LinkStats struct {
+	SentMessages      uint64
+	SentBytes         uint64
+	SentWireBytes     uint64
+	ReceivedMessages  uint64
+	ReceivedBytes     uint64
+	ReceivedWireBytes uint64
+}
+
+// SentRatio returns ratio of wire bytes to message bytes sent, 0 if nothing sent.
+func (s LinkStats) SentRatio() float64 {
+	return ratio(s.SentWireBytes, s.SentBytes)
+}
+
+// ReceivedRatio returns ratio of wire bytes to message bytes received, 0 if nothing received.
+func (s LinkStats) ReceivedRatio() float64 {
+	return ratio(s.ReceivedWireBytes, s.ReceivedBytes)
+}
+
+func (s LinkStats) String() string {
+	return fmt.Sprintf("sent %d messages %d bytes, %d on wire (ratio %.2f), "+
+		"received %d messages %d bytes, %d on wire (ratio %.2f)",
+		s.SentMessages, s.SentBytes, s.SentWireBytes, s.SentRatio(),
+		s.ReceivedMessages, s.ReceivedBytes, s.ReceivedWireBytes, s.ReceivedRatio())
+}
+
+// LinkInfo is the state of a link.
+type LinkInfo struct {
+	ConnectedAt time.Time `json:"connectedAt"`
+	// LastMessageAt is the time the last message is received, zero if none.
+	LastMessageAt time.Time `json:"lastMessageAt"`
+	Stats         LinkStats `json:"stats"`
+}
+
+func ratio(wire, bytes uint64) float64 {
+	if bytes == 0 {
+		return 0
+	}
+	return float64(wire) / float64(bytes)
+}
+
+// linkCounter counts messages of a WSClient, it is updated concurrently.
+type linkCounter struct {
+	sentMessages     uint64
+	sentBytes        uint64
+	sentEncoded      uint64
+	receivedMessages uint64
+	receivedBytes    uint64
+	receivedEncoded  uint64
+	// lastReceived is unix nano of the last received message.
+	lastReceived int64
+}
+
+func (c *linkCounter) sent(bytes, encoded int) {
+	atomic.AddUint64(&c.sentMessages, 1)
+	atomic.AddUint64(&c.sentBytes, uint64(bytes))
+	atomic.AddUint64(&c.sentEncoded, uint64(encoded))
+}
+
+func (c *linkCounter) received(bytes, encoded int) {
+	atomic.AddUint64(&c.receivedMessages, 1)
+	atomic.AddUint64(&c.receivedBytes, uint64(bytes))
+	atomic.AddUint64(&c.receivedEncoded, uint64(encoded))
+	atomic.StoreInt64(&c.lastReceived, time.Now().UnixNano())
+}
+
+// lastReceivedTime returns time of the last received message, zero if none.
+func (c *linkCounter) lastReceivedTime() time.Time {
+	last := atomic.LoadInt64(&c.lastReceived)
+	if last == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, last)
+}
+
+// stats returns stats of the link, wire is nil if the network connection is not counted.
+func (c *linkCounter) stats(wire *wireCounter) LinkStats {
+	s := LinkStats{
+		SentMessages:      atomic.LoadUint64(&c.sentMessages),
+		SentBytes:         atomic.LoadUint64(&c.sentBytes),
+		SentWireBytes:     atomic.LoadUint64(&c.sentEncoded),
+		ReceivedMessages:  atomic.LoadUint64(&c.receivedMessages),
+		ReceivedBytes:     atomic.LoadUint64(&c.receivedBytes),
+		ReceivedWireBytes: atomic.LoadUint64(&c.receivedEncoded),
+	}
+	if wire != nil {
+		s.SentWireBytes = atomic.LoadUint64(&wire.written)
+		s.ReceivedWireBytes = atomic.LoadUint64(&wire.read)
+	}
+	return s
+}
+
+// wireCounter counts bytes of a network connection.
+type wireCounter struct {
+	read    uint64
+	written uint64
+}
+
+// wireCounted is implemented by Conn which counts bytes of its network connection.
+type wireCounted interface {
+	wireCounter() *wireCounter
+}
+
+// countingConn is a network connection counting bytes.
+type countingConn struct {
+	net.Conn
+	counter   *wireCounter
+	closeOnce sync.Once
+	onClose   func()
+}
+
+func newCountingConn(conn net.Conn, counter *wireCounter) *countingConn {
+	return &countingConn{Conn: conn, counter: counter}
+}
+
+func (c *countingConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	atomic.AddUint64(&c.counter.read, uint64(n))
+	return n, err
+}
+
+func (c *countingConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	atomic.AddUint64(&c.counter.written, uint64(n))
+	return n, err
+}
+
+func (c *countingConn) Close() error {
+	if c.onClose != nil {
+		c.closeOnce.Do(c.onClose)
+	}
+	return c.Conn.Close()
+}
+
+// countingListener counts bytes of accepted connections,
+// and keeps their counters by remote address until they are closed.
+type countingListener struct {
+	net.Listener
+	counters sync.Map // remote addr -> *wireCounter
+}
+
+func newCountingListener(ln net.Listener) *countingListener {
+	return &countingListener{Listener: ln}
+}
+
+func (l *countingListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	addr := conn.RemoteAddr().String()
+	c := newCountingConn(conn, &wireCounter{})
+	c.onClose = func() { l.counters.Delete(addr) }
+	l.counters.Store(addr, c.counter)
+	return c, nil
+}
+
+// counter returns counter of the connection from remoteAddr, or nil if it is not found.
+func (l *countingListener) counter(remoteAddr string) *wireCounter {
+	if l == nil {
+		return nil
+	}
+	counter, ok := l.counters.Load(remoteAddr)
+	if !ok {
+		return nil
+	}
+	return counter.(*wireCounter)
+}
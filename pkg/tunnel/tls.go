@@ -0,0 +1,129 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+const (
+	schemeWS  = "ws"
+	schemeWSS = "wss"
+)
+
+// NewServerTLSConfig returns tls config for cloud tunnel.
+// It returns nil if certFile or keyFile is empty, which means tls is disabled.
+// If caFile is set, client must present a certificate signed by the ca (mutual tls).
+func NewServerTLSConfig(certFile, keyFile, caFile string) (*tls.Config, error) {
+	if certFile == "" || keyFile == "" {
+		return nil, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("load tunnel certificate failed: %v", err)
+	}
+	ret := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if caFile != "" {
+		pool, err := loadCertPool(caFile)
+		if err != nil {
+			return nil, err
+		}
+		ret.ClientCAs = pool
+		ret.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return ret, nil
+}
+
+// NewClientTLSConfig returns tls config for edge tunnel and controller tunnel.
+// It returns nil if all files are empty, which means tls is disabled.
+// Server certificate is verified by caFile, or by system roots if caFile is empty.
+// certFile and keyFile are optional, they are presented as client certificate if set.
+func NewClientTLSConfig(certFile, keyFile, caFile string) (*tls.Config, error) {
+	if certFile == "" && keyFile == "" && caFile == "" {
+		return nil, nil
+	}
+
+	ret := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load tunnel client certificate failed: %v", err)
+		}
+		ret.Certificates = []tls.Certificate{cert}
+	}
+	if caFile != "" {
+		pool, err := loadCertPool(caFile)
+		if err != nil {
+			return nil, err
+		}
+		ret.RootCAs = pool
+	}
+	return ret, nil
+}
+
+func loadCertPool(caFile string) (*x509.CertPool, error) {
+	data, err := ioutil.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("read tunnel ca %s failed: %v", caFile, err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no valid certificate found in tunnel ca %s", caFile)
+	}
+	return pool, nil
+}
+
+// tunnelScheme returns websocket scheme according to tls config.
+func tunnelScheme(tlsConfig *tls.Config) string {
+	if tlsConfig == nil {
+		return schemeWS
+	}
+	return schemeWSS
+}
+
+// checkClusterCertificate checks the client certificate of a request
+// which is presented by the cluster named clusterName.
+// The common name or one of the dns names of the certificate should be the cluster name.
+// It does nothing if no client certificate presented.
+func checkClusterCertificate(r *http.Request, clusterName string) error {
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return nil
+	}
+
+	cert := r.TLS.PeerCertificates[0]
+	if cert.Subject.CommonName == clusterName {
+		return nil
+	}
+	for _, name := range cert.DNSNames {
+		if name == clusterName {
+			return nil
+		}
+	}
+	return fmt.Errorf("certificate of %s is not issued to cluster %s",
+		cert.Subject.CommonName, clusterName)
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/baidu/ote-stack/pkg/config"
+)
+
+type testCertFiles struct {
+	dir      string
+	caFile   string
+	certFile string
+	keyFile  string
+}
+
+// newTestCertFiles writes a ca and a certificate signed by the ca with common name cn.
+func newTestCertFiles(t *testing.T, cn string) *testCertFiles {
+	dir, err := ioutil.TempDir("", "tunnel-tls")
+	assert.Nil(t, err)
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDer, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	assert.Nil(t, err)
+	ca, err := x509.ParseCertificate(caDer)
+	assert.Nil(t, err)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	assert.Nil(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.Nil(t, err)
+
+	ret := &testCertFiles{
+		dir:      dir,
+		caFile:   filepath.Join(dir, "ca.crt"),
+		certFile: filepath.Join(dir, "tls.crt"),
+		keyFile:  filepath.Join(dir, "tls.key"),
+	}
+	writePem := func(file, typ string, data []byte) {
+		err := ioutil.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: data}), 0600)
+		assert.Nil(t, err)
+	}
+	writePem(ret.caFile, "CERTIFICATE", caDer)
+	writePem(ret.certFile, "CERTIFICATE", der)
+	writePem(ret.keyFile, "EC PRIVATE KEY", keyDer)
+	return ret
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	files := newTestCertFiles(t, "c1")
+	defer os.RemoveAll(files.dir)
+
+	conf, err := NewServerTLSConfig("", "", "")
+	assert.Nil(t, err)
+	assert.Nil(t, conf)
+	conf, err = NewServerTLSConfig(files.certFile, files.keyFile, "")
+	assert.Nil(t, err)
+	assert.Equal(t, tls.NoClientCert, conf.ClientAuth)
+	conf, err = NewServerTLSConfig(files.certFile, files.keyFile, files.caFile)
+	assert.Nil(t, err)
+	assert.Equal(t, tls.RequireAndVerifyClientCert, conf.ClientAuth)
+	_, err = NewServerTLSConfig(files.certFile, files.keyFile, "notexist")
+	assert.NotNil(t, err)
+
+	conf, err = NewClientTLSConfig("", "", "")
+	assert.Nil(t, err)
+	assert.Nil(t, conf)
+	conf, err = NewClientTLSConfig("", "", files.caFile)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(conf.Certificates))
+	conf, err = NewClientTLSConfig(files.certFile, files.keyFile, files.caFile)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(conf.Certificates))
+
+	assert.Equal(t, schemeWS, tunnelScheme(nil))
+	assert.Equal(t, schemeWSS, tunnelScheme(conf))
+}
+
+func TestCheckClusterCertificate(t *testing.T) {
+	r := &http.Request{}
+	assert.Nil(t, checkClusterCertificate(r, "c1"))
+
+	r.TLS = &tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{
+			{
+				Subject:  pkix.Name{CommonName: "c1"},
+				DNSNames: []string{"c2"},
+			},
+		},
+	}
+	assert.Nil(t, checkClusterCertificate(r, "c1"))
+	assert.Nil(t, checkClusterCertificate(r, "c2"))
+	assert.NotNil(t, checkClusterCertificate(r, "c3"))
+}
+
+func TestMutualTLSTunnel(t *testing.T) {
+	testMutualTLSTunnel(t, true)
+
+	// grpc transport is tls handshaked by protocolMux, the certificate is checked as well.
+	// grpc retries a failed tls handshake until timeout, which is not tested.
+	name := TransportName
+	TransportName = TransportGRPC
+	defer func() {
+		TransportName = name
+	}()
+	testMutualTLSTunnel(t, false)
+}
+
+func testMutualTLSTunnel(t *testing.T, handshakeFailures bool) {
+	files := newTestCertFiles(t, "c1")
+	defer os.RemoveAll(files.dir)
+
+	serverConf, err := NewServerTLSConfig(files.certFile, files.keyFile, files.caFile)
+	assert.Nil(t, err)
+	ct := NewCloudTunnel("127.0.0.1:0", serverConf).(*cloudTunnel)
+	assert.Nil(t, ct.Start())
+	defer ct.Stop()
+
+	newEdge := func(name string, conf *config.ClusterControllerConfig) *edgeTunnel {
+		e := &edgeTunnel{
+			conf:               conf,
+			name:               name,
+			cloudAddr:          ct.server.Addr,
+			listenAddr:         "fake",
+			afterConnectToHook: func() {},
+		}
+		assert.Nil(t, e.initDialer())
+		return e
+	}
+
+	// connect with the certificate issued to c1
+	e := newEdge("c1", &config.ClusterControllerConfig{
+		TunnelCertFile: files.certFile,
+		TunnelKeyFile:  files.keyFile,
+		TunnelCAFile:   files.caFile,
+	})
+	assert.Nil(t, e.connect())
+	e.wsclient.Close()
+
+	// the certificate is not issued to c2
+	e = newEdge("c2", &config.ClusterControllerConfig{
+		TunnelCertFile: files.certFile,
+		TunnelKeyFile:  files.keyFile,
+		TunnelCAFile:   files.caFile,
+	})
+	assert.NotNil(t, e.connect())
+	if !handshakeFailures {
+		return
+	}
+
+	// no client certificate
+	e = newEdge("c1", &config.ClusterControllerConfig{
+		TunnelCAFile: files.caFile,
+	})
+	assert.NotNil(t, e.connect())
+
+	// plain websocket to tls server
+	e = newEdge("c1", &config.ClusterControllerConfig{})
+	assert.NotNil(t, e.connect())
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+/*
+Transport carries messages of tunnels between a parent and its childs,
+controller managers or cluster shim.
+
+A connection request is an http request with path and header in any transport,
+so the server handles requests of all transports with the same http handler,
+which answers the request by http status and header, or accepts it by Transport.Accept.
+The dialer gets the answer as an http response.
+*/
+
+// Transport* are the names of transports, set by TransportName.
+const (
+	TransportWebsocket = "websocket"
+	TransportGRPC      = "grpc"
+)
+
+var (
+	// TransportName is the transport of tunnels, both ends of a tunnel must use the same one.
+	TransportName = TransportWebsocket
+)
+
+// Conn is a connection of a transport which carries binary messages.
+// A dead peer is detected by heartbeat of the transport, so ReadMessage returns error in bounded time.
+type Conn interface {
+	// ReadMessage blocks until a message is read or the connection is broken.
+	ReadMessage() ([]byte, error)
+	// WriteMessage writes a message, it is not called concurrently.
+	WriteMessage(msg []byte) error
+	// Close closes the connection.
+	Close() error
+}
+
+// Transport dials and accepts connections of tunnels.
+type Transport interface {
+	// Dial connects to path of the server at addr, header is presented to the server.
+	// The answer of the server is returned as resp if the server answers.
+	Dial(addr, path string, header http.Header) (conn Conn, resp *http.Response, err error)
+	// Accept accepts a request which is being handled by handler of server,
+	// header is answered to the dialer.
+	Accept(w http.ResponseWriter, r *http.Request, header http.Header) (Conn, error)
+	// Serve serves requests on ln by handler of server, it blocks until Shutdown.
+	Serve(server *http.Server, ln net.Listener) error
+	// Shutdown stops serving gracefully.
+	Shutdown(ctx context.Context) error
+}
+
+// NewTransport returns the transport named by TransportName,
+// tlsConfig is used to serve or dial with tls if it is not nil,
+// and proxy is used to dial, proxy from environment is used if it is nil.
+// It fails if Compression is unknown or its dictionary can not be loaded.
+func NewTransport(tlsConfig *tls.Config, proxy *ProxyConfig) (Transport, error) {
+	if MaxMessageSize <= 0 {
+		return nil, fmt.Errorf("max message size %d is not positive", MaxMessageSize)
+	}
+	if err := loadCompression(); err != nil {
+		return nil, err
+	}
+	switch TransportName {
+	case TransportWebsocket, "":
+		return newWebsocketTransport(tlsConfig, proxy), nil
+	case TransportGRPC:
+		return newGRPCTransport(tlsConfig, proxy), nil
+	default:
+		return nil, fmt.Errorf("unknown tunnel transport %s", TransportName)
+	}
+}
@@ -0,0 +1,232 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"k8s.io/klog"
+)
+
+var upgrader = websocket.Upgrader{}
+
+// websocketConn is a websocket connection with heartbeat.
+type websocketConn struct {
+	conn *websocket.Conn
+	wire *wireCounter
+
+	heartbeatInterval      time.Duration
+	heartbeatMissThreshold int
+	closed                 chan struct{}
+	closeOnce              sync.Once
+}
+
+// newWebsocketConn wraps conn and starts heartbeat with HeartbeatInterval and HeartbeatMissThreshold,
+// so that a dead peer is detected by ReadMessage in bounded time.
+func newWebsocketConn(conn *websocket.Conn) *websocketConn {
+	c := &websocketConn{
+		conn:                   conn,
+		heartbeatInterval:      HeartbeatInterval,
+		heartbeatMissThreshold: HeartbeatMissThreshold,
+		closed:                 make(chan struct{}),
+	}
+	conn.SetReadLimit(maxFrameSize())
+	c.startHeartbeat()
+	return c
+}
+
+// deadPeerTimeout returns the time without message from peer after which peer is dead.
+func (c *websocketConn) deadPeerTimeout() time.Duration {
+	return c.heartbeatInterval * time.Duration(c.heartbeatMissThreshold)
+}
+
+// extendReadDeadline resets read deadline once the peer is alive.
+func (c *websocketConn) extendReadDeadline() {
+	if c.heartbeatInterval <= 0 || c.heartbeatMissThreshold <= 0 {
+		return
+	}
+	c.conn.SetReadDeadline(time.Now().Add(c.deadPeerTimeout()))
+}
+
+// startHeartbeat pings the peer every heartbeat interval,
+// and resets read deadline once pong received.
+func (c *websocketConn) startHeartbeat() {
+	if c.heartbeatInterval <= 0 || c.heartbeatMissThreshold <= 0 {
+		return
+	}
+
+	c.extendReadDeadline()
+	c.conn.SetPongHandler(func(string) error {
+		c.extendReadDeadline()
+		return nil
+	})
+
+	go func() {
+		ticker := time.NewTicker(c.heartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-c.closed:
+				return
+			case <-ticker.C:
+				// WriteControl can be called concurrently with WriteMessage.
+				err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(WriteTimeout))
+				if err != nil {
+					// read deadline will be exceeded if peer is really dead.
+					klog.Warningf("websocket to %s send ping failed: %v", c.conn.RemoteAddr(), err)
+				}
+			}
+		}
+	}()
+}
+
+func (c *websocketConn) ReadMessage() ([]byte, error) {
+	_, message, err := c.conn.ReadMessage()
+	if err != nil {
+		return nil, err
+	}
+	// any message from the peer means it is alive.
+	c.extendReadDeadline()
+	return message, nil
+}
+
+func (c *websocketConn) WriteMessage(msg []byte) error {
+	c.conn.SetWriteDeadline(time.Now().Add(WriteTimeout))
+	return c.conn.WriteMessage(websocket.BinaryMessage, msg)
+}
+
+func (c *websocketConn) wireCounter() *wireCounter {
+	return c.wire
+}
+
+func (c *websocketConn) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+	})
+	return c.conn.Close()
+}
+
+// websocketTransport carries messages by websocket over http.
+type websocketTransport struct {
+	tlsConfig *tls.Config
+	proxy     *ProxyConfig
+	dialer    *websocket.Dialer
+	mutex     sync.Mutex
+	server    *http.Server
+	listener  *countingListener
+	shutdown  bool
+}
+
+func newWebsocketTransport(tlsConfig *tls.Config, proxy *ProxyConfig) *websocketTransport {
+	return &websocketTransport{
+		tlsConfig: tlsConfig,
+		proxy:     proxy,
+		dialer:    newDialer(tlsConfig, proxy),
+	}
+}
+
+// newDialer returns a websocket dialer which dials with tlsConfig through proxy.
+func newDialer(tlsConfig *tls.Config, proxy *ProxyConfig) *websocket.Dialer {
+	return &websocket.Dialer{
+		NetDial: func(network, addr string) (net.Conn, error) {
+			ctx, cancel := context.WithTimeout(context.Background(), handshakeTimeout)
+			defer cancel()
+			return proxy.dial(ctx, addr)
+		},
+		HandshakeTimeout:  handshakeTimeout,
+		TLSClientConfig:   tlsConfig,
+		EnableCompression: Compression == CompressionDeflate,
+	}
+}
+
+func (t *websocketTransport) Dial(addr, path string, header http.Header) (Conn, *http.Response, error) {
+	// count bytes of the network connection of this dial.
+	counter := &wireCounter{}
+	dialer := *t.dialer
+	dialer.NetDial = func(network, addr string) (net.Conn, error) {
+		conn, err := t.dialer.NetDial(network, addr)
+		if err != nil {
+			return nil, err
+		}
+		return newCountingConn(conn, counter), nil
+	}
+
+	u := url.URL{Scheme: tunnelScheme(t.tlsConfig), Host: addr, Path: path}
+	conn, resp, err := dialer.Dial(u.String(), header)
+	if err != nil {
+		return nil, resp, err
+	}
+	c := newWebsocketConn(conn)
+	c.wire = counter
+	return c, resp, nil
+}
+
+func (t *websocketTransport) Accept(w http.ResponseWriter, r *http.Request, header http.Header) (Conn, error) {
+	// permessage-deflate is accepted whenever the dialer offers it.
+	u := upgrader
+	u.EnableCompression = true
+	conn, err := u.Upgrade(w, r, header)
+	if err != nil {
+		return nil, err
+	}
+	c := newWebsocketConn(conn)
+	t.mutex.Lock()
+	c.wire = t.listener.counter(r.RemoteAddr)
+	t.mutex.Unlock()
+	return c, nil
+}
+
+func (t *websocketTransport) Serve(server *http.Server, ln net.Listener) error {
+	listener := newCountingListener(ln)
+	ln = listener
+	if t.tlsConfig != nil {
+		klog.Infof("websocket transport serves with tls")
+		ln = tls.NewListener(ln, t.tlsConfig)
+	}
+	t.mutex.Lock()
+	if t.shutdown {
+		t.mutex.Unlock()
+		return ln.Close()
+	}
+	t.server = server
+	t.listener = listener
+	t.mutex.Unlock()
+
+	if err := server.Serve(ln); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+func (t *websocketTransport) Shutdown(ctx context.Context) error {
+	t.mutex.Lock()
+	server := t.server
+	t.shutdown = true
+	t.mutex.Unlock()
+
+	if server == nil {
+		return nil
+	}
+	return server.Shutdown(ctx)
+}
@@ -14,7 +14,8 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-// Package tunnel implements websocket communication between the cloud server and edge clients.
+// Package tunnel implements communication between the cloud server and edge clients,
+// over websocket or another Transport.
 package tunnel
 
 import (
@@ -24,6 +25,7 @@ import (
 	"github.com/gorilla/websocket"
 	"k8s.io/klog"
 
+	"github.com/baidu/ote-stack/pkg/clustermessage"
 	"github.com/baidu/ote-stack/pkg/config"
 )
 
@@ -32,15 +34,38 @@ const (
 	ReadTimeout  = time.Second * 15
 	IdleTimeout  = time.Second * 60
 	StopTimeout  = time.Second * 15
+
+	handshakeTimeout = time.Second * 45
+)
+
+var (
+	// HeartbeatInterval is the interval to send ping to the peer,
+	// heartbeat is disabled if it is not positive.
+	HeartbeatInterval = time.Second * 10
+	// HeartbeatMissThreshold is the number of heartbeat intervals without
+	// any message or pong from the peer, after which the peer is considered dead.
+	HeartbeatMissThreshold = 3
 )
 
-// WSClient is a websocket client.
+// WSClient is a client of a tunnel connection, named after websocket which is the default transport.
 type WSClient struct {
 	// Name defines uuid of the client.
 	Name string
-	// Conn defines websocket connection.
-	Conn  *websocket.Conn
+	// Conn defines connection of the transport.
+	Conn  Conn
 	mutex sync.Mutex
+
+	// session is set if reliable delivery is negotiated on the connection.
+	session *reliableSession
+	// codec is set if zstd compression is negotiated on the connection.
+	codec *messageCodec
+	// peerMaxSize is the max message size of the peer, it is set if chunking is negotiated.
+	peerMaxSize int
+	reassembler *clustermessage.Reassembler
+	counter     linkCounter
+	connectedAt time.Time
+	closed      chan struct{}
+	closeOnce   sync.Once
 }
 
 // RedirectFunc is a function called before ClusterNameChecker,
@@ -50,6 +75,13 @@ type RedirectFunc func() string
 // ClusterNameChecker is a function to check cluster name.
 type ClusterNameChecker func(*config.ClusterRegistry) bool
 
+// ClusterAuthenticator is a function called before ClusterNameChecker
+// to authenticate a cluster with the token it presents.
+type ClusterAuthenticator func(token string, cr *config.ClusterRegistry) error
+
+// ControllerManagerAuthenticator is a function to authenticate a controller manager with the token it presents.
+type ControllerManagerAuthenticator func(token string) error
+
 // TunnelReadMessageFunc is a function to handle message from tunnel.
 // this function takes 2 arguments, first mean client name(cluster name),
 // the second is message data.
@@ -67,40 +99,187 @@ type AfterConnectToHook func()
 // AfterDisconnectHook is a function of edge tunnel to call after disconnect from parent.
 type AfterDisconnectHook func()
 
-// NewWSClient returns a websocket client.
+// NewWSClient returns a client of a websocket connection.
+// Heartbeat starts with HeartbeatInterval and HeartbeatMissThreshold,
+// so that a dead peer is detected by ReadMessage in bounded time.
 func NewWSClient(name string, conn *websocket.Conn) *WSClient {
-	wsclient := &WSClient{
-		Name: name,
-		Conn: conn,
+	return NewClient(name, newWebsocketConn(conn))
+}
+
+// NewClient returns a client of a connection of any transport.
+// Stats of the connection are logged every LinkStatsPeriod until it is closed.
+func NewClient(name string, conn Conn) *WSClient {
+	c := &WSClient{
+		Name:        name,
+		Conn:        conn,
+		reassembler: clustermessage.NewReassembler(MaxMessageSize, ChunkReassembleTimeout),
+		connectedAt: time.Now(),
+		closed:      make(chan struct{}),
+	}
+	if LinkStatsPeriod > 0 {
+		go c.logStats(LinkStatsPeriod)
 	}
-	return wsclient
+	return c
 }
 
-// Close closes websocket connection.
+// Close closes the connection.
 func (c *WSClient) Close() error {
+	c.closeOnce.Do(func() {
+		if c.closed != nil {
+			close(c.closed)
+		}
+		klog.Infof("link %s closed, %s", c.Name, c.Stats())
+	})
 	return c.Conn.Close()
 }
 
+// Stats returns traffic stats of the connection.
+func (c *WSClient) Stats() LinkStats {
+	var wire *wireCounter
+	if counted, ok := c.Conn.(wireCounted); ok {
+		wire = counted.wireCounter()
+	}
+	return c.counter.stats(wire)
+}
+
+// Info returns state of the connection.
+func (c *WSClient) Info() LinkInfo {
+	return LinkInfo{
+		ConnectedAt:   c.connectedAt,
+		LastMessageAt: c.counter.lastReceivedTime(),
+		Stats:         c.Stats(),
+	}
+}
+
+// logStats logs stats of the connection every period until it is closed.
+func (c *WSClient) logStats(period time.Duration) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.closed:
+			return
+		case <-ticker.C:
+			klog.Infof("link %s %s", c.Name, c.Stats())
+		}
+	}
+}
+
 // WriteMessage writes binary message to connection.
+// If chunking is negotiated, msg larger than MessageChunkSize is split into chunks,
+// and chunks of other messages may be written between them.
+// If reliable delivery is negotiated, msg is sent by the session of the connection.
 func (c *WSClient) WriteMessage(msg []byte) error {
+	maxSize := MaxMessageSize
+	if c.peerMaxSize > 0 && c.peerMaxSize < maxSize {
+		maxSize = c.peerMaxSize
+	}
+	if len(msg) > maxSize {
+		klog.Errorf("wsclient %s refuse to write msg of %d bytes: %v", c.Name, len(msg), ErrMessageTooLarge)
+		return ErrMessageTooLarge
+	}
+	if c.peerMaxSize == 0 || MessageChunkSize <= 0 || len(msg) <= MessageChunkSize {
+		return c.writeMessage(msg)
+	}
+
+	chunks, err := clustermessage.SplitMessage(msg, MessageChunkSize)
+	if err != nil {
+		return err
+	}
+	for _, chunk := range chunks {
+		if err := c.writeMessage(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeMessage writes msg by the session or directly.
+func (c *WSClient) writeMessage(msg []byte) error {
+	if c.session != nil {
+		return c.session.send(msg)
+	}
+	return c.writeRaw(msg)
+}
+
+// writeRaw writes binary message to connection without framing,
+// it is compressed if zstd is negotiated.
+func (c *WSClient) writeRaw(msg []byte) error {
+	data := c.codec.encode(msg)
+
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
-	c.Conn.SetWriteDeadline(time.Now().Add(WriteTimeout))
-	if err := c.Conn.WriteMessage(websocket.BinaryMessage, msg); err != nil {
+	if err := c.Conn.WriteMessage(data); err != nil {
 		klog.Errorf("wsclient %s write msg failed: %s", c.Name, err.Error())
 		return err
 	}
+	c.counter.sent(len(msg), len(data))
 
 	return nil
 }
 
-// ReadMessage reads binary message from connection.
+// ReadMessage reads binary message from connection, chunks are reassembled inside.
+// If reliable delivery is negotiated, acks and duplicated messages are handled inside.
 func (c *WSClient) ReadMessage() ([]byte, error) {
-	_, message, err := c.Conn.ReadMessage()
-	if err != nil {
-		klog.Errorf("wsclient %s read msg failed: %s", c.Name, err.Error())
-		return nil, err
+	for {
+		msg, err := c.readMessage()
+		if err != nil || c.reassembler == nil {
+			return msg, err
+		}
+		msg, err = c.reassembler.Add(msg)
+		if err != nil {
+			klog.Errorf("wsclient %s reassemble msg failed: %v", c.Name, err)
+			continue
+		}
+		if msg != nil {
+			return msg, nil
+		}
+	}
+}
+
+// readMessage reads a message or chunk by the session or directly.
+func (c *WSClient) readMessage() ([]byte, error) {
+	if c.session == nil {
+		return c.readRaw()
+	}
+
+	for {
+		frame, err := c.readRaw()
+		if err != nil {
+			return nil, err
+		}
+		msg, deliver, ack, err := c.session.receive(frame)
+		if err != nil {
+			klog.Errorf("wsclient %s read invalid frame: %v", c.Name, err)
+			continue
+		}
+		if ack > 0 {
+			if err := c.writeRaw(encodeFrame(frameAck, ack, nil)); err != nil {
+				klog.Errorf("wsclient %s ack %d failed: %v", c.Name, ack, err)
+			}
+		}
+		if deliver {
+			return msg, nil
+		}
+	}
+}
+
+// readRaw reads binary message from connection without framing,
+// it is decompressed if zstd is negotiated.
+func (c *WSClient) readRaw() ([]byte, error) {
+	for {
+		data, err := c.Conn.ReadMessage()
+		if err != nil {
+			klog.Errorf("wsclient %s read msg failed: %s", c.Name, err.Error())
+			return nil, err
+		}
+		message, err := c.codec.decode(data)
+		if err != nil {
+			klog.Errorf("wsclient %s read invalid msg: %v", c.Name, err)
+			continue
+		}
+		c.counter.received(len(message), len(data))
+		return message, nil
 	}
-	return message, nil
 }
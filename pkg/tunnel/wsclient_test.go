@@ -22,8 +22,10 @@ import (
 	"net/url"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
 )
 
 var (
@@ -83,7 +85,7 @@ func TestReadMessage(t *testing.T) {
 
 	// test receive
 	expectMsg := "test msg"
-	client.Conn.WriteMessage(websocket.BinaryMessage, []byte(expectMsg))
+	client.Conn.WriteMessage([]byte(expectMsg))
 	msg, err := client.ReadMessage()
 	if err != nil {
 		t.Errorf("fail to read msg, err: %v", err)
@@ -99,6 +101,63 @@ func TestReadMessage(t *testing.T) {
 	}
 }
 
+func TestHeartbeat(t *testing.T) {
+	interval, threshold := HeartbeatInterval, HeartbeatMissThreshold
+	HeartbeatInterval, HeartbeatMissThreshold = 100*time.Millisecond, 3
+	defer func() {
+		HeartbeatInterval, HeartbeatMissThreshold = interval, threshold
+	}()
+
+	readErr := func(client *WSClient) chan error {
+		errChan := make(chan error, 1)
+		go func() {
+			_, err := client.ReadMessage()
+			errChan <- err
+		}()
+		return errChan
+	}
+
+	// peer answers ping with pong
+	client := newTestWSClient()
+	assert.NotNil(t, client)
+	errChan := readErr(client)
+	select {
+	case err := <-errChan:
+		t.Errorf("alive peer is considered dead: %v", err)
+	case <-time.After(10 * HeartbeatInterval):
+		client.Close()
+		<-errChan
+	}
+
+	// peer never answers ping
+	deadServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u := websocket.Upgrader{}
+		c, err := u.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		c.SetPingHandler(func(string) error { return nil })
+		for {
+			if _, _, err := c.ReadMessage(); err != nil {
+				break
+			}
+		}
+	}))
+	defer deadServer.Close()
+	u := url.URL{Scheme: "ws", Host: deadServer.Listener.Addr().String(), Path: "/"}
+	conn, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
+	assert.Nil(t, err)
+	client = NewWSClient("dead", conn)
+	defer client.Close()
+	select {
+	case err := <-readErr(client):
+		assert.NotNil(t, err)
+	case <-time.After(10 * HeartbeatInterval):
+		t.Errorf("dead peer is not detected")
+	}
+}
+
 func TestMain(m *testing.M) {
 	initTestServer()
 	exit := m.Run()
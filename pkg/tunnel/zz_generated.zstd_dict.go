@@ -0,0 +1,535 @@
+/*
+Copyright 2019 Baidu, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by hack/gen-zstd-dict.go. DO NOT EDIT.
+
+package tunnel
+
+// builtinZstdDict is the zstd dictionary trained on EdgeReport messages of k8s objects.
+var builtinZstdDict = []byte{
+	0x37, 0xa4, 0x30, 0xec, 0x92, 0xf8, 0xe7, 0x5a, 0x30, 0x10, 0xc0, 0x0a, 0x5a, 0x1d, 0x33, 0x30,
+	0x03, 0x33, 0x30, 0x03, 0x33, 0x00, 0x20, 0x25, 0x8b, 0xdf, 0x1f, 0xd1, 0x2c, 0x89, 0xa5, 0xb4,
+	0xcd, 0x2f, 0xf2, 0x7b, 0x22, 0x4f, 0xb4, 0xfe, 0x3f, 0x24, 0x99, 0xd8, 0x5e, 0x7b, 0xfa, 0xa8,
+	0x02, 0x8d, 0x10, 0x91, 0x9f, 0x01, 0x33, 0xcb, 0x0d, 0x63, 0x02, 0x00, 0x00, 0x06, 0x02, 0x83,
+	0xc1, 0xd0, 0x90, 0xac, 0x9a, 0x3b, 0x00, 0x04, 0x00, 0x0a, 0x0a, 0x87, 0x05, 0xc8, 0xc6, 0x85,
+	0x42, 0xc3, 0x04, 0x64, 0xf1, 0x68, 0x20, 0x0e, 0x06, 0x83, 0x60, 0x30, 0x20, 0x18, 0x08, 0x89,
+	0x83, 0xe2, 0x28, 0x80, 0x81, 0x30, 0x88, 0x81, 0x30, 0x1c, 0xc6, 0x49, 0xe6, 0xa5, 0x00, 0x00,
+	0x34, 0x2f, 0xc8, 0x53, 0x99, 0x44, 0x22, 0x15, 0x85, 0x39, 0x8a, 0x71, 0x18, 0x30, 0xd4, 0x21,
+	0x00, 0x60, 0x50, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x04, 0x00, 0x00, 0x00, 0x08, 0x00,
+	0x00, 0x00, 0x4e, 0x53, 0x49, 0x73, 0x49, 0x6e, 0x42, 0x76, 0x5a, 0x43, 0x31, 0x30, 0x5a, 0x57,
+	0x31, 0x77, 0x62, 0x47, 0x46, 0x30, 0x5a, 0x53, 0x31, 0x6f, 0x59, 0x58, 0x4e, 0x6f, 0x49, 0x6a,
+	0x6f, 0x69, 0x5a, 0x44, 0x68, 0x6e, 0x62, 0x48, 0x45, 0x34, 0x59, 0x33, 0x59, 0x35, 0x59, 0x69,
+	0x4a, 0x39, 0x66, 0x53, 0x77, 0x69, 0x63, 0x33, 0x42, 0x6c, 0x59, 0x79, 0x49, 0x36, 0x65, 0x79,
+	0x4a, 0x77, 0x62, 0x33, 0x4a, 0x30, 0x63, 0x79, 0x49, 0x36, 0x57, 0x33, 0x73, 0x69, 0x62, 0x6d,
+	0x46, 0x74, 0x5a, 0x53, 0x49, 0x36, 0x49, 0x6d, 0x68, 0x30, 0x64, 0x48, 0x41, 0x69, 0x4c, 0x43,
+	0x4a, 0x77, 0x63, 0x6d, 0x39, 0x30, 0x62, 0x32, 0x4e, 0x76, 0x62, 0x43, 0x49, 0x36, 0x49, 0x6c,
+	0x52, 0x44, 0x55, 0x43, 0x49, 0x73, 0x49, 0x6e, 0x42, 0x76, 0x63, 0x6e, 0x51, 0x69, 0x4f, 0x6a,
+	0x59, 0x77, 0x4e, 0x54, 0x49, 0x73, 0x49, 0x6e, 0x52, 0x68, 0x63, 0x73, 0x69, 0x61, 0x33, 0x56,
+	0x69, 0x5a, 0x57, 0x78, 0x6c, 0x64, 0x45, 0x56, 0x75, 0x5a, 0x48, 0x42, 0x76, 0x61, 0x57, 0x35,
+	0x30, 0x49, 0x6a, 0x70, 0x37, 0x49, 0x6c, 0x42, 0x76, 0x63, 0x6e, 0x51, 0x69, 0x4f, 0x6a, 0x42,
+	0x39, 0x66, 0x53, 0x77, 0x69, 0x62, 0x6d, 0x39, 0x6b, 0x5a, 0x55, 0x6c, 0x75, 0x5a, 0x6d, 0x38,
+	0x69, 0x4f, 0x6e, 0x73, 0x69, 0x62, 0x57, 0x46, 0x6a, 0x61, 0x47, 0x6c, 0x75, 0x5a, 0x55, 0x6c,
+	0x45, 0x49, 0x6a, 0x6f, 0x69, 0x5a, 0x48, 0x42, 0x72, 0x4f, 0x57, 0x31, 0x6e, 0x62, 0x6d, 0x67,
+	0x31, 0x64, 0x6a, 0x64, 0x33, 0x4e, 0x6d, 0x6f, 0x31, 0x62, 0x6a, 0x6c, 0x6a, 0x4e, 0x7a, 0x6c,
+	0x6f, 0x4f, 0x47, 0x68, 0x36, 0x63, 0x32, 0x31, 0x6d, 0x63, 0x57, 0x49, 0x31, 0x62, 0x48, 0x4d,
+	0x69, 0x4c, 0x43, 0x4a, 0x7a, 0x65, 0x58, 0x4e, 0x30, 0x5a, 0x57, 0x31, 0x56, 0x56, 0x55, 0x6c,
+	0x45, 0x49, 0x6a, 0x6f, 0x69, 0x65, 0x44, 0x5a, 0x7a, 0x4e, 0x57, 0x6f, 0x79, 0x64, 0x32, 0x64,
+	0x6b, 0x61, 0x47, 0x31, 0x69, 0x4f, 0x58, 0x52, 0x36, 0x65, 0x48, 0x52, 0x6b, 0x61, 0x48, 0x4e,
+	0x73, 0x61, 0x6e, 0x4e, 0x34, 0x62, 0x6d, 0x52, 0x74, 0x5a, 0x44, 0x56, 0x7a, 0x64, 0x33, 0x41,
+	0x69, 0x4c, 0x43, 0x4a, 0x69, 0x62, 0x32, 0x39, 0x30, 0x53, 0x55, 0x51, 0x69, 0x4f, 0x69, 0x49,
+	0x69, 0x4c, 0x43, 0x4a, 0x72, 0x5a, 0x58, 0x4a, 0x75, 0x5a, 0x57, 0x78, 0x57, 0x5a, 0x58, 0x4a,
+	0x7a, 0x61, 0x57, 0x39, 0x75, 0x49, 0x6a, 0x6f, 0x69, 0x4e, 0x43, 0x34, 0x78, 0x4e, 0x53, 0x34,
+	0x77, 0x4c, 0x54, 0x63, 0x79, 0x4c, 0x57, 0x64, 0x6c, 0x62, 0x6d, 0x56, 0x79, 0x61, 0x57, 0x4d,
+	0x69, 0x4c, 0x43, 0x4a, 0x76, 0x63, 0x30, 0x6c, 0x74, 0x59, 0x57, 0x64, 0x6c, 0x49, 0x6a, 0x6f,
+	0x69, 0x56, 0x57, 0x4a, 0x31, 0x62, 0x6e, 0x52, 0x31, 0x49, 0x44, 0x45, 0x34, 0x4c, 0x6a, 0x41,
+	0x30, 0x4c, 0x6a, 0x4d, 0x67, 0x54, 0x46, 0x52, 0x54, 0x49, 0x69, 0x77, 0x69, 0x59, 0x32, 0x39,
+	0x75, 0x64, 0x47, 0x46, 0x70, 0x62, 0x6d, 0x56, 0x79, 0x55, 0x6e, 0x56, 0x75, 0x64, 0x47, 0x6c,
+	0x74, 0x5a, 0x56, 0x5a, 0x6c, 0x63, 0x6e, 0x4e, 0x70, 0x62, 0x32, 0x34, 0x69, 0x4f, 0x69, 0x4a,
+	0x6b, 0x62, 0x32, 0x4e, 0x72, 0x5a, 0x58, 0x49, 0x36, 0x4c, 0x79, 0x38, 0x78, 0x4f, 0x53, 0x34,
+	0x7a, 0x4c, 0x6a, 0x55, 0x69, 0x4c, 0x43, 0x4a, 0x72, 0x64, 0x57, 0x4a, 0x6c, 0x62, 0x47, 0x56,
+	0x30, 0x56, 0x6d, 0x56, 0x79, 0x63, 0x32, 0x6c, 0x76, 0x62, 0x69, 0x49, 0x36, 0x49, 0x6e, 0x59,
+	0x78, 0x4c, 0x6a, 0x45, 0x32, 0x4c, 0x6a, 0x4d, 0x69, 0x4c, 0x43, 0x4a, 0x72, 0x64, 0x57, 0x4a,
+	0x6c, 0x55, 0x48, 0x4a, 0x76, 0x65, 0x48, 0x6c, 0x57, 0x5a, 0x58, 0x4a, 0x7a, 0x61, 0x57, 0x39,
+	0x75, 0x49, 0x6a, 0x6f, 0x69, 0x64, 0x6a, 0x45, 0x75, 0x4d, 0x54, 0x59, 0x75, 0x4d, 0x79, 0x49,
+	0x73, 0x49, 0x6d, 0x39, 0x77, 0x5a, 0x58, 0x4a, 0x68, 0x64, 0x47, 0x6c, 0x75, 0x5a, 0x31, 0x4e,
+	0x35, 0x63, 0x33, 0x52, 0x6c, 0x62, 0x53, 0x49, 0x36, 0x49, 0x6d, 0x78, 0x70, 0x62, 0x6e, 0x56,
+	0x34, 0x49, 0x69, 0x77, 0x69, 0x59, 0x58, 0x4a, 0x6a, 0x61, 0x47, 0x6c, 0x30, 0x5a, 0x57, 0x4e,
+	0x30, 0x64, 0x58, 0x4a, 0x6c, 0x49, 0x6a, 0x6f, 0x69, 0x59, 0x58, 0x4a, 0x74, 0x4e, 0x6a, 0x51,
+	0x69, 0x66, 0x58, 0x31, 0x39, 0x66, 0x53, 0x77, 0x69, 0x5a, 0x47, 0x56, 0x73, 0x54, 0x57, 0x46,
+	0x77, 0x49, 0x6a, 0x70, 0x75, 0x64, 0x57, 0x78, 0x73, 0x4c, 0x43, 0x4a, 0x6d, 0x64, 0x57, 0x78,
+	0x73, 0x54, 0x47, 0x6c, 0x7a, 0x64, 0x43, 0x49, 0x36, 0x62, 0x6e, 0x56, 0x73, 0x62, 0x48, 0x30,
+	0x3d, 0x22, 0x7d, 0x5d, 0x5a, 0x57, 0x4e, 0x31, 0x64, 0x47, 0x55, 0x69, 0x66, 0x56, 0x31, 0x39,
+	0x66, 0x53, 0x77, 0x69, 0x63, 0x33, 0x52, 0x79, 0x59, 0x58, 0x52, 0x6c, 0x5a, 0x33, 0x6b, 0x69,
+	0x4f, 0x6e, 0x73, 0x69, 0x64, 0x48, 0x6c, 0x77, 0x5a, 0x53, 0x49, 0x36, 0x49, 0x6c, 0x4a, 0x76,
+	0x62, 0x47, 0x78, 0x70, 0x62, 0x6d, 0x64, 0x56, 0x63, 0x47, 0x52, 0x68, 0x64, 0x47, 0x55, 0x69,
+	0x66, 0x58, 0x30, 0x73, 0x49, 0x6e, 0x4e, 0x30, 0x59, 0x58, 0x52, 0x31, 0x63, 0x79, 0x49, 0x36,
+	0x65, 0x79, 0x4a, 0x76, 0x59, 0x6e, 0x4e, 0x6c, 0x63, 0x6e, 0x5a, 0x6c, 0x5a, 0x45, 0x64, 0x6c,
+	0x62, 0x6d, 0x56, 0x79, 0x59, 0x58, 0x52, 0x70, 0x62, 0x32, 0x34, 0x69, 0x4f, 0x6a, 0x45, 0x73,
+	0x49, 0x6e, 0x4a, 0x6c, 0x63, 0x47, 0x78, 0x70, 0x59, 0x32, 0x46, 0x7a, 0x49, 0x6a, 0x6f, 0x7a,
+	0x4c, 0x43, 0x4a, 0x31, 0x63, 0x47, 0x52, 0x68, 0x64, 0x47, 0x56, 0x6b, 0x55, 0x6d, 0x56, 0x77,
+	0x62, 0x47, 0x6c, 0x6a, 0x59, 0x58, 0x4d, 0x69, 0x4f, 0x6a, 0x4d, 0x73, 0x49, 0x6e, 0x4a, 0x6c,
+	0x59, 0x57, 0x52, 0x35, 0x55, 0x6d, 0x56, 0x77, 0x62, 0x47, 0x6c, 0x6a, 0x59, 0x58, 0x4d, 0x69,
+	0x4f, 0x6a, 0x4d, 0x73, 0x49, 0x6d, 0x46, 0x32, 0x59, 0x57, 0x6c, 0x73, 0x59, 0x57, 0x4a, 0x73,
+	0x5a, 0x56, 0x4a, 0x6c, 0x63, 0x47, 0x78, 0x70, 0x59, 0x32, 0x46, 0x7a, 0x49, 0x6a, 0x6f, 0x7a,
+	0x4c, 0x43, 0x4a, 0x6a, 0x62, 0x32, 0x35, 0x6b, 0x61, 0x58, 0x52, 0x70, 0x62, 0x32, 0x35, 0x7a,
+	0x49, 0x6a, 0x70, 0x62, 0x65, 0x79, 0x4a, 0x30, 0x65, 0x58, 0x42, 0x6c, 0x49, 0x6a, 0x6f, 0x69,
+	0x51, 0x58, 0x5a, 0x68, 0x61, 0x57, 0x78, 0x68, 0x59, 0x6d, 0x78, 0x6c, 0x49, 0x69, 0x77, 0x69,
+	0x63, 0x33, 0x52, 0x68, 0x64, 0x48, 0x56, 0x7a, 0x49, 0x6a, 0x6f, 0x69, 0x56, 0x48, 0x4a, 0x31,
+	0x5a, 0x53, 0x49, 0x73, 0x49, 0x6d, 0x78, 0x68, 0x63, 0x33, 0x52, 0x56, 0x63, 0x47, 0x52, 0x68,
+	0x64, 0x47, 0x56, 0x55, 0x61, 0x57, 0x31, 0x6c, 0x49, 0x6a, 0x70, 0x75, 0x64, 0x57, 0x78, 0x73,
+	0x4c, 0x43, 0x4a, 0x73, 0x59, 0x58, 0x4e, 0x30, 0x56, 0x48, 0x4a, 0x68, 0x62, 0x6e, 0x4e, 0x70,
+	0x64, 0x47, 0x6c, 0x76, 0x62, 0x6c, 0x52, 0x70, 0x62, 0x57, 0x55, 0x69, 0x4f, 0x6d, 0x35, 0x31,
+	0x62, 0x47, 0x77, 0x73, 0x49, 0x6e, 0x4a, 0x6c, 0x59, 0x58, 0x4e, 0x76, 0x62, 0x69, 0x49, 0x36,
+	0x49, 0x6b, 0x31, 0x70, 0x62, 0x6d, 0x6c, 0x74, 0x64, 0x57, 0x31, 0x53, 0x5a, 0x58, 0x42, 0x73,
+	0x61, 0x57, 0x4e, 0x68, 0x63, 0x30, 0x46, 0x32, 0x59, 0x57, 0x6c, 0x73, 0x59, 0x57, 0x4a, 0x73,
+	0x5a, 0x53, 0x49, 0x73, 0x49, 0x6d, 0x31, 0x6c, 0x63, 0x33, 0x4e, 0x68, 0x5a, 0x32, 0x55, 0x69,
+	0x4f, 0x69, 0x4a, 0x45, 0x5a, 0x58, 0x42, 0x73, 0x62, 0x33, 0x6c, 0x74, 0x5a, 0x57, 0x35, 0x30,
+	0x49, 0x47, 0x68, 0x68, 0x63, 0x79, 0x42, 0x74, 0x61, 0x57, 0x35, 0x70, 0x62, 0x58, 0x56, 0x74,
+	0x49, 0x47, 0x46, 0x32, 0x59, 0x57, 0x6c, 0x73, 0x59, 0x57, 0x4a, 0x70, 0x62, 0x47, 0x6c, 0x30,
+	0x65, 0x53, 0x34, 0x69, 0x66, 0x53, 0x78, 0x37, 0x49, 0x6e, 0x52, 0x35, 0x63, 0x47, 0x55, 0x69,
+	0x4f, 0x69, 0x4a, 0x51, 0x63, 0x6d, 0x39, 0x6e, 0x63, 0x6d, 0x56, 0x7a, 0x63, 0x32, 0x6c, 0x75,
+	0x5a, 0x79, 0x49, 0x73, 0x49, 0x6e, 0x4e, 0x30, 0x59, 0x58, 0x52, 0x31, 0x63, 0x79, 0x49, 0x36,
+	0x49, 0x6c, 0x52, 0x79, 0x64, 0x57, 0x55, 0x69, 0x4c, 0x43, 0x4a, 0x73, 0x59, 0x58, 0x4e, 0x30,
+	0x56, 0x58, 0x42, 0x6b, 0x59, 0x58, 0x52, 0x6c, 0x56, 0x47, 0x6c, 0x74, 0x5a, 0x53, 0x49, 0x36,
+	0x62, 0x6e, 0x56, 0x73, 0x62, 0x43, 0x77, 0x69, 0x62, 0x47, 0x46, 0x7a, 0x64, 0x58, 0x42, 0x6c,
+	0x49, 0x6a, 0x6f, 0x69, 0x51, 0x32, 0x78, 0x31, 0x63, 0x33, 0x52, 0x6c, 0x63, 0x6b, 0x6c, 0x51,
+	0x49, 0x69, 0x77, 0x69, 0x63, 0x32, 0x56, 0x7a, 0x63, 0x32, 0x6c, 0x76, 0x62, 0x6b, 0x46, 0x6d,
+	0x5a, 0x6d, 0x6c, 0x75, 0x61, 0x58, 0x52, 0x35, 0x49, 0x6a, 0x6f, 0x69, 0x54, 0x6d, 0x39, 0x75,
+	0x5a, 0x53, 0x4a, 0x39, 0x4c, 0x43, 0x4a, 0x7a, 0x64, 0x47, 0x46, 0x30, 0x64, 0x58, 0x4d, 0x69,
+	0x4f, 0x6e, 0x73, 0x69, 0x62, 0x47, 0x39, 0x68, 0x5a, 0x45, 0x4a, 0x68, 0x62, 0x47, 0x46, 0x75,
+	0x59, 0x32, 0x56, 0x79, 0x49, 0x6a, 0x70, 0x37, 0x66, 0x58, 0x31, 0x39, 0x4c, 0x43, 0x4a, 0x74,
+	0x62, 0x32, 0x35, 0x70, 0x64, 0x47, 0x39, 0x79, 0x61, 0x57, 0x35, 0x6e, 0x4c, 0x32, 0x74, 0x31,
+	0x59, 0x6d, 0x55, 0x74, 0x63, 0x48, 0x4a, 0x76, 0x65, 0x48, 0x6b, 0x74, 0x5a, 0x44, 0x4d, 0x31,
+	0x4e, 0x54, 0x41, 0x77, 0x59, 0x54, 0x51, 0x74, 0x4e, 0x33, 0x70, 0x71, 0x61, 0x32, 0x34, 0x69,
+	0x4f, 0x6e, 0x73, 0x69, 0x61, 0x32, 0x6c, 0x75, 0x5a, 0x43, 0x49, 0x36, 0x49, 0x6c, 0x4e, 0x6c,
+	0x63, 0x6e, 0x5a, 0x70, 0x59, 0x32, 0x55, 0x69, 0x4c, 0x43, 0x4a, 0x68, 0x63, 0x47, 0x6c, 0x57,
+	0x5a, 0x58, 0x4a, 0x7a, 0x61, 0x57, 0x39, 0x75, 0x49, 0x6a, 0x6f, 0x69, 0x64, 0x6a, 0x45, 0x69,
+	0x4c, 0x43, 0x4a, 0x74, 0x5a, 0x58, 0x52, 0x68, 0x5a, 0x47, 0x46, 0x30, 0x59, 0x53, 0x49, 0x36,
+	0x65, 0x79, 0x4a, 0x75, 0x59, 0x57, 0x31, 0x6c, 0x49, 0x6a, 0x6f, 0x69, 0x61, 0x33, 0x56, 0x69,
+	0x5a, 0x53, 0x31, 0x77, 0x63, 0x6d, 0x39, 0x34, 0x65, 0x53, 0x31, 0x6b, 0x4d, 0x7a, 0x55, 0x31,
+	0x4d, 0x44, 0x42, 0x68, 0x4e, 0x43, 0x30, 0x33, 0x65, 0x6d, 0x70, 0x72, 0x62, 0x69, 0x49, 0x73,
+	0x49, 0x6d, 0x35, 0x68, 0x62, 0x57, 0x56, 0x7a, 0x63, 0x47, 0x46, 0x6a, 0x5a, 0x53, 0x49, 0x36,
+	0x49, 0x6d, 0x31, 0x76, 0x62, 0x6d, 0x6c, 0x30, 0x62, 0x33, 0x4a, 0x70, 0x62, 0x6d, 0x63, 0x69,
+	0x4c, 0x43, 0x4a, 0x79, 0x5a, 0x58, 0x4e, 0x76, 0x64, 0x58, 0x4a, 0x6a, 0x5a, 0x56, 0x5a, 0x6c,
+	0x63, 0x6e, 0x4e, 0x70, 0x62, 0x32, 0x34, 0x69, 0x4f, 0x69, 0x49, 0x7a, 0x4e, 0x44, 0x49, 0x79,
+	0x4d, 0x7a, 0x49, 0x78, 0x49, 0x69, 0x77, 0x69, 0x59, 0x33, 0x4a, 0x6c, 0x59, 0x58, 0x52, 0x70,
+	0x62, 0x32, 0x35, 0x55, 0x61, 0x57, 0x31, 0x6c, 0x63, 0x33, 0x52, 0x68, 0x62, 0x58, 0x41, 0x69,
+	0x4f, 0x6d, 0x35, 0x31, 0x62, 0x47, 0x77, 0x73, 0x49, 0x6d, 0x78, 0x68, 0x59, 0x6d, 0x56, 0x73,
+	0x63, 0x79, 0x49, 0x36, 0x65, 0x79, 0x4a, 0x68, 0x63, 0x48, 0x41, 0x69, 0x4f, 0x69, 0x4a, 0x72,
+	0x64, 0x57, 0x4a, 0x6c, 0x4c, 0x58, 0x42, 0x79, 0x62, 0x33, 0x68, 0x35, 0x49, 0x69, 0x77, 0x69,
+	0x59, 0x58, 0x42, 0x77, 0x4c, 0x6d, 0x74, 0x31, 0x59, 0x6d, 0x56, 0x79, 0x62, 0x6d, 0x56, 0x30,
+	0x5a, 0x58, 0x4d, 0x75, 0x61, 0x57, 0x38, 0x76, 0x63, 0x47, 0x46, 0x79, 0x64, 0x43, 0x31, 0x76,
+	0x5a, 0x69, 0x49, 0x36, 0x49, 0x6d, 0x39, 0x30, 0x5a, 0x53, 0x49, 0x73, 0x49, 0x6d, 0x56, 0x6b,
+	0x5a, 0x32, 0x55, 0x74, 0x64, 0x6d, 0x56, 0x79, 0x63, 0x32, 0x6c, 0x76, 0x62, 0x69, 0x49, 0x36,
+	0x49, 0x6a, 0x6b, 0x32, 0x4e, 0x44, 0x41, 0x78, 0x49, 0x69, 0x77, 0x69, 0x62, 0x33, 0x52, 0x6c,
+	0x4c, 0x57, 0x4e, 0x73, 0x64, 0x58, 0x4e, 0x30, 0x5a, 0x58, 0x49, 0x69, 0x4f, 0x69, 0x4a, 0x6c,
+	0x5a, 0x47, 0x64, 0x6c, 0x4c, 0x54, 0x6b, 0x69, 0x4c, 0x43, 0x4a, 0x77, 0x62, 0x32, 0x51, 0x74,
+	0x64, 0x47, 0x56, 0x74, 0x63, 0x47, 0x78, 0x68, 0x64, 0x47, 0x55, 0x74, 0x61, 0x47, 0x46, 0x7a,
+	0x61, 0x43, 0x49, 0x36, 0x49, 0x6e, 0x31, 0x6e, 0x5a, 0x57, 0x35, 0x6c, 0x63, 0x6d, 0x6c, 0x6a,
+	0x49, 0x69, 0x77, 0x69, 0x62, 0x33, 0x4e, 0x4a, 0x62, 0x57, 0x46, 0x6e, 0x5a, 0x53, 0x49, 0x36,
+	0x49, 0x6c, 0x56, 0x69, 0x64, 0x57, 0x35, 0x30, 0x64, 0x53, 0x41, 0x78, 0x4f, 0x43, 0x34, 0x77,
+	0x4e, 0x43, 0x34, 0x7a, 0x49, 0x45, 0x78, 0x55, 0x55, 0x79, 0x49, 0x73, 0x49, 0x6d, 0x4e, 0x76,
+	0x62, 0x6e, 0x52, 0x68, 0x61, 0x57, 0x35, 0x6c, 0x63, 0x6c, 0x4a, 0x31, 0x62, 0x6e, 0x52, 0x70,
+	0x62, 0x57, 0x56, 0x57, 0x5a, 0x58, 0x4a, 0x7a, 0x61, 0x57, 0x39, 0x75, 0x49, 0x6a, 0x6f, 0x69,
+	0x5a, 0x47, 0x39, 0x6a, 0x61, 0x32, 0x56, 0x79, 0x4f, 0x69, 0x38, 0x76, 0x4d, 0x54, 0x6b, 0x75,
+	0x4d, 0x79, 0x34, 0x31, 0x49, 0x69, 0x77, 0x69, 0x61, 0x33, 0x56, 0x69, 0x5a, 0x57, 0x78, 0x6c,
+	0x64, 0x46, 0x5a, 0x6c, 0x63, 0x6e, 0x4e, 0x70, 0x62, 0x32, 0x34, 0x69, 0x4f, 0x69, 0x4a, 0x32,
+	0x4d, 0x53, 0x34, 0x78, 0x4e, 0x69, 0x34, 0x7a, 0x49, 0x69, 0x77, 0x69, 0x61, 0x33, 0x56, 0x69,
+	0x5a, 0x56, 0x42, 0x79, 0x62, 0x33, 0x68, 0x35, 0x56, 0x6d, 0x56, 0x79, 0x63, 0x32, 0x6c, 0x76,
+	0x62, 0x69, 0x49, 0x36, 0x49, 0x6e, 0x59, 0x78, 0x4c, 0x6a, 0x45, 0x32, 0x4c, 0x6a, 0x4d, 0x69,
+	0x4c, 0x43, 0x4a, 0x76, 0x63, 0x47, 0x56, 0x79, 0x59, 0x58, 0x52, 0x70, 0x62, 0x6d, 0x64, 0x54,
+	0x65, 0x58, 0x4e, 0x30, 0x5a, 0x57, 0x30, 0x69, 0x4f, 0x69, 0x4a, 0x73, 0x61, 0x57, 0x35, 0x31,
+	0x65, 0x43, 0x49, 0x73, 0x49, 0x6d, 0x46, 0x79, 0x59, 0x32, 0x68, 0x70, 0x64, 0x47, 0x56, 0x6a,
+	0x64, 0x48, 0x56, 0x79, 0x5a, 0x53, 0x49, 0x36, 0x49, 0x6d, 0x46, 0x74, 0x5a, 0x44, 0x59, 0x30,
+	0x49, 0x6e, 0x31, 0x39, 0x66, 0x58, 0x30, 0x73, 0x49, 0x6d, 0x52, 0x6c, 0x62, 0x45, 0x31, 0x68,
+	0x63, 0x43, 0x49, 0x36, 0x62, 0x6e, 0x56, 0x73, 0x62, 0x43, 0x77, 0x69, 0x5a, 0x6e, 0x56, 0x73,
+	0x62, 0x45, 0x78, 0x70, 0x63, 0x33, 0x51, 0x69, 0x4f, 0x6d, 0x35, 0x31, 0x62, 0x47, 0x78, 0x39,
+	0x22, 0x7d, 0x5d, 0x0a, 0x0b, 0x10, 0x09, 0x22, 0x07, 0x65, 0x64, 0x67, 0x65, 0x2d, 0x33, 0x36,
+	0x12, 0x82, 0x13, 0x5b, 0x7b, 0x22, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x54, 0x79,
+	0x70, 0x65, 0x22, 0x3a, 0x33, 0x2c, 0x22, 0x62, 0x6f, 0x64, 0x79, 0x22, 0x3a, 0x22, 0x65, 0x79,
+	0x4a, 0x31, 0x63, 0x47, 0x52, 0x68, 0x64, 0x47, 0x56, 0x4e, 0x59, 0x58, 0x41, 0x69, 0x4f, 0x6e,
+	0x73, 0x69, 0x5a, 0x47, 0x56, 0x6d, 0x59, 0x58, 0x56, 0x73, 0x64, 0x43, 0x39, 0x75, 0x5a, 0x32,
+	0x6c, 0x75, 0x65, 0x43, 0x30, 0x32, 0x59, 0x54, 0x4d, 0x78, 0x4e, 0x47, 0x4d, 0x77, 0x4f, 0x53,
+	0x31, 0x6e, 0x63, 0x6d, 0x4e, 0x74, 0x64, 0x79, 0x49, 0x36, 0x65, 0x79, 0x4a, 0x72, 0x61, 0x57,
+	0x35, 0x6b, 0x49, 0x6a, 0x6f, 0x69, 0x52, 0x47, 0x56, 0x77, 0x62, 0x47, 0x39, 0x35, 0x62, 0x57,
+	0x56, 0x75, 0x64, 0x43, 0x49, 0x73, 0x49, 0x6d, 0x46, 0x77, 0x61, 0x56, 0x5a, 0x6c, 0x63, 0x6e,
+	0x4e, 0x70, 0x62, 0x32, 0x34, 0x69, 0x4f, 0x69, 0x4a, 0x68, 0x63, 0x48, 0x42, 0x7a, 0x4c, 0x33,
+	0x59, 0x78, 0x49, 0x69, 0x77, 0x69, 0x62, 0x57, 0x56, 0x30, 0x59, 0x57, 0x52, 0x68, 0x64, 0x47,
+	0x45, 0x69, 0x4f, 0x6e, 0x73, 0x69, 0x62, 0x6d, 0x46, 0x74, 0x5a, 0x53, 0x49, 0x36, 0x49, 0x6d,
+	0x35, 0x6e, 0x61, 0x57, 0x35, 0x34, 0x4c, 0x54, 0x5a, 0x68, 0x4d, 0x7a, 0x45, 0x30, 0x59, 0x7a,
+	0x41, 0x35, 0x4c, 0x57, 0x64, 0x79, 0x59, 0x32, 0x31, 0x33, 0x49, 0x69, 0x77, 0x69, 0x62, 0x6d,
+	0x46, 0x74, 0x5a, 0x58, 0x4e, 0x77, 0x59, 0x57, 0x4e, 0x6c, 0x49, 0x6a, 0x6f, 0x69, 0x5a, 0x62,
+	0x32, 0x34, 0x69, 0x4f, 0x69, 0x4a, 0x4c, 0x64, 0x57, 0x4a, 0x6c, 0x62, 0x47, 0x56, 0x30, 0x53,
+	0x47, 0x46, 0x7a, 0x54, 0x6d, 0x39, 0x45, 0x61, 0x58, 0x4e, 0x72, 0x55, 0x48, 0x4a, 0x6c, 0x63,
+	0x33, 0x4e, 0x31, 0x63, 0x6d, 0x55, 0x69, 0x4c, 0x43, 0x4a, 0x74, 0x5a, 0x58, 0x4e, 0x7a, 0x59,
+	0x57, 0x64, 0x6c, 0x49, 0x6a, 0x6f, 0x69, 0x61, 0x33, 0x56, 0x69, 0x5a, 0x57, 0x78, 0x6c, 0x64,
+	0x43, 0x42, 0x6f, 0x59, 0x58, 0x4d, 0x67, 0x62, 0x6d, 0x38, 0x67, 0x5a, 0x47, 0x6c, 0x7a, 0x61,
+	0x79, 0x42, 0x77, 0x63, 0x6d, 0x56, 0x7a, 0x63, 0x33, 0x56, 0x79, 0x5a, 0x53, 0x4a, 0x39, 0x4c,
+	0x48, 0x73, 0x69, 0x64, 0x48, 0x6c, 0x77, 0x5a, 0x53, 0x49, 0x36, 0x49, 0x6c, 0x4a, 0x6c, 0x59,
+	0x57, 0x52, 0x35, 0x49, 0x69, 0x77, 0x69, 0x63, 0x33, 0x52, 0x68, 0x64, 0x48, 0x56, 0x7a, 0x49,
+	0x6a, 0x6f, 0x69, 0x56, 0x48, 0x4a, 0x31, 0x5a, 0x53, 0x49, 0x73, 0x49, 0x6d, 0x78, 0x68, 0x63,
+	0x33, 0x52, 0x49, 0x5a, 0x57, 0x46, 0x79, 0x64, 0x47, 0x4a, 0x6c, 0x59, 0x58, 0x52, 0x55, 0x61,
+	0x57, 0x31, 0x6c, 0x49, 0x6a, 0x6f, 0x69, 0x4d, 0x6a, 0x41, 0x79, 0x4e, 0x69, 0x30, 0x78, 0x4d,
+	0x43, 0x30, 0x78, 0x4e, 0x31, 0x51, 0x77, 0x4d, 0x7a, 0x6f, 0x30, 0x4f, 0x54, 0x6f, 0x79, 0x4f,
+	0x56, 0x6f, 0x69, 0x4c, 0x43, 0x4a, 0x73, 0x59, 0x58, 0x4e, 0x30, 0x56, 0x48, 0x4a, 0x68, 0x62,
+	0x6e, 0x4e, 0x70, 0x64, 0x47, 0x6c, 0x76, 0x62, 0x6c, 0x52, 0x70, 0x62, 0x57, 0x55, 0x69, 0x4f,
+	0x6d, 0x35, 0x31, 0x62, 0x47, 0x77, 0x73, 0x49, 0x6e, 0x4a, 0x6c, 0x59, 0x58, 0x4e, 0x76, 0x62,
+	0x69, 0x49, 0x36, 0x49, 0x6b, 0x74, 0x31, 0x59, 0x6d, 0x56, 0x73, 0x5a, 0x58, 0x52, 0x53, 0x5a,
+	0x57, 0x46, 0x6b, 0x65, 0x53, 0x49, 0x73, 0x49, 0x6d, 0x31, 0x6c, 0x63, 0x33, 0x4e, 0x68, 0x5a,
+	0x32, 0x55, 0x69, 0x4f, 0x69, 0x4a, 0x72, 0x64, 0x57, 0x4a, 0x6c, 0x62, 0x47, 0x56, 0x30, 0x49,
+	0x47, 0x6c, 0x7a, 0x49, 0x48, 0x42, 0x76, 0x63, 0x33, 0x52, 0x70, 0x62, 0x6d, 0x63, 0x67, 0x63,
+	0x6d, 0x56, 0x68, 0x5a, 0x48, 0x6b, 0x67, 0x63, 0x33, 0x52, 0x68, 0x64, 0x48, 0x56, 0x7a, 0x49,
+	0x6e, 0x31, 0x64, 0x4c, 0x43, 0x4a, 0x68, 0x5a, 0x47, 0x52, 0x79, 0x5a, 0x58, 0x4e, 0x7a, 0x5a,
+	0x58, 0x4d, 0x69, 0x4f, 0x6c, 0x74, 0x37, 0x49, 0x6e, 0x52, 0x35, 0x63, 0x47, 0x55, 0x69, 0x4f,
+	0x69, 0x4a, 0x4a, 0x62, 0x6e, 0x52, 0x6c, 0x63, 0x6d, 0x35, 0x68, 0x62, 0x45, 0x6c, 0x51, 0x49,
+	0x69, 0x77, 0x69, 0x59, 0x57, 0x52, 0x6b, 0x63, 0x6d, 0x56, 0x7a, 0x63, 0x79, 0x49, 0x36, 0x49,
+	0x6a, 0x45, 0x77, 0x4c, 0x6a, 0x45, 0x78, 0x4e, 0x69, 0x34, 0x79, 0x4d, 0x6a, 0x6b, 0x75, 0x4d,
+	0x7a, 0x63, 0x69, 0x66, 0x53, 0x78, 0x37, 0x49, 0x6e, 0x52, 0x35, 0x63, 0x47, 0x55, 0x69, 0x4f,
+	0x69, 0x4a, 0x49, 0x62, 0x33, 0x4e, 0x30, 0x62, 0x6d, 0x46, 0x74, 0x5a, 0x53, 0x49, 0x73, 0x49,
+	0x6d, 0x46, 0x6b, 0x5a, 0x48, 0x4a, 0x6c, 0x63, 0x33, 0x4d, 0x69, 0x4f, 0x69, 0x4a, 0x6c, 0x5a,
+	0x47, 0x64, 0x6c, 0x4c, 0x57, 0x35, 0x76, 0x5a, 0x47, 0x55, 0x74, 0x4e, 0x44, 0x49, 0x69, 0x66,
+	0x56, 0x30, 0x73, 0x49, 0x6d, 0x52, 0x68, 0x5a, 0x57, 0x31, 0x76, 0x62, 0x6b, 0x56, 0x75, 0x5a,
+	0x48, 0x42, 0x76, 0x61, 0x57, 0x35, 0x30, 0x63, 0x79, 0x49, 0x36, 0x65, 0x79, 0x4a, 0x72, 0x64,
+	0x57, 0x4a, 0x6c, 0x62, 0x47, 0x56, 0x30, 0x52, 0x57, 0x35, 0x6b, 0x63, 0x47, 0x39, 0x70, 0x62,
+	0x6e, 0x51, 0x69, 0x4f, 0x6e, 0x73, 0x69, 0x55, 0x47, 0x39, 0x79, 0x64, 0x43, 0x49, 0x36, 0x4d,
+	0x48, 0x31, 0x39, 0x4c, 0x43, 0x4a, 0x75, 0x62, 0x49, 0x73, 0x49, 0x6e, 0x4a, 0x6c, 0x59, 0x57,
+	0x52, 0x50, 0x62, 0x6d, 0x78, 0x35, 0x49, 0x6a, 0x70, 0x30, 0x63, 0x6e, 0x56, 0x6c, 0x4c, 0x43,
+	0x4a, 0x74, 0x62, 0x33, 0x56, 0x75, 0x64, 0x46, 0x42, 0x68, 0x64, 0x47, 0x67, 0x69, 0x4f, 0x69,
+	0x49, 0x76, 0x64, 0x6d, 0x46, 0x79, 0x4c, 0x33, 0x4a, 0x31, 0x62, 0x69, 0x39, 0x7a, 0x5a, 0x57,
+	0x4e, 0x79, 0x5a, 0x58, 0x52, 0x7a, 0x4c, 0x32, 0x74, 0x31, 0x59, 0x6d, 0x56, 0x79, 0x62, 0x6d,
+	0x56, 0x30, 0x5a, 0x58, 0x4d, 0x75, 0x61, 0x57, 0x38, 0x76, 0x63, 0x32, 0x56, 0x79, 0x64, 0x6d,
+	0x6c, 0x6a, 0x5a, 0x57, 0x46, 0x6a, 0x59, 0x32, 0x39, 0x31, 0x62, 0x6e, 0x51, 0x69, 0x66, 0x56,
+	0x30, 0x73, 0x49, 0x6e, 0x52, 0x6c, 0x63, 0x6d, 0x31, 0x70, 0x62, 0x6d, 0x46, 0x30, 0x61, 0x57,
+	0x39, 0x75, 0x54, 0x57, 0x56, 0x7a, 0x63, 0x32, 0x46, 0x6e, 0x5a, 0x56, 0x42, 0x68, 0x64, 0x47,
+	0x67, 0x69, 0x4f, 0x69, 0x49, 0x76, 0x5a, 0x47, 0x56, 0x32, 0x4c, 0x33, 0x52, 0x6c, 0x63, 0x6d,
+	0x31, 0x70, 0x62, 0x6d, 0x46, 0x30, 0x61, 0x57, 0x39, 0x75, 0x4c, 0x57, 0x78, 0x76, 0x5a, 0x79,
+	0x49, 0x73, 0x49, 0x6e, 0x52, 0x6c, 0x63, 0x6d, 0x31, 0x70, 0x62, 0x6d, 0x46, 0x30, 0x61, 0x57,
+	0x39, 0x75, 0x54, 0x57, 0x56, 0x7a, 0x63, 0x32, 0x46, 0x6e, 0x5a, 0x56, 0x42, 0x76, 0x62, 0x47,
+	0x6c, 0x6a, 0x65, 0x53, 0x49, 0x36, 0x49, 0x6b, 0x5a, 0x70, 0x62, 0x47, 0x55, 0x69, 0x4c, 0x43,
+	0x4a, 0x70, 0x62, 0x57, 0x46, 0x6e, 0x5a, 0x56, 0x42, 0x31, 0x62, 0x47, 0x78, 0x51, 0x62, 0x32,
+	0x78, 0x70, 0x59, 0x33, 0x6b, 0x69, 0x4f, 0x69, 0x4a, 0x4a, 0x5a, 0x6b, 0x35, 0x76, 0x64, 0x46,
+	0x42, 0x79, 0x5a, 0x58, 0x4e, 0x6c, 0x62, 0x6e, 0x51, 0x69, 0x66, 0x56, 0x30, 0x73, 0x49, 0x6d,
+	0x35, 0x76, 0x5a, 0x47, 0x56, 0x4f, 0x59, 0x57, 0x31, 0x6c, 0x49, 0x6a, 0x6f, 0x69, 0x5a, 0x57,
+	0x52, 0x6e, 0x5a, 0x53, 0x31, 0x75, 0x62, 0x32, 0x52, 0x6c, 0x4c, 0x54, 0x51, 0x69, 0x4c, 0x43,
+	0x4a, 0x30, 0x62, 0x32, 0x78, 0x6c, 0x63, 0x6d, 0x46, 0x30, 0x61, 0x57, 0x39, 0x75, 0x63, 0x79,
+	0x49, 0x36, 0x57, 0x33, 0x73, 0x69, 0x61, 0x32, 0x56, 0x35, 0x49, 0x6a, 0x6f, 0x69, 0x62, 0x6d,
+	0x39, 0x6b, 0x5a, 0x53, 0x35, 0x72, 0x64, 0x57, 0x4a, 0x6c, 0x63, 0x6d, 0x35, 0x6c, 0x64, 0x47,
+	0x56, 0x7a, 0x4c, 0x6d, 0x6c, 0x76, 0x4c, 0x32, 0x35, 0x76, 0x64, 0x43, 0x31, 0x79, 0x5a, 0x57,
+	0x46, 0x6b, 0x65, 0x53, 0x49, 0x73, 0x49, 0x6d, 0x39, 0x77, 0x5a, 0x58, 0x4a, 0x68, 0x64, 0x47,
+	0x39, 0x79, 0x49, 0x6a, 0x6f, 0x69, 0x52, 0x58, 0x68, 0x70, 0x63, 0x33, 0x52, 0x7a, 0x49, 0x69,
+	0x77, 0x69, 0x5a, 0x57, 0x5a, 0x6d, 0x5a, 0x57, 0x4e, 0x30, 0x49, 0x6a, 0x6f, 0x69, 0x54, 0x6d,
+	0x39, 0x46, 0x65, 0x47, 0x56, 0x6a, 0x64, 0x58, 0x52, 0x6c, 0x49, 0x6e, 0x30, 0x73, 0x65, 0x79,
+	0x4a, 0x72, 0x5a, 0x58, 0x6b, 0x69, 0x4f, 0x69, 0x4a, 0x75, 0x62, 0x32, 0x52, 0x6c, 0x4c, 0x6d,
+	0x74, 0x31, 0x59, 0x6d, 0x56, 0x79, 0x62, 0x6d, 0x56, 0x30, 0x5a, 0x58, 0x4d, 0x75, 0x61, 0x57,
+	0x38, 0x76, 0x64, 0x57, 0x35, 0x79, 0x5a, 0x57, 0x46, 0x6a, 0x61, 0x47, 0x46, 0x69, 0x62, 0x47,
+	0x55, 0x69, 0x4c, 0x43, 0x4a, 0x76, 0x63, 0x47, 0x56, 0x79, 0x59, 0x58, 0x52, 0x76, 0x63, 0x69,
+	0x49, 0x36, 0x49, 0x6b, 0x56, 0x34, 0x61, 0x58, 0x4e, 0x30, 0x63, 0x79, 0x49, 0x73, 0x49, 0x6d,
+	0x56, 0x6d, 0x5a, 0x6d, 0x56, 0x6a, 0x64, 0x43, 0x49, 0x36, 0x49, 0x6b, 0x35, 0x76, 0x52, 0x58,
+	0x68, 0x6c, 0x59, 0x33, 0x56, 0x30, 0x5a, 0x53, 0x4a, 0x39, 0x58, 0x58, 0x30, 0x73, 0x49, 0x6e,
+	0x4e, 0x51, 0x79, 0x4f, 0x47, 0x63, 0x69, 0x66, 0x58, 0x31, 0x64, 0x4c, 0x43, 0x4a, 0x6a, 0x62,
+	0x32, 0x35, 0x30, 0x59, 0x57, 0x6c, 0x75, 0x5a, 0x58, 0x4a, 0x7a, 0x49, 0x6a, 0x70, 0x62, 0x65,
+	0x79, 0x4a, 0x75, 0x59, 0x57, 0x31, 0x6c, 0x49, 0x6a, 0x6f, 0x69, 0x64, 0x6d, 0x6c, 0x6b, 0x5a,
+	0x57, 0x38, 0x74, 0x59, 0x57, 0x35, 0x68, 0x62, 0x48, 0x6c, 0x36, 0x5a, 0x58, 0x49, 0x69, 0x4c,
+	0x43, 0x4a, 0x70, 0x62, 0x57, 0x46, 0x6e, 0x5a, 0x53, 0x49, 0x36, 0x49, 0x6e, 0x4a, 0x6c, 0x5a,
+	0x32, 0x6c, 0x7a, 0x64, 0x48, 0x4a, 0x35, 0x4c, 0x6d, 0x78, 0x76, 0x59, 0x32, 0x46, 0x73, 0x4c,
+	0x33, 0x5a, 0x70, 0x5a, 0x47, 0x56, 0x76, 0x4c, 0x57, 0x46, 0x75, 0x59, 0x57, 0x78, 0x35, 0x65,
+	0x6d, 0x56, 0x79, 0x4f, 0x6e, 0x59, 0x79, 0x4c, 0x6a, 0x4d, 0x75, 0x4d, 0x53, 0x49, 0x73, 0x49,
+	0x6e, 0x42, 0x76, 0x63, 0x6e, 0x52, 0x7a, 0x49, 0x6a, 0x70, 0x62, 0x65, 0x79, 0x4a, 0x6a, 0x62,
+	0x32, 0x35, 0x30, 0x59, 0x57, 0x6c, 0x75, 0x5a, 0x58, 0x4a, 0x51, 0x62, 0x33, 0x4a, 0x30, 0x49,
+	0x6a, 0x6f, 0x34, 0x4d, 0x6a, 0x41, 0x78, 0x4c, 0x43, 0x4a, 0x77, 0x63, 0x6d, 0x39, 0x30, 0x62,
+	0x32, 0x4e, 0x76, 0x62, 0x43, 0x49, 0x36, 0x49, 0x6c, 0x52, 0x44, 0x55, 0x43, 0x4a, 0x39, 0x58,
+	0x53, 0x77, 0x69, 0x63, 0x6d, 0x56, 0x7a, 0x62, 0x33, 0x56, 0x79, 0x59, 0x32, 0x56, 0x7a, 0x49,
+	0x6a, 0x70, 0x37, 0x49, 0x6d, 0x78, 0x70, 0x62, 0x57, 0x6c, 0x30, 0x63, 0x79, 0x49, 0x36, 0x65,
+	0x79, 0x4a, 0x6a, 0x63, 0x48, 0x55, 0x69, 0x4f, 0x69, 0x49, 0x31, 0x4d, 0x44, 0x42, 0x74, 0x49,
+	0x69, 0x77, 0x69, 0x62, 0x57, 0x56, 0x74, 0x62, 0x33, 0x4a, 0x35, 0x49, 0x6a, 0x6f, 0x69, 0x4e,
+	0x54, 0x45, 0x79, 0x54, 0x57, 0x6b, 0x69, 0x66, 0x53, 0x77, 0x69, 0x63, 0x6d, 0x56, 0x78, 0x64,
+	0x57, 0x56, 0x7a, 0x64, 0x48, 0x4d, 0x69, 0x4f, 0x6e, 0x73, 0x69, 0x59, 0x33, 0x42, 0x31, 0x49,
+	0x6a, 0x6f, 0x69, 0x4d, 0x54, 0x41, 0x77, 0x62, 0x53, 0x49, 0x73, 0x49, 0x6d, 0x31, 0x6c, 0x62,
+	0x57, 0x39, 0x79, 0x65, 0x53, 0x49, 0x36, 0x49, 0x6a, 0x45, 0x79, 0x4f, 0x45, 0x31, 0x70, 0x49,
+	0x6e, 0x31, 0x39, 0x4c, 0x43, 0x4a, 0x32, 0x62, 0x32, 0x78, 0x31, 0x62, 0x57, 0x56, 0x4e, 0x62,
+	0x33, 0x56, 0x75, 0x64, 0x48, 0x4d, 0x69, 0x4f, 0x6c, 0x74, 0x37, 0x49, 0x6d, 0x35, 0x68, 0x62,
+	0x57, 0x55, 0x69, 0x4f, 0x69, 0x4a, 0x6b, 0x5a, 0x57, 0x5a, 0x68, 0x64, 0x57, 0x78, 0x30, 0x4c,
+	0x58, 0x52, 0x76, 0x61, 0x32, 0x56, 0x75, 0x4c, 0x57, 0x70, 0x30, 0x4d, 0x6a, 0x64, 0x30, 0x49,
+	0x69, 0x77, 0x69, 0x63, 0x6d, 0x56, 0x68, 0x5a, 0x45, 0x39, 0x75, 0x62, 0x48, 0x6b, 0x69, 0x4f,
+	0x6e, 0x52, 0x79, 0x64, 0x57, 0x55, 0x73, 0x49, 0x6d, 0x31, 0x76, 0x64, 0x57, 0x35, 0x30, 0x55,
+	0x47, 0x46, 0x30, 0x61, 0x43, 0x49, 0x36, 0x49, 0x69, 0x39, 0x32, 0x59, 0x58, 0x49, 0x76, 0x63,
+	0x6e, 0x56, 0x75, 0x4c, 0x33, 0x4e, 0x6c, 0x59, 0x33, 0x4a, 0x6c, 0x64, 0x48, 0x4d, 0x76, 0x61,
+	0x33, 0x56, 0x69, 0x5a, 0x58, 0x4a, 0x75, 0x5a, 0x58, 0x52, 0x6c, 0x63, 0x79, 0x35, 0x70, 0x62,
+	0x79, 0x39, 0x7a, 0x5a, 0x58, 0x4a, 0x32, 0x61, 0x57, 0x4e, 0x6c, 0x59, 0x57, 0x4e, 0x6a, 0x62,
+	0x33, 0x56, 0x75, 0x64, 0x43, 0x4a, 0x39, 0x58, 0x53, 0x77, 0x69, 0x64, 0x47, 0x56, 0x79, 0x62,
+	0x57, 0x6c, 0x75, 0x59, 0x58, 0x52, 0x70, 0x62, 0x32, 0x35, 0x4e, 0x5a, 0x58, 0x4e, 0x7a, 0x59,
+	0x57, 0x64, 0x6c, 0x55, 0x47, 0x46, 0x30, 0x61, 0x43, 0x49, 0x36, 0x49, 0x69, 0x39, 0x6b, 0x5a,
+	0x58, 0x59, 0x76, 0x64, 0x47, 0x56, 0x79, 0x62, 0x57, 0x6c, 0x64, 0x57, 0x78, 0x73, 0x4c, 0x43,
+	0x4a, 0x79, 0x5a, 0x57, 0x46, 0x7a, 0x62, 0x32, 0x34, 0x69, 0x4f, 0x69, 0x4a, 0x4f, 0x5a, 0x58,
+	0x64, 0x53, 0x5a, 0x58, 0x42, 0x73, 0x61, 0x57, 0x4e, 0x68, 0x55, 0x32, 0x56, 0x30, 0x51, 0x58,
+	0x5a, 0x68, 0x61, 0x57, 0x78, 0x68, 0x59, 0x6d, 0x78, 0x6c, 0x49, 0x69, 0x77, 0x69, 0x62, 0x57,
+	0x56, 0x7a, 0x63, 0x32, 0x46, 0x6e, 0x5a, 0x53, 0x49, 0x36, 0x49, 0x6c, 0x4a, 0x6c, 0x63, 0x47,
+	0x78, 0x70, 0x59, 0x32, 0x46, 0x54, 0x5a, 0x58, 0x51, 0x67, 0x58, 0x43, 0x4a, 0x72, 0x64, 0x57,
+	0x4a, 0x6c, 0x4c, 0x58, 0x42, 0x79, 0x62, 0x33, 0x68, 0x35, 0x4c, 0x54, 0x56, 0x6c, 0x4d, 0x54,
+	0x67, 0x30, 0x5a, 0x6d, 0x52, 0x6c, 0x4c, 0x54, 0x55, 0x34, 0x59, 0x33, 0x68, 0x6f, 0x58, 0x43,
+	0x49, 0x67, 0x61, 0x47, 0x46, 0x7a, 0x49, 0x48, 0x4e, 0x31, 0x59, 0x32, 0x4e, 0x6c, 0x63, 0x33,
+	0x4e, 0x6d, 0x64, 0x57, 0x78, 0x73, 0x65, 0x53, 0x42, 0x77, 0x63, 0x6d, 0x39, 0x6e, 0x63, 0x6d,
+	0x56, 0x7a, 0x63, 0x32, 0x56, 0x6b, 0x4c, 0x69, 0x4a, 0x39, 0x58, 0x58, 0x31, 0x39, 0x4c, 0x43,
+	0x4a, 0x6c, 0x5a, 0x47, 0x64, 0x6c, 0x4c, 0x57, 0x46, 0x77, 0x63, 0x48, 0x4d, 0x76, 0x62, 0x58,
+	0x46, 0x30, 0x64, 0x43, 0x31, 0x69, 0x63, 0x6d, 0x39, 0x72, 0x5a, 0x58, 0x49, 0x74, 0x5a, 0x54,
+	0x49, 0x7a, 0x5a, 0x6d, 0x55, 0x79, 0x5a, 0x57, 0x45, 0x74, 0x4e, 0x44, 0x63, 0x34, 0x65, 0x6d,
+	0x6f, 0x69, 0x4f, 0x6e, 0x73, 0x69, 0x61, 0x32, 0x6c, 0x75, 0x5a, 0x43, 0x49, 0x36, 0x49, 0x6b,
+	0x52, 0x6c, 0x63, 0x47, 0x78, 0x76, 0x65, 0x57, 0x31, 0x6c, 0x62, 0x6e, 0x51, 0x69, 0x4c, 0x43,
+	0x4a, 0x68, 0x63, 0x47, 0x6c, 0x57, 0x5a, 0x58, 0x4a, 0x7a, 0x61, 0x57, 0x39, 0x75, 0x49, 0x6a,
+	0x6f, 0x69, 0x59, 0x58, 0x42, 0x77, 0x63, 0x79, 0x39, 0x32, 0x4d, 0x53, 0x49, 0x73, 0x49, 0x6d,
+	0x31, 0x6c, 0x64, 0x47, 0x46, 0x6b, 0x59, 0x58, 0x52, 0x68, 0x49, 0x6a, 0x70, 0x37, 0x49, 0x6d,
+	0x35, 0x68, 0x62, 0x57, 0x55, 0x69, 0x4f, 0x69, 0x4a, 0x74, 0x63, 0x58, 0x52, 0x30, 0x4c, 0x57,
+	0x4a, 0x79, 0x62, 0x32, 0x74, 0x6c, 0x63, 0x69, 0x31, 0x6c, 0x4d, 0x6a, 0x4e, 0x6d, 0x5a, 0x54,
+	0x4a, 0x6c, 0x59, 0x53, 0x30, 0x30, 0x4e, 0x7a, 0x68, 0x36, 0x61, 0x69, 0x49, 0x73, 0x49, 0x6d,
+	0x35, 0x68, 0x62, 0x57, 0x56, 0x7a, 0x63, 0x47, 0x46, 0x6a, 0x5a, 0x53, 0x49, 0x36, 0x49, 0x6d,
+	0x56, 0x6b, 0x5a, 0x32, 0x55, 0x74, 0x59, 0x58, 0x42, 0x77, 0x63, 0x79, 0x49, 0x73, 0x49, 0x6e,
+	0x4a, 0x6c, 0x63, 0x32, 0x39, 0x31, 0x63, 0x6d, 0x4e, 0x6c, 0x56, 0x6d, 0x56, 0x79, 0x63, 0x32,
+	0x6c, 0x76, 0x62, 0x69, 0x49, 0x36, 0x49, 0x6a, 0x49, 0x34, 0x4e, 0x54, 0x45, 0x7a, 0x4e, 0x7a,
+	0x41, 0x69, 0x4c, 0x43, 0x4a, 0x6a, 0x63, 0x6d, 0x56, 0x68, 0x64, 0x47, 0x6c, 0x76, 0x62, 0x6c,
+	0x52, 0x70, 0x62, 0x57, 0x56, 0x7a, 0x64, 0x47, 0x46, 0x74, 0x63, 0x43, 0x49, 0x36, 0x62, 0x6e,
+	0x56, 0x73, 0x62, 0x43, 0x77, 0x69, 0x62, 0x47, 0x46, 0x69, 0x5a, 0x57, 0x78, 0x7a, 0x49, 0x6a,
+	0x70, 0x37, 0x49, 0x6d, 0x46, 0x77, 0x63, 0x43, 0x49, 0x36, 0x49, 0x6d, 0x31, 0x78, 0x64, 0x48,
+	0x51, 0x74, 0x59, 0x6e, 0x4a, 0x76, 0x61, 0x32, 0x56, 0x79, 0x49, 0x69, 0x77, 0x69, 0x59, 0x58,
+	0x42, 0x77, 0x4c, 0x6d, 0x74, 0x31, 0x59, 0x6d, 0x56, 0x79, 0x62, 0x6d, 0x56, 0x30, 0x5a, 0x58,
+	0x4d, 0x75, 0x61, 0x57, 0x38, 0x76, 0x63, 0x47, 0x46, 0x79, 0x64, 0x43, 0x31, 0x76, 0x5a, 0x69,
+	0x49, 0x36, 0x49, 0x6d, 0x39, 0x30, 0x5a, 0x53, 0x49, 0x73, 0x49, 0x6d, 0x56, 0x6b, 0x5a, 0x32,
+	0x55, 0x74, 0x64, 0x69, 0x4c, 0x43, 0x4a, 0x68, 0x63, 0x48, 0x41, 0x75, 0x61, 0x33, 0x56, 0x69,
+	0x5a, 0x58, 0x4a, 0x75, 0x5a, 0x58, 0x52, 0x6c, 0x63, 0x79, 0x35, 0x70, 0x62, 0x79, 0x39, 0x77,
+	0x59, 0x58, 0x4a, 0x30, 0x4c, 0x57, 0x39, 0x6d, 0x49, 0x6a, 0x6f, 0x69, 0x62, 0x33, 0x52, 0x6c,
+	0x49, 0x69, 0x77, 0x69, 0x5a, 0x57, 0x52, 0x6e, 0x5a, 0x53, 0x31, 0x32, 0x5a, 0x58, 0x4a, 0x7a,
+	0x61, 0x57, 0x39, 0x75, 0x49, 0x6a, 0x6f, 0x69, 0x4f, 0x44, 0x55, 0x35, 0x4e, 0x44, 0x59, 0x69,
+	0x4c, 0x43, 0x4a, 0x76, 0x64, 0x47, 0x55, 0x74, 0x59, 0x32, 0x78, 0x31, 0x63, 0x33, 0x52, 0x6c,
+	0x63, 0x69, 0x49, 0x36, 0x49, 0x6d, 0x56, 0x6b, 0x5a, 0x32, 0x55, 0x74, 0x4e, 0x69, 0x49, 0x73,
+	0x49, 0x6e, 0x42, 0x76, 0x5a, 0x43, 0x31, 0x30, 0x5a, 0x57, 0x31, 0x77, 0x62, 0x47, 0x46, 0x30,
+	0x5a, 0x53, 0x31, 0x6f, 0x59, 0x58, 0x4e, 0x6f, 0x49, 0x6a, 0x6f, 0x69, 0x4e, 0x6e, 0x45, 0x34,
+	0x5a, 0x7a, 0x64, 0x74, 0x5a, 0x47, 0x4e, 0x6d, 0x63, 0x53, 0x4a, 0x39, 0x66, 0x53, 0x77, 0x69,
+	0x63, 0x33, 0x42, 0x6c, 0x59, 0x79, 0x49, 0x36, 0x65, 0x79, 0x4a, 0x32, 0x62, 0x32, 0x78, 0x31,
+	0x62, 0x57, 0x56, 0x7a, 0x49, 0x6a, 0x70, 0x62, 0x65, 0x79, 0x4a, 0x75, 0x59, 0x57, 0x31, 0x6c,
+	0x49, 0x6a, 0x6f, 0x69, 0x5a, 0x47, 0x56, 0x6d, 0x59, 0x58, 0x56, 0x73, 0x64, 0x43, 0x31, 0x30,
+	0x62, 0x32, 0x74, 0x6c, 0x62, 0x69, 0x30, 0x33, 0x63, 0x6a, 0x4a, 0x30, 0x61, 0x43, 0x49, 0x73,
+	0x49, 0x6e, 0x4e, 0x6c, 0x59, 0x33, 0x4a, 0x6c, 0x64, 0x43, 0x49, 0x36, 0x65, 0x79, 0x4a, 0x7a,
+	0x5a, 0x57, 0x4e, 0x79, 0x5a, 0x58, 0x52, 0x4f, 0x59, 0x57, 0x31, 0x6c, 0x49, 0x6a, 0x6f, 0x69,
+	0x5a, 0x47, 0x56, 0x6d, 0x59, 0x58, 0x56, 0x73, 0x64, 0x43, 0x31, 0x30, 0x62, 0x32, 0x74, 0x6c,
+	0x62, 0x69, 0x31, 0x77, 0x63, 0x7a, 0x68, 0x36, 0x62, 0x43, 0x4a, 0x39, 0x66, 0x56, 0x30, 0x73,
+	0x49, 0x6d, 0x4e, 0x76, 0x62, 0x6e, 0x52, 0x68, 0x61, 0x57, 0x35, 0x6c, 0x63, 0x6e, 0x4d, 0x69,
+	0x4f, 0x6c, 0x74, 0x37, 0x49, 0x6d, 0x35, 0x68, 0x62, 0x57, 0x55, 0x69, 0x4f, 0x69, 0x4a, 0x32,
+	0x61, 0x57, 0x52, 0x6c, 0x62, 0x79, 0x31, 0x68, 0x62, 0x6d, 0x46, 0x73, 0x65, 0x58, 0x70, 0x6c,
+	0x63, 0x69, 0x49, 0x73, 0x49, 0x6d, 0x6c, 0x74, 0x59, 0x57, 0x64, 0x6c, 0x49, 0x6a, 0x6f, 0x69,
+	0x63, 0x6d, 0x56, 0x6e, 0x61, 0x58, 0x4e, 0x30, 0x63, 0x6e, 0x6b, 0x75, 0x62, 0x47, 0x39, 0x6a,
+	0x59, 0x57, 0x77, 0x76, 0x64, 0x6d, 0x6c, 0x6b, 0x5a, 0x57, 0x38, 0x74, 0x59, 0x57, 0x35, 0x68,
+	0x62, 0x48, 0x6c, 0x36, 0x5a, 0x58, 0x49, 0x36, 0x64, 0x6a, 0x49, 0x75, 0x4d, 0x79, 0x34, 0x78,
+	0x49, 0x69, 0x77, 0x69, 0x63, 0x47, 0x39, 0x79, 0x64, 0x48, 0x4d, 0x69, 0x4f, 0x6c, 0x74, 0x37,
+	0x49, 0x6d, 0x4e, 0x76, 0x62, 0x6e, 0x52, 0x68, 0x61, 0x57, 0x35, 0x6c, 0x63, 0x6c, 0x42, 0x76,
+	0x63, 0x6e, 0x51, 0x69, 0x4f, 0x6a, 0x67, 0x30, 0x4e, 0x54, 0x55, 0x73, 0x49, 0x6e, 0x42, 0x79,
+	0x62, 0x33, 0x52, 0x76, 0x59, 0x32, 0x39, 0x73, 0x49, 0x6a, 0x6f, 0x69, 0x56, 0x45, 0x4e, 0x51,
+	0x49, 0x6e, 0x31, 0x64, 0x4c, 0x43, 0x4a, 0x79, 0x5a, 0x58, 0x4e, 0x76, 0x64, 0x58, 0x4a, 0x6a,
+	0x5a, 0x58, 0x4d, 0x69, 0x4f, 0x6e, 0x73, 0x69, 0x62, 0x47, 0x6c, 0x74, 0x61, 0x58, 0x52, 0x7a,
+	0x49, 0x6a, 0x70, 0x37, 0x49, 0x6d, 0x4e, 0x77, 0x64, 0x53, 0x49, 0x36, 0x49, 0x6a, 0x55, 0x77,
+	0x4d, 0x47, 0x30, 0x69, 0x4c, 0x43, 0x4a, 0x74, 0x5a, 0x57, 0x31, 0x76, 0x63, 0x6e, 0x6b, 0x69,
+	0x4f, 0x69, 0x49, 0x31, 0x4d, 0x54, 0x4a, 0x4e, 0x61, 0x53, 0x4a, 0x39, 0x6d, 0x46, 0x70, 0x62,
+	0x47, 0x46, 0x69, 0x62, 0x47, 0x55, 0x69, 0x4c, 0x43, 0x4a, 0x74, 0x5a, 0x58, 0x4e, 0x7a, 0x59,
+	0x57, 0x64, 0x6c, 0x49, 0x6a, 0x6f, 0x69, 0x52, 0x47, 0x56, 0x77, 0x62, 0x47, 0x39, 0x35, 0x62,
+	0x57, 0x56, 0x75, 0x64, 0x43, 0x42, 0x6f, 0x59, 0x58, 0x4d, 0x67, 0x62, 0x57, 0x6c, 0x75, 0x61,
+	0x57, 0x31, 0x31, 0x62, 0x53, 0x42, 0x68, 0x64, 0x6d, 0x46, 0x70, 0x62, 0x47, 0x46, 0x69, 0x61,
+	0x57, 0x78, 0x70, 0x64, 0x48, 0x6b, 0x75, 0x49, 0x6e, 0x30, 0x73, 0x65, 0x79, 0x4a, 0x30, 0x65,
+	0x58, 0x42, 0x6c, 0x49, 0x6a, 0x6f, 0x69, 0x55, 0x48, 0x4a, 0x76, 0x5a, 0x33, 0x4a, 0x6c, 0x63,
+	0x33, 0x4e, 0x70, 0x62, 0x6d, 0x63, 0x69, 0x4c, 0x43, 0x4a, 0x7a, 0x64, 0x47, 0x46, 0x30, 0x64,
+	0x58, 0x4d, 0x69, 0x4f, 0x69, 0x4a, 0x55, 0x63, 0x6e, 0x56, 0x6c, 0x49, 0x69, 0x77, 0x69, 0x62,
+	0x47, 0x46, 0x7a, 0x64, 0x46, 0x56, 0x77, 0x5a, 0x47, 0x46, 0x30, 0x5a, 0x56, 0x52, 0x70, 0x62,
+	0x57, 0x55, 0x69, 0x4f, 0x6d, 0x35, 0x31, 0x62, 0x47, 0x77, 0x73, 0x49, 0x6d, 0x78, 0x68, 0x63,
+	0x33, 0x52, 0x55, 0x63, 0x6d, 0x46, 0x75, 0x63, 0x32, 0x6c, 0x30, 0x61, 0x57, 0x39, 0x75, 0x56,
+	0x47, 0x6c, 0x74, 0x5a, 0x53, 0x49, 0x36, 0x62, 0x6e, 0x56, 0x73, 0x62, 0x43, 0x77, 0x69, 0x63,
+	0x6d, 0x56, 0x68, 0x63, 0x32, 0x39, 0x75, 0x49, 0x6a, 0x6f, 0x69, 0x54, 0x6d, 0x56, 0x33, 0x55,
+	0x6d, 0x56, 0x77, 0x62, 0x47, 0x6c, 0x6a, 0x59, 0x56, 0x4e, 0x6c, 0x64, 0x45, 0x46, 0x32, 0x59,
+	0x57, 0x6c, 0x73, 0x59, 0x57, 0x4a, 0x73, 0x5a, 0x53, 0x49, 0x73, 0x49, 0x6d, 0x31, 0x6c, 0x63,
+	0x33, 0x4e, 0x68, 0x5a, 0x32, 0x55, 0x69, 0x4f, 0x69, 0x4a, 0x53, 0x5a, 0x58, 0x42, 0x73, 0x61,
+	0x57, 0x4e, 0x68, 0x55, 0x32, 0x56, 0x30, 0x49, 0x46, 0x77, 0x69, 0x63, 0x6d, 0x56, 0x6b, 0x61,
+	0x58, 0x4d, 0x74, 0x4e, 0x44, 0x51, 0x31, 0x59, 0x57, 0x52, 0x6b, 0x59, 0x32, 0x55, 0x74, 0x5a,
+	0x6a, 0x59, 0x33, 0x62, 0x57, 0x74, 0x63, 0x49, 0x69, 0x42, 0x6f, 0x59, 0x58, 0x4d, 0x67, 0x63,
+	0x33, 0x56, 0x6a, 0x59, 0x32, 0x56, 0x7a, 0x63, 0x32, 0x5a, 0x31, 0x62, 0x47, 0x78, 0x35, 0x49,
+	0x48, 0x42, 0x79, 0x62, 0x32, 0x64, 0x79, 0x5a, 0x58, 0x4e, 0x7a, 0x5a, 0x57, 0x51, 0x75, 0x49,
+	0x6e, 0x31, 0x64, 0x66, 0x58, 0x31, 0x39, 0x4c, 0x43, 0x4a, 0x6b, 0x5a, 0x57, 0x78, 0x4e, 0x59,
+	0x58, 0x41, 0x69, 0x4f, 0x6d, 0x35, 0x31, 0x62, 0x47, 0x77, 0x73, 0x49, 0x6d, 0x5a, 0x31, 0x62,
+	0x47, 0x78, 0x4d, 0x61, 0x58, 0x4e, 0x30, 0x49, 0x6a, 0x70, 0x75, 0x64, 0x57, 0x78, 0x73, 0x66,
+	0x51, 0x3d, 0x3d, 0x22, 0x7d, 0x5d, 0x0a, 0x0b, 0x10, 0x09, 0x22, 0x07, 0x65, 0x64, 0x67, 0x65,
+	0x2d, 0x36, 0x32, 0x12, 0xea, 0x2f, 0x5b, 0x7b, 0x22, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63,
+	0x65, 0x54, 0x79, 0x70, 0x65, 0x22, 0x3a, 0x31, 0x2c, 0x22, 0x62, 0x6f, 0x64, 0x79, 0x22, 0x3a,
+	0x22, 0x65, 0x79, 0x4a, 0x31, 0x63, 0x47, 0x52, 0x68, 0x64, 0x47, 0x56, 0x4e, 0x59, 0x58, 0x41,
+	0x69, 0x4f, 0x6e, 0x73, 0x69, 0x5a, 0x57, 0x52, 0x6e, 0x5a, 0x53, 0x31, 0x75, 0x62, 0x32, 0x52,
+	0x6c, 0x4c, 0x54, 0x4d, 0x7a, 0x4d, 0x57, 0x59, 0x35, 0x4d, 0x7a, 0x59, 0x30, 0x4c, 0x57, 0x4a,
+	0x6e, 0x4e, 0x47, 0x78, 0x79, 0x49, 0x6a, 0x70, 0x37, 0x49, 0x6d, 0x74, 0x70, 0x62, 0x6d, 0x51,
+	0x69, 0x4f, 0x69, 0x4a, 0x4f, 0x62, 0x32, 0x52, 0x6c, 0x49, 0x69, 0x77, 0x69, 0x59, 0x58, 0x42,
+	0x70, 0x56, 0x6d, 0x56, 0x79, 0x63, 0x32, 0x6c, 0x76, 0x62, 0x69, 0x49, 0x36, 0x49, 0x6e, 0x59,
+	0x78, 0x49, 0x69, 0x77, 0x69, 0x46, 0x31, 0x5a, 0x58, 0x4e, 0x30, 0x63, 0x79, 0x49, 0x36, 0x65,
+	0x79, 0x4a, 0x6a, 0x63, 0x48, 0x55, 0x69, 0x4f, 0x69, 0x49, 0x78, 0x4d, 0x44, 0x42, 0x74, 0x49,
+	0x69, 0x77, 0x69, 0x62, 0x57, 0x56, 0x74, 0x62, 0x33, 0x4a, 0x35, 0x49, 0x6a, 0x6f, 0x69, 0x4d,
+	0x54, 0x49, 0x34, 0x54, 0x57, 0x6b, 0x69, 0x66, 0x58, 0x30, 0x73, 0x49, 0x6e, 0x5a, 0x76, 0x62,
+	0x48, 0x56, 0x74, 0x5a, 0x55, 0x31, 0x76, 0x64, 0x57, 0x35, 0x30, 0x63, 0x79, 0x49, 0x36, 0x57,
+	0x33, 0x73, 0x69, 0x62, 0x6d, 0x46, 0x74, 0x5a, 0x53, 0x49, 0x36, 0x49, 0x6d, 0x52, 0x6c, 0x5a,
+	0x6d, 0x46, 0x31, 0x62, 0x48, 0x51, 0x74, 0x64, 0x47, 0x39, 0x72, 0x5a, 0x57, 0x34, 0x74, 0x4e,
+	0x33, 0x64, 0x32, 0x63, 0x44, 0x6b, 0x69, 0x4c, 0x43, 0x4a, 0x79, 0x5a, 0x57, 0x46, 0x6b, 0x54,
+	0x32, 0x35, 0x73, 0x65, 0x53, 0x49, 0x36, 0x64, 0x48, 0x4a, 0x31, 0x5a, 0x53, 0x77, 0x69, 0x62,
+	0x57, 0x39, 0x31, 0x62, 0x6e, 0x52, 0x51, 0x59, 0x58, 0x52, 0x6f, 0x49, 0x6a, 0x6f, 0x69, 0x4c,
+	0x33, 0x5a, 0x68, 0x63, 0x69, 0x39, 0x79, 0x64, 0x57, 0x34, 0x76, 0x63, 0x32, 0x56, 0x6a, 0x63,
+	0x6d, 0x56, 0x30, 0x63, 0x79, 0x39, 0x72, 0x64, 0x57, 0x4a, 0x6c, 0x63, 0x6d, 0x35, 0x6c, 0x64,
+	0x47, 0x56, 0x7a, 0x4c, 0x6d, 0x6c, 0x76, 0x4c, 0x33, 0x4e, 0x6c, 0x63, 0x6e, 0x5a, 0x70, 0x59,
+	0x32, 0x56, 0x68, 0x59, 0x32, 0x4e, 0x76, 0x64, 0x57, 0x35, 0x30, 0x49, 0x6e, 0x31, 0x64, 0x4c,
+	0x43, 0x4a, 0x30, 0x5a, 0x58, 0x4a, 0x74, 0x61, 0x57, 0x35, 0x68, 0x64, 0x47, 0x6c, 0x76, 0x62,
+	0x6b, 0x31, 0x6c, 0x63, 0x33, 0x4e, 0x68, 0x5a, 0x32, 0x56, 0x51, 0x59, 0x58, 0x52, 0x6f, 0x49,
+	0x6a, 0x6f, 0x69, 0x4c, 0x32, 0x52, 0x6c, 0x64, 0x69, 0x39, 0x30, 0x5a, 0x58, 0x4a, 0x74, 0x61,
+	0x57, 0x35, 0x68, 0x64, 0x47, 0x6c, 0x76, 0x62, 0x69, 0x31, 0x73, 0x62, 0x32, 0x63, 0x69, 0x4c,
+	0x43, 0x4a, 0x30, 0x5a, 0x58, 0x4a, 0x74, 0x61, 0x57, 0x35, 0x68, 0x64, 0x47, 0x6c, 0x76, 0x62,
+	0x6b, 0x31, 0x6c, 0x63, 0x33, 0x4e, 0x68, 0x5a, 0x32, 0x56, 0x51, 0x62, 0x32, 0x78, 0x70, 0x59,
+	0x33, 0x6b, 0x69, 0x4f, 0x69, 0x4a, 0x47, 0x61, 0x57, 0x78, 0x6c, 0x49, 0x69, 0x77, 0x69, 0x61,
+	0x57, 0x31, 0x68, 0x5a, 0x32, 0x56, 0x51, 0x64, 0x57, 0x78, 0x73, 0x55, 0x47, 0x39, 0x73, 0x61,
+	0x57, 0x4e, 0x35, 0x49, 0x6a, 0x6f, 0x69, 0x53, 0x57, 0x5a, 0x4f, 0x62, 0x33, 0x52, 0x51, 0x63,
+	0x6d, 0x56, 0x7a, 0x5a, 0x57, 0x35, 0x30, 0x49, 0x6e, 0x31, 0x64, 0x4c, 0x43, 0x4a, 0x75, 0x62,
+	0x32, 0x52, 0x6c, 0x54, 0x6d, 0x46, 0x74, 0x5a, 0x53, 0x49, 0x36, 0x49, 0x6d, 0x56, 0x6b, 0x5a,
+	0x32, 0x55, 0x74, 0x62, 0x6d, 0x39, 0x6b, 0x5a, 0x53, 0x30, 0x7a, 0x49, 0x69, 0x77, 0x69, 0x64,
+	0x47, 0x39, 0x73, 0x5a, 0x58, 0x4a, 0x68, 0x64, 0x47, 0x6c, 0x76, 0x62, 0x6e, 0x4d, 0x69, 0x4f,
+	0x6c, 0x74, 0x37, 0x49, 0x6d, 0x74, 0x6c, 0x65, 0x53, 0x49, 0x36, 0x49, 0x6d, 0x35, 0x76, 0x5a,
+	0x47, 0x55, 0x75, 0x61, 0x33, 0x56, 0x69, 0x5a, 0x58, 0x4a, 0x75, 0x5a, 0x58, 0x52, 0x6c, 0x63,
+	0x79, 0x35, 0x70, 0x62, 0x79, 0x39, 0x75, 0x62, 0x33, 0x51, 0x74, 0x63, 0x6d, 0x56, 0x68, 0x5a,
+	0x48, 0x6b, 0x69, 0x4c, 0x43, 0x4a, 0x76, 0x63, 0x47, 0x56, 0x79, 0x59, 0x58, 0x52, 0x76, 0x63,
+	0x69, 0x49, 0x36, 0x49, 0x6b, 0x56, 0x34, 0x61, 0x58, 0x4e, 0x30, 0x63, 0x79, 0x49, 0x73, 0x49,
+	0x6d, 0x56, 0x6d, 0x5a, 0x6d, 0x56, 0x6a, 0x64, 0x43, 0x49, 0x36, 0x49, 0x6b, 0x35, 0x76, 0x52,
+	0x58, 0x68, 0x6c, 0x59, 0x33, 0x56, 0x30, 0x5a, 0x53, 0x4a, 0x39, 0x4c, 0x48, 0x73, 0x55, 0x69,
+	0x4f, 0x6e, 0x73, 0x69, 0x62, 0x57, 0x56, 0x30, 0x59, 0x57, 0x52, 0x68, 0x64, 0x47, 0x45, 0x69,
+	0x4f, 0x6e, 0x73, 0x69, 0x59, 0x33, 0x4a, 0x6c, 0x59, 0x58, 0x52, 0x70, 0x62, 0x32, 0x35, 0x55,
+	0x61, 0x57, 0x31, 0x6c, 0x63, 0x33, 0x52, 0x68, 0x62, 0x58, 0x41, 0x69, 0x4f, 0x6d, 0x35, 0x31,
+	0x62, 0x47, 0x77, 0x73, 0x49, 0x6d, 0x78, 0x68, 0x59, 0x6d, 0x56, 0x73, 0x63, 0x79, 0x49, 0x36,
+	0x65, 0x79, 0x4a, 0x68, 0x63, 0x48, 0x41, 0x69, 0x4f, 0x69, 0x4a, 0x6a, 0x62, 0x33, 0x4a, 0x6c,
+	0x5a, 0x47, 0x35, 0x7a, 0x49, 0x6e, 0x31, 0x39, 0x4c, 0x43, 0x4a, 0x7a, 0x63, 0x47, 0x56, 0x6a,
+	0x49, 0x6a, 0x70, 0x37, 0x49, 0x6e, 0x5a, 0x76, 0x62, 0x48, 0x56, 0x74, 0x5a, 0x58, 0x4d, 0x69,
+	0x4f, 0x6c, 0x74, 0x37, 0x49, 0x6d, 0x35, 0x68, 0x62, 0x57, 0x55, 0x69, 0x4f, 0x69, 0x4a, 0x6b,
+	0x5a, 0x57, 0x5a, 0x68, 0x64, 0x57, 0x78, 0x30, 0x4c, 0x58, 0x52, 0x76, 0x61, 0x32, 0x56, 0x75,
+	0x4c, 0x58, 0x70, 0x33, 0x63, 0x33, 0x4d, 0x79, 0x49, 0x69, 0x77, 0x69, 0x63, 0x32, 0x56, 0x6a,
+	0x63, 0x6d, 0x56, 0x30, 0x49, 0x6a, 0x70, 0x37, 0x49, 0x6e, 0x4e, 0x6c, 0x59, 0x33, 0x4a, 0x6c,
+	0x64, 0x45, 0x35, 0x68, 0x62, 0x57, 0x55, 0x69, 0x4f, 0x69, 0x4a, 0x6b, 0x5a, 0x57, 0x5a, 0x68,
+	0x64, 0x57, 0x78, 0x30, 0x4c, 0x58, 0x52, 0x76, 0x61, 0x32, 0x56, 0x75, 0x4c, 0x58, 0x5a, 0x74,
+	0x63, 0x47, 0x74, 0x6d, 0x49, 0x6e, 0x31, 0x39, 0x58, 0x53, 0x77, 0x69, 0x59, 0x32, 0x39, 0x75,
+	0x64, 0x47, 0x46, 0x70, 0x62, 0x6d, 0x56, 0x79, 0x63, 0x79, 0x49, 0x36, 0x57, 0x33, 0x73, 0x69,
+	0x62, 0x6d, 0x46, 0x74, 0x5a, 0x53, 0x49, 0x36, 0x49, 0x6e, 0x4a, 0x6c, 0x5a, 0x47, 0x6c, 0x7a,
+	0x49, 0x69, 0x77, 0x69, 0x61, 0x57, 0x31, 0x68, 0x5a, 0x32, 0x55, 0x69, 0x4f, 0x69, 0x4a, 0x79,
+	0x5a, 0x57, 0x52, 0x70, 0x63, 0x7a, 0x6f, 0x31, 0x4c, 0x6a, 0x41, 0x69, 0x4c, 0x43, 0x4a, 0x77,
+	0x62, 0x33, 0x4a, 0x30, 0x63, 0x79, 0x49, 0x36, 0x57, 0x33, 0x73, 0x69, 0x59, 0x32, 0x39, 0x75,
+	0x64, 0x47, 0x46, 0x70, 0x62, 0x6d, 0x56, 0x79, 0x55, 0x47, 0x39, 0x79, 0x64, 0x43, 0x49, 0x36,
+	0x4f, 0x44, 0x41, 0x32, 0x4d, 0x69, 0x77, 0x69, 0x63, 0x48, 0x4a, 0x76, 0x64, 0x47, 0x39, 0x6a,
+	0x62, 0x32, 0x77, 0x69, 0x4f, 0x69, 0x4a, 0x55, 0x51, 0x31, 0x41, 0x69, 0x66, 0x56, 0x30, 0x73,
+	0x49, 0x6e, 0x4a, 0x6c, 0x63, 0x32, 0x39, 0x31, 0x63, 0x6d, 0x4e, 0x6c, 0x63, 0x79, 0x49, 0x36,
+	0x65, 0x79, 0x4a, 0x73, 0x61, 0x57, 0x31, 0x70, 0x64, 0x48, 0x4d, 0x69, 0x4f, 0x6e, 0x73, 0x69,
+	0x59, 0x33, 0x42, 0x31, 0x49, 0x6a, 0x6f, 0x69, 0x4e, 0x54, 0x41, 0x77, 0x62, 0x53, 0x49, 0x73,
+	0x49, 0x6d, 0x31, 0x6c, 0x62, 0x57, 0x39, 0x79, 0x65, 0x53, 0x49, 0x36, 0x49, 0x6a, 0x55, 0x78,
+	0x4d, 0x6b, 0x31, 0x70, 0x49, 0x6e, 0x30, 0x73, 0x49, 0x6e, 0x4a, 0x6c, 0x63, 0x58, 0x56, 0x6c,
+	0x63, 0x33, 0x52, 0x7a, 0x49, 0x6a, 0x70, 0x37, 0x49, 0x6d, 0x4e, 0x77, 0x64, 0x53, 0x49, 0x36,
+	0x49, 0x6a, 0x45, 0x77, 0x4d, 0x47, 0x30, 0x69, 0x4c, 0x43, 0x4a, 0x74, 0x5a, 0x57, 0x31, 0x76,
+	0x63, 0x6e, 0x6b, 0x69, 0x4f, 0x69, 0x49, 0x78, 0x4d, 0x6a, 0x68, 0x4e, 0x61, 0x53, 0x4a, 0x39,
+	0x66, 0x53, 0x77, 0x69, 0x64, 0x6d, 0x39, 0x73, 0x64, 0x57, 0x31, 0x6c, 0x54, 0x57, 0x39, 0x31,
+	0x62, 0x6e, 0x52, 0x7a, 0x49, 0x6a, 0x70, 0x62, 0x65, 0x79, 0x4a, 0x75, 0x59, 0x57, 0x31, 0x6c,
+	0x49, 0x6a, 0x6f, 0x69, 0x5a, 0x47, 0x56, 0x6d, 0x59, 0x58, 0x56, 0x73, 0x64, 0x43, 0x31, 0x30,
+	0x62, 0x32, 0x74, 0x6c, 0x62, 0x69, 0x31, 0x4a, 0x73, 0x59, 0x58, 0x4e, 0x30, 0x56, 0x48, 0x4a,
+	0x68, 0x62, 0x6e, 0x4e, 0x70, 0x64, 0x47, 0x6c, 0x76, 0x62, 0x6c, 0x52, 0x70, 0x62, 0x57, 0x55,
+	0x69, 0x4f, 0x6d, 0x35, 0x31, 0x62, 0x47, 0x77, 0x73, 0x49, 0x6e, 0x4a, 0x6c, 0x59, 0x58, 0x4e,
+	0x76, 0x62, 0x69, 0x49, 0x36, 0x49, 0x6b, 0x74, 0x31, 0x59, 0x6d, 0x56, 0x73, 0x5a, 0x58, 0x52,
+	0x49, 0x59, 0x58, 0x4e, 0x54, 0x64, 0x57, 0x5a, 0x6d, 0x61, 0x57, 0x4e, 0x70, 0x5a, 0x57, 0x35,
+	0x30, 0x54, 0x57, 0x56, 0x74, 0x62, 0x33, 0x4a, 0x35, 0x49, 0x69, 0x77, 0x69, 0x62, 0x57, 0x56,
+	0x7a, 0x63, 0x32, 0x46, 0x6e, 0x5a, 0x53, 0x49, 0x36, 0x49, 0x6d, 0x74, 0x31, 0x59, 0x6d, 0x56,
+	0x73, 0x5a, 0x58, 0x51, 0x67, 0x61, 0x47, 0x46, 0x7a, 0x49, 0x48, 0x4e, 0x31, 0x5a, 0x6d, 0x5a,
+	0x70, 0x59, 0x32, 0x6c, 0x6c, 0x62, 0x6e, 0x51, 0x67, 0x62, 0x57, 0x56, 0x74, 0x62, 0x33, 0x4a,
+	0x35, 0x49, 0x47, 0x46, 0x32, 0x59, 0x57, 0x6c, 0x73, 0x59, 0x57, 0x4a, 0x73, 0x5a, 0x53, 0x4a,
+	0x39, 0x4c, 0x48, 0x73, 0x69, 0x64, 0x48, 0x6c, 0x77, 0x5a, 0x53, 0x49, 0x36, 0x49, 0x6b, 0x52,
+	0x70, 0x63, 0x32, 0x74, 0x51, 0x63, 0x6d, 0x56, 0x7a, 0x63, 0x33, 0x56, 0x79, 0x5a, 0x53, 0x49,
+	0x73, 0x49, 0x6e, 0x4e, 0x30, 0x59, 0x58, 0x52, 0x31, 0x63, 0x79, 0x49, 0x36, 0x49, 0x6b, 0x5a,
+	0x68, 0x62, 0x48, 0x4e, 0x6c, 0x49, 0x69, 0x77, 0x69, 0x62, 0x47, 0x46, 0x7a, 0x64, 0x45, 0x68,
+	0x6c, 0x59, 0x58, 0x4a, 0x30, 0x59, 0x6d, 0x56, 0x68, 0x64, 0x46, 0x52, 0x70, 0x62, 0x57, 0x55,
+	0x69, 0x4f, 0x69, 0x49, 0x79, 0x4d, 0x44, 0x49, 0x32, 0x4c, 0x54, 0x45, 0x77, 0x4c, 0x54, 0x45,
+	0x33, 0x56, 0x44, 0x41, 0x7a, 0x4f, 0x6a, 0x51, 0x35, 0x4f, 0x6a, 0x49, 0x35, 0x57, 0x69, 0x49,
+	0x73, 0x49, 0x6d, 0x78, 0x68, 0x63, 0x33, 0x52, 0x55, 0x63, 0x6d, 0x46, 0x75, 0x63, 0x32, 0x6c,
+	0x30, 0x61, 0x57, 0x39, 0x75, 0x56, 0x47, 0x6c, 0x74, 0x5a, 0x53, 0x49, 0x36, 0x62, 0x6e, 0x56,
+	0x73, 0x62, 0x43, 0x77, 0x69, 0x63, 0x6d, 0x56, 0x68, 0x63, 0x32, 0x39, 0x75, 0x49, 0x6a, 0x6f,
+	0x69, 0x53, 0x33, 0x56, 0x69, 0x5a, 0x57, 0x78, 0x6c, 0x64, 0x45, 0x68, 0x68, 0x63, 0x30, 0x35,
+	0x76, 0x52, 0x47, 0x6c, 0x7a, 0x61, 0x31, 0x42, 0x79, 0x5a, 0x58, 0x4e, 0x7a, 0x64, 0x58, 0x4a,
+	0x6c, 0x49, 0x69, 0x77, 0x69, 0x62, 0x57, 0x56, 0x7a, 0x63, 0x32, 0x46, 0x6e, 0x5a, 0x53, 0x49,
+	0x36, 0x49, 0x6d, 0x74, 0x31, 0x59, 0x6d, 0x56, 0x73, 0x5a, 0x58, 0x51, 0x67, 0x61, 0x47, 0x46,
+	0x7a, 0x49, 0x47, 0x35, 0x76, 0x49, 0x47, 0x52, 0x70, 0x63, 0x32, 0x73, 0x67, 0x63, 0x48, 0x4a,
+	0x6c, 0x63, 0x33, 0x4e, 0x31, 0x63, 0x6d, 0x55, 0x69, 0x66, 0x53, 0x78, 0x37, 0x49, 0x6e, 0x52,
+	0x35, 0x63, 0x47, 0x55, 0x69, 0x4f, 0x69, 0x4a, 0x53, 0x5a, 0x57, 0x46, 0x6b, 0x65, 0x53, 0x49,
+	0x73, 0x49, 0x6e, 0x4e, 0x30, 0x59, 0x58, 0x52, 0x31, 0x63, 0x79, 0x49, 0x36, 0x49, 0x6c, 0x52,
+	0x79, 0x64, 0x57, 0x55, 0x69, 0x4c, 0x43, 0x4a, 0x73, 0x59, 0x58, 0x4e, 0x30, 0x53, 0x47, 0x56,
+	0x68, 0x63, 0x6e, 0x52, 0x69, 0x5a, 0x57, 0x46, 0x30, 0x56, 0x47, 0x6c, 0x74, 0x5a, 0x53, 0x49,
+	0x36, 0x49, 0x6a, 0x49, 0x77, 0x4d, 0x6a, 0x59, 0x74, 0x4d, 0x54, 0x41, 0x74, 0x4d, 0x54, 0x64,
+	0x55, 0x4d, 0x44, 0x4d, 0x36, 0x4e, 0x44, 0x6b, 0x36, 0x4d, 0x6a, 0x6c, 0x61, 0x49, 0x69, 0x77,
+	0x69, 0x62, 0x47, 0x46, 0x7a, 0x64, 0x46, 0x52, 0x79, 0x59, 0x57, 0x35, 0x7a, 0x61, 0x58, 0x52,
+	0x70, 0x62, 0x32, 0x35, 0x55, 0x61, 0x57, 0x31, 0x6c, 0x49, 0x6a, 0x70, 0x75, 0x64, 0x57, 0x78,
+	0x62, 0x32, 0x34, 0x74, 0x62, 0x47, 0x39, 0x6e, 0x49, 0x69, 0x77, 0x69, 0x64, 0x47, 0x56, 0x79,
+	0x62, 0x57, 0x6c, 0x75, 0x59, 0x58, 0x52, 0x70, 0x62, 0x32, 0x35, 0x4e, 0x5a, 0x58, 0x4e, 0x7a,
+	0x59, 0x57, 0x64, 0x6c, 0x55, 0x47, 0x39, 0x73, 0x61, 0x57, 0x4e, 0x35, 0x49, 0x6a, 0x6f, 0x69,
+	0x52, 0x6d, 0x6c, 0x73, 0x5a, 0x53, 0x49, 0x73, 0x49, 0x6d, 0x6c, 0x74, 0x59, 0x57, 0x64, 0x6c,
+	0x55, 0x48, 0x56, 0x73, 0x62, 0x46, 0x42, 0x76, 0x62, 0x47, 0x6c, 0x6a, 0x65, 0x53, 0x49, 0x36,
+	0x49, 0x6b, 0x6c, 0x6d, 0x54, 0x6d, 0x39, 0x30, 0x55, 0x48, 0x4a, 0x6c, 0x63, 0x32, 0x56, 0x75,
+	0x64, 0x43, 0x4a, 0x39, 0x58, 0x53, 0x77, 0x69, 0x62, 0x6d, 0x39, 0x6b, 0x5a, 0x55, 0x35, 0x68,
+	0x62, 0x57, 0x55, 0x69, 0x4f, 0x69, 0x4a, 0x6c, 0x5a, 0x47, 0x64, 0x6c, 0x4c, 0x57, 0x35, 0x76,
+	0x5a, 0x47, 0x55, 0x74, 0x4d, 0x43, 0x49, 0x73, 0x49, 0x6e, 0x52, 0x76, 0x62, 0x47, 0x56, 0x79,
+	0x59, 0x58, 0x52, 0x70, 0x62, 0x32, 0x35, 0x7a, 0x49, 0x6a, 0x70, 0x62, 0x65, 0x79, 0x4a, 0x72,
+	0x5a, 0x58, 0x6b, 0x69, 0x4f, 0x69, 0x4a, 0x75, 0x62, 0x32, 0x52, 0x6c, 0x4c, 0x6d, 0x74, 0x31,
+	0x59, 0x6d, 0x56, 0x79, 0x62, 0x6d, 0x56, 0x30, 0x5a, 0x58, 0x4d, 0x75, 0x61, 0x57, 0x38, 0x76,
+	0x62, 0x6d, 0x39, 0x30, 0x4c, 0x58, 0x4a, 0x6c, 0x59, 0x57, 0x52, 0x35, 0x49, 0x69, 0x77, 0x69,
+	0x62, 0x33, 0x42, 0x6c, 0x63, 0x6d, 0x46, 0x30, 0x62, 0x33, 0x49, 0x69, 0x4f, 0x69, 0x4a, 0x46,
+	0x65, 0x47, 0x6c, 0x7a, 0x64, 0x48, 0x4d, 0x69, 0x4c, 0x43, 0x4a, 0x6c, 0x5a, 0x6d, 0x5a, 0x6c,
+	0x59, 0x33, 0x51, 0x69, 0x4f, 0x69, 0x4a, 0x4f, 0x62, 0x30, 0x56, 0x34, 0x5a, 0x57, 0x4e, 0x31,
+	0x64, 0x47, 0x55, 0x69, 0x66, 0x53, 0x78, 0x37, 0x49, 0x6d, 0x74, 0x6c, 0x65, 0x53, 0x49, 0x36,
+	0x49, 0x6d, 0x35, 0x76, 0x5a, 0x47, 0x55, 0x75, 0x61, 0x33, 0x56, 0x69, 0x5a, 0x58, 0x4a, 0x75,
+	0x5a, 0x58, 0x52, 0x6c, 0x63, 0x79, 0x35, 0x70, 0x62, 0x79, 0x39, 0x31, 0x62, 0x6e, 0x4a, 0x6c,
+	0x59, 0x57, 0x4e, 0x6f, 0x59, 0x57, 0x4a, 0x73, 0x5a, 0x53, 0x49, 0x73, 0x49, 0x6d, 0x39, 0x77,
+	0x5a, 0x58, 0x4a, 0x68, 0x64, 0x47, 0x39, 0x79, 0x49, 0x6a, 0x6f, 0x69, 0x52, 0x58, 0x68, 0x70,
+	0x63, 0x33, 0x52, 0x7a, 0x49, 0x69, 0x77, 0x69, 0x5a, 0x57, 0x5a, 0x6d, 0x5a, 0x57, 0x4e, 0x30,
+	0x49, 0x6a, 0x6f, 0x69, 0x54, 0x6d, 0x39, 0x46, 0x65, 0x47, 0x56, 0x6a, 0x64, 0x58, 0x52, 0x6c,
+	0x49, 0x6e, 0x31, 0x64, 0x66, 0x53, 0x77, 0x69, 0x63, 0x33, 0x52, 0x68, 0x64, 0x48, 0x56, 0x7a,
+	0x49, 0x6a, 0x70, 0x37, 0x49, 0x6e, 0x42, 0x6f, 0x59, 0x58, 0x4e, 0x6c, 0x49, 0x6a, 0x6f, 0x69,
+	0x55, 0x6e, 0x56, 0x75, 0x62, 0x6d, 0x6c, 0x75, 0x5a, 0x79, 0x49, 0x73, 0x49, 0x6d, 0x4e, 0x76,
+	0x62, 0x6e, 0x52, 0x68, 0x61, 0x57, 0x35, 0x6c, 0x63, 0x6c, 0x4e, 0x30, 0x59, 0x58, 0x52, 0x31,
+	0x63, 0x32, 0x56, 0x7a, 0x49, 0x6a, 0x70, 0x62, 0x65, 0x79, 0x4a, 0x75, 0x59, 0x57, 0x31, 0x6c,
+	0x49, 0x6a, 0x6f, 0x69, 0x61, 0x33, 0x56, 0x69, 0x5a, 0x53, 0x31, 0x77, 0x63, 0x6d, 0x39, 0x34,
+	0x65, 0x53, 0x49, 0x73, 0x49, 0x6e, 0x4e, 0x30, 0x59, 0x58, 0x52, 0x6c, 0x49, 0x6a, 0x70, 0x37,
+	0x49, 0x6e, 0x4a, 0x31, 0x62, 0x6d, 0x35, 0x70, 0x62, 0x6d, 0x63, 0x69, 0x4f, 0x6e, 0x73, 0x69,
+	0x63, 0x33, 0x52, 0x68, 0x63, 0x6e, 0x52, 0x6c, 0x5a, 0x45, 0x46, 0x30, 0x49, 0x6a, 0x6f, 0x69,
+	0x4d, 0x6a, 0x41, 0x79, 0x4e, 0x69, 0x30, 0x78, 0x4d, 0x43, 0x30, 0x78, 0x4e, 0x31, 0x51, 0x77,
+	0x4d, 0x7a, 0x6f, 0x30, 0x4f, 0x54, 0x6f, 0x79, 0x4f, 0x61, 0x57, 0x35, 0x6e, 0x49, 0x69, 0x77,
+	0x69, 0x63, 0x6d, 0x56, 0x7a, 0x62, 0x33, 0x56, 0x79, 0x59, 0x32, 0x56, 0x57, 0x5a, 0x58, 0x4a,
+	0x7a, 0x61, 0x57, 0x39, 0x75, 0x49, 0x6a, 0x6f, 0x69, 0x4f, 0x44, 0x6b, 0x79, 0x4d, 0x6a, 0x49,
+	0x79, 0x4d, 0x53, 0x49, 0x73, 0x49, 0x6d, 0x4e, 0x79, 0x5a, 0x57, 0x46, 0x30, 0x61, 0x57, 0x39,
+	0x75, 0x56, 0x47, 0x6c, 0x74, 0x5a, 0x58, 0x4e, 0x30, 0x59, 0x57, 0x31, 0x77, 0x49, 0x6a, 0x70,
+	0x75, 0x64, 0x57, 0x78, 0x73, 0x4c, 0x43, 0x4a, 0x73, 0x59, 0x57, 0x4a, 0x6c, 0x62, 0x48, 0x4d,
+	0x69, 0x4f, 0x6e, 0x73, 0x69, 0x59, 0x58, 0x42, 0x77, 0x49, 0x6a, 0x6f, 0x69, 0x62, 0x6d, 0x39,
+	0x6b, 0x5a, 0x53, 0x31, 0x6c, 0x65, 0x48, 0x42, 0x76, 0x63, 0x6e, 0x52, 0x6c, 0x63, 0x69, 0x49,
+	0x73, 0x49, 0x6d, 0x46, 0x77, 0x63, 0x43, 0x35, 0x72, 0x64, 0x57, 0x4a, 0x6c, 0x63, 0x6d, 0x35,
+	0x6c, 0x64, 0x47, 0x56, 0x7a, 0x4c, 0x6d, 0x6c, 0x76, 0x4c, 0x33, 0x42, 0x68, 0x63, 0x6e, 0x51,
+	0x74, 0x62, 0x32, 0x59, 0x69, 0x4f, 0x69, 0x4a, 0x76, 0x64, 0x47, 0x55, 0x69, 0x4c, 0x43, 0x4a,
+	0x6c, 0x5a, 0x47, 0x64, 0x6c, 0x4c, 0x58, 0x5a, 0x6c, 0x63, 0x6e, 0x4e, 0x70, 0x62, 0x32, 0x34,
+	0x69, 0x4f, 0x69, 0x49, 0x78, 0x4d, 0x54, 0x63, 0x33, 0x4e, 0x79, 0x49, 0x73, 0x49, 0x6d, 0x39,
+	0x30, 0x5a, 0x53, 0x31, 0x6a, 0x62, 0x48, 0x56, 0x7a, 0x64, 0x47, 0x56, 0x79, 0x49, 0x6a, 0x6f,
+	0x69, 0x5a, 0x57, 0x52, 0x6e, 0x5a, 0x53, 0x30, 0x31, 0x49, 0x69, 0x77, 0x69, 0x63, 0x47, 0x39,
+	0x6b, 0x4c, 0x58, 0x52, 0x6c, 0x62, 0x58, 0x42, 0x73, 0x59, 0x58, 0x52, 0x6c, 0x4c, 0x57, 0x68,
+	0x68, 0x63, 0x32, 0x67, 0x69, 0x4f, 0x69, 0x4a, 0x6d, 0x62, 0x47, 0x64, 0x78, 0x59, 0x6e, 0x64,
+	0x7a, 0x59, 0x33, 0x52, 0x72, 0x49, 0x6e, 0x31, 0x39, 0x4c, 0x43, 0x4a, 0x7a, 0x63, 0x47, 0x56,
+	0x6a, 0x49, 0x6a, 0x70, 0x37, 0x49, 0x6e, 0x4a, 0x6c, 0x63, 0x47, 0x78, 0x70, 0x59, 0x32, 0x46,
+	0x7a, 0x49, 0x6a, 0x6f, 0x78, 0x4c, 0x43, 0x4a, 0x7a, 0x5a, 0x57, 0x78, 0x6c, 0x59, 0x33, 0x52,
+	0x76, 0x63, 0x69, 0x49, 0x36, 0x65, 0x79, 0x4a, 0x74, 0x59, 0x58, 0x52, 0x6a, 0x61, 0x45, 0x78,
+	0x68, 0x59, 0x6d, 0x56, 0x73, 0x63, 0x79, 0x49, 0x36, 0x65, 0x79, 0x4a, 0x68, 0x63, 0x48, 0x41,
+	0x69, 0x4f, 0x69, 0x4a, 0x75, 0x62, 0x32, 0x52, 0x6c, 0x4c, 0x57, 0x56, 0x34, 0x63, 0x47, 0x39,
+	0x79, 0x64, 0x47, 0x56, 0x79, 0x49, 0x6e, 0x31, 0x39, 0x4c, 0x43, 0x4a, 0x30, 0x5a, 0x57, 0x31,
+	0x77, 0x62, 0x47, 0x46, 0x30, 0x5a, 0x53, 0x49, 0x36, 0x65, 0x79, 0x4a, 0x74, 0x5a, 0x58, 0x52,
+}